@@ -113,6 +113,39 @@ func IsClientToken(tok string) bool {
 	return strings.HasPrefix(tok, "xoxc-")
 }
 
+// TokenKind is the kind of the Slack token, determined by its prefix.
+type TokenKind string
+
+// Known token kinds.
+const (
+	TokenUnknown TokenKind = ""
+	TokenClient  TokenKind = "client" // xoxc-, web-client token, requires cookies
+	TokenUser    TokenKind = "user"   // xoxp-, OAuth user token
+	TokenBot     TokenKind = "bot"    // xoxb-, OAuth bot token
+	TokenApp     TokenKind = "app"    // xapp-, app-level token, can't call the Web API methods
+)
+
+// KindOf returns the kind of the token tok.
+func KindOf(tok string) TokenKind {
+	switch {
+	case IsClientToken(tok):
+		return TokenClient
+	case strings.HasPrefix(tok, "xoxp-"):
+		return TokenUser
+	case strings.HasPrefix(tok, "xoxb-"):
+		return TokenBot
+	case strings.HasPrefix(tok, "xapp-"):
+		return TokenApp
+	}
+	return TokenUnknown
+}
+
+// HasScopes returns true if the token of this kind is limited by the OAuth
+// scopes.
+func (k TokenKind) HasScopes() bool {
+	return k == TokenUser || k == TokenBot
+}
+
 // TestAuth attempts to authenticate with the given provider.  It will return
 // AuthError if failed.
 func (s simpleProvider) Test(ctx context.Context) error {
@@ -27,3 +27,21 @@ func (*Survey) RequestWorkspace(w io.Writer) (string, error) {
 }
 
 func (*Survey) Stop() {}
+
+func (*Survey) RequestCreds(w io.Writer, workspace string) (string, string, error) {
+	email, err := ui.String(
+		"Enter Email: ",
+		"HELP:\nThe email and password are used to login to "+workspace+" in the headless browser.\n"+
+			"Leave empty to login in the browser window.  If the workspace uses SSO, or 2FA\n"+
+			"is enabled, the browser window will open to complete the login.",
+	)
+	if err != nil || email == "" {
+		return "", "", err
+	}
+	password, err := ui.Password("Enter Password: ", "Slack password for "+email)
+	if err != nil {
+		return "", "", err
+	}
+	fmt.Fprintln(w, "Logging in...")
+	return email, password, nil
+}
@@ -25,6 +25,7 @@ type browserOpts struct {
 	browser      browser.Browser
 	flow         BrowserAuthUI
 	loginTimeout time.Duration
+	headless     bool
 }
 
 type BrowserAuthUI interface {
@@ -32,6 +33,13 @@ type BrowserAuthUI interface {
 	Stop()
 }
 
+// CredentialsUI is implemented by the BrowserAuthUI, that is able to request
+// the email and password for the headless login.  If the flow does not
+// implement it, the headless login is not attempted.
+type CredentialsUI interface {
+	RequestCreds(w io.Writer, workspace string) (email string, password string, err error)
+}
+
 func NewBrowserAuth(ctx context.Context, opts ...Option) (BrowserAuth, error) {
 	var br = BrowserAuth{
 		opts: browserOpts{
@@ -55,13 +63,24 @@ func NewBrowserAuth(ctx context.Context, opts ...Option) (BrowserAuth, error) {
 		}
 		defer br.opts.flow.Stop()
 	}
-	if wsp, err := sanitize(br.opts.workspace); err != nil {
+	if wsp, err := SanitizeWorkspace(br.opts.workspace); err != nil {
 		return br, err
 	} else {
 		br.opts.workspace = wsp
 	}
 
-	auther, err := browser.New(br.opts.workspace, browser.OptBrowser(br.opts.browser), browser.OptTimeout(br.opts.loginTimeout))
+	bopts := []browser.Option{browser.OptBrowser(br.opts.browser), browser.OptTimeout(br.opts.loginTimeout)}
+	if br.opts.headless {
+		if cui, ok := br.opts.flow.(CredentialsUI); ok {
+			email, password, err := cui.RequestCreds(os.Stdout, br.opts.workspace)
+			if err != nil {
+				return br, err
+			}
+			bopts = append(bopts, browser.OptHeadless(email, password))
+		}
+	}
+
+	auther, err := browser.New(br.opts.workspace, bopts...)
 	if err != nil {
 		return br, err
 	}
@@ -80,7 +99,10 @@ func (BrowserAuth) Type() Type {
 	return TypeBrowser
 }
 
-func sanitize(workspace string) (string, error) {
+// SanitizeWorkspace returns the workspace name from the workspace URL, i.e.
+// "https://evilcorp.slack.com" becomes "evilcorp".  The names are returned as
+// is.
+func SanitizeWorkspace(workspace string) (string, error) {
 	if !strings.Contains(workspace, ".slack.com") {
 		return workspace, nil
 	}
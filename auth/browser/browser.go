@@ -39,6 +39,21 @@ func OptTimeout(d time.Duration) Option {
 	}
 }
 
+// OptHeadless enables the headless login with the email and password.  If
+// the login requires interaction, i.e. the workspace uses SSO, or Slack asks
+// for the 2FA code, the visible browser is started instead.  If the email is
+// empty, the option is ignored.
+func OptHeadless(email, password string) Option {
+	return func(c *Client) {
+		if email == "" {
+			return
+		}
+		c.headless = true
+		c.email = email
+		c.password = password
+	}
+}
+
 func (e *Browser) Set(v string) error {
 	v = strings.ToLower(v)
 	for i := 0; i < len(_Browser_index)-1; i++ {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -19,14 +20,19 @@ import (
 const (
 	slackDomain    = ".slack.com"
 	requestTimeout = 600 * time.Second
+	// headlessTimeout is the time given to the headless login to complete,
+	// before falling back to the visible browser.
+	headlessTimeout = 30 * time.Second
 )
 
 // Client is the client for Browser Auth Provider.
 type Client struct {
 	workspace    string
-	pageClosed   chan bool // will receive a notification that the page is closed prematurely.
 	br           Browser
 	loginTimeout float64 // slack login page timeout in milliseconds.
+	headless     bool    // attempt the headless login first.
+	email        string  // email and password for the headless login.
+	password     string
 }
 
 var Logger logger.Interface = logger.Default
@@ -40,7 +46,6 @@ func New(workspace string, opts ...Option) (*Client, error) {
 	}
 	cl := &Client{
 		workspace:    strings.ToLower(workspace),
-		pageClosed:   make(chan bool, 1),
 		br:           Bfirefox,
 		loginTimeout: float64(DefLoginTimeout.Milliseconds()),
 	}
@@ -64,20 +69,37 @@ func (cl *Client) Authenticate(ctx context.Context) (string, []*http.Cookie, err
 	ctx, task := trace.NewTask(ctx, "Authenticate")
 	defer task.End()
 
-	var (
-		_s = playwright.String
-		_f = playwright.Float
-		_b = playwright.Bool
-	)
-
 	pw, err := playwright.Run()
 	if err != nil {
 		return "", nil, err
 	}
 	defer pw.Stop()
 
+	if cl.headless {
+		token, cookies, err := cl.authenticate(ctx, pw, true)
+		if err == nil {
+			return token, cookies, nil
+		}
+		if !errors.Is(err, ErrInteractionRequired) {
+			return "", nil, err
+		}
+		l().Printf("%s, opening the browser window to complete the login", err)
+	}
+	return cl.authenticate(ctx, pw, false)
+}
+
+// authenticate starts the browser, and waits for the user to login.  If
+// headless is true, the browser is started in the headless mode, and the
+// login form is filled with the email and password, see headlessLogin.
+func (cl *Client) authenticate(ctx context.Context, pw *playwright.Playwright, headless bool) (string, []*http.Cookie, error) {
+	var (
+		_s = playwright.String
+		_f = playwright.Float
+		_b = playwright.Bool
+	)
+
 	opts := playwright.BrowserTypeLaunchOptions{
-		Headless: _b(false),
+		Headless: _b(headless),
 	}
 
 	browser, err := cl.br.client(pw).Launch(opts)
@@ -110,22 +132,29 @@ func (cl *Client) Authenticate(ctx context.Context) (string, []*http.Cookie, err
 		return "", nil, err
 	}
 	// page close sentinel.
-	page.On("close", func() { trace.Log(ctx, "user", "page closed"); close(cl.pageClosed) })
+	pageClosed := make(chan bool)
+	page.On("close", func() { trace.Log(ctx, "user", "page closed"); close(pageClosed) })
 
 	uri := fmt.Sprintf("https://%s"+slackDomain, cl.workspace)
-	l().Debugf("opening browser URL=%s", uri)
+	l().Debugf("opening browser URL=%s, headless=%v", uri, headless)
 
 	if _, err := page.Goto(uri); err != nil {
 		return "", nil, err
 	}
 
 	var r playwright.Request
-	if err := cl.withBrowserGuard(ctx, func() error {
-		r, err = page.ExpectRequest(uri+"/api/api.features*", func() error { return nil }, playwright.PageExpectRequestOptions{
-			Timeout: &cl.loginTimeout,
+	if headless {
+		r, err = cl.headlessLogin(ctx, page, pageClosed, uri)
+	} else {
+		err = withBrowserGuard(ctx, pageClosed, func() error {
+			var err error
+			r, err = page.ExpectRequest(uri+"/api/api.features*", func() error { return nil }, playwright.PageExpectRequestOptions{
+				Timeout: &cl.loginTimeout,
+			})
+			return err
 		})
-		return err
-	}); err != nil {
+	}
+	if err != nil {
 		return "", nil, err
 	}
 
@@ -145,12 +174,64 @@ func (cl *Client) Authenticate(ctx context.Context) (string, []*http.Cookie, err
 	return token, convertCookies(state.Cookies), nil
 }
 
-var ErrBrowserClosed = errors.New("browser closed or timed out")
+// login form selectors.
+const (
+	selEmail    = "input#email"
+	selPassword = "input#password"
+	selSignIn   = "button#signin_btn"
+)
+
+// headlessLogin fills the email and password login form on the page, and
+// waits for the login to complete.  If the form is not found, i.e. the
+// workspace uses SSO, or the login does not complete within the
+// headlessTimeout, i.e. Slack requests the 2FA code, it returns
+// ErrInteractionRequired.
+func (cl *Client) headlessLogin(ctx context.Context, page playwright.Page, pageClosed <-chan bool, uri string) (playwright.Request, error) {
+	timeout := math.Min(cl.loginTimeout, float64(headlessTimeout.Milliseconds()))
+
+	if _, err := page.WaitForSelector(selEmail, playwright.PageWaitForSelectorOptions{Timeout: &timeout}); err != nil {
+		if errors.Is(err, playwright.TimeoutError) {
+			return nil, fmt.Errorf("%w: email login form not found", ErrInteractionRequired)
+		}
+		return nil, err
+	}
+	if err := page.Fill(selEmail, cl.email); err != nil {
+		return nil, err
+	}
+	if err := page.Fill(selPassword, cl.password); err != nil {
+		return nil, err
+	}
+
+	var r playwright.Request
+	err := withBrowserGuard(ctx, pageClosed, func() error {
+		var err error
+		r, err = page.ExpectRequest(uri+"/api/api.features*", func() error {
+			return page.Click(selSignIn)
+		}, playwright.PageExpectRequestOptions{
+			Timeout: &timeout,
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, playwright.TimeoutError) {
+			return nil, fmt.Errorf("%w: login has not completed, Slack may be asking for the 2FA code", ErrInteractionRequired)
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+var (
+	ErrBrowserClosed = errors.New("browser closed or timed out")
+	// ErrInteractionRequired is returned by the headless login, if the
+	// login can't be completed without the user.
+	ErrInteractionRequired = errors.New("login requires interaction")
+)
 
 // withBrowserGuard starts the function fn in a goroutine, and waits for it to
 // finish.  If the context is canceled, or the page is closed, it returns
 // the appropriate error.
-func (cl *Client) withBrowserGuard(ctx context.Context, fn func() error) error {
+func withBrowserGuard(ctx context.Context, pageClosed <-chan bool, fn func() error) error {
 	var errC = make(chan error, 1)
 	go func() {
 		defer close(errC)
@@ -159,7 +240,7 @@ func (cl *Client) withBrowserGuard(ctx context.Context, fn func() error) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case <-cl.pageClosed:
+	case <-pageClosed:
 		return ErrBrowserClosed
 	case err := <-errC:
 		return err
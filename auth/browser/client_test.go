@@ -106,3 +106,24 @@ func Test_pwIsKnownProblem(t *testing.T) {
 		}
 	})
 }
+
+func TestOptHeadless(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		want     Client
+	}{
+		{"credentials", "me@example.com", "secret", Client{headless: true, email: "me@example.com", password: "secret"}},
+		{"empty email", "", "secret", Client{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Client
+			OptHeadless(tt.email, tt.password)(&got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OptHeadless() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package auth
 
 import "testing"
 
-func Test_sanitize(t *testing.T) {
+func TestSanitizeWorkspace(t *testing.T) {
 	type args struct {
 		workspace string
 	}
@@ -20,13 +20,13 @@ func Test_sanitize(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := sanitize(tt.args.workspace)
+			got, err := SanitizeWorkspace(tt.args.workspace)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("sanitize() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("SanitizeWorkspace() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if got != tt.want {
-				t.Errorf("sanitize() got = %v, want %v", got, tt.want)
+				t.Errorf("SanitizeWorkspace() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -42,3 +42,12 @@ func BrowserWithTimeout(d time.Duration) Option {
 		o.browserOpts.loginTimeout = d
 	}
 }
+
+// BrowserWithHeadless enables the headless login.  The email and password are
+// requested from the user, if the auth flow implements CredentialsUI.  If the
+// login requires interaction, the visible browser is started.
+func BrowserWithHeadless(b bool) Option {
+	return func(o *options) {
+		o.browserOpts.headless = b
+	}
+}
@@ -1,6 +1,12 @@
 package slackdump
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
 
 // AuthError is the error returned by New, the underlying Err contains
 // an API error returned by slack.AuthTest call.
@@ -19,3 +25,31 @@ func (ae *AuthError) Unwrap() error {
 func (ae *AuthError) Is(target error) bool {
 	return target == ae.Err
 }
+
+// fatalErrors are the Slack API errors, that affect all requests, not just
+// the single conversation.
+var fatalErrors = []string{"invalid_auth", "not_authed", "account_inactive", "token_revoked", "token_expired", "org_login_required", "ekm_access_denied"}
+
+// IsFatalError returns true, if the error err is not specific to the
+// conversation being processed, i.e. the authentication error, or the
+// cancelled context, so that there's no point in continuing with the other
+// conversations.
+func IsFatalError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var ae *AuthError
+	if errors.As(err, &ae) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ser slack.SlackErrorResponse
+	if !errors.As(err, &ser) {
+		return false
+	}
+	for _, fe := range fatalErrors {
+		if ser.Err == fe {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,12 @@
 package slackdump
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/slack-go/slack"
 )
 
 var errSample = errors.New("test error")
@@ -77,3 +80,26 @@ func TestAuthError_Is(t *testing.T) {
 		})
 	}
 }
+
+func TestIsFatalError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"invalid auth", fmt.Errorf("error: %w", slack.SlackErrorResponse{Err: "invalid_auth"}), true},
+		{"token revoked", slack.SlackErrorResponse{Err: "token_revoked"}, true},
+		{"auth error", &AuthError{Err: errSample}, true},
+		{"cancelled", fmt.Errorf("error: %w", context.Canceled), true},
+		{"channel not found", fmt.Errorf("error: %w", slack.SlackErrorResponse{Err: "channel_not_found"}), false},
+		{"other error", errSample, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsFatalError(tt.err); got != tt.want {
+				t.Errorf("IsFatalError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
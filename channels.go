@@ -4,22 +4,40 @@ package slackdump
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
 	"runtime/trace"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/slack-go/slack"
 
+	"github.com/rusq/slackdump/v2/internal/encio"
 	"github.com/rusq/slackdump/v2/internal/network"
 	"github.com/rusq/slackdump/v2/types"
 )
 
+// ListChannels returns all conversations of chanTypes, that are visible to
+// the user, handling the pagination.  If chanTypes is empty, AllChanTypes
+// are listed.  Archived channels are included, unless the ExcludeArchived
+// option is set.  Channels are loaded from the channel cache, if it is
+// enabled and valid.  For very large workspaces, consider using
+// StreamChannels, that calls the callback as the channels are received.
+func (sd *Session) ListChannels(ctx context.Context, chanTypes []string) ([]slack.Channel, error) {
+	return sd.GetChannels(ctx, chanTypes...)
+}
+
 // GetChannels list all conversations for a user.  `chanTypes` specifies the
 // type of messages to fetch.  See github.com/rusq/slack docs for possible
 // values.  If large number of channels is to be returned, consider using
 // StreamChannels.
 func (sd *Session) GetChannels(ctx context.Context, chanTypes ...string) (types.Channels, error) {
 	var allChannels types.Channels
-	if err := sd.getChannels(ctx, chanTypes, func(cc types.Channels) error {
+	if err := sd.cachedChannels(ctx, chanTypes, func(cc types.Channels) error {
 		allChannels = append(allChannels, cc...)
 		return nil
 	}); err != nil {
@@ -28,10 +46,11 @@ func (sd *Session) GetChannels(ctx context.Context, chanTypes ...string) (types.
 	return allChannels, nil
 }
 
-// StreamChannels requests the channels from the API and calls the callback
-// function cb for each.
+// StreamChannels requests the channels from the API, or loads them from the
+// cache, and calls the callback function cb for each.  If cb returns an
+// error, the listing stops and the error is returned.
 func (sd *Session) StreamChannels(ctx context.Context, chanTypes []string, cb func(ch slack.Channel) error) error {
-	return sd.getChannels(ctx, chanTypes, func(chans types.Channels) error {
+	return sd.cachedChannels(ctx, chanTypes, func(chans types.Channels) error {
 		for _, ch := range chans {
 			if err := cb(ch); err != nil {
 				return err
@@ -41,6 +60,131 @@ func (sd *Session) StreamChannels(ctx context.Context, chanTypes []string, cb fu
 	})
 }
 
+// cachedChannels calls cb with the channels from the cache, if it is valid,
+// otherwise, it fetches the channels from the API and saves them to the cache
+// once all of them are fetched.
+func (sd *Session) cachedChannels(ctx context.Context, chanTypes []string, cb func(types.Channels) error) error {
+	ctx, task := trace.NewTask(ctx, "cachedChannels")
+	defer task.End()
+
+	if len(chanTypes) == 0 {
+		chanTypes = AllChanTypes
+	}
+	if sd.options.NoChannelCache {
+		return sd.getChannels(ctx, chanTypes, cb)
+	}
+
+	chans, err := sd.loadChannelCache(sd.options.ChannelCacheFilename, sd.wspInfo.TeamID, chanTypes, sd.options.MaxChannelCacheAge)
+	if err == nil {
+		sd.l().Printf("loaded %d channels from cache", len(chans))
+		return cb(chans)
+	}
+	if os.IsNotExist(err) {
+		sd.l().Println("  caching channels for the first time")
+	} else {
+		sd.l().Printf("  %s: it will be recreated.", err)
+	}
+
+	var fetched types.Channels
+	if err := sd.getChannels(ctx, chanTypes, func(cc types.Channels) error {
+		fetched = append(fetched, cc...)
+		return cb(cc)
+	}); err != nil {
+		return err
+	}
+	if err := sd.saveChannelCache(sd.options.ChannelCacheFilename, sd.wspInfo.TeamID, chanTypes, fetched); err != nil {
+		trace.Logf(ctx, "error", "saving channel cache to %q, error: %s", sd.options.ChannelCacheFilename, err)
+		sd.l().Printf("error saving channel cache to %q: %s, but nevermind, let's continue", sd.options.ChannelCacheFilename, err)
+	}
+	return nil
+}
+
+// channelCacheHeader is the first record of the channel cache file.  The
+// cache is valid only for the same set of channel types, and the same
+// setting of the archived channels exclusion.
+type channelCacheHeader struct {
+	ChanTypes       []string `json:"chan_types"`
+	ExcludeArchived bool     `json:"exclude_archived,omitempty"`
+}
+
+var (
+	// errCacheChanTypes is returned, if the channel cache was created for a
+	// different set of channel types.
+	errCacheChanTypes = errors.New("channel cache has different channel types")
+	// errCacheArchived is returned, if the channel cache was created with a
+	// different setting of the archived channels exclusion.
+	errCacheArchived = errors.New("channel cache has different archived channels setting")
+)
+
+// loadChannelCache loads the channels of chanTypes from the cache file.
+func (sd *Session) loadChannelCache(filename string, suffix string, chanTypes []string, maxAge time.Duration) (types.Channels, error) {
+	filename = sd.makeCacheFilename(filename, suffix)
+
+	if err := checkCacheFile(filename, maxAge); err != nil {
+		return nil, err
+	}
+
+	f, err := encio.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	var hdr channelCacheHeader
+	if err := dec.Decode(&hdr); err != nil {
+		return nil, fmt.Errorf("failed to decode channels from %s: %w", filename, err)
+	}
+	if chanTypesKey(hdr.ChanTypes) != chanTypesKey(chanTypes) {
+		return nil, errCacheChanTypes
+	}
+	if hdr.ExcludeArchived != sd.options.ExcludeArchived {
+		return nil, errCacheArchived
+	}
+	var cc types.Channels
+	for {
+		var ch slack.Channel
+		if err := dec.Decode(&ch); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode channels from %s: %w", filename, err)
+		}
+		cc = append(cc, ch)
+	}
+	return cc, nil
+}
+
+// saveChannelCache saves the channels of chanTypes to the cache file.
+func (sd *Session) saveChannelCache(filename string, suffix string, chanTypes []string, cc types.Channels) error {
+	filename = sd.makeCacheFilename(filename, suffix)
+
+	f, err := encio.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	if err := enc.Encode(channelCacheHeader{ChanTypes: chanTypes, ExcludeArchived: sd.options.ExcludeArchived}); err != nil {
+		return fmt.Errorf("failed to encode data for %s: %w", filename, err)
+	}
+	for _, ch := range cc {
+		if err := enc.Encode(ch); err != nil {
+			return fmt.Errorf("failed to encode data for %s: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+// chanTypesKey returns the order-independent representation of chanTypes.
+func chanTypesKey(chanTypes []string) string {
+	sorted := make([]string, len(chanTypes))
+	copy(sorted, chanTypes)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
 // getChannels list all conversations for a user.  `chanTypes` specifies
 // the type of messages to fetch.  See github.com/rusq/slack docs for possible
 // values
@@ -48,13 +192,18 @@ func (sd *Session) getChannels(ctx context.Context, chanTypes []string, cb func(
 	ctx, task := trace.NewTask(ctx, "getChannels")
 	defer task.End()
 
-	limiter := network.NewLimiter(network.Tier2, sd.options.Tier2Burst, int(sd.options.Tier2Boost))
+	limiter := sd.limiter(network.Tier2)
 
-	if chanTypes == nil {
+	if len(chanTypes) == 0 {
 		chanTypes = AllChanTypes
 	}
 
-	params := &slack.GetConversationsParameters{Types: chanTypes, Limit: sd.options.ChannelsPerReq}
+	params := &slack.GetConversationsParameters{
+		Types:           chanTypes,
+		Limit:           sd.options.ChannelsPerReq,
+		ExcludeArchived: sd.options.ExcludeArchived,
+		TeamID:          sd.gridTeamID(),
+	}
 	fetchStart := time.Now()
 	var total int
 	for i := 1; ; i++ {
@@ -125,3 +274,50 @@ func (sd *Session) GetChannelMembers(ctx context.Context, channelID string) ([]s
 	}
 	return ids, nil
 }
+
+// CanvasTypes is the files.list type filter of the canvases and lists.
+const CanvasTypes = "canvas,list"
+
+// GetChannelCanvases returns the canvases and lists, that are shared in the
+// channel.  Canvases and lists are files, the content can be downloaded
+// the same way as the content of any other file.  Canvases may not be
+// available on all plans, in which case the list is empty.
+func (sd *Session) GetChannelCanvases(ctx context.Context, channelID string) ([]slack.File, error) {
+	var ff []slack.File
+	params := slack.NewGetFilesParameters()
+	params.Channel = channelID
+	params.Types = CanvasTypes
+	for {
+		var (
+			page   []slack.File
+			paging *slack.Paging
+		)
+		if err := network.WithRetry(ctx, sd.limiter(network.Tier3), sd.options.Tier3Retries, func() error {
+			var err error
+			page, paging, err = sd.client.GetFilesContext(ctx, params)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		ff = append(ff, page...)
+
+		if paging == nil || paging.Page >= paging.Pages {
+			break
+		}
+		params.Page = paging.Page + 1
+	}
+	return ff, nil
+}
+
+// GetChannelPins returns the items pinned in the channel.
+func (sd *Session) GetChannelPins(ctx context.Context, channelID string) ([]slack.Item, error) {
+	var items []slack.Item
+	if err := network.WithRetry(ctx, sd.limiter(network.Tier2), sd.options.Tier2Retries, func() error {
+		var err error
+		items, _, err = sd.client.ListPinsContext(ctx, channelID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
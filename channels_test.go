@@ -5,6 +5,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/rusq/slackdump/v2/fsadapter"
@@ -16,6 +17,7 @@ import (
 
 func TestSession_getChannels(t *testing.T) {
 	type fields struct {
+		wspInfo   *slack.AuthTestResponse
 		Users     types.Users
 		UserIndex structures.UserIndex
 		options   Options
@@ -55,6 +57,32 @@ func TestSession_getChannels(t *testing.T) {
 			}}},
 			false,
 		},
+		{
+			"enterprise grid, team ID is passed",
+			fields{wspInfo: &slack.AuthTestResponse{TeamID: "T01", EnterpriseID: "E01"}, options: DefOptions},
+			args{
+				context.Background(),
+				AllChanTypes,
+			},
+			func(mc *mockClienter) {
+				mc.EXPECT().GetConversationsContext(gomock.Any(), &slack.GetConversationsParameters{
+					Limit:  DefOptions.ChannelsPerReq,
+					Types:  AllChanTypes,
+					TeamID: "T01",
+				}).Return(types.Channels{
+					slack.Channel{GroupConversation: slack.GroupConversation{
+						Conversation: slack.Conversation{IsOrgShared: true},
+						Name:         "shared",
+					}}},
+					"",
+					nil)
+			},
+			types.Channels{slack.Channel{GroupConversation: slack.GroupConversation{
+				Conversation: slack.Conversation{IsOrgShared: true},
+				Name:         "shared",
+			}}},
+			false,
+		},
 		{
 			"function made a boo boo",
 			fields{options: DefOptions},
@@ -80,6 +108,7 @@ func TestSession_getChannels(t *testing.T) {
 			mc := newmockClienter(gomock.NewController(t))
 			sd := &Session{
 				client:    mc,
+				wspInfo:   tt.fields.wspInfo,
 				Users:     tt.fields.Users,
 				UserIndex: tt.fields.UserIndex,
 				options:   tt.fields.options,
@@ -236,3 +265,181 @@ func TestSession_GetChannelMembers(t *testing.T) {
 		})
 	}
 }
+
+func TestSession_GetChannelPins(t *testing.T) {
+	items := []slack.Item{
+		slack.NewMessageItem("chanID", &slack.Message{Msg: slack.Msg{Timestamp: "1645095600.000100", User: "user1"}}),
+		slack.NewFileItem(&slack.File{ID: "F01", User: "user2"}),
+	}
+	tests := []struct {
+		name    string
+		expect  func(mc *mockClienter)
+		want    []slack.Item
+		wantErr bool
+	}{
+		{
+			"ok",
+			func(mc *mockClienter) {
+				mc.EXPECT().ListPinsContext(gomock.Any(), "chanID").Return(items, &slack.Paging{}, nil)
+			},
+			items,
+			false,
+		},
+		{
+			"error",
+			func(mc *mockClienter) {
+				mc.EXPECT().ListPinsContext(gomock.Any(), "chanID").Return(nil, nil, errors.New("pin failed"))
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := newmockClienter(gomock.NewController(t))
+			tt.expect(mc)
+			sd := &Session{client: mc, options: DefOptions}
+			got, err := sd.GetChannelPins(context.Background(), "chanID")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Session.GetChannelPins() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Session.GetChannelPins() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_GetChannelCanvases(t *testing.T) {
+	params := func(page int) slack.GetFilesParameters {
+		p := slack.NewGetFilesParameters()
+		p.Channel = "chanID"
+		p.Types = CanvasTypes
+		p.Page = page
+		return p
+	}
+	tests := []struct {
+		name    string
+		expect  func(mc *mockClienter)
+		want    []slack.File
+		wantErr bool
+	}{
+		{
+			"several pages",
+			func(mc *mockClienter) {
+				mc.EXPECT().GetFilesContext(gomock.Any(), params(1)).Return([]slack.File{{ID: "F01"}}, &slack.Paging{Page: 1, Pages: 2}, nil)
+				mc.EXPECT().GetFilesContext(gomock.Any(), params(2)).Return([]slack.File{{ID: "F02"}}, &slack.Paging{Page: 2, Pages: 2}, nil)
+			},
+			[]slack.File{{ID: "F01"}, {ID: "F02"}},
+			false,
+		},
+		{
+			"no canvases",
+			func(mc *mockClienter) {
+				mc.EXPECT().GetFilesContext(gomock.Any(), params(1)).Return(nil, &slack.Paging{Page: 1, Pages: 0}, nil)
+			},
+			nil,
+			false,
+		},
+		{
+			"error",
+			func(mc *mockClienter) {
+				mc.EXPECT().GetFilesContext(gomock.Any(), params(1)).Return(nil, nil, errors.New("files failed"))
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := newmockClienter(gomock.NewController(t))
+			tt.expect(mc)
+			sd := &Session{client: mc, options: DefOptions}
+			got, err := sd.GetChannelCanvases(context.Background(), "chanID")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Session.GetChannelCanvases() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Session.GetChannelCanvases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_GetChannels_cache(t *testing.T) {
+	testChans := types.Channels{
+		slack.Channel{GroupConversation: slack.GroupConversation{Name: "lol"}},
+	}
+	newSession := func(t *testing.T, opts Options) (*Session, *mockClienter) {
+		mc := newmockClienter(gomock.NewController(t))
+		opts.ChannelCacheFilename = gimmeTempFile(t, t.TempDir())
+		opts.MaxChannelCacheAge = 5 * time.Hour
+		opts.Tier2Burst = 1
+		opts.ChannelsPerReq = 100
+		return &Session{
+			client:  mc,
+			wspInfo: &slack.AuthTestResponse{TeamID: testSuffix},
+			options: opts,
+		}, mc
+	}
+	expectCall := func(mc *mockClienter, chanTypes []string) *gomock.Call {
+		return mc.EXPECT().GetConversationsContext(gomock.Any(), &slack.GetConversationsParameters{
+			Limit: 100,
+			Types: chanTypes,
+		}).Return([]slack.Channel(testChans), "", nil)
+	}
+	t.Run("second call is served from cache", func(t *testing.T) {
+		sd, mc := newSession(t, Options{})
+		expectCall(mc, AllChanTypes).Times(1)
+
+		for i := 0; i < 2; i++ {
+			got, err := sd.GetChannels(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, testChans, got)
+		}
+	})
+	t.Run("different channel types invalidate cache", func(t *testing.T) {
+		sd, mc := newSession(t, Options{})
+		expectCall(mc, AllChanTypes).Times(1)
+		expectCall(mc, []string{"im"}).Times(1)
+
+		_, err := sd.GetChannels(context.Background())
+		assert.NoError(t, err)
+		_, err = sd.GetChannels(context.Background(), "im")
+		assert.NoError(t, err)
+	})
+	t.Run("archived exclusion invalidates cache", func(t *testing.T) {
+		sd, mc := newSession(t, Options{})
+		expectCall(mc, AllChanTypes).Times(1)
+		mc.EXPECT().GetConversationsContext(gomock.Any(), &slack.GetConversationsParameters{
+			Limit:           100,
+			Types:           AllChanTypes,
+			ExcludeArchived: true,
+		}).Return([]slack.Channel(testChans), "", nil).Times(1)
+
+		_, err := sd.ListChannels(context.Background(), nil)
+		assert.NoError(t, err)
+		sd.options.ExcludeArchived = true
+		for i := 0; i < 2; i++ {
+			got, err := sd.ListChannels(context.Background(), nil)
+			assert.NoError(t, err)
+			assert.Equal(t, []slack.Channel(testChans), got)
+		}
+	})
+	t.Run("cache disabled", func(t *testing.T) {
+		sd, mc := newSession(t, Options{NoChannelCache: true})
+		expectCall(mc, AllChanTypes).Times(2)
+
+		for i := 0; i < 2; i++ {
+			_, err := sd.GetChannels(context.Background())
+			assert.NoError(t, err)
+		}
+	})
+}
+
+func Test_chanTypesKey(t *testing.T) {
+	assert.Equal(t, chanTypesKey([]string{"im", "mpim"}), chanTypesKey([]string{"mpim", "im"}))
+	assert.NotEqual(t, chanTypesKey([]string{"im"}), chanTypesKey([]string{"im", "mpim"}))
+}
@@ -0,0 +1,211 @@
+package slackdump
+
+// In this file: the checkpoints, that allow to resume the interrupted
+// conversation dumps.
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// checkpoint is the state of the interrupted conversation dump.
+type checkpoint struct {
+	Cursor   string    `json:"cursor"`    // next_cursor of the next request
+	OldestTS string    `json:"oldest_ts"` // timestamp of the oldest processed message
+	Size     int64     `json:"size"`      // size of the messages file, that matches the cursor
+	Oldest   time.Time `json:"oldest"`    // oldest and latest of the dump, the checkpoint
+	Latest   time.Time `json:"latest"`    // is only used if they match.
+	Updated  time.Time `json:"updated"`
+}
+
+// checkpoints holds the checkpoints of the conversations.  The checkpoints
+// are saved to the checkpoint file, and the messages fetched so far, to the
+// messages file of each conversation, see msgFilename.  The nil checkpoints
+// do nothing.
+type checkpoints struct {
+	filename string
+
+	mu       sync.Mutex
+	Channels map[string]*checkpoint `json:"channels"`
+}
+
+// loadCheckpoints loads the checkpoints from the file.  If the file does not
+// exist, empty checkpoints are returned.
+func loadCheckpoints(filename string) (*checkpoints, error) {
+	cps := &checkpoints{filename: filename, Channels: make(map[string]*checkpoint)}
+	f, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cps, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(cps); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %w", filename, err)
+	}
+	if cps.Channels == nil {
+		cps.Channels = make(map[string]*checkpoint)
+	}
+	return cps, nil
+}
+
+// msgFilename returns the name of the messages file of the channel.
+func (cps *checkpoints) msgFilename(channelID string) string {
+	return cps.filename + "." + channelID + ".jsonl"
+}
+
+// resume returns the cursor and the messages of the interrupted dump of the
+// channel.  If there's no checkpoint for the channel, or it was made by the
+// dump with a different time frame, the empty cursor and no messages are
+// returned.
+func (cps *checkpoints) resume(channelID string, oldest, latest time.Time) (string, []types.Message, error) {
+	if cps == nil {
+		return "", nil, nil
+	}
+	cps.mu.Lock()
+	cp, ok := cps.Channels[channelID]
+	cps.mu.Unlock()
+	if !ok {
+		return "", nil, nil
+	}
+	if !cp.Oldest.Equal(oldest) || !cp.Latest.Equal(latest) {
+		// different time frame, start over.
+		return "", nil, cps.remove(channelID)
+	}
+	msgs, err := readCheckpointMsgs(cps.msgFilename(channelID), cp.Size)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// messages are lost, start over.
+			return "", nil, cps.remove(channelID)
+		}
+		return "", nil, err
+	}
+	return cp.Cursor, msgs, nil
+}
+
+// readCheckpointMsgs reads the messages from the messages file, truncating it
+// to size first, as the messages, that were written after the last
+// checkpoint, will be fetched again.
+func readCheckpointMsgs(filename string, size int64) ([]types.Message, error) {
+	if err := os.Truncate(filename, size); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var msgs []types.Message
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var m types.Message
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("invalid checkpoint messages file %s: %w", filename, err)
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs, nil
+}
+
+// save appends the chunk of messages to the messages file of the channel,
+// and saves the checkpoint with the cursor of the next request.
+func (cps *checkpoints) save(channelID string, chunk []types.Message, cursor string, oldest, latest time.Time) error {
+	if cps == nil {
+		return nil
+	}
+	f, err := os.OpenFile(cps.msgFilename(channelID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for i := range chunk {
+		if err := enc.Encode(chunk[i]); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	cp := &checkpoint{
+		Cursor:  cursor,
+		Size:    fi.Size(),
+		Oldest:  oldest,
+		Latest:  latest,
+		Updated: time.Now(),
+	}
+	if len(chunk) > 0 {
+		// messages are returned newest first.
+		cp.OldestTS = chunk[len(chunk)-1].Timestamp
+	}
+
+	cps.mu.Lock()
+	defer cps.mu.Unlock()
+	cps.Channels[channelID] = cp
+	return cps.write()
+}
+
+// remove removes the checkpoint of the channel and its messages file.
+func (cps *checkpoints) remove(channelID string) error {
+	if cps == nil {
+		return nil
+	}
+	if err := os.Remove(cps.msgFilename(channelID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	cps.mu.Lock()
+	defer cps.mu.Unlock()
+	if _, ok := cps.Channels[channelID]; !ok {
+		return nil
+	}
+	delete(cps.Channels, channelID)
+	if len(cps.Channels) == 0 {
+		if err := os.Remove(cps.filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return cps.write()
+}
+
+// write writes the checkpoint file atomically, by writing to the temporary
+// file and renaming it.  It must be called with the mutex held.
+func (cps *checkpoints) write() error {
+	f, err := os.CreateTemp(filepath.Dir(cps.filename), filepath.Base(cps.filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(cps); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), cps.filename); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
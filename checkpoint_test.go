@@ -0,0 +1,218 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func Test_checkpoints(t *testing.T) {
+	var (
+		oldest = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		latest = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+	t.Run("save and resume", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "checkpoint.json")
+		cps, err := loadCheckpoints(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cps.save("C01", []types.Message{testMsg3, testMsg2}, "cur1", oldest, latest); err != nil {
+			t.Fatal(err)
+		}
+		if err := cps.save("C01", []types.Message{testMsg1}, "cur2", oldest, latest); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, testMsg1.Timestamp, cps.Channels["C01"].OldestTS)
+
+		// messages written after the last checkpoint are discarded.
+		f, err := os.OpenFile(cps.msgFilename("C01"), os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(`{"text":"partial"`)
+		f.Close()
+
+		// new run
+		cps, err = loadCheckpoints(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cursor, msgs, err := cps.resume("C01", oldest, latest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "cur2", cursor)
+		assert.Equal(t, []types.Message{testMsg3, testMsg2, testMsg1}, msgs)
+
+		// unknown channel
+		cursor, msgs, err = cps.resume("C02", oldest, latest)
+		assert.NoError(t, err)
+		assert.Empty(t, cursor)
+		assert.Empty(t, msgs)
+
+		if err := cps.remove("C01"); err != nil {
+			t.Fatal(err)
+		}
+		assert.NoFileExists(t, filename)
+		assert.NoFileExists(t, cps.msgFilename("C01"))
+	})
+	t.Run("different time frame starts over", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "checkpoint.json")
+		cps, _ := loadCheckpoints(filename)
+		if err := cps.save("C01", []types.Message{testMsg1}, "cur1", oldest, latest); err != nil {
+			t.Fatal(err)
+		}
+		cursor, msgs, err := cps.resume("C01", time.Time{}, latest)
+		assert.NoError(t, err)
+		assert.Empty(t, cursor)
+		assert.Empty(t, msgs)
+		assert.NoFileExists(t, cps.msgFilename("C01"))
+	})
+	t.Run("other channels are kept", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "checkpoint.json")
+		cps, _ := loadCheckpoints(filename)
+		cps.save("C01", []types.Message{testMsg1}, "cur1", oldest, latest)
+		cps.save("C02", []types.Message{testMsg2}, "cur2", oldest, latest)
+		if err := cps.remove("C01"); err != nil {
+			t.Fatal(err)
+		}
+		cps, err := loadCheckpoints(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, cps.Channels, 1)
+		assert.Equal(t, "cur2", cps.Channels["C02"].Cursor)
+	})
+	t.Run("nil checkpoints", func(t *testing.T) {
+		var cps *checkpoints
+		cursor, msgs, err := cps.resume("C01", oldest, latest)
+		assert.NoError(t, err)
+		assert.Empty(t, cursor)
+		assert.Empty(t, msgs)
+		assert.NoError(t, cps.save("C01", []types.Message{testMsg1}, "cur", oldest, latest))
+		assert.NoError(t, cps.remove("C01"))
+	})
+	t.Run("invalid file", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "checkpoint.json")
+		os.WriteFile(filename, []byte("not json"), 0600)
+		_, err := loadCheckpoints(filename)
+		assert.Error(t, err)
+	})
+}
+
+func TestSession_dumpChannel_checkpoint(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "checkpoint.json")
+	params := func(cursor string) *slack.GetConversationHistoryParameters {
+		return &slack.GetConversationHistoryParameters{
+			ChannelID: "CHANNEL",
+			Cursor:    cursor,
+			Limit:     DefOptions.ConversationsPerReq,
+			Inclusive: true,
+		}
+	}
+	page := func(hasMore bool, cursor string, msgs ...types.Message) *slack.GetConversationHistoryResponse {
+		resp := &slack.GetConversationHistoryResponse{
+			HasMore:       hasMore,
+			SlackResponse: slack.SlackResponse{Ok: true},
+		}
+		resp.ResponseMetaData.NextCursor = cursor
+		for _, m := range msgs {
+			resp.Messages = append(resp.Messages, m.Message)
+		}
+		return resp
+	}
+
+	// first run fails on the second page.
+	mc := newmockClienter(gomock.NewController(t))
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), params("")).Return(page(true, "cur", testMsg3), nil)
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), params("cur")).Return(nil, errors.New("connection reset"))
+	mockConvInfo(mc, "CHANNEL", "channel_name")
+
+	cps, err := loadCheckpoints(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd := &Session{client: mc, options: DefOptions, cps: cps}
+	if _, err := sd.dumpChannel(context.Background(), "CHANNEL", time.Time{}, time.Time{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	assert.FileExists(t, filename)
+
+	// second run continues from the cursor.
+	mc = newmockClienter(gomock.NewController(t))
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), params("cur")).Return(page(false, "", testMsg2, testMsg1), nil)
+	mockConvInfo(mc, "CHANNEL", "channel_name")
+
+	cps, err = loadCheckpoints(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd = &Session{client: mc, options: DefOptions, cps: cps}
+	got, err := sd.dumpChannel(context.Background(), "CHANNEL", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []types.Message{testMsg1, testMsg2, testMsg3}, got.Messages)
+	assert.NoFileExists(t, filename, "checkpoint must be removed on completion")
+	assert.NoFileExists(t, cps.msgFilename("CHANNEL"))
+}
+
+func TestSession_dumpChannel_checkpointLimit(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "checkpoint.json")
+	params := func(cursor string) *slack.GetConversationHistoryParameters {
+		return &slack.GetConversationHistoryParameters{
+			ChannelID: "CHANNEL",
+			Cursor:    cursor,
+			Limit:     DefOptions.ConversationsPerReq,
+			Inclusive: true,
+		}
+	}
+	limited := DefOptions
+	limited.MessageLimit = 2
+	limited.SkipThreads = true
+
+	// first run fetches one message, and fails on the second page.
+	mc := newmockClienter(gomock.NewController(t))
+	first := &slack.GetConversationHistoryResponse{HasMore: true, SlackResponse: slack.SlackResponse{Ok: true}, Messages: []slack.Message{testMsg3.Message}}
+	first.ResponseMetaData.NextCursor = "cur"
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), params("")).Return(first, nil)
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), params("cur")).Return(nil, errors.New("connection reset"))
+	mockConvInfo(mc, "CHANNEL", "channel_name")
+
+	cps, err := loadCheckpoints(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd := &Session{client: mc, options: limited, cps: cps}
+	if _, err := sd.dumpChannel(context.Background(), "CHANNEL", time.Time{}, time.Time{}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// second run must count the restored message towards the limit.
+	mc = newmockClienter(gomock.NewController(t))
+	second := &slack.GetConversationHistoryResponse{HasMore: true, SlackResponse: slack.SlackResponse{Ok: true}, Messages: []slack.Message{testMsg2.Message, testMsg1.Message}}
+	second.ResponseMetaData.NextCursor = "cur2"
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), params("cur")).Return(second, nil)
+	mockConvInfo(mc, "CHANNEL", "channel_name")
+
+	cps, err = loadCheckpoints(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd = &Session{client: mc, options: limited, cps: cps}
+	got, err := sd.dumpChannel(context.Background(), "CHANNEL", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []types.Message{testMsg2, testMsg3}, got.Messages)
+}
@@ -128,6 +128,22 @@ func (mr *mockClienterMockRecorder) GetFile(downloadURL, writer interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFile", reflect.TypeOf((*mockClienter)(nil).GetFile), downloadURL, writer)
 }
 
+// GetFilesContext mocks base method.
+func (m *mockClienter) GetFilesContext(ctx context.Context, params slack.GetFilesParameters) ([]slack.File, *slack.Paging, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilesContext", ctx, params)
+	ret0, _ := ret[0].([]slack.File)
+	ret1, _ := ret[1].(*slack.Paging)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetFilesContext indicates an expected call of GetFilesContext.
+func (mr *mockClienterMockRecorder) GetFilesContext(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilesContext", reflect.TypeOf((*mockClienter)(nil).GetFilesContext), ctx, params)
+}
+
 // GetTeamInfo mocks base method.
 func (m *mockClienter) GetTeamInfo() (*slack.TeamInfo, error) {
 	m.ctrl.T.Helper()
@@ -143,6 +159,51 @@ func (mr *mockClienterMockRecorder) GetTeamInfo() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamInfo", reflect.TypeOf((*mockClienter)(nil).GetTeamInfo))
 }
 
+// GetTeamProfileContext mocks base method.
+func (m *mockClienter) GetTeamProfileContext(ctx context.Context) (*slack.TeamProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeamProfileContext", ctx)
+	ret0, _ := ret[0].(*slack.TeamProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeamProfileContext indicates an expected call of GetTeamProfileContext.
+func (mr *mockClienterMockRecorder) GetTeamProfileContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamProfileContext", reflect.TypeOf((*mockClienter)(nil).GetTeamProfileContext), ctx)
+}
+
+// GetUserPresenceContext mocks base method.
+func (m *mockClienter) GetUserPresenceContext(ctx context.Context, user string) (*slack.UserPresence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPresenceContext", ctx, user)
+	ret0, _ := ret[0].(*slack.UserPresence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserPresenceContext indicates an expected call of GetUserPresenceContext.
+func (mr *mockClienterMockRecorder) GetUserPresenceContext(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPresenceContext", reflect.TypeOf((*mockClienter)(nil).GetUserPresenceContext), ctx, user)
+}
+
+// GetUserProfileContext mocks base method.
+func (m *mockClienter) GetUserProfileContext(ctx context.Context, params *slack.GetUserProfileParameters) (*slack.UserProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserProfileContext", ctx, params)
+	ret0, _ := ret[0].(*slack.UserProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserProfileContext indicates an expected call of GetUserProfileContext.
+func (mr *mockClienterMockRecorder) GetUserProfileContext(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfileContext", reflect.TypeOf((*mockClienter)(nil).GetUserProfileContext), ctx, params)
+}
+
 // GetUsersContext mocks base method.
 func (m *mockClienter) GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error) {
 	m.ctrl.T.Helper()
@@ -178,3 +239,19 @@ func (mr *mockClienterMockRecorder) GetUsersInConversationContext(ctx, params in
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersInConversationContext", reflect.TypeOf((*mockClienter)(nil).GetUsersInConversationContext), ctx, params)
 }
+
+// ListPinsContext mocks base method.
+func (m *mockClienter) ListPinsContext(ctx context.Context, channel string) ([]slack.Item, *slack.Paging, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPinsContext", ctx, channel)
+	ret0, _ := ret[0].([]slack.Item)
+	ret1, _ := ret[1].(*slack.Paging)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPinsContext indicates an expected call of ListPinsContext.
+func (mr *mockClienterMockRecorder) ListPinsContext(ctx, channel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPinsContext", reflect.TypeOf((*mockClienter)(nil).ListPinsContext), ctx, channel)
+}
@@ -0,0 +1,122 @@
+package main
+
+// In this file: the config file support.
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envFlags maps the flags to the environment variables, that set their
+// default values.  The values set in the environment take precedence over
+// the config file.
+var envFlags = map[string]string{
+	"t":            envSlackToken,
+	"cookie":       envSlackCookie,
+	"export-token": envSlackFileToken,
+	"log":          "LOG_FILE",
+	"trace":        "TRACE_FILE",
+	"v":            "DEBUG",
+}
+
+// configTimeFmt is the format of the timestamps that are passed to the
+// time flags, if the config file contains an unquoted timestamp.
+const configTimeFmt = "2006-01-02T15:04:05"
+
+// envFlagNames returns the names of the flags, that are set by the
+// environment variables.  It must be called before the flags are defined,
+// as the secret variables are removed from the environment once read.
+func envFlagNames() []string {
+	var names []string
+	for name, env := range envFlags {
+		if os.Getenv(env) != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// applyConfig reads the YAML config file filename, and sets the flags in fs.
+// The keys of the config file are the flag names without the leading dash.
+// The flags, which values are in skip, are left untouched, this allows the
+// command line flags and the environment variables to override the config
+// file.  As the flag aliases, i.e. -c and -list-channels, share the same
+// value, they are skipped as well.  It returns the keys, that do not match
+// any flag.
+func applyConfig(fs *flag.FlagSet, filename string, skip []flag.Value) ([]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", filename, err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var unknown []string
+	for _, k := range keys {
+		name := strings.TrimLeft(k, "-")
+		f := fs.Lookup(name)
+		if f == nil || name == "config" {
+			unknown = append(unknown, k)
+			continue
+		}
+		if isSkipped(f.Value, skip) {
+			continue
+		}
+		s, err := configValue(values[k])
+		if err != nil {
+			return nil, fmt.Errorf("config file %s: %s: %w", filename, k, err)
+		}
+		if err := fs.Set(name, s); err != nil {
+			return nil, fmt.Errorf("config file %s: invalid value for %s: %w", filename, k, err)
+		}
+	}
+	return unknown, nil
+}
+
+func isSkipped(v flag.Value, skip []flag.Value) bool {
+	for _, s := range skip {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// configValue converts the value from the config file to the flag value.
+// The lists are converted to the comma-separated values.
+func configValue(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case time.Time:
+		return val.Format(configTimeFmt), nil
+	case []interface{}:
+		items := make([]string, 0, len(val))
+		for _, item := range val {
+			s, err := configValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		return "", errors.New("nested values are not supported")
+	}
+	return fmt.Sprint(v), nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/internal/app/config"
+)
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "slackdump.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(data), 0644))
+	return filename
+}
+
+func Test_applyConfig(t *testing.T) {
+	var (
+		base      string
+		channels  bool
+		types     []string
+		workers   int
+		from      config.TimeValue
+		unchanged string
+	)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.StringVar(&base, "base", "", "")
+	fs.BoolVar(&channels, "c", false, "")
+	fs.BoolVar(&channels, "list-channels", false, "")
+	fs.Var((*config.ListValue)(&types), "channel-types", "")
+	fs.IntVar(&workers, "download-workers", 4, "")
+	fs.Var(&from, "dump-from", "")
+	fs.StringVar(&unchanged, "o", "-", "")
+	require.NoError(t, fs.Parse([]string{"-c", "-o", "out.txt"}))
+
+	filename := writeConfig(t, `
+base: archive.zip
+list-channels: false      # alias of -c, which is set on the command line
+channel-types: [im, mpim]
+download-workers: 8
+dump-from: 2020-12-31T23:59:59
+o: config.txt
+unknown-flag: 42
+`)
+	var skip []flag.Value
+	fs.Visit(func(f *flag.Flag) { skip = append(skip, f.Value) })
+
+	unknown, err := applyConfig(fs, filename, skip)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unknown-flag"}, unknown)
+	assert.Equal(t, "archive.zip", base)
+	assert.True(t, channels, "command line value must take precedence")
+	assert.Equal(t, []string{"im", "mpim"}, types)
+	assert.Equal(t, 8, workers)
+	assert.Equal(t, time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC), time.Time(from))
+	assert.Equal(t, "out.txt", unchanged)
+}
+
+func Test_applyConfig_errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"invalid yaml", "base: [unclosed"},
+		{"invalid value", "download-workers: many"},
+		{"nested value", "base:\n  name: archive.zip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				base    string
+				workers int
+			)
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.StringVar(&base, "base", "", "")
+			fs.IntVar(&workers, "download-workers", 4, "")
+			_, err := applyConfig(fs, writeConfig(t, tt.config), nil)
+			assert.Error(t, err)
+		})
+	}
+	_, err := applyConfig(flag.NewFlagSet("test", flag.ContinueOnError), filepath.Join(t.TempDir(), "missing.yaml"), nil)
+	assert.Error(t, err)
+}
+
+func Test_parseCmdLine_config(t *testing.T) {
+	filename := writeConfig(t, "base: archive.zip\nlist-users: true\nt: config-token\ncookie: config-cookie\n")
+
+	t.Setenv(envSlackToken, "env-token")
+	p, err := parseCmdLine([]string{"-config", filename, "-cookie", "cli-cookie"})
+	require.NoError(t, err)
+	assert.Equal(t, "archive.zip", p.appCfg.Output.Base)
+	assert.True(t, p.appCfg.ListFlags.Users)
+	assert.Equal(t, "env-token", p.creds.Token, "environment must take precedence over the config")
+	assert.Equal(t, "cli-cookie", p.creds.Cookie, "command line must take precedence over the config")
+}
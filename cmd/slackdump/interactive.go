@@ -1,19 +1,20 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
-	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 
 	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/internal/app"
 	"github.com/rusq/slackdump/v2/internal/app/config"
 	"github.com/rusq/slackdump/v2/internal/app/ui"
 	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
 )
 
 var errExit = errors.New("exit")
@@ -96,7 +97,7 @@ func surveyList(p *params) error {
 			Validate: survey.Required,
 			Prompt: &survey.Select{
 				Message: "Report format: ",
-				Options: []string{config.OutputTypeText, config.OutputTypeJSON},
+				Options: []string{config.OutputTypeText, config.OutputTypeJSON, config.OutputTypeCSV},
 				Description: func(value string, index int) string {
 					return "produce output in " + value + " format"
 				},
@@ -121,10 +122,46 @@ func surveyList(p *params) error {
 		p.appCfg.ListFlags.Users = true
 	}
 	p.appCfg.Output.Format = mode.Format
+	if mode.Format == config.OutputTypeText {
+		if p.appCfg.Output.Columns, err = questColumns(); err != nil {
+			return err
+		}
+	}
 	p.appCfg.Output.Filename, err = questOutputFile()
 	return err
 }
 
+// columnPresets are the column selections of the text output, offered in the
+// interactive mode.  The columns are valid for both users and channels.
+var columnPresets = []struct {
+	Name    string
+	Descr   string
+	Columns []string
+}{
+	{"Full", "all columns", nil},
+	{"ID and name", "conversation or user ID and name", []string{"id", "name"}},
+	{"ID only", "conversation or user ID, i.e. to use as the input list", []string{"id"}},
+}
+
+func questColumns() ([]string, error) {
+	var names []string
+	for _, cp := range columnPresets {
+		names = append(names, cp.Name)
+	}
+	mode := &survey.Select{
+		Message: "Columns: ",
+		Options: names,
+		Description: func(value string, index int) string {
+			return columnPresets[index].Descr
+		},
+	}
+	var resp int
+	if err := survey.AskOne(mode, &resp); err != nil {
+		return nil, err
+	}
+	return columnPresets[resp].Columns, nil
+}
+
 func surveyExport(p *params) error {
 	var err error
 
@@ -135,10 +172,11 @@ func surveyExport(p *params) error {
 	if err != nil {
 		return err
 	}
-	p.appCfg.Input.List, err = questConversationList("Conversations to export? (Conversation ID, Date (MM/DD/YY), All or Empty for full export): ")
-  	if err != nil {
-        return err
-    	}
+	sel, err := questConversations(p, "Conversations to export? (Conversation IDs or URLs, Date (MM/DD/YY), All or Empty for full export): ")
+	if err != nil {
+		return err
+	}
+	p.appCfg.Input.List = sel.List
 	p.appCfg.Options.DumpFiles, err = ui.Confirm("Export files?", true)
 	if err != nil {
 		return err
@@ -160,11 +198,13 @@ func surveyExport(p *params) error {
 func questExportType() (export.ExportType, error) {
 	mode := &survey.Select{
 		Message: "Export type: ",
-		Options: []string{export.TMattermost.String(), export.TStandard.String()},
+		Options: []string{export.TMattermost.String(), export.TStandard.String(), export.THTML.String(), export.TMarkdown.String()},
 		Description: func(value string, index int) string {
 			descr := []string{
 				"Mattermost bulk upload compatible export (see doc)",
 				"Standard export format",
+				"HTML pages, that can be viewed in a browser",
+				"Markdown files, i.e. for the documentation archives",
 			}
 			return descr[index]
 		},
@@ -179,50 +219,136 @@ func questExportType() (export.ExportType, error) {
 }
 
 func surveyDump(p *params) error {
+	for {
+		sel, err := questConversations(p, "Enter conversations to dump: ")
+		if err != nil {
+			return err
+		}
+		if sel.Type != export.SelList || !sel.List.HasIncludes() {
+			fmt.Println("Please enter at least one conversation ID or URL to dump.")
+			continue
+		}
+		p.appCfg.Input.List = sel.List
+		break
+	}
+	if len(p.appCfg.Input.List.DateFilters) > 0 {
+		// the dates were entered along with the conversations.
+		return nil
+	}
 	var err error
-	p.appCfg.Input.List, err = questConversationList("Enter conversations to dump: ")
+	p.appCfg.Input.List.DateFilters, err = questDateFilters()
 	return err
 }
 
-// questConversationList enquires the channel list.
-func questConversationList(msg string) (*structures.EntityList, error) {
-    const dateFormat = "01/02/06"
-
-    for {
-        // User prompt for input
-        inputStr, err := ui.String(msg, "Enter a date range (MM/DD/YY - MM/DD/YY) or 'ALL'.")
-        if err != nil {
-            return nil, err // Return error if there's an issue with input
-        }
-
-        // If 'ALL' or empty input, return EntityList for all conversations
-        if inputStr == "" || strings.ToLower(inputStr) == "all" {
-            return &structures.EntityList{AllConversations: true}, nil
-        }
-
-        // Processing date range input
-        if strings.Contains(inputStr, "-") {
-            dateRange := strings.Split(inputStr, "-")
-            if len(dateRange) != 2 {
-                fmt.Printf("Invalid date range format: %s\n", inputStr)
-                continue // Invalid format, prompt again
-            }
+// questDateFilters enquires the date ranges of the messages.
+func questDateFilters() ([]structures.DateFilter, error) {
+	for {
+		input, err := ui.String(
+			"Date ranges (MM/DD/YY - MM/DD/YY, comma separated, leave empty for all messages): ",
+			"Enter the date ranges to limit the messages to.  Both dates are inclusive, either of them\n"+
+				"can be omitted, i.e. \"01/31/23 -\" selects all messages since that date.  Several ranges\n"+
+				"can be separated with commas, i.e. \"01/02/23 - 01/05/23, 03/01/23\".  Dates can also be\n"+
+				"entered as YYYY-MM-DD or Jan 2 2023.",
+		)
+		if err != nil {
+			return nil, err
+		}
+		dfs, err := export.ParseDateFilters(input)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		return dfs, nil
+	}
+}
 
-            startDate, errStart := time.Parse(dateFormat, strings.TrimSpace(dateRange[0]))
-            endDate, errEnd := time.Parse(dateFormat, strings.TrimSpace(dateRange[1]))
-            if errStart != nil || errEnd != nil || startDate.After(endDate) {
-                fmt.Printf("Invalid date range: %s\n", inputStr)
-                continue // Invalid date, prompt again
-            }
+// questConversations enquires how the conversations are going to be selected,
+// and then either asks to enter them with msg prompt, or offers to pick them
+// from the list of the workspace conversations.
+func questConversations(p *params, msg string) (export.ExportSelection, error) {
+	mode := &survey.Select{
+		Message: "Select conversations: ",
+		Options: []string{"Enter", "Pick from the list"},
+		Description: func(value string, index int) string {
+			descr := []string{
+				"enter the conversation IDs, URLs or dates",
+				"pick the conversations from the list (requires login)",
+			}
+			return descr[index]
+		},
+	}
+	var resp int
+	if err := survey.AskOne(mode, &resp); err != nil {
+		return export.ExportSelection{}, err
+	}
+	if resp == 0 {
+		return questConversationList(msg)
+	}
+	return pickConversations(p)
+}
 
-            // Return EntityList for the specified date range
-            return &structures.EntityList{DateFilter: DateFilter{Start: startDate, End: endDate}}, nil
-        } else {
-            fmt.Println("Invalid input. Please enter a valid date range or 'ALL'.")
-        }
-    }
+// pickConversations logs in, if not yet logged in, fetches the conversations
+// of the workspace, and offers to pick them from the list.
+func pickConversations(p *params) (export.ExportSelection, error) {
+	ctx := context.Background()
+	if p.provider == nil {
+		prov, err := initProvider(ctx, logger.Default, p)
+		if err != nil {
+			return export.ExportSelection{}, err
+		}
+		p.provider = prov
+	}
+	items, err := app.Conversations(ctx, p.appCfg, p.provider)
+	if err != nil {
+		return export.ExportSelection{}, err
+	}
+	if len(items) == 0 {
+		return export.ExportSelection{}, errors.New("no conversations found")
+	}
+	var labels = make([]string, len(items))
+	for i, it := range items {
+		labels[i] = fmt.Sprintf("%s (%s)", it.Name, it.ID)
+	}
+	q := &survey.MultiSelect{
+		Message:  "Conversations: ",
+		Options:  labels,
+		PageSize: 15,
+		Help:     "Use arrow keys to move, Space to select, type to filter the list, Enter to confirm.",
+	}
+	var picked []int
+	if err := survey.AskOne(q, &picked, survey.WithValidator(survey.MinItems(1))); err != nil {
+		return export.ExportSelection{}, err
+	}
+	var ids = make([]string, len(picked))
+	for i, idx := range picked {
+		ids[i] = items[idx].ID
+	}
+	return export.ExportSelection{Type: export.SelList, List: &structures.EntityList{Include: ids}}, nil
 }
 
+// questConversationList enquires the conversation selection.
+func questConversationList(msg string) (export.ExportSelection, error) {
+	for {
+		input, err := ui.String(
+			msg,
+			"Enter whitespace separated conversation IDs or URLs, a date (MM/DD/YY), a date range\n"+
+				"(MM/DD/YY - MM/DD/YY) or several comma separated dates and ranges, 'ALL' or leave\n"+
+				"empty to select all conversations.  Dates can also be entered as YYYY-MM-DD or Jan 2 2023.\n"+
+				"   - prefix with ^ (caret) to exclude the conversation\n"+
+				"   - prefix with @ to read the list of conversations from the file\n"+
+				"   - follow the conversations with the dates to limit the messages, i.e. C01234567 01/02/23 - 01/05/23.",
+		)
+		if err != nil {
+			return export.ExportSelection{}, err
+		}
+		sel, err := export.ParseUserInput(input)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		return sel, nil
+	}
+}
 
 // questOutputFile prints the output file question.
 func questOutputFile() (string, error) {
@@ -0,0 +1,64 @@
+package downloader
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// newBandwidthLimiter returns the limiter that allows bytesPerSec bytes per
+// second, or nil if bytesPerSec is zero or negative, which means unlimited.
+// The burst is equal to one second worth of bytes.
+func newBandwidthLimiter(bytesPerSec int64) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	burst := bytesPerSec
+	if burst > maxBurst {
+		burst = maxBurst
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), int(burst))
+}
+
+// maxBurst caps the burst so that it fits into int on all platforms.
+const maxBurst = 1<<31 - 1
+
+// limitedWriter is the io.Writer that waits for the limiter before writing
+// the data to the underlying writer.
+type limitedWriter struct {
+	ctx context.Context
+	w   io.Writer
+	lim *rate.Limiter
+}
+
+// newLimitedWriter wraps w with a writer that is throttled by lim.  If lim is
+// nil, w is returned as is.
+func newLimitedWriter(ctx context.Context, w io.Writer, lim *rate.Limiter) io.Writer {
+	if lim == nil {
+		return w
+	}
+	return &limitedWriter{ctx: ctx, w: w, lim: lim}
+}
+
+// Write writes p to the underlying writer in chunks that do not exceed the
+// limiter burst, waiting for the limiter before writing each chunk.
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	var total int
+	for len(p) > 0 {
+		chunk := p
+		if burst := lw.lim.Burst(); len(chunk) > burst {
+			chunk = chunk[:burst]
+		}
+		if err := lw.lim.WaitN(lw.ctx, len(chunk)); err != nil {
+			return total, err
+		}
+		n, err := lw.w.Write(chunk)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		p = p[n:]
+	}
+	return total, nil
+}
@@ -0,0 +1,53 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newBandwidthLimiter(t *testing.T) {
+	assert.Nil(t, newBandwidthLimiter(0))
+	assert.Nil(t, newBandwidthLimiter(-1))
+	lim := newBandwidthLimiter(1024)
+	require.NotNil(t, lim)
+	assert.Equal(t, 1024, lim.Burst())
+	assert.Equal(t, maxBurst, newBandwidthLimiter(1<<40).Burst())
+}
+
+func Test_limitedWriter(t *testing.T) {
+	t.Run("unlimited returns the writer as is", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Equal(t, &buf, newLimitedWriter(context.Background(), &buf, nil))
+	})
+	t.Run("writes are throttled", func(t *testing.T) {
+		const bps = 1000
+		var buf bytes.Buffer
+		w := newLimitedWriter(context.Background(), &buf, newBandwidthLimiter(bps))
+		data := bytes.Repeat([]byte("x"), bps*3/2) // larger than the burst
+
+		start := time.Now()
+		n, err := w.Write(data)
+		elapsed := time.Since(start)
+
+		require.NoError(t, err)
+		assert.Equal(t, len(data), n)
+		assert.Equal(t, data, buf.Bytes())
+		// the first second worth of bytes is the burst, the rest should take
+		// about half a second.
+		assert.GreaterOrEqual(t, elapsed, 400*time.Millisecond)
+	})
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var buf bytes.Buffer
+		w := newLimitedWriter(ctx, &buf, newBandwidthLimiter(10))
+		_, err := w.Write([]byte("hello"))
+		assert.Error(t, err)
+		assert.Zero(t, buf.Len())
+	})
+}
@@ -0,0 +1,111 @@
+package downloader
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+)
+
+// compressedTypes are the prefixes of the mimetypes of the files, that are
+// already compressed, and gain nothing from being compressed again.
+var compressedTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-bzip2",
+	"application/x-xz",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/vnd.rar",
+	"application/zstd",
+	"application/vnd.openxmlformats-officedocument.", // docx, xlsx, pptx are zip archives
+}
+
+// uncompressedTypes are the exceptions from compressedTypes.
+var uncompressedTypes = []string{
+	"image/svg+xml",
+	"image/bmp",
+}
+
+// isCompressed returns true if the file sf is already compressed, judging by
+// its mimetype.
+func isCompressed(sf *slack.File) bool {
+	mt := strings.ToLower(sf.Mimetype)
+	for _, t := range uncompressedTypes {
+		if strings.HasPrefix(mt, t) {
+			return false
+		}
+	}
+	for _, t := range compressedTypes {
+		if strings.HasPrefix(mt, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// compresses returns true if the file sf is going to be compressed.
+func (c *Client) compresses(sf *slack.File) bool {
+	return c.compress && !isCompressed(sf)
+}
+
+// Filename returns the name of the file sf on the filesystem, generated by the
+// naming function, with the gzip extension, if the file is compressed.
+func (c *Client) Filename(sf *slack.File) string {
+	if c.compresses(sf) {
+		return c.nameFn(sf) + fsadapter.GzipExt
+	}
+	return c.nameFn(sf)
+}
+
+// fileOpener is implemented by the filesystem adapters that are able to open
+// the files for reading, i.e. fsadapter.Directory.
+type fileOpener interface {
+	Open(name string) (*os.File, error)
+}
+
+// uncompressedSize returns the size of the decompressed contents of the gzip
+// file at filePath.  It returns false, if the file can not be read, or is not
+// a valid gzip file, i.e. if it was not written completely.  The file is
+// decompressed on the fly, without reading it into memory.
+func (c *Client) uncompressedSize(filePath string) (int64, bool) {
+	fo, ok := c.fs.(fileOpener)
+	if !ok {
+		return 0, false
+	}
+	f, err := fo.Open(filePath)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return 0, false
+	}
+	defer gz.Close()
+	n, err := io.Copy(io.Discard, gz)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// countWriter counts the number of bytes written to the underlying writer.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
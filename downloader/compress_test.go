@@ -0,0 +1,118 @@
+package downloader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
+)
+
+func Test_isCompressed(t *testing.T) {
+	tests := []struct {
+		mimetype string
+		want     bool
+	}{
+		{"text/plain", false},
+		{"application/json", false},
+		{"image/svg+xml", false},
+		{"image/bmp", false},
+		{"", false},
+		{"image/jpeg", true},
+		{"IMAGE/PNG", true},
+		{"video/mp4", true},
+		{"audio/mpeg", true},
+		{"application/zip", true},
+		{"application/gzip", true},
+		{"application/vnd.openxmlformats-officedocument.wordprocessingml.document", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mimetype, func(t *testing.T) {
+			assert.Equal(t, tt.want, isCompressed(&slack.File{Mimetype: tt.mimetype}))
+		})
+	}
+}
+
+func TestClient_saveFile_compress(t *testing.T) {
+	content := strings.Repeat("all work and no play makes jack a dull boy\n", 100)
+	writeFn := func(_ string, w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	}
+	var (
+		text  = slack.File{ID: "fc1", Name: "log.txt", Mimetype: "text/plain", URLPrivateDownload: "url1", Size: len(content)}
+		image = slack.File{ID: "fc2", Name: "cat.jpg", Mimetype: "image/jpeg", URLPrivateDownload: "url2", Size: len(content)}
+	)
+	t.Run("text file is compressed", func(t *testing.T) {
+		dir := t.TempDir()
+		c := clientWithMock(t, dir)
+		Compress(true)(c)
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(text.URLPrivateDownload, gomock.Any()).
+			DoAndReturn(writeFn)
+
+		res, err := c.saveFile(context.Background(), "C1", &text)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join("C1", "fc1-log.txt.gz"), res.Path)
+		assert.Equal(t, int64(len(content)), res.Size)
+
+		data, err := os.ReadFile(filepath.Join(dir, res.Path))
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(data)), res.CompressedSize)
+		assert.Less(t, res.CompressedSize, res.Size)
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		got, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(got))
+	})
+	t.Run("image is saved as is", func(t *testing.T) {
+		dir := t.TempDir()
+		c := clientWithMock(t, dir)
+		Compress(true)(c)
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(image.URLPrivateDownload, gomock.Any()).
+			DoAndReturn(writeFn)
+
+		res, err := c.saveFile(context.Background(), "C1", &image)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join("C1", "fc2-cat.jpg"), res.Path)
+		assert.Zero(t, res.CompressedSize)
+		assert.FileExists(t, filepath.Join(dir, res.Path))
+	})
+	t.Run("existing compressed file is skipped", func(t *testing.T) {
+		dir := t.TempDir()
+		c := clientWithMock(t, dir)
+		Compress(true)(c)
+		SkipExisting(true)(c)
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(text.URLPrivateDownload, gomock.Any()).
+			DoAndReturn(writeFn).
+			Times(1)
+
+		_, err := c.saveFile(context.Background(), "C1", &text)
+		require.NoError(t, err)
+		res, err := c.saveFile(context.Background(), "C1", &text)
+		require.NoError(t, err)
+		assert.Equal(t, FileResult{Path: filepath.Join("C1", "fc1-log.txt.gz")}, res)
+	})
+}
+
+func TestClient_Filename(t *testing.T) {
+	f := &slack.File{ID: "F1", Name: "log.txt", Mimetype: "text/plain"}
+	c := New(mock_downloader.NewMockDownloader(gomock.NewController(t)), nil)
+	assert.Equal(t, "F1-log.txt", c.Filename(f))
+	Compress(true)(c)
+	assert.Equal(t, "F1-log.txt.gz", c.Filename(f))
+	assert.Equal(t, "F2-cat.png", c.Filename(&slack.File{ID: "F2", Name: "cat.png", Mimetype: "image/png"}))
+}
@@ -0,0 +1,82 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sync"
+)
+
+// linker is implemented by the filesystem adapters that are able to link
+// files, i.e. fsadapter.Directory.
+type linker interface {
+	Link(oldname, newname string) error
+}
+
+// hashIndex maps the SHA-256 sum of the file contents to the path of the
+// first file saved with that content.
+type hashIndex struct {
+	mu    sync.Mutex
+	paths map[string]string
+}
+
+// lookup returns the path of the file with the sum, if there is one.
+func (hi *hashIndex) lookup(sum string) (string, bool) {
+	hi.mu.Lock()
+	defer hi.mu.Unlock()
+	p, ok := hi.paths[sum]
+	return p, ok
+}
+
+// add records that the file at path has the contents with the sum.  If there
+// is already a file with the same sum, it is kept.
+func (hi *hashIndex) add(sum string, path string) {
+	hi.mu.Lock()
+	defer hi.mu.Unlock()
+	if hi.paths == nil {
+		hi.paths = make(map[string]string)
+	}
+	if _, ok := hi.paths[sum]; !ok {
+		hi.paths[sum] = path
+	}
+}
+
+// hashContents returns the hex-encoded SHA-256 sum and the size of the
+// contents of r.  It rewinds r to the start before returning.
+func hashContents(r io.ReadSeeker) (string, int64, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// linkDuplicate links filePath to the previously saved file with the same
+// contents as r, if there's one, and the filesystem supports links.  It
+// returns the FileResult and true, if the file was linked.  Failure to link
+// is not fatal, the file will be saved as usual.
+func (c *Client) linkDuplicate(filePath string, r io.ReadSeeker) (FileResult, bool) {
+	lk, ok := c.fs.(linker)
+	if !ok {
+		return FileResult{}, false
+	}
+	sum, n, err := hashContents(r)
+	if err != nil {
+		c.l().Debugf("failed to hash %q: %s", filePath, err)
+		return FileResult{}, false
+	}
+	orig, ok := c.hashes.lookup(sum)
+	if !ok || orig == filePath {
+		return FileResult{}, false
+	}
+	if err := lk.Link(orig, filePath); err != nil {
+		c.l().Debugf("failed to link %q to %q: %s", filePath, orig, err)
+		return FileResult{}, false
+	}
+	c.l().Debugf("file %q has the same contents as %q, linked", filePath, orig)
+	return FileResult{Path: filePath, Size: n, SHA256: sum, Duplicate: orig}, true
+}
@@ -0,0 +1,72 @@
+package downloader
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
+)
+
+func TestClient_saveFile_dedup(t *testing.T) {
+	const content = "same image"
+	var (
+		f1 = slack.File{ID: "fd1", Name: "cat.png", URLPrivateDownload: "url1", Size: len(content)}
+		f2 = slack.File{ID: "fd2", Name: "cat.png", URLPrivateDownload: "url2", Size: len(content)}
+	)
+	writeFn := func(_ string, w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	}
+	t.Run("identical contents are linked", func(t *testing.T) {
+		dir := t.TempDir()
+		c := clientWithMock(t, dir)
+		DedupByContent(true)(c)
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(gomock.Any(), gomock.Any()).
+			DoAndReturn(writeFn).
+			Times(2)
+
+		res1, err := c.saveFile(context.Background(), "C1", &f1)
+		require.NoError(t, err)
+		assert.Empty(t, res1.Duplicate)
+		res2, err := c.saveFile(context.Background(), "C2", &f2)
+		require.NoError(t, err)
+		assert.Equal(t, res1.Path, res2.Duplicate)
+		assert.Equal(t, res1.SHA256, res2.SHA256)
+		assert.Equal(t, int64(len(content)), res2.Size)
+
+		fi1, err := os.Stat(filepath.Join(dir, res1.Path))
+		require.NoError(t, err)
+		fi2, err := os.Stat(filepath.Join(dir, res2.Path))
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(fi1, fi2), "expected a hard link")
+	})
+	t.Run("disabled by default", func(t *testing.T) {
+		dir := t.TempDir()
+		c := clientWithMock(t, dir)
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(gomock.Any(), gomock.Any()).
+			DoAndReturn(writeFn).
+			Times(2)
+
+		res1, err := c.saveFile(context.Background(), "C1", &f1)
+		require.NoError(t, err)
+		res2, err := c.saveFile(context.Background(), "C2", &f2)
+		require.NoError(t, err)
+		assert.Empty(t, res2.Duplicate)
+
+		fi1, err := os.Stat(filepath.Join(dir, res1.Path))
+		require.NoError(t, err)
+		fi2, err := os.Stat(filepath.Join(dir, res2.Path))
+		require.NoError(t, err)
+		assert.False(t, os.SameFile(fi1, fi2))
+	})
+}
@@ -2,14 +2,23 @@
 package downloader
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/fs"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
 	"runtime/trace"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"errors"
 
@@ -22,28 +31,53 @@ import (
 )
 
 const (
-	defRetries    = 3    // default number of retries if download fails
-	defNumWorkers = 4    // number of download processes
-	defLimit      = 5000 // default API limit, in events per second.
-	defFileBufSz  = 100  // default download channel buffer.
+	defRetries   = 3    // default number of retries if download fails
+	defLimit     = 5000 // default API limit, in events per second.
+	defFileBufSz = 100  // default download channel buffer.
+
+	minWorkers = 2  // minimum default number of download processes
+	maxWorkers = 16 // maximum default number of download processes
 )
 
 // Client is the instance of the downloader.
 type Client struct {
-	client  Downloader
-	limiter *rate.Limiter
-	fs      fsadapter.FS
-	dlog    logger.Interface
-
-	retries int
-	workers int
+	client    Downloader
+	limiter   *network.Limiter
+	bwLimiter *rate.Limiter // limits the download bandwidth, nil if unlimited
+	fs        fsadapter.FS
+	dlog      logger.Interface
+
+	retries      int
+	workers      int
+	skipExisting bool
+	maxFileSize  int64
+	maxTotal     int64        // maximum number of bytes to write in total, zero means no limit
+	total        atomic.Int64 // number of bytes written to the filesystem, see MaxTotalBytes
+	totalLogged  atomic.Bool  // the total limit was logged
+	keepTimes    bool
+	dedup        bool
+	hashes       hashIndex // contents hashes of saved files, if dedup is enabled
+	compress     bool      // compress the files with gzip, see Compress
 
 	mu           sync.Mutex // mutex prevents race condition when starting/stopping
 	fileRequests chan fileRequest
 	wg           *sync.WaitGroup
 	started      bool
 
-	nameFn FilenameFunc
+	errMu   sync.Mutex // protects errs, records and counters, as workers run concurrently
+	errs    DownloadErrors
+	records []Record // download records of the processed files
+	skipped int      // number of files skipped by the filters
+	saved   int      // number of files saved
+	written int64    // number of bytes written
+
+	nameFn  FilenameFunc
+	filters []FilterFunc
+
+	progMu     sync.Mutex // serialises progress callback calls
+	progressFn ProgressFunc
+	queued     int // number of files queued for download
+	processed  int // number of files processed
 }
 
 // FilenameFunc is the file naming function that should return the output
@@ -60,6 +94,12 @@ type Downloader interface {
 	GetFile(downloadURL string, writer io.Writer) error
 }
 
+// contextDownloader is implemented by the clients that are able to abort the
+// download when the context is cancelled, i.e. slack.Client.
+type contextDownloader interface {
+	GetFileContext(ctx context.Context, downloadURL string, writer io.Writer) error
+}
+
 // Option is the function signature for the option functions.
 type Option func(*Client)
 
@@ -67,7 +107,7 @@ type Option func(*Client)
 func Limiter(l *rate.Limiter) Option {
 	return func(c *Client) {
 		if l != nil {
-			c.limiter = l
+			c.limiter = network.AdaptiveLimiter(l)
 		}
 	}
 }
@@ -82,16 +122,33 @@ func Retries(n int) Option {
 	}
 }
 
-// Workers sets the number of workers for the download queue.
+// Workers sets the number of workers for the download queue.  If n is not
+// positive, the DefaultWorkers is used.
 func Workers(n int) Option {
 	return func(c *Client) {
 		if n <= 0 {
-			n = defNumWorkers
+			n = DefaultWorkers()
 		}
 		c.workers = n
 	}
 }
 
+// DefaultWorkers returns the default number of the download workers.  It
+// is the number of CPUs available to the process (GOMAXPROCS), bounded to
+// [2, 16]: the downloads are mostly waiting on the network, so the small
+// machines still benefit from a couple of workers, and too many workers on
+// the large ones would only hit the rate limits.
+func DefaultWorkers() int {
+	n := runtime.GOMAXPROCS(0)
+	if n < minWorkers {
+		return minWorkers
+	}
+	if n > maxWorkers {
+		return maxWorkers
+	}
+	return n
+}
+
 // Logger allows to use an external log library, that satisfies the
 // logger.Interface.
 func Logger(l logger.Interface) Option {
@@ -103,6 +160,98 @@ func Logger(l logger.Interface) Option {
 	}
 }
 
+// SkipExisting enables or disables skipping the files that already exist on
+// the filesystem and have the expected size.  It has effect only if the
+// filesystem is able to stat the files, i.e. a directory.
+func SkipExisting(b bool) Option {
+	return func(c *Client) {
+		c.skipExisting = b
+	}
+}
+
+// WithFilter adds the filter function.  Files that are rejected by any of the
+// filters are not downloaded.  nil filter is ignored.
+func WithFilter(fn FilterFunc) Option {
+	return func(c *Client) {
+		if fn != nil {
+			c.filters = append(c.filters, fn)
+		}
+	}
+}
+
+// PreserveTimes enables or disables setting the modification time of the
+// downloaded files to the time when the file was uploaded to Slack.  It has
+// effect only if the filesystem supports it, i.e. a directory.
+func PreserveTimes(b bool) Option {
+	return func(c *Client) {
+		c.keepTimes = b
+	}
+}
+
+// DedupByContent enables or disables the deduplication of the files by their
+// contents.  If enabled, the file that has the same contents as one of the
+// files saved previously, i.e. the same image shared in several channels, is
+// linked to that file, instead of being saved again.  It has effect only if
+// the filesystem supports links, i.e. a directory.  Files are deduplicated by
+// their ID regardless of this setting.
+func DedupByContent(b bool) Option {
+	return func(c *Client) {
+		c.dedup = b
+	}
+}
+
+// Compress enables or disables the gzip compression of the downloaded files.
+// Compressed files are saved with the ".gz" extension.  Files that are
+// already compressed, judging by their mimetype, i.e. images, videos or zip
+// archives, are saved as is.
+func Compress(b bool) Option {
+	return func(c *Client) {
+		c.compress = b
+	}
+}
+
+// Progress sets the function that is called each time a file is processed.
+// See ProgressFunc.
+func Progress(fn ProgressFunc) Option {
+	return func(c *Client) {
+		c.progressFn = fn
+	}
+}
+
+// BytesPerSec limits the download bandwidth to n bytes per second, shared by
+// all workers.  Unlike Limiter, that paces the requests, it throttles the
+// transfer of the file contents.  Zero means unlimited.
+func BytesPerSec(n int64) Option {
+	return func(c *Client) {
+		c.bwLimiter = newBandwidthLimiter(n)
+	}
+}
+
+// MaxFileSize sets the maximum size of the file in bytes.  Files larger than
+// that are not downloaded.  Zero means no limit.
+func MaxFileSize(n int64) Option {
+	return func(c *Client) {
+		if n < 0 {
+			n = 0
+		}
+		c.maxFileSize = n
+	}
+}
+
+// MaxTotalBytes sets the maximum number of bytes the downloader writes to the
+// filesystem in total.  Once the limit is reached, the files that are being
+// downloaded are completed, and the rest are skipped.  Zero means no limit.
+func MaxTotalBytes(n int64) Option {
+	return func(c *Client) {
+		if n < 0 {
+			n = 0
+		}
+		c.maxTotal = n
+	}
+}
+
+// WithNameFunc sets the file naming function, if fn is nil, the standard
+// Filename is used.
 func WithNameFunc(fn FilenameFunc) Option {
 	return func(c *Client) {
 		if fn != nil {
@@ -122,9 +271,9 @@ func New(client Downloader, fs fsadapter.FS, opts ...Option) *Client {
 	c := &Client{
 		client:  client,
 		fs:      fs,
-		limiter: rate.NewLimiter(defLimit, 1),
+		limiter: network.AdaptiveLimiter(rate.NewLimiter(defLimit, 1)),
 		retries: defRetries,
-		workers: defNumWorkers,
+		workers: DefaultWorkers(),
 		nameFn:  Filename,
 	}
 	for _, opt := range opts {
@@ -135,9 +284,35 @@ func New(client Downloader, fs fsadapter.FS, opts ...Option) *Client {
 
 // SaveFile saves a single file to the specified directory synchrounously.
 func (c *Client) SaveFile(ctx context.Context, dir string, f *slack.File) (int64, error) {
+	res, err := c.saveFile(ctx, dir, f)
+	return res.Size, err
+}
+
+// SaveFileResult saves a single file to the specified directory
+// synchronously, and returns the FileResult, that contains the SHA-256 sum of
+// the file, which can be used to detect corruption after the fact.
+func (c *Client) SaveFileResult(ctx context.Context, dir string, f *slack.File) (FileResult, error) {
 	return c.saveFile(ctx, dir, f)
 }
 
+// FileResult is the result of the file download.
+type FileResult struct {
+	Path   string // path of the file on the filesystem
+	Size   int64  // number of bytes written, before compression
+	SHA256 string // hex-encoded SHA-256 sum of the file contents
+	// CompressedSize is the number of bytes written to the filesystem, if the
+	// file was compressed, see Compress, otherwise it's zero.
+	CompressedSize int64
+	// Duplicate is the path of the previously saved file with the same
+	// contents, that this file is linked to, if deduplication by content is
+	// enabled.
+	Duplicate string
+	// External is true if the file is an external reference, i.e. a Google
+	// Drive link, that can not be downloaded.  Nothing is written to the
+	// filesystem for such files.
+	External bool
+}
+
 type fileRequest struct {
 	Directory string
 	File      *slack.File
@@ -164,15 +339,15 @@ func (c *Client) Start(ctx context.Context) {
 // req channel is closed, workers will stop, and wg.Wait() completes.
 func (c *Client) startWorkers(ctx context.Context, req <-chan fileRequest) *sync.WaitGroup {
 	if c.workers == 0 {
-		c.workers = defNumWorkers
+		c.workers = DefaultWorkers()
 	}
-	seenC := c.fltSeen(req)
+	seen := new(seenSet)
 	var wg sync.WaitGroup
 	// create workers
 	for i := 0; i < c.workers; i++ {
 		wg.Add(1)
 		go func(workerNum int) {
-			c.worker(ctx, seenC)
+			c.worker(ctx, req, seen)
 			wg.Done()
 			c.l().Debugf("download worker %d terminated", workerNum)
 		}(i)
@@ -181,8 +356,9 @@ func (c *Client) startWorkers(ctx context.Context, req <-chan fileRequest) *sync
 }
 
 // worker receives requests from reqC and passes them to saveFile function.
-// It will stop if either context is Done, or reqC is closed.
-func (c *Client) worker(ctx context.Context, reqC <-chan fileRequest) {
+// Requests that were already seen by any of the workers sharing the seen set
+// are skipped.  It will stop if either context is Done, or reqC is closed.
+func (c *Client) worker(ctx context.Context, reqC <-chan fileRequest, seen *seenSet) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -192,19 +368,83 @@ func (c *Client) worker(ctx context.Context, reqC <-chan fileRequest) {
 			if !moar {
 				return
 			}
-			c.l().Debugf("saving %q to %s, size: %d", c.nameFn(req.File), req.Directory, req.File.Size)
-			n, err := c.saveFile(ctx, req.Directory, req.File)
-			if err != nil {
-				c.l().Printf("error saving %q to %q: %s", c.nameFn(req.File), req.Directory, err)
-				break
+			if ctx.Err() != nil {
+				// select picks at random if both are ready, don't drain reqC.
+				trace.Log(ctx, "info", "worker context cancelled")
+				return
 			}
-			c.l().Printf("file %q saved to %s: %d bytes written", c.nameFn(req.File), req.Directory, n)
+			c.process(ctx, req, seen)
+			c.reportProgress(req.File)
 		}
 	}
 }
 
+// process processes a single file request.
+func (c *Client) process(ctx context.Context, req fileRequest, seen *seenSet) {
+	if seen.markSeen(req) {
+		c.l().Debugf("already seen %q, skipping", c.nameFn(req.File))
+		return
+	}
+	if c.skip(req.File) {
+		c.addRecord(req.Directory, req.File, StatusSkipped, int64(req.File.Size), 0, nil)
+		return
+	}
+	lg := logger.With(c.l(), "file", c.nameFn(req.File), "directory", req.Directory)
+	lg.Debugf("saving %q to %s, size: %d", c.nameFn(req.File), req.Directory, req.File.Size)
+	res, err := c.saveFile(ctx, req.Directory, req.File)
+	if err != nil {
+		lg.Printf("error saving %q to %q: %s", c.nameFn(req.File), req.Directory, err)
+		c.addError(*req.File, err)
+		c.addRecord(req.Directory, req.File, StatusFailed, int64(req.File.Size), 0, err)
+		return
+	}
+	c.errMu.Lock()
+	if res.External || res.Path == "" {
+		c.skipped++
+	} else {
+		c.saved++
+		c.written += res.Size
+	}
+	c.errMu.Unlock()
+	c.addTotal(res)
+	if res.External || res.Path == "" {
+		// external references and files that are not downloadable.
+		c.addRecord(req.Directory, req.File, StatusSkipped, int64(req.File.Size), 0, nil)
+		return
+	}
+	size := res.Size
+	if size == 0 {
+		// the file existed, and was not downloaded again.
+		size = int64(req.File.Size)
+	}
+	c.addRecord(req.Directory, req.File, StatusDownloaded, size, res.CompressedSize, nil)
+	logger.With(lg, "bytes", res.Size).Debugf("file %q saved to %s: %d bytes written, sha256: %s", c.nameFn(req.File), req.Directory, res.Size, res.SHA256)
+}
+
 var ErrNoFS = errors.New("fs adapter not initialised")
 
+// addError records the download error for the file f.
+func (c *Client) addError(f slack.File, err error) {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	c.errs = append(c.errs, DownloadError{File: f, Err: err})
+}
+
+// Errors returns the errors for all files that the downloader failed to
+// save.  It should be called after the "done" channel returned by
+// AsyncDownloader is closed, or after Stop returns, otherwise the list may be
+// incomplete.  It returns nil if there were no errors.
+func (c *Client) Errors() DownloadErrors {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	if len(c.errs) == 0 {
+		return nil
+	}
+	errs := make(DownloadErrors, len(c.errs))
+	copy(errs, c.errs)
+	return errs
+}
+
 // AsyncDownloader starts Client.worker goroutines to download files
 // concurrently. It will download any file that is received on fileDlQueue
 // channel. It returns the "done" channel and an error. "done" channel will be
@@ -219,6 +459,7 @@ func (c *Client) AsyncDownloader(ctx context.Context, dir string, fileDlQueue <-
 	go func() {
 		defer close(req)
 		for f := range fileDlQueue {
+			c.addQueued()
 			req <- fileRequest{Directory: dir, File: f}
 		}
 	}()
@@ -228,69 +469,280 @@ func (c *Client) AsyncDownloader(ctx context.Context, dir string, fileDlQueue <-
 	// sentinel
 	go func() {
 		wg.Wait()
+		c.logSummary()
 		close(done)
 	}()
 
 	return done, nil
 }
 
-// saveFileWithLimiter saves the file to specified directory, it will use the provided limiter l for throttling.
-func (c *Client) saveFile(ctx context.Context, dir string, sf *slack.File) (int64, error) {
+// saveFile saves the file to specified directory, the download is throttled
+// by the client limiter.  If the size of the downloaded file does not match
+// the size reported by Slack, i.e. if it was truncated by a proxy, the download
+// is retried.  The file is downloaded into a temporary file first, so if ctx
+// is cancelled mid-download, nothing is written to the filesystem.
+func (c *Client) saveFile(ctx context.Context, dir string, sf *slack.File) (FileResult, error) {
 	if c.fs == nil {
-		return 0, ErrNoFS
+		return FileResult{}, ErrNoFS
+	}
+	if isExternal(sf) {
+		c.l().Printf("file %q is an external reference, skipping", sf.Name)
+		return FileResult{External: true}, nil
 	}
-	if mode := sf.Mode; mode == "hidden_by_limit" || mode == "external" || sf.IsExternal {
+	srcURL := fileURL(sf)
+	if sf.Mode == "hidden_by_limit" || srcURL == "" {
 		trace.Logf(ctx, "info", "file %q is not downloadable", sf.Name)
-		return 0, nil
+		return FileResult{}, nil
+	}
+	filePath := filepath.Join(dir, c.Filename(sf))
+	if c.skipExisting && c.isComplete(filePath, sf) {
+		c.l().Debugf("file %q already exists, skipping", filePath)
+		return FileResult{Path: filePath}, nil
 	}
-	filePath := filepath.Join(dir, c.nameFn(sf))
 
 	tf, err := os.CreateTemp("", "")
 	if err != nil {
-		return 0, err
+		return FileResult{}, err
 	}
 	defer func() {
 		tf.Close()
 		os.Remove(tf.Name())
 	}()
 
-	if err := network.WithRetry(ctx, c.limiter, c.retries, func() error {
-		region := trace.StartRegion(ctx, "GetFile")
-		defer region.End()
-
-		if err := c.client.GetFile(sf.URLPrivateDownload, tf); err != nil {
-			if _, err := tf.Seek(0, io.SeekStart); err != nil {
-				c.l().Debugf("seek error: %s", err)
-			}
-			return fmt.Errorf("download to %q failed, [src=%s]: %w", filePath, sf.URLPrivateDownload, err)
+	for attempt := 1; ; attempt++ {
+		err = c.download(ctx, tf, srcURL, sf)
+		if err == nil || !errors.Is(err, ErrSizeMismatch) || attempt >= c.retries {
+			break
 		}
-		return nil
-	}); err != nil {
-		return 0, err
+		logger.With(c.l(), "file", filePath).Printf("file %q: %s, retrying (attempt %d of %d)", filePath, err, attempt, c.retries)
+	}
+	if err != nil {
+		return FileResult{}, fmt.Errorf("download to %q failed, [src=%s]: %w", filePath, srcURL, err)
 	}
 
 	// at this point, temporary file position would be at EOF, we need to reset
 	// it prior to copying.
 	if _, err := tf.Seek(0, io.SeekStart); err != nil {
-		return 0, err
+		return FileResult{}, err
+	}
+
+	if bogus, err := isUnexpectedHTML(sf, tf); err != nil {
+		return FileResult{}, err
+	} else if bogus {
+		return FileResult{}, fmt.Errorf("download to %q failed, [src=%s]: %w", filePath, srcURL, ErrHTMLResponse)
+	}
+
+	if c.dedup {
+		if res, ok := c.linkDuplicate(filePath, tf); ok {
+			return res, nil
+		}
 	}
 
 	fsf, err := c.fs.Create(filePath)
 	if err != nil {
-		return 0, err
+		return FileResult{}, err
 	}
 	defer fsf.Close()
 
-	n, err := io.Copy(fsf, tf)
+	var (
+		w  io.Writer = fsf
+		cw *countWriter
+		gz *gzip.Writer
+	)
+	if c.compresses(sf) {
+		cw = &countWriter{w: fsf}
+		gz = gzip.NewWriter(cw)
+		w = gz
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, h), tf)
 	if err != nil {
-		return 0, err
+		return FileResult{}, err
 	}
+	var compressed int64
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return FileResult{}, err
+		}
+		compressed = cw.n
+	}
+	if err := fsf.Close(); err != nil {
+		return FileResult{}, err
+	}
+	if c.keepTimes {
+		c.setTimes(filePath, sf)
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	if c.dedup {
+		c.hashes.add(sum, filePath)
+	}
+
+	return FileResult{Path: filePath, Size: n, SHA256: sum, CompressedSize: compressed}, nil
+}
+
+// chtimer is implemented by the filesystem adapters that are able to change
+// the file times, i.e. fsadapter.Directory.
+type chtimer interface {
+	Chtimes(name string, atime time.Time, mtime time.Time) error
+}
+
+// setTimes sets the access and modification time of the file at filePath to
+// the time when sf was uploaded to Slack.  Failure to set the time is not
+// fatal, as the file has been downloaded.
+func (c *Client) setTimes(filePath string, sf *slack.File) {
+	ct, ok := c.fs.(chtimer)
+	if !ok {
+		return
+	}
+	ts := sf.Timestamp
+	if ts == 0 {
+		ts = sf.Created
+	}
+	if ts == 0 {
+		return
+	}
+	t := ts.Time()
+	if err := ct.Chtimes(filePath, t, t); err != nil {
+		c.l().Debugf("failed to set the time of %q: %s", filePath, err)
+	}
+}
+
+// getFile downloads the file from downloadURL into w.  If the client supports
+// it, the download is aborted when ctx is cancelled.
+func (c *Client) getFile(ctx context.Context, downloadURL string, w io.Writer) error {
+	if cd, ok := c.client.(contextDownloader); ok {
+		return cd.GetFileContext(ctx, downloadURL, w)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.client.GetFile(downloadURL, w)
+}
+
+// ErrSizeMismatch is returned if the size of the downloaded file differs from
+// the size reported by Slack.
+var ErrSizeMismatch = errors.New("downloaded file size mismatch")
+
+// isExternal returns true if the file is a reference to the externally hosted
+// file, i.e. Google Drive document, that can not be fetched from Slack.
+func isExternal(sf *slack.File) bool {
+	return sf.IsExternal || sf.Mode == "external"
+}
 
-	return int64(n), nil
+// fileURL returns the URL to download the file from.  URLPrivateDownload is
+// preferred, if it's empty, it falls back to URLPrivate and PermalinkPublic.
+// It returns an empty string, if the file has no URLs.
+func fileURL(sf *slack.File) string {
+	for _, u := range []string{sf.URLPrivateDownload, sf.URLPrivate, sf.PermalinkPublic} {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
 }
 
+// download downloads the file sf from srcURL into the temporary file tf,
+// truncating it first.  The download is retried, if Slack rate limits it, or if there's a
+// transient network error.  It returns ErrSizeMismatch, if the number of bytes
+// received differs from the file size, unless the file size is unknown.
+func (c *Client) download(ctx context.Context, tf *os.File, srcURL string, sf *slack.File) error {
+	if err := network.WithRetry(ctx, c.limiter, c.retries, func() error {
+		region := trace.StartRegion(ctx, "GetFile")
+		defer region.End()
+
+		// discard anything that the previous attempt might have written.
+		if err := tf.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := tf.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return c.getFile(ctx, srcURL, newLimitedWriter(ctx, tf, c.bwLimiter))
+	}); err != nil {
+		return err
+	}
+	if sf.Size <= 0 {
+		return nil
+	}
+	fi, err := tf.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() != int64(sf.Size) {
+		return fmt.Errorf("%w: got %d bytes, want %d", ErrSizeMismatch, fi.Size(), sf.Size)
+	}
+	return nil
+}
+
+// statter is implemented by the filesystem adapters that are able to report on
+// the existing files, i.e. fsadapter.Directory.
+type statter interface {
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// isComplete returns true if the file at filePath exists on the filesystem and
+// has the same size as sf.  Files that have a different size are considered
+// partial, and will be downloaded again from scratch.  The size of the
+// compressed files is checked after decompression.
+func (c *Client) isComplete(filePath string, sf *slack.File) bool {
+	if c.compresses(sf) {
+		n, ok := c.uncompressedSize(filePath)
+		return ok && n == int64(sf.Size)
+	}
+	st, ok := c.fs.(statter)
+	if !ok {
+		return false
+	}
+	fi, err := st.Stat(filePath)
+	if err != nil {
+		return false
+	}
+	return fi.Mode().IsRegular() && fi.Size() == int64(sf.Size)
+}
+
+// ErrHTMLResponse is returned if the server responded with an HTML page
+// instead of the requested file.
+var ErrHTMLResponse = errors.New("server returned an HTML page instead of the file, check the credentials")
+
+// isUnexpectedHTML sniffs the content of r and returns true if it looks like
+// an HTML page, while the file sf is not expected to be one.  Slack returns
+// the login page with 200 OK status, if the file is requested with the wrong
+// credentials.  The text files are not sniffed, see isTextFile.  It rewinds r
+// to the start before returning.
+func isUnexpectedHTML(sf *slack.File, r io.ReadSeeker) (bool, error) {
+	if isTextFile(sf) {
+		return false, nil
+	}
+	var buf [512]byte // http.DetectContentType considers at most 512 bytes.
+	n, err := io.ReadFull(r, buf[:])
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(http.DetectContentType(buf[:n]), "text/html"), nil
+}
+
+// isTextFile returns true if the file is expected to be a text document,
+// i.e. the snippet, the XML or SVG document, that may legitimately start
+// with the markup, that is sniffed as HTML.  The content of the canvases is
+// downloaded as HTML.
+func isTextFile(sf *slack.File) bool {
+	mimetype := strings.ToLower(sf.Mimetype)
+	return strings.HasPrefix(mimetype, "text/") || strings.Contains(mimetype, "xml") || strings.EqualFold(sf.Filetype, "html") || IsCanvas(sf)
+}
+
+// IsCanvas returns true if the file sf is a canvas.
+func IsCanvas(sf *slack.File) bool {
+	return sf.Mimetype == "application/vnd.slack-docs" || strings.EqualFold(sf.Filetype, "quip") || strings.EqualFold(sf.Filetype, "canvas")
+}
+
+// stdFilenameFn returns the filename in the form of "ID-Name", that is safe
+// to use on any platform.
 func stdFilenameFn(f *slack.File) string {
-	return fmt.Sprintf("%s-%s", f.ID, f.Name)
+	return sanitizeFilename(fmt.Sprintf("%s-%s", f.ID, f.Name))
 }
 
 // Stop waits for all transfers to finish, and stops the downloader.
@@ -306,19 +758,133 @@ func (c *Client) Stop() {
 	c.l().Debugf("download files channel closed, waiting for downloads to complete")
 	c.wg.Wait()
 	c.l().Debugf("wait complete:  all files downloaded")
+	c.logSummary()
 
 	c.fileRequests = nil
 	c.wg = nil
 	c.started = false
 }
 
-var ErrNotStarted = errors.New("downloader not started")
+var (
+	ErrNotStarted = errors.New("downloader not started")
+	// ErrSkipped is returned by DownloadFile, if the file was rejected by the
+	// filters, or exceeds the maximum file size.
+	ErrSkipped = errors.New("file skipped by the filter")
+)
+
+// Accepts returns true if the file would be downloaded, i.e. if it is not an
+// external reference, does not exceed the maximum file size, the total
+// download limit is not reached, and it is accepted by all filters.  As the
+// files are downloaded concurrently, the file that was accepted may still be
+// skipped, if the limit is reached by the time it gets to the worker.
+func (c *Client) Accepts(f *slack.File) bool {
+	if isExternal(f) || c.tooLarge(f) || c.limitReached() {
+		return false
+	}
+	for _, fn := range c.filters {
+		if !fn(f) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Client) tooLarge(f *slack.File) bool {
+	return c.maxFileSize > 0 && int64(f.Size) > c.maxFileSize
+}
+
+// limitReached returns true if the total download limit is reached.
+func (c *Client) limitReached() bool {
+	return c.maxTotal > 0 && c.total.Load() >= c.maxTotal
+}
+
+// addTotal adds the number of bytes, that were written to the filesystem for
+// the file result res, to the total.  Linked duplicates and the existing
+// files, that were not downloaded again, do not count.
+func (c *Client) addTotal(res FileResult) {
+	if res.Duplicate != "" {
+		return
+	}
+	n := res.Size
+	if res.CompressedSize > 0 {
+		n = res.CompressedSize
+	}
+	c.total.Add(n)
+}
+
+// skip returns true if the file should not be downloaded.  Skipped files are
+// logged and counted.
+func (c *Client) skip(f *slack.File) bool {
+	if c.Accepts(f) {
+		return false
+	}
+	if isExternal(f) {
+		c.l().Printf("file %q is an external reference, skipping", f.Name)
+	} else if c.tooLarge(f) {
+		logger.With(c.l(), "file", c.nameFn(f), "bytes", f.Size).Printf("file %q is %d bytes, exceeds the limit of %d bytes, skipping", c.nameFn(f), f.Size, c.maxFileSize)
+	} else if c.limitReached() {
+		if c.totalLogged.CompareAndSwap(false, true) {
+			c.l().Printf("WARNING: the total download limit of %d bytes is reached, the remaining files will be skipped", c.maxTotal)
+		}
+		c.l().Debugf("file %q is over the total download limit, skipping", c.nameFn(f))
+	} else {
+		c.l().Debugf("file %q is filtered out, skipping", c.nameFn(f))
+	}
+	c.errMu.Lock()
+	c.skipped++
+	c.errMu.Unlock()
+	return true
+}
+
+// logSummary logs the number of files downloaded, skipped and failed.  Nothing
+// is logged, if there were no files.
+func (c *Client) logSummary() {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	if c.saved+c.skipped+len(c.errs) == 0 {
+		return
+	}
+	c.l().Printf("files: %d downloaded, %d skipped, %d failed", c.saved, c.skipped, len(c.errs))
+}
+
+// Skipped returns the number of files that were not downloaded, because they
+// were rejected by the filters, exceeded the maximum file size, or the total
+// download limit was reached.
+func (c *Client) Skipped() int {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	return c.skipped
+}
+
+// Stats is the file download statistics.
+type Stats struct {
+	Saved   int   // number of files saved
+	Skipped int   // number of files skipped
+	Failed  int   // number of files that failed to download
+	Bytes   int64 // number of bytes written
+}
+
+// Add adds the statistics of other to s.
+func (s *Stats) Add(other Stats) {
+	s.Saved += other.Saved
+	s.Skipped += other.Skipped
+	s.Failed += other.Failed
+	s.Bytes += other.Bytes
+}
+
+// Stats returns the download statistics.  Same as Errors, it should be called
+// once the downloads are complete.
+func (c *Client) Stats() Stats {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	return Stats{Saved: c.saved, Skipped: c.skipped, Failed: len(c.errs), Bytes: c.written}
+}
 
 // DownloadFile requires a started downloader, otherwise it will return
 // ErrNotStarted. Will place the file to the download queue, and save the file
 // to the directory that was specified when Start was called. If the file buffer
 // is full, will block until it becomes empty.  It returns the filepath within the
-// filesystem.
+// filesystem.  If the file is rejected by the filters, it returns ErrSkipped.
 func (c *Client) DownloadFile(dir string, f slack.File) (string, error) {
 	c.mu.Lock()
 	started := c.started
@@ -327,8 +893,13 @@ func (c *Client) DownloadFile(dir string, f slack.File) (string, error) {
 	if !started {
 		return "", ErrNotStarted
 	}
+	if c.skip(&f) {
+		c.addRecord(dir, &f, StatusSkipped, int64(f.Size), 0, nil)
+		return "", ErrSkipped
+	}
+	c.addQueued()
 	c.fileRequests <- fileRequest{Directory: dir, File: &f}
-	return path.Join(dir, Filename(&f)), nil
+	return path.Join(dir, c.Filename(&f)), nil
 }
 
 func (c *Client) l() logger.Interface {
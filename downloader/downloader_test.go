@@ -1,16 +1,24 @@
 package downloader
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"errors"
 
 	gomock "github.com/golang/mock/gomock"
+	"github.com/rusq/dlog"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,6 +27,7 @@ import (
 	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/internal/fixtures"
 	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
+	"github.com/rusq/slackdump/v2/internal/network"
 )
 
 var (
@@ -59,7 +68,7 @@ func TestSession_SaveFileTo(t *testing.T) {
 				l:       rate.NewLimiter(defLimit, 1),
 				fs:      fsadapter.NewDirectory(tmpdir),
 				retries: defRetries,
-				workers: defNumWorkers,
+				workers: DefaultWorkers(),
 				nameFn:  Filename,
 			},
 			args{
@@ -82,7 +91,7 @@ func TestSession_SaveFileTo(t *testing.T) {
 				l:       rate.NewLimiter(defLimit, 1),
 				fs:      fsadapter.NewDirectory(tmpdir),
 				retries: defRetries,
-				workers: defNumWorkers,
+				workers: DefaultWorkers(),
 				nameFn:  Filename,
 			},
 			args{
@@ -109,7 +118,7 @@ func TestSession_SaveFileTo(t *testing.T) {
 			sd := &Client{
 				client:  mc,
 				fs:      tt.fields.fs,
-				limiter: tt.fields.l,
+				limiter: network.AdaptiveLimiter(tt.fields.l),
 				retries: tt.fields.retries,
 				workers: tt.fields.workers,
 				nameFn:  tt.fields.nameFn,
@@ -155,7 +164,7 @@ func TestSession_saveFile(t *testing.T) {
 				l:       rate.NewLimiter(defLimit, 1),
 				fs:      fsadapter.NewDirectory(tmpdir),
 				retries: defRetries,
-				workers: defNumWorkers,
+				workers: DefaultWorkers(),
 				nameFn:  Filename,
 			},
 			args{
@@ -178,7 +187,7 @@ func TestSession_saveFile(t *testing.T) {
 				l:       rate.NewLimiter(defLimit, 1),
 				fs:      fsadapter.NewDirectory(tmpdir),
 				retries: defRetries,
-				workers: defNumWorkers,
+				workers: DefaultWorkers(),
 				nameFn:  Filename,
 			},
 			args{
@@ -205,7 +214,7 @@ func TestSession_saveFile(t *testing.T) {
 			sd := &Client{
 				client:  mc,
 				fs:      tt.fields.fs,
-				limiter: tt.fields.l,
+				limiter: network.AdaptiveLimiter(tt.fields.l),
 				retries: tt.fields.retries,
 				workers: tt.fields.workers,
 				nameFn:  tt.fields.nameFn,
@@ -215,7 +224,7 @@ func TestSession_saveFile(t *testing.T) {
 				t.Errorf("Session.saveFileWithLimiter() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if got != tt.want {
+			if got.Size != tt.want {
 				t.Errorf("Session.saveFileWithLimiter() = %v, want %v", got, tt.want)
 			}
 		})
@@ -232,6 +241,7 @@ func Test_filename(t *testing.T) {
 		want string
 	}{
 		{"file1", args{&file1}, "f1-filename1.ext"},
+		{"unsafe name", args{&slack.File{ID: "f2", Name: "a/b:c?.txt"}}, "f2-a_b_c_.txt"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -255,7 +265,7 @@ func TestSession_newFileDownloader(t *testing.T) {
 		sd := Client{
 			client:  mc,
 			fs:      fsadapter.NewDirectory(tmpdir),
-			limiter: tl,
+			limiter: network.AdaptiveLimiter(tl),
 			retries: 3,
 			workers: 4,
 			nameFn:  Filename,
@@ -263,6 +273,7 @@ func TestSession_newFileDownloader(t *testing.T) {
 
 		mc.EXPECT().
 			GetFile(file9.URLPrivateDownload, gomock.Any()).
+			SetArg(1, *fixtures.FilledFile(file9.Size)).
 			Return(nil).
 			Times(1)
 
@@ -290,9 +301,9 @@ func TestSession_worker(t *testing.T) {
 		return &Client{
 			client:  mc,
 			fs:      fsadapter.NewDirectory(tmpdir),
-			limiter: tl,
+			limiter: network.AdaptiveLimiter(tl),
 			retries: defRetries,
-			workers: defNumWorkers,
+			workers: DefaultWorkers(),
 			nameFn:  Filename,
 		}
 	}
@@ -303,6 +314,7 @@ func TestSession_worker(t *testing.T) {
 
 		mc.EXPECT().
 			GetFile(file1.URLPrivateDownload, gomock.Any()).
+			SetArg(1, *fixtures.FilledFile(file1.Size)).
 			Return(nil).
 			Times(1)
 
@@ -313,7 +325,7 @@ func TestSession_worker(t *testing.T) {
 		reqC <- fileRequest{Directory: ".", File: &file1}
 		close(reqC)
 
-		sd.worker(ctx, reqC)
+		sd.worker(ctx, reqC, new(seenSet))
 		assert.FileExists(t, filepath.Join(tmpdir, Filename(&file1)))
 	})
 	t.Run("getfile error", func(t *testing.T) {
@@ -332,9 +344,14 @@ func TestSession_worker(t *testing.T) {
 		reqC <- fileRequest{Directory: "01", File: &file1}
 		close(reqC)
 
-		sd.worker(ctx, reqC)
+		sd.worker(ctx, reqC, new(seenSet))
 		_, err := os.Stat(filepath.Join(tmpdir, "01", Filename(&file1)))
 		assert.True(t, os.IsNotExist(err))
+
+		errs := sd.Errors()
+		require.Len(t, errs, 1)
+		assert.Equal(t, file1.ID, errs[0].File.ID)
+		assert.ErrorContains(t, errs[0], "rekt")
 	})
 	t.Run("cancelled context", func(t *testing.T) {
 		mc := mock_downloader.NewMockDownloader(gomock.NewController(t))
@@ -345,7 +362,7 @@ func TestSession_worker(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 		cancel()
 
-		sd.worker(ctx, reqC)
+		sd.worker(ctx, reqC, new(seenSet))
 	})
 }
 
@@ -358,8 +375,8 @@ func TestClient_startWorkers(t *testing.T) {
 		cl := Client{
 			client:  dc,
 			fs:      fsadapter.NewDirectory(t.TempDir()),
-			limiter: rate.NewLimiter(5000, 1),
-			workers: defNumWorkers,
+			limiter: network.AdaptiveLimiter(rate.NewLimiter(5000, 1)),
+			workers: DefaultWorkers(),
 			nameFn:  Filename,
 		}
 
@@ -373,6 +390,39 @@ func TestClient_startWorkers(t *testing.T) {
 	})
 }
 
+func TestClient_AsyncDownloader_errors(t *testing.T) {
+	const (
+		numFiles = 50
+		numFail  = 20
+	)
+	ctrl := gomock.NewController(t)
+	dc := mock_downloader.NewMockDownloader(ctrl)
+	cl := New(dc, fsadapter.NewDirectory(t.TempDir()), Workers(8))
+
+	files := make([]*slack.File, numFiles)
+	for i := range files {
+		files[i] = &slack.File{ID: fmt.Sprintf("F%03d", i), Name: "file.ext", URLPrivateDownload: fmt.Sprintf("url%03d", i)}
+		var err error
+		if i < numFail {
+			err = errors.New("not found")
+		}
+		dc.EXPECT().GetFile(files[i].URLPrivateDownload, gomock.Any()).Return(err)
+	}
+	assert.Nil(t, cl.Errors(), "no errors before download")
+
+	done, err := cl.AsyncDownloader(context.Background(), "dir", slice2chan(files, 0))
+	require.NoError(t, err)
+	<-done
+
+	errs := cl.Errors()
+	require.Len(t, errs, numFail)
+	for _, e := range errs {
+		assert.Less(t, e.File.ID, fmt.Sprintf("F%03d", numFail))
+	}
+	var target DownloadErrors
+	assert.ErrorAs(t, error(errs), &target)
+}
+
 // slice2chan takes the slice of []T, create a chan T and sends all elements of
 // []T to it.  It closes the channel after all elements are sent.
 func slice2chan[T any](input []T, bufSz int) <-chan T {
@@ -426,8 +476,8 @@ func clientWithMock(t *testing.T, dir string) *Client {
 	c := &Client{
 		client:  dc,
 		fs:      fsadapter.NewDirectory(dir),
-		limiter: rate.NewLimiter(5000, 1),
-		workers: defNumWorkers,
+		limiter: network.AdaptiveLimiter(rate.NewLimiter(5000, 1)),
+		workers: DefaultWorkers(),
 		nameFn:  Filename,
 	}
 	return c
@@ -466,3 +516,476 @@ func TestClient_DownloadFile(t *testing.T) {
 		c.Stop()
 	})
 }
+
+func TestClient_saveFile_html(t *testing.T) {
+	const loginPage = "<!DOCTYPE html><html><head><title>Slack</title></head><body>Sign in</body></html>"
+	var (
+		pngFile  = slack.File{ID: "fp", Name: "image.png", Mimetype: "image/png", Filetype: "png", URLPrivateDownload: "png_url"}
+		htmlFile = slack.File{ID: "fh", Name: "page.html", Mimetype: "text/html", Filetype: "html", URLPrivateDownload: "html_url"}
+	)
+	writeLoginPage := func(_ string, w io.Writer) error {
+		_, err := io.WriteString(w, loginPage)
+		return err
+	}
+	t.Run("html instead of png", func(t *testing.T) {
+		dir := t.TempDir()
+		c := clientWithMock(t, dir)
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(pngFile.URLPrivateDownload, gomock.Any()).
+			DoAndReturn(writeLoginPage)
+
+		_, err := c.saveFile(context.Background(), "01", &pngFile)
+		assert.ErrorIs(t, err, ErrHTMLResponse)
+		assert.NoFileExists(t, filepath.Join(dir, "01", Filename(&pngFile)))
+	})
+	t.Run("html file is saved", func(t *testing.T) {
+		dir := t.TempDir()
+		c := clientWithMock(t, dir)
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(htmlFile.URLPrivateDownload, gomock.Any()).
+			DoAndReturn(writeLoginPage)
+
+		res, err := c.saveFile(context.Background(), "01", &htmlFile)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(loginPage)), res.Size)
+		assert.FileExists(t, filepath.Join(dir, "01", Filename(&htmlFile)))
+	})
+	t.Run("text files are not sniffed", func(t *testing.T) {
+		files := []struct {
+			file    slack.File
+			content string
+		}{
+			{slack.File{ID: "ft", Name: "snippet.txt", Mimetype: "text/plain", Filetype: "text", URLPrivateDownload: "text_url"}, "<!-- comment -->\n<p>not a login page</p>"},
+			{slack.File{ID: "fs", Name: "logo.svg", Mimetype: "image/svg+xml", Filetype: "svg", URLPrivateDownload: "svg_url"}, "<!-- logo -->\n<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"},
+			{slack.File{ID: "fx", Name: "feed.xml", Mimetype: "application/xml", Filetype: "xml", URLPrivateDownload: "xml_url"}, "<!-- feed -->\n<div>entry</div>"},
+		}
+		for _, f := range files {
+			dir := t.TempDir()
+			c := clientWithMock(t, dir)
+			c.client.(*mock_downloader.MockDownloader).EXPECT().
+				GetFile(f.file.URLPrivateDownload, gomock.Any()).
+				DoAndReturn(func(_ string, w io.Writer) error {
+					_, err := io.WriteString(w, f.content)
+					return err
+				})
+
+			res, err := c.saveFile(context.Background(), "01", &f.file)
+			require.NoError(t, err, f.file.Name)
+			assert.Equal(t, int64(len(f.content)), res.Size, f.file.Name)
+		}
+	})
+}
+
+func TestClient_saveFile_skipExisting(t *testing.T) {
+	const content = "0123456789"
+	f := slack.File{ID: "fs", Name: "data.bin", URLPrivateDownload: "data_url", Size: len(content)}
+	writeContent := func(_ string, w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	}
+	prepare := func(t *testing.T, existing string) (*Client, string) {
+		dir := t.TempDir()
+		fpath := filepath.Join(dir, "01", Filename(&f))
+		require.NoError(t, os.MkdirAll(filepath.Dir(fpath), 0755))
+		require.NoError(t, os.WriteFile(fpath, []byte(existing), 0644))
+		c := clientWithMock(t, dir)
+		c.skipExisting = true
+		return c, fpath
+	}
+	t.Run("complete file is skipped", func(t *testing.T) {
+		c, fpath := prepare(t, content)
+		// no GetFile calls expected
+		res, err := c.saveFile(context.Background(), "01", &f)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), res.Size)
+		data, err := os.ReadFile(fpath)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+	t.Run("partial file is downloaded from scratch", func(t *testing.T) {
+		c, fpath := prepare(t, "01234")
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(f.URLPrivateDownload, gomock.Any()).
+			DoAndReturn(writeContent)
+
+		res, err := c.saveFile(context.Background(), "01", &f)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), res.Size)
+		data, err := os.ReadFile(fpath)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		c, _ := prepare(t, content)
+		c.skipExisting = false
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(f.URLPrivateDownload, gomock.Any()).
+			DoAndReturn(writeContent)
+
+		_, err := c.saveFile(context.Background(), "01", &f)
+		require.NoError(t, err)
+	})
+}
+
+func TestClient_AsyncDownloader_zip(t *testing.T) {
+	const numFiles = 100
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	ctrl := gomock.NewController(t)
+	dc := mock_downloader.NewMockDownloader(ctrl)
+	cl := New(dc, fsadapter.NewZIP(zw), Workers(8))
+
+	want := make(map[string]string, numFiles)
+	files := make([]*slack.File, numFiles)
+	for i := range files {
+		files[i] = &slack.File{ID: fmt.Sprintf("F%03d", i), Name: "file.txt", URLPrivateDownload: fmt.Sprintf("url%03d", i)}
+		content := strings.Repeat(files[i].ID, 1000+i)
+		want[path.Join("C123", Filename(files[i]))] = content
+		dc.EXPECT().
+			GetFile(files[i].URLPrivateDownload, gomock.Any()).
+			DoAndReturn(func(_ string, w io.Writer) error {
+				_, err := io.WriteString(w, content)
+				return err
+			})
+	}
+
+	done, err := cl.AsyncDownloader(context.Background(), "C123", slice2chan(files, 0))
+	require.NoError(t, err)
+	<-done
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	got := make(map[string]string, numFiles)
+	for _, zf := range zr.File {
+		if strings.HasSuffix(zf.Name, "/") {
+			continue // directory
+		}
+		rc, err := zf.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		got[zf.Name] = string(data)
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestClient_saveFile_verify(t *testing.T) {
+	const content = "0123456789"
+	f := slack.File{ID: "fv", Name: "data.bin", URLPrivateDownload: "data_url", Size: len(content)}
+	writeFn := func(s string) func(string, io.Writer) error {
+		return func(_ string, w io.Writer) error {
+			_, err := io.WriteString(w, s)
+			return err
+		}
+	}
+	t.Run("sha256 is returned", func(t *testing.T) {
+		c := clientWithMock(t, t.TempDir())
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(f.URLPrivateDownload, gomock.Any()).
+			DoAndReturn(writeFn(content))
+
+		res, err := c.SaveFileResult(context.Background(), "01", &f)
+		require.NoError(t, err)
+		assert.Equal(t, FileResult{
+			Path:   filepath.Join("01", Filename(&f)),
+			Size:   int64(len(content)),
+			SHA256: "84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882",
+		}, res)
+	})
+	t.Run("truncated download is retried", func(t *testing.T) {
+		dir := t.TempDir()
+		c := clientWithMock(t, dir)
+		c.retries = 3
+		gomock.InOrder(
+			c.client.(*mock_downloader.MockDownloader).EXPECT().
+				GetFile(f.URLPrivateDownload, gomock.Any()).
+				DoAndReturn(writeFn(content[:5])),
+			c.client.(*mock_downloader.MockDownloader).EXPECT().
+				GetFile(f.URLPrivateDownload, gomock.Any()).
+				DoAndReturn(writeFn(content)),
+		)
+
+		res, err := c.saveFile(context.Background(), "01", &f)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), res.Size)
+		data, err := os.ReadFile(filepath.Join(dir, "01", Filename(&f)))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+	t.Run("gives up after all retries", func(t *testing.T) {
+		dir := t.TempDir()
+		c := clientWithMock(t, dir)
+		c.retries = 2
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(f.URLPrivateDownload, gomock.Any()).
+			DoAndReturn(writeFn(content[:5])).
+			Times(2)
+
+		_, err := c.saveFile(context.Background(), "01", &f)
+		assert.ErrorIs(t, err, ErrSizeMismatch)
+		assert.NoFileExists(t, filepath.Join(dir, "01", Filename(&f)))
+	})
+	t.Run("unknown size is not verified", func(t *testing.T) {
+		c := clientWithMock(t, t.TempDir())
+		unsized := f
+		unsized.Size = 0
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile(f.URLPrivateDownload, gomock.Any()).
+			DoAndReturn(writeFn(content[:5]))
+
+		res, err := c.saveFile(context.Background(), "01", &unsized)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), res.Size)
+	})
+}
+
+// blockingDownloader is the Downloader that writes some data and then blocks
+// until the context is cancelled.
+type blockingDownloader struct {
+	started chan struct{}
+}
+
+func (blockingDownloader) GetFile(string, io.Writer) error {
+	panic("GetFileContext should be used")
+}
+
+func (bd blockingDownloader) GetFileContext(ctx context.Context, _ string, w io.Writer) error {
+	if _, err := io.WriteString(w, "partial"); err != nil {
+		return err
+	}
+	close(bd.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestClient_saveFile_cancel(t *testing.T) {
+	dir := t.TempDir()
+	bd := blockingDownloader{started: make(chan struct{})}
+	c := New(bd, fsadapter.NewDirectory(dir))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-bd.started
+		cancel()
+	}()
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := c.saveFile(ctx, "01", &file1)
+		errC <- err
+	}()
+	select {
+	case err := <-errC:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("saveFile did not return after the context was cancelled")
+	}
+	assert.NoFileExists(t, filepath.Join(dir, "01", Filename(&file1)))
+}
+
+func TestClient_worker_cancelledDoesNotDrain(t *testing.T) {
+	c := clientWithMock(t, t.TempDir()) // no GetFile calls expected
+
+	reqC := make(chan fileRequest, 10)
+	for _, f := range []slack.File{file1, file2, file3, file4, file5} {
+		f := f
+		reqC <- fileRequest{Directory: "01", File: &f}
+	}
+	close(reqC)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.worker(ctx, reqC, new(seenSet))
+	assert.NotEmpty(t, reqC, "worker should not drain the channel")
+}
+
+func TestClient_saveFile_preserveTimes(t *testing.T) {
+	uploaded := time.Date(2021, 12, 3, 2, 15, 51, 0, time.UTC)
+	f := slack.File{ID: "ft", Name: "photo.jpg", URLPrivateDownload: "photo_url", Timestamp: slack.JSONTime(uploaded.Unix())}
+	for _, keep := range []bool{true, false} {
+		t.Run(fmt.Sprintf("keepTimes=%v", keep), func(t *testing.T) {
+			dir := t.TempDir()
+			c := clientWithMock(t, dir)
+			c.keepTimes = keep
+			c.client.(*mock_downloader.MockDownloader).EXPECT().
+				GetFile(f.URLPrivateDownload, gomock.Any()).
+				Return(nil)
+
+			_, err := c.saveFile(context.Background(), "01", &f)
+			require.NoError(t, err)
+			fi, err := os.Stat(filepath.Join(dir, "01", Filename(&f)))
+			require.NoError(t, err)
+			assert.Equal(t, keep, uploaded.Equal(fi.ModTime()), "mtime: %s", fi.ModTime())
+		})
+	}
+}
+
+func TestClient_logging(t *testing.T) {
+	run := func(t *testing.T, debug bool) string {
+		var buf bytes.Buffer
+		ctrl := gomock.NewController(t)
+		dc := mock_downloader.NewMockDownloader(ctrl)
+		c := New(dc, fsadapter.NewDirectory(t.TempDir()), Logger(dlog.New(&buf, "", 0, debug)))
+		dc.EXPECT().GetFile(file1.URLPrivateDownload, gomock.Any()).SetArg(1, *fixtures.FilledFile(file1.Size)).Return(nil)
+		dc.EXPECT().GetFile(file2.URLPrivateDownload, gomock.Any()).Return(errors.New("rekt"))
+
+		done, err := c.AsyncDownloader(context.Background(), "x", slice2chan([]*slack.File{&file1, &file2}, 0))
+		require.NoError(t, err)
+		<-done
+		return buf.String()
+	}
+	t.Run("normal", func(t *testing.T) {
+		out := run(t, false)
+		assert.NotContains(t, out, "saved to")
+		assert.Contains(t, out, "error saving")
+		assert.Contains(t, out, "files: 1 downloaded, 0 skipped, 1 failed")
+	})
+	t.Run("verbose", func(t *testing.T) {
+		out := run(t, true)
+		assert.Contains(t, out, "saving \"f1-filename1.ext\"")
+		assert.Contains(t, out, "saved to")
+		assert.Contains(t, out, "files: 1 downloaded, 0 skipped, 1 failed")
+	})
+}
+
+func TestClient_saveFile_retry(t *testing.T) {
+	// make the waits between retries short.
+	network.SetMaxAllowedWaitTime(10 * time.Millisecond)
+	defer network.SetMaxAllowedWaitTime(5 * time.Minute)
+
+	var (
+		errUnavailable = slack.StatusCodeError{Code: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+		errNotFound    = slack.StatusCodeError{Code: http.StatusNotFound, Status: "404 Not Found"}
+		errRateLimited = &slack.RateLimitedError{RetryAfter: 10 * time.Millisecond}
+	)
+	tests := []struct {
+		name      string
+		errs      []error // errors returned by consecutive GetFile calls
+		wantCalls int
+		wantErr   bool
+	}{
+		{"fails twice then succeeds", []error{errUnavailable, errRateLimited, nil}, 3, false},
+		{"permanent error fails fast", []error{errNotFound, nil}, 1, true},
+		{"runs out of retries", []error{errUnavailable, errUnavailable, errUnavailable, nil}, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			c := clientWithMock(t, dir)
+			c.retries = 3
+
+			calls := 0
+			c.client.(*mock_downloader.MockDownloader).EXPECT().
+				GetFile(file1.URLPrivateDownload, gomock.Any()).
+				DoAndReturn(func(_ string, w io.Writer) error {
+					err := tt.errs[calls]
+					calls++
+					if err == nil {
+						_, err = w.Write(make([]byte, file1.Size))
+					}
+					return err
+				}).
+				AnyTimes()
+
+			_, err := c.saveFile(context.Background(), "01", &file1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("saveFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantCalls, calls)
+			if !tt.wantErr {
+				assert.FileExists(t, filepath.Join(dir, "01", Filename(&file1)))
+			}
+		})
+	}
+}
+
+func TestClient_saveFile_external(t *testing.T) {
+	const content = "0123456789"
+	writeFn := func(_ string, w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	}
+	t.Run("falls back to URLPrivate", func(t *testing.T) {
+		dir := t.TempDir()
+		f := slack.File{ID: "fe1", Name: "doc.txt", URLPrivate: "private_url", Size: len(content)}
+		c := clientWithMock(t, dir)
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile("private_url", gomock.Any()).
+			DoAndReturn(writeFn)
+
+		res, err := c.saveFile(context.Background(), "01", &f)
+		require.NoError(t, err)
+		assert.False(t, res.External)
+		assert.FileExists(t, filepath.Join(dir, "01", Filename(&f)))
+	})
+	t.Run("falls back to PermalinkPublic", func(t *testing.T) {
+		f := slack.File{ID: "fe2", Name: "doc.txt", PermalinkPublic: "public_url", Size: len(content)}
+		c := clientWithMock(t, t.TempDir())
+		c.client.(*mock_downloader.MockDownloader).EXPECT().
+			GetFile("public_url", gomock.Any()).
+			DoAndReturn(writeFn)
+
+		_, err := c.saveFile(context.Background(), "01", &f)
+		require.NoError(t, err)
+	})
+	t.Run("external file is skipped", func(t *testing.T) {
+		dir := t.TempDir()
+		f := slack.File{ID: "fe3", Name: "Drive doc", IsExternal: true, URLPrivate: "https://docs.google.com/x"}
+		c := clientWithMock(t, dir) // no calls expected
+
+		res, err := c.saveFile(context.Background(), "01", &f)
+		require.NoError(t, err)
+		assert.Equal(t, FileResult{External: true}, res)
+		assert.NoFileExists(t, filepath.Join(dir, "01", Filename(&f)))
+		assert.False(t, c.Accepts(&f))
+	})
+	t.Run("file without URLs is skipped", func(t *testing.T) {
+		f := slack.File{ID: "fe4", Name: "nothing"}
+		c := clientWithMock(t, t.TempDir())
+
+		res, err := c.saveFile(context.Background(), "01", &f)
+		require.NoError(t, err)
+		assert.Equal(t, FileResult{}, res)
+	})
+	t.Run("file without URLs is counted as skipped", func(t *testing.T) {
+		f := slack.File{ID: "fe5", Name: "nothing"}
+		c := clientWithMock(t, t.TempDir())
+
+		reqC := make(chan fileRequest, 1)
+		reqC <- fileRequest{Directory: "01", File: &f}
+		close(reqC)
+		c.worker(context.Background(), reqC, new(seenSet))
+
+		assert.Equal(t, Stats{Skipped: 1}, c.Stats())
+	})
+}
+
+func TestDefaultWorkers(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	tests := []struct {
+		procs int
+		want  int
+	}{
+		{1, minWorkers},
+		{8, 8},
+		{64, maxWorkers},
+	}
+	for _, tt := range tests {
+		runtime.GOMAXPROCS(tt.procs)
+		if got := DefaultWorkers(); got != tt.want {
+			t.Errorf("DefaultWorkers() with GOMAXPROCS=%d = %d, want %d", tt.procs, got, tt.want)
+		}
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	var c Client
+	Workers(32)(&c)
+	assert.Equal(t, 32, c.workers, "explicit value must be used as is")
+	Workers(0)(&c)
+	assert.Equal(t, DefaultWorkers(), c.workers)
+}
@@ -0,0 +1,44 @@
+package downloader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// maxErrorsShown is the maximum number of individual errors that
+// DownloadErrors.Error includes in the message.
+const maxErrorsShown = 3
+
+// DownloadError is the error that occurred while downloading the File.
+type DownloadError struct {
+	File slack.File
+	Err  error
+}
+
+func (e DownloadError) Error() string {
+	return fmt.Sprintf("file %s (%s): %s", e.File.ID, e.File.Name, e.Err)
+}
+
+func (e DownloadError) Unwrap() error {
+	return e.Err
+}
+
+// DownloadErrors is the list of download errors.  It satisfies the error
+// interface, so it can be returned to the caller as is.
+type DownloadErrors []DownloadError
+
+func (de DownloadErrors) Error() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%d file(s) failed to download", len(de))
+	for i, e := range de {
+		if i == maxErrorsShown {
+			fmt.Fprintf(&buf, "; and %d more", len(de)-maxErrorsShown)
+			break
+		}
+		buf.WriteString("; ")
+		buf.WriteString(e.Error())
+	}
+	return buf.String()
+}
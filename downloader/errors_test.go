@@ -0,0 +1,48 @@
+package downloader
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadErrors_Error(t *testing.T) {
+	errNotFound := errors.New("not found")
+	mkErr := func(id string) DownloadError {
+		return DownloadError{File: slack.File{ID: id, Name: id + ".png"}, Err: errNotFound}
+	}
+	tests := []struct {
+		name string
+		de   DownloadErrors
+		want string
+	}{
+		{
+			"single",
+			DownloadErrors{mkErr("F1")},
+			"1 file(s) failed to download; file F1 (F1.png): not found",
+		},
+		{
+			"at limit",
+			DownloadErrors{mkErr("F1"), mkErr("F2"), mkErr("F3")},
+			"3 file(s) failed to download; file F1 (F1.png): not found; file F2 (F2.png): not found; file F3 (F3.png): not found",
+		},
+		{
+			"over limit",
+			DownloadErrors{mkErr("F1"), mkErr("F2"), mkErr("F3"), mkErr("F4"), mkErr("F5")},
+			"5 file(s) failed to download; file F1 (F1.png): not found; file F2 (F2.png): not found; file F3 (F3.png): not found; and 2 more",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.de.Error())
+		})
+	}
+}
+
+func TestDownloadError_Unwrap(t *testing.T) {
+	errNotFound := errors.New("not found")
+	var err error = DownloadError{File: slack.File{ID: "F1"}, Err: errNotFound}
+	assert.ErrorIs(t, err, errNotFound)
+}
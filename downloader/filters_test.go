@@ -1,14 +1,23 @@
 package downloader
 
 import (
+	"context"
+	"io"
+	"sync"
 	"testing"
 
-	"github.com/rusq/slackdump/v2/internal/fixtures"
+	"github.com/golang/mock/gomock"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/fixtures"
+	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
+	"github.com/rusq/slackdump/v2/internal/network"
 )
 
-func Test_fltSeen(t *testing.T) {
+func Test_seenSet(t *testing.T) {
 	t.Run("ensure that we don't get dup files", func(t *testing.T) {
 		source := []fileRequest{
 			{Directory: "x", File: &file1},
@@ -31,44 +40,90 @@ func Test_fltSeen(t *testing.T) {
 			{Directory: "y", File: &file5},
 		}
 
-		filesC := make(chan fileRequest)
-		go func() {
-			defer close(filesC)
-			for _, f := range source {
-				filesC <- f
-			}
-		}()
-
-		c := Client{}
-		dlqC := c.fltSeen(filesC)
-
+		var seen seenSet
 		var got []fileRequest
-		for f := range dlqC {
+		for _, f := range source {
+			if seen.markSeen(f) {
+				continue
+			}
 			got = append(got, f)
 		}
 		assert.Equal(t, want, got)
 	})
+	t.Run("nil set never reports seen", func(t *testing.T) {
+		var seen *seenSet
+		assert.False(t, seen.markSeen(fileRequest{Directory: "x", File: &file1}))
+		assert.False(t, seen.markSeen(fileRequest{Directory: "x", File: &file1}))
+	})
 }
 
-func BenchmarkFltSeen(b *testing.B) {
-	const numReq = 100_000
-	input := makeFileReqQ(numReq, b.TempDir())
-	inputC := make(chan fileRequest)
-	go func() {
-		defer close(inputC)
-		for _, req := range input {
-			inputC <- req
-		}
-	}()
-	c := Client{}
-	outputC := c.fltSeen(inputC)
+func TestClient_startWorkers_noDuplicates(t *testing.T) {
+	const (
+		numUnique = 200
+		numDupes  = 10 // each file is sent this many times
+		numWorker = 64
+	)
+	ctrl := gomock.NewController(t)
+	dc := mock_downloader.NewMockDownloader(ctrl)
+	cl := Client{
+		client:  dc,
+		fs:      fsadapter.NewDirectory(t.TempDir()),
+		limiter: network.AdaptiveLimiter(rate.NewLimiter(rate.Inf, 1)),
+		workers: numWorker,
+		nameFn:  Filename,
+	}
 
-	for n := 0; n < b.N; n++ {
-		for out := range outputC {
-			_ = out
-		}
+	var (
+		mu    sync.Mutex
+		calls = make(map[string]int, numUnique)
+	)
+	dc.EXPECT().GetFile(gomock.Any(), gomock.Any()).Times(numUnique).DoAndReturn(func(url string, _ any) error {
+		mu.Lock()
+		calls[url]++
+		mu.Unlock()
+		return nil
+	})
+
+	unique := makeFileReqQ(numUnique, "dir")
+	for i := range unique {
+		unique[i].File.URLPrivateDownload = unique[i].File.ID
+	}
+	var queue []fileRequest
+	for i := 0; i < numDupes; i++ {
+		queue = append(queue, unique...)
+	}
+
+	wg := cl.startWorkers(context.Background(), slice2chan(queue, defFileBufSz))
+	wg.Wait()
+
+	assert.Len(t, calls, numUnique)
+	for url, n := range calls {
+		assert.Equalf(t, 1, n, "file %s downloaded %d times", url, n)
 	}
+}
+
+func BenchmarkSeenSet(b *testing.B) {
+	const numWorker = 8
+	input := makeFileReqQ(b.N, b.TempDir())
+	inputC := make(chan fileRequest)
+	var seen seenSet
 
+	b.ResetTimer()
+	var wg sync.WaitGroup
+	for i := 0; i < numWorker; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range inputC {
+				_ = seen.markSeen(req)
+			}
+		}()
+	}
+	for _, req := range input {
+		inputC <- req
+	}
+	close(inputC)
+	wg.Wait()
 }
 
 func makeFileReqQ(numReq int, dir string) []fileRequest {
@@ -80,5 +135,115 @@ func makeFileReqQ(numReq int, dir string) []fileRequest {
 }
 
 func randomFileReq(dirname string) fileRequest {
-	return fileRequest{Directory: dirname, File: &slack.File{ID: fixtures.RandString(8), Name: fixtures.RandString(12)}}
+	return fileRequest{Directory: dirname, File: &slack.File{ID: fixtures.RandString(8), Name: fixtures.RandString(12), URLPrivateDownload: "https://files.slack.com/" + fixtures.RandString(8)}}
+}
+
+func TestTypeFilter(t *testing.T) {
+	var (
+		jpg = &slack.File{ID: "f1", Filetype: "jpg", Mimetype: "image/jpeg"}
+		png = &slack.File{ID: "f2", Filetype: "PNG", Mimetype: "image/png"}
+		mp4 = &slack.File{ID: "f3", Filetype: "mp4", Mimetype: "video/mp4"}
+		pdf = &slack.File{ID: "f4", Filetype: "pdf", Mimetype: "application/pdf"}
+	)
+	all := []*slack.File{jpg, png, mp4, pdf}
+	tests := []struct {
+		name  string
+		types []string
+		want  []*slack.File
+	}{
+		{"include", []string{"jpg", "png"}, []*slack.File{jpg, png}},
+		{"case insensitive", []string{"JPG", " Png "}, []*slack.File{jpg, png}},
+		{"exclude", []string{"!mp4"}, []*slack.File{jpg, png, pdf}},
+		{"include and exclude", []string{"jpg", "mp4", "!mp4"}, []*slack.File{jpg}},
+		{"mime type wildcard", []string{"image/*"}, []*slack.File{jpg, png}},
+		{"mime type exclude", []string{"!video/mp4", "!application/*"}, []*slack.File{jpg, png}},
+		{"empty items", []string{"", "!"}, all},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := TypeFilter(tt.types)
+			var got []*slack.File
+			for _, f := range all {
+				if fn == nil || fn(f) {
+					got = append(got, f)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	t.Run("empty list returns nil", func(t *testing.T) {
+		assert.Nil(t, TypeFilter(nil))
+	})
+}
+
+func TestClient_DownloadFile_filtered(t *testing.T) {
+	c := clientWithMock(t, t.TempDir()) // no GetFile calls expected
+	WithFilter(TypeFilter([]string{"!ext"}))(c)
+	c.Start(context.Background())
+	defer c.Stop()
+
+	_, err := c.DownloadFile("x", slack.File{ID: "f1", Name: "a.ext", Filetype: "ext"})
+	assert.ErrorIs(t, err, ErrSkipped)
+}
+
+func TestClient_MaxFileSize(t *testing.T) {
+	var (
+		small = slack.File{ID: "fs", Name: "small.png", Filetype: "png", URLPrivateDownload: "small_url", Size: 10}
+		large = slack.File{ID: "fl", Name: "large.mp4", Filetype: "mp4", URLPrivateDownload: "large_url", Size: 2 << 30}
+		video = slack.File{ID: "fv", Name: "video.mp4", Filetype: "mp4", URLPrivateDownload: "video_url", Size: 10}
+	)
+	c := clientWithMock(t, t.TempDir())
+	MaxFileSize(1 << 20)(c)
+	WithFilter(TypeFilter([]string{"!mp4"}))(c)
+
+	assert.True(t, c.Accepts(&small))
+	assert.False(t, c.Accepts(&large))
+	assert.False(t, c.Accepts(&video))
+
+	c.client.(*mock_downloader.MockDownloader).EXPECT().
+		GetFile(small.URLPrivateDownload, gomock.Any()).
+		SetArg(1, *fixtures.FilledFile(small.Size)).
+		Return(nil)
+
+	reqC := make(chan fileRequest, 3)
+	for _, f := range []slack.File{small, large, video} {
+		f := f
+		reqC <- fileRequest{Directory: "x", File: &f}
+	}
+	close(reqC)
+	c.worker(context.Background(), reqC, new(seenSet))
+
+	assert.Equal(t, 2, c.Skipped())
+	assert.Nil(t, c.Errors())
+	assert.Equal(t, Stats{Saved: 1, Skipped: 2, Bytes: int64(small.Size)}, c.Stats())
+}
+
+func TestClient_MaxTotalBytes(t *testing.T) {
+	const content = "0123456789"
+	var (
+		f1 = slack.File{ID: "f1", Name: "one.txt", URLPrivateDownload: "url1", Size: len(content)}
+		f2 = slack.File{ID: "f2", Name: "two.txt", URLPrivateDownload: "url2", Size: len(content)}
+		f3 = slack.File{ID: "f3", Name: "three.txt", URLPrivateDownload: "url3", Size: len(content)}
+	)
+	c := clientWithMock(t, t.TempDir())
+	MaxTotalBytes(15)(c)
+
+	c.client.(*mock_downloader.MockDownloader).EXPECT().
+		GetFile(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, w io.Writer) error {
+			_, err := io.WriteString(w, content)
+			return err
+		}).
+		Times(2)
+
+	reqC := make(chan fileRequest, 3)
+	for _, f := range []slack.File{f1, f2, f3} {
+		f := f
+		reqC <- fileRequest{Directory: "x", File: &f}
+	}
+	close(reqC)
+	c.worker(context.Background(), reqC, new(seenSet))
+
+	assert.False(t, c.Accepts(&f1), "limit is reached")
+	assert.Equal(t, Stats{Saved: 2, Skipped: 1, Bytes: 2 * int64(len(content))}, c.Stats())
 }
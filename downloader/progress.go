@@ -0,0 +1,30 @@
+package downloader
+
+import "github.com/slack-go/slack"
+
+// ProgressFunc is called by the downloader each time a file is processed,
+// whether it was downloaded, skipped or failed.  done is the number of files
+// processed so far, and total is the number of files queued for download so
+// far, which may grow, while files are being queued.  current is the file that
+// was just processed.  Calls are serialised, so that the implementation does
+// not need to be safe for concurrent use, but it should return quickly, as it
+// blocks the download workers.
+type ProgressFunc func(done, total int, current slack.File)
+
+// addQueued increments the number of queued files.
+func (c *Client) addQueued() {
+	c.progMu.Lock()
+	c.queued++
+	c.progMu.Unlock()
+}
+
+// reportProgress increments the number of processed files, and calls the
+// progress function, if it's set.
+func (c *Client) reportProgress(f *slack.File) {
+	c.progMu.Lock()
+	defer c.progMu.Unlock()
+	c.processed++
+	if c.progressFn != nil {
+		c.progressFn(c.processed, c.queued, *f)
+	}
+}
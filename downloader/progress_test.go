@@ -0,0 +1,57 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
+)
+
+func TestClient_Progress(t *testing.T) {
+	const numFiles = 30
+
+	ctrl := gomock.NewController(t)
+	dc := mock_downloader.NewMockDownloader(ctrl)
+
+	var (
+		calls    int
+		lastDone int
+		seen     = make(map[string]bool)
+	)
+	progressFn := func(done, total int, current slack.File) {
+		// not synchronised on purpose, the race detector will complain if
+		// calls are not serialised.
+		calls++
+		assert.Equal(t, lastDone+1, done, "done must increase by one")
+		assert.LessOrEqual(t, done, total)
+		lastDone = done
+		seen[current.ID] = true
+	}
+	cl := New(dc, fsadapter.NewDirectory(t.TempDir()), Workers(8), Progress(progressFn))
+
+	files := make([]*slack.File, numFiles)
+	for i := range files {
+		files[i] = &slack.File{ID: fmt.Sprintf("F%03d", i), Name: "file.ext", URLPrivateDownload: fmt.Sprintf("url%03d", i)}
+		var err error
+		if i%3 == 0 {
+			err = errors.New("failed files are reported too")
+		}
+		dc.EXPECT().GetFile(files[i].URLPrivateDownload, gomock.Any()).Return(err)
+	}
+
+	done, err := cl.AsyncDownloader(context.Background(), "x", slice2chan(files, 0))
+	require.NoError(t, err)
+	<-done
+
+	assert.Equal(t, numFiles, calls)
+	assert.Equal(t, numFiles, lastDone)
+	assert.Len(t, seen, numFiles)
+}
@@ -0,0 +1,64 @@
+package downloader
+
+import (
+	"path"
+
+	"github.com/slack-go/slack"
+)
+
+// FileStatus is the download status of the file.
+type FileStatus string
+
+const (
+	StatusDownloaded FileStatus = "downloaded" // the file is saved
+	StatusSkipped    FileStatus = "skipped"    // the file is rejected by the filters or is not downloadable
+	StatusFailed     FileStatus = "failed"     // the file failed to download
+)
+
+// Record is the download record of the single file.
+type Record struct {
+	ID     string     // Slack file ID
+	Name   string     // original file name
+	Path   string     // path of the file on the filesystem, or the path it would have, if it was not saved
+	Size   int64      // number of bytes written, or the size reported by Slack, if the file was not saved
+	Status FileStatus // download status
+	Error  string     // download error, if the status is StatusFailed
+	// CompressedSize is the size of the file on the filesystem, if it was
+	// compressed, see Compress, otherwise it's zero.
+	CompressedSize int64
+}
+
+// addRecord records the download status of the file f, that was requested to
+// be saved into the directory dir.  compressed is the size of the compressed
+// file, if it was compressed.
+func (c *Client) addRecord(dir string, f *slack.File, status FileStatus, size int64, compressed int64, err error) {
+	r := Record{
+		ID:             f.ID,
+		Name:           f.Name,
+		Path:           path.Join(dir, c.Filename(f)),
+		Size:           size,
+		Status:         status,
+		CompressedSize: compressed,
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	c.errMu.Lock()
+	c.records = append(c.records, r)
+	c.errMu.Unlock()
+}
+
+// Records returns the download records of all files that the downloader
+// processed, including the skipped and the failed ones, in the order they
+// were processed.  Same as Errors, it should be called once the downloads are
+// complete.
+func (c *Client) Records() []Record {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	if len(c.records) == 0 {
+		return nil
+	}
+	recs := make([]Record, len(c.records))
+	copy(recs, c.records)
+	return recs
+}
@@ -0,0 +1,51 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
+)
+
+func TestClient_Records(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dc := mock_downloader.NewMockDownloader(ctrl)
+	cl := New(dc, fsadapter.NewDirectory(t.TempDir()), Workers(2), MaxFileSize(10))
+
+	var (
+		ok      = &slack.File{ID: "F1", Name: "ok.txt", URLPrivateDownload: "url1", Size: 8}
+		failed  = &slack.File{ID: "F2", Name: "failed.txt", URLPrivateDownload: "url2", Size: 8}
+		tooBig  = &slack.File{ID: "F3", Name: "big.txt", URLPrivateDownload: "url3", Size: 100}
+		extFile = &slack.File{ID: "F4", Name: "doc", IsExternal: true, ExternalType: "gdrive", Size: 1}
+	)
+	dc.EXPECT().GetFile("url1", gomock.Any()).DoAndReturn(func(_ string, w io.Writer) error {
+		_, err := io.WriteString(w, "contents")
+		return err
+	})
+	dc.EXPECT().GetFile("url2", gomock.Any()).Return(errors.New("not found"))
+
+	assert.Nil(t, cl.Records(), "no records before download")
+
+	done, err := cl.AsyncDownloader(context.Background(), "dir", slice2chan([]*slack.File{ok, failed, tooBig, extFile, ok}, 0))
+	require.NoError(t, err)
+	<-done
+
+	got := cl.Records()
+	sort.Slice(got, func(i, j int) bool { return got[i].ID < got[j].ID })
+	require.Len(t, got, 4, "duplicates must not be recorded")
+	assert.Equal(t, Record{ID: "F1", Name: "ok.txt", Path: "dir/F1-ok.txt", Size: 8, Status: StatusDownloaded}, got[0])
+	assert.Equal(t, "F2", got[1].ID)
+	assert.Equal(t, StatusFailed, got[1].Status)
+	assert.NotEmpty(t, got[1].Error)
+	assert.Equal(t, Record{ID: "F3", Name: "big.txt", Path: "dir/F3-big.txt", Size: 100, Status: StatusSkipped}, got[2])
+	assert.Equal(t, StatusSkipped, got[3].Status)
+}
@@ -0,0 +1,34 @@
+package slackdump
+
+// In this file: Enterprise Grid support.
+
+// EnterpriseID returns the ID of the Enterprise Grid organisation, that the
+// workspace belongs to, or an empty string, if the workspace is not a part of
+// the Enterprise Grid.
+func (sd *Session) EnterpriseID() string {
+	if sd.wspInfo == nil {
+		return ""
+	}
+	return sd.wspInfo.EnterpriseID
+}
+
+// TeamID returns the ID of the workspace.
+func (sd *Session) TeamID() string {
+	if sd.wspInfo == nil {
+		return ""
+	}
+	return sd.wspInfo.TeamID
+}
+
+// gridTeamID returns the team ID, that should be passed to the API methods
+// that list the workspace entities, on the Enterprise Grid.  The org-wide
+// tokens are not bound to the workspace, and without the team ID, the
+// methods return the entities of the organisation, that the user has access
+// to, missing the workspace context of the shared channels.  It returns an
+// empty string, if the workspace is not a part of the Enterprise Grid.
+func (sd *Session) gridTeamID() string {
+	if sd.EnterpriseID() == "" {
+		return ""
+	}
+	return sd.TeamID()
+}
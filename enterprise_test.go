@@ -0,0 +1,27 @@
+package slackdump
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestSession_gridTeamID(t *testing.T) {
+	tests := []struct {
+		name    string
+		wspInfo *slack.AuthTestResponse
+		want    string
+	}{
+		{"no workspace info", nil, ""},
+		{"regular workspace", &slack.AuthTestResponse{TeamID: "T01"}, ""},
+		{"enterprise grid", &slack.AuthTestResponse{TeamID: "T01", EnterpriseID: "E01"}, "T01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sd := &Session{wspInfo: tt.wspInfo}
+			if got := sd.gridTeamID(); got != tt.want {
+				t.Errorf("Session.gridTeamID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
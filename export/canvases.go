@@ -0,0 +1,87 @@
+package export
+
+// In this file: the canvases and lists of the channels.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// canvas types.
+const (
+	canvasTypeCanvas = "canvas"
+	canvasTypeList   = "list"
+)
+
+// ExportCanvas is the canvas or list entry in the "canvases" list of the
+// channel.  The content is saved as a separate file, Path is empty, if it
+// was not downloaded.
+type ExportCanvas struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Type    string `json:"type"`           // "canvas" or "list"
+	User    string `json:"user,omitempty"` // author of the canvas
+	Created int64  `json:"created"`
+	Path    string `json:"path,omitempty"` // path of the content, relative to the root of the export
+}
+
+// newExportCanvases converts the canvas files ff to the export canvases, paths
+// are the paths of the downloaded content of the files.
+func newExportCanvases(ff []slack.File, paths []string) []ExportCanvas {
+	var canvases []ExportCanvas
+	for i, f := range ff {
+		typ := canvasTypeList
+		if downloader.IsCanvas(&f) {
+			typ = canvasTypeCanvas
+		}
+		ec := ExportCanvas{ID: f.ID, Title: f.Title, Type: typ, User: f.User, Created: int64(f.Created)}
+		if i < len(paths) {
+			ec.Path = paths[i]
+		}
+		canvases = append(canvases, ec)
+	}
+	return canvases
+}
+
+// channelCanvases returns the canvases and lists of the channel ch, and
+// submits their content for download.  Direct messages are not supported by
+// the export format, and are skipped.  The canvases of archived channels,
+// that can not be retrieved, are skipped with a warning.
+func (se *Export) channelCanvases(ctx context.Context, ch slack.Channel) ([]ExportCanvas, error) {
+	if ch.IsIM {
+		return nil, nil
+	}
+	ff, err := se.sd.GetChannelCanvases(ctx, ch.ID)
+	if err != nil {
+		if ch.IsArchived || isNotFound(err) {
+			logger.With(se.l(), "channel", ch.ID).Printf("WARNING: unable to get the canvases of %s (%s), skipping: %s", ch.ID, ch.Name, err)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting canvases for %s: %w", ch.ID, err)
+	}
+	if len(ff) == 0 {
+		return nil, nil
+	}
+	paths, err := se.dl.Canvases(ff)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading canvases of %s: %w", ch.ID, err)
+	}
+	return newExportCanvases(ff, paths), nil
+}
+
+// setCanvases records the canvases of the channel channelID, to be written to
+// the index.
+func (se *Export) setCanvases(channelID string, canvases []ExportCanvas) {
+	if len(canvases) == 0 {
+		return
+	}
+	if se.canvases == nil {
+		se.canvases = make(map[string][]ExportCanvas)
+	}
+	se.canvases[channelID] = canvases
+}
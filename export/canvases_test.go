@@ -0,0 +1,135 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/internal/mocks/mock_dl"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func Test_newExportCanvases(t *testing.T) {
+	ff := []slack.File{
+		{ID: "F01", Title: "Roadmap", Filetype: "quip", Mimetype: "application/vnd.slack-docs", User: "U01", Created: 1645095600},
+		{ID: "F02", Title: "Tasks", Filetype: "list", User: "U02", Created: 1645095700},
+	}
+	want := []ExportCanvas{
+		{ID: "F01", Title: "Roadmap", Type: canvasTypeCanvas, User: "U01", Created: 1645095600, Path: "canvases/F01-Roadmap.html"},
+		{ID: "F02", Title: "Tasks", Type: canvasTypeList, User: "U02", Created: 1645095700},
+	}
+	assert.Equal(t, want, newExportCanvases(ff, []string{"canvases/F01-Roadmap.html", ""}))
+}
+
+func TestExport_channelCanvases(t *testing.T) {
+	var (
+		channel     = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01"}, Name: "general"}}
+		archived    = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C02"}, Name: "old", IsArchived: true}}
+		im          = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "D01", IsIM: true}}}
+		canvas      = slack.File{ID: "F01", Title: "Roadmap", Filetype: "quip", User: "U01"}
+		errCanvases = errors.New("files failed")
+	)
+	tests := []struct {
+		name    string
+		ch      slack.Channel
+		expect  func(d *Mockdumper, dl *mock_dl.MockExporter)
+		want    []ExportCanvas
+		wantErr bool
+	}{
+		{
+			"ok",
+			channel,
+			func(d *Mockdumper, dl *mock_dl.MockExporter) {
+				d.EXPECT().GetChannelCanvases(gomock.Any(), "C01").Return([]slack.File{canvas}, nil)
+				dl.EXPECT().Canvases([]slack.File{canvas}).Return([]string{"canvases/F01-Roadmap.html"}, nil)
+			},
+			[]ExportCanvas{{ID: "F01", Title: "Roadmap", Type: canvasTypeCanvas, User: "U01", Path: "canvases/F01-Roadmap.html"}},
+			false,
+		},
+		{
+			"no canvases",
+			channel,
+			func(d *Mockdumper, dl *mock_dl.MockExporter) {
+				d.EXPECT().GetChannelCanvases(gomock.Any(), "C01").Return(nil, nil)
+			},
+			nil,
+			false,
+		},
+		{
+			"error",
+			channel,
+			func(d *Mockdumper, dl *mock_dl.MockExporter) {
+				d.EXPECT().GetChannelCanvases(gomock.Any(), "C01").Return(nil, errCanvases)
+			},
+			nil,
+			true,
+		},
+		{
+			"download error",
+			channel,
+			func(d *Mockdumper, dl *mock_dl.MockExporter) {
+				d.EXPECT().GetChannelCanvases(gomock.Any(), "C01").Return([]slack.File{canvas}, nil)
+				dl.EXPECT().Canvases(gomock.Any()).Return(nil, errors.New("not started"))
+			},
+			nil,
+			true,
+		},
+		{
+			"archived channel error is skipped",
+			archived,
+			func(d *Mockdumper, dl *mock_dl.MockExporter) {
+				d.EXPECT().GetChannelCanvases(gomock.Any(), "C02").Return(nil, errCanvases)
+			},
+			nil,
+			false,
+		},
+		{
+			"direct messages are not requested",
+			im,
+			func(d *Mockdumper, dl *mock_dl.MockExporter) {},
+			nil,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			d := NewMockdumper(ctrl)
+			dl := mock_dl.NewMockExporter(ctrl)
+			tt.expect(d, dl)
+			se := &Export{sd: d, dl: dl, lg: logger.Silent}
+			got, err := se.channelCanvases(context.Background(), tt.ch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Export.channelCanvases() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_index_addCanvases(t *testing.T) {
+	var (
+		pub      = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01"}, Name: "general"}, IsChannel: true}
+		priv     = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "G01", IsGroup: true}, Name: "secret"}}
+		canvases = []ExportCanvas{{ID: "F01", Title: "Roadmap", Type: canvasTypeCanvas, Created: 1645095600, Path: "canvases/F01-Roadmap.html"}}
+	)
+	idx, err := createIndex([]slack.Channel{pub, priv}, []slack.User{{ID: "U01"}}, "U01")
+	require.NoError(t, err)
+	idx.addCanvases(map[string][]ExportCanvas{"C01": canvases})
+
+	assert.Equal(t, canvases, idx.Channels[0].Canvases)
+	assert.Empty(t, idx.Groups[0].Canvases)
+
+	data, err := json.Marshal(idx.Channels[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"canvases":[{"id":"F01","title":"Roadmap","type":"canvas","created":1645095600,"path":"canvases/F01-Roadmap.html"}]`)
+	data, err = json.Marshal(idx.Groups[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "canvases")
+}
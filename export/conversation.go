@@ -15,10 +15,11 @@ const dateFmt = "2006-01-02"
 
 // byDate sorts the messages by date and returns a map date->[]ExportMessage.
 // userIdx should contain the users in the conversation for populating the
-// required fields.  Threads are flattened.
-func (Export) byDate(c *types.Conversation, userIdx structures.UserIndex) (messagesByDate, error) {
+// required fields.  Threads are flattened.  Messages are grouped by the date
+// in UTC, regardless of Options.Location.
+func (se Export) byDate(c *types.Conversation, userIdx structures.UserIndex) (messagesByDate, error) {
 	msgsByDate := make(map[string][]*ExportMessage, 0)
-	if err := flattenMsgs(msgsByDate, c.Messages, userIdx); err != nil {
+	if err := flattenMsgs(msgsByDate, make(map[string]bool), c.Messages, userIdx, se.opts.Location, se.opts.EditMarkers); err != nil {
 		return nil, err
 	}
 
@@ -46,18 +47,27 @@ func (mbd messagesByDate) validate() error {
 }
 
 // flattenMsgs takes the messages input, splits them by the date and
-// populates the msgsByDate map.
-func flattenMsgs(msgsByDate messagesByDate, messages []types.Message, usrIdx structures.UserIndex) error {
+// populates the msgsByDate map.  The thread broadcasts are returned both in
+// the channel history and in the thread replies, seen holds the timestamps of
+// the added messages, so that they are added only once.  If markers is true,
+// the edit and deletion markers are kept, see setMarkers.
+func flattenMsgs(msgsByDate messagesByDate, seen map[string]bool, messages []types.Message, usrIdx structures.UserIndex, loc *time.Location, markers bool) error {
 	for i := range messages {
-		expMsg := newExportMessage(&messages[i], usrIdx)
-
 		if len(messages[i].ThreadReplies) > 0 {
 			// Recursive call:  are you ready, mr. stack?
-			if err := flattenMsgs(msgsByDate, messages[i].ThreadReplies, usrIdx); err != nil {
+			if err := flattenMsgs(msgsByDate, seen, messages[i].ThreadReplies, usrIdx, loc, markers); err != nil {
 				return fmt.Errorf("thread ID %s: %w", messages[i].Timestamp, err)
 			}
 		}
+		if seen[messages[i].Timestamp] {
+			continue
+		}
+		seen[messages[i].Timestamp] = true
 
+		expMsg := newExportMessage(&messages[i], usrIdx, loc)
+		if markers {
+			expMsg.setMarkers(&messages[i])
+		}
 		formattedDt := expMsg.slackdumpTime.Format(dateFmt)
 		msgsByDate[formattedDt] = append(msgsByDate[formattedDt], expMsg)
 	}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"runtime/trace"
 	"testing"
 	"time"
 
+	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/internal/fixtures"
 	"github.com/rusq/slackdump/v2/internal/fixtures/fixgen"
 	"github.com/rusq/slackdump/v2/types"
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,7 +30,7 @@ func TestConversation_ByDate(t *testing.T) {
 	}
 
 	// uncomment to write the json for fixtures
-	require.NoError(t, writeOutput("convDt", convDt))
+	require.NoError(t, writeOutput(filepath.Join(t.TempDir(), "convDt"), convDt))
 
 	want := fixtures.Load[messagesByDate](fixtures.TestConversationExportJSON)
 
@@ -56,6 +59,42 @@ func writeOutput(name string, v interface{}) error {
 	return enc.Encode(v)
 }
 
+func TestExport_byDate_thread(t *testing.T) {
+	const threadTS = "1645095600.000100"
+	var (
+		parent = types.Message{Message: slack.Message{Msg: slack.Msg{Type: "message", User: "U1", Text: "parent", Timestamp: threadTS, ThreadTimestamp: threadTS, ReplyCount: 2, LatestReply: "1645095700.000300"}}}
+		reply  = types.Message{Message: slack.Message{Msg: slack.Msg{Type: "message", User: "U2", Text: "reply", Timestamp: "1645095650.000200", ThreadTimestamp: threadTS, ParentUserId: "U1"}}}
+		bcast  = types.Message{Message: slack.Message{Msg: slack.Msg{Type: "message", SubType: "thread_broadcast", User: "U3", Text: "broadcast", Timestamp: "1645095700.000300", ThreadTimestamp: threadTS, ParentUserId: "U1"}}}
+	)
+	parent.ThreadReplies = []types.Message{reply, bcast}
+	// the broadcast is returned in the channel history as well.
+	conv := types.Conversation{ID: "C01", Messages: []types.Message{parent, bcast}}
+
+	dir := t.TempDir()
+	exp := Export{fs: fsadapter.NewDirectory(dir)}
+	msgs, err := exp.byDate(&conv, nil)
+	require.NoError(t, err)
+	require.NoError(t, exp.saveChannel("general", msgs))
+
+	data, err := os.ReadFile(filepath.Join(dir, "general", "2022-02-17.json"))
+	require.NoError(t, err)
+	var got []ExportMessage
+	require.NoError(t, json.Unmarshal(data, &got))
+
+	require.Len(t, got, 3, "the broadcast must be exported once")
+	for i, ts := range []string{threadTS, "1645095650.000200", "1645095700.000300"} {
+		assert.Equal(t, ts, got[i].Timestamp)
+		assert.Equal(t, threadTS, got[i].ThreadTimestamp, "message %s", ts)
+	}
+	assert.Equal(t, 2, got[0].ReplyCount)
+	assert.Equal(t, 2, got[0].ReplyUsersCount)
+	assert.Equal(t, []string{"U2", "U3"}, got[0].ReplyUsers)
+	assert.Equal(t, []slack.Reply{{User: "U2", Timestamp: "1645095650.000200"}, {User: "U3", Timestamp: "1645095700.000300"}}, got[0].Replies)
+	assert.Equal(t, "", got[1].SubType)
+	assert.Equal(t, "thread_broadcast", got[2].SubType)
+	assert.Equal(t, "U1", got[2].ParentUserId)
+}
+
 func Test_messagesByDate_validate(t *testing.T) {
 	tests := []struct {
 		name    string
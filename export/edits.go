@@ -0,0 +1,64 @@
+package export
+
+// In this file: the edit and deletion markers of the messages.
+
+import (
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// subTypeTombstone is the subtype of the placeholder, that Slack leaves in
+// place of the deleted message, that had the thread replies.
+const subTypeTombstone = "tombstone"
+
+// isDeleted returns true if m marks the deleted message, it is either the
+// tombstone, or the message_deleted event.
+func isDeleted(m *types.Message) bool {
+	return m.SubType == subTypeTombstone || m.SubType == slack.MsgSubTypeMessageDeleted
+}
+
+// setMarkers copies the deleted or the original message of the
+// message_deleted and message_changed events msg, and the changed message of
+// message_changed to em, so that the content of the deleted or changed
+// message is not lost.
+func (em *ExportMessage) setMarkers(msg *types.Message) {
+	em.PreviousMessage = msg.PreviousMessage
+	em.SubMessage = msg.SubMessage
+}
+
+// renderedMessage returns the message to render in place of m: for the
+// message_deleted event, it is the deleted message, with the subtype of the
+// event, if it is known, otherwise it is m itself.
+func renderedMessage(m *types.Message) *types.Message {
+	if m.SubType != slack.MsgSubTypeMessageDeleted || m.PreviousMessage == nil {
+		return m
+	}
+	deleted := types.Message{Message: slack.Message{Msg: *m.PreviousMessage}}
+	deleted.SubType = m.SubType
+	return &deleted
+}
+
+// editMarker returns the marker of the message m for the rendered exports:
+// "deleted" for the deleted message, "edited" with the time of the edit, and
+// the name of the editor, if it's not the author, for the edited message, or
+// an empty string.  Time is formatted with the layout in the location loc.
+func editMarker(m *types.Message, userIdx structures.UserIndex, loc *time.Location, layout string) string {
+	if isDeleted(m) {
+		return "deleted"
+	}
+	if m.Edited == nil {
+		return ""
+	}
+	marker := "edited"
+	if t, err := structures.ParseSlackTS(m.Edited.Timestamp); err == nil {
+		marker += " " + t.In(loc).Format(layout)
+	}
+	if m.Edited.User != "" && m.Edited.User != m.User {
+		marker += " by " + userIdx.DisplayName(m.Edited.User)
+	}
+	return marker
+}
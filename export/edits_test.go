@@ -0,0 +1,138 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+var (
+	testEdited = types.Message{Message: slack.Message{Msg: slack.Msg{
+		Type:      "message",
+		User:      "U01",
+		Text:      "fixed typo",
+		Timestamp: "1674086400.000100",
+		Edited:    &slack.Edited{User: "U02", Timestamp: "1674090000.000000"},
+	}}}
+	testDeleted = types.Message{Message: slack.Message{
+		Msg: slack.Msg{
+			Type:             "message",
+			SubType:          slack.MsgSubTypeMessageDeleted,
+			Hidden:           true,
+			Timestamp:        "1674086500.000300",
+			DeletedTimestamp: "1674086460.000200",
+		},
+		PreviousMessage: &slack.Msg{Type: "message", User: "U02", Text: "the evidence", Timestamp: "1674086460.000200"},
+	}}
+)
+
+func TestExport_byDate_editMarkers(t *testing.T) {
+	conv := types.Conversation{ID: "C01", Messages: []types.Message{testEdited, testDeleted}}
+
+	export := func(t *testing.T, markers bool) []ExportMessage {
+		t.Helper()
+		dir := t.TempDir()
+		exp := Export{fs: fsadapter.NewDirectory(dir), opts: Options{EditMarkers: markers}}
+		msgs, err := exp.byDate(&conv, nil)
+		require.NoError(t, err)
+		require.NoError(t, exp.saveChannel("general", msgs))
+
+		data, err := os.ReadFile(filepath.Join(dir, "general", "2023-01-19.json"))
+		require.NoError(t, err)
+		var got []ExportMessage
+		require.NoError(t, json.Unmarshal(data, &got))
+		require.Len(t, got, 2, "the deleted message must not be dropped")
+		return got
+	}
+
+	t.Run("markers", func(t *testing.T) {
+		got := export(t, true)
+		assert.Equal(t, &slack.Edited{User: "U02", Timestamp: "1674090000.000000"}, got[0].Edited)
+
+		assert.Equal(t, slack.MsgSubTypeMessageDeleted, got[1].SubType)
+		assert.Equal(t, "1674086460.000200", got[1].DeletedTimestamp)
+		assert.True(t, got[1].Hidden)
+		if assert.NotNil(t, got[1].PreviousMessage) {
+			assert.Equal(t, "the evidence", got[1].PreviousMessage.Text)
+			assert.Equal(t, "U02", got[1].PreviousMessage.User)
+		}
+	})
+	t.Run("no markers", func(t *testing.T) {
+		got := export(t, false)
+		assert.NotNil(t, got[0].Edited, "edited is always kept")
+		assert.Equal(t, slack.MsgSubTypeMessageDeleted, got[1].SubType)
+		assert.Nil(t, got[1].PreviousMessage)
+	})
+}
+
+func Test_editMarker(t *testing.T) {
+	users := types.Users{
+		{ID: "U01", Profile: slack.UserProfile{DisplayName: "Alice"}},
+		{ID: "U02", Profile: slack.UserProfile{DisplayName: "Bob"}},
+	}.IndexByID()
+	selfEdited := types.Message{Message: slack.Message{Msg: slack.Msg{User: "U01", Edited: &slack.Edited{User: "U01", Timestamp: "1674090000.000000"}}}}
+	tombstone := types.Message{Message: slack.Message{Msg: slack.Msg{User: "USLACKBOT", SubType: subTypeTombstone, Text: "This message was deleted."}}}
+
+	tests := []struct {
+		name string
+		m    *types.Message
+		want string
+	}{
+		{"not edited", &types.Message{Message: slack.Message{Msg: slack.Msg{User: "U01"}}}, ""},
+		{"edited by the author", &selfEdited, "edited 2023-01-19 01:00:00 UTC"},
+		{"edited by another user", &testEdited, "edited 2023-01-19 01:00:00 UTC by Bob"},
+		{"message_deleted", &testDeleted, "deleted"},
+		{"tombstone", &tombstone, "deleted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, editMarker(tt.m, structures.UserIndex(users), time.UTC, htmlTimeFmt))
+		})
+	}
+}
+
+func Test_renderedMessage(t *testing.T) {
+	got := renderedMessage(&testDeleted)
+	assert.Equal(t, "the evidence", got.Text)
+	assert.Equal(t, "U02", got.User)
+	assert.Equal(t, slack.MsgSubTypeMessageDeleted, got.SubType, "must keep the deletion subtype")
+
+	assert.Same(t, &testEdited, renderedMessage(&testEdited))
+	noPrevious := types.Message{Message: slack.Message{Msg: slack.Msg{SubType: slack.MsgSubTypeMessageDeleted}}}
+	assert.Same(t, &noPrevious, renderedMessage(&noPrevious))
+}
+
+func Test_writeMarkdownMessages_markers(t *testing.T) {
+	msgs := []types.Message{testEdited, testDeleted}
+	var buf strings.Builder
+	writeMarkdownMessages(&buf, msgs, structures.UserIndex(testHTMLUsers), testMentions, time.UTC, "", true)
+	got := buf.String()
+	assert.Contains(t, got, "**Alice** · 2023-01-19 00:00:00 UTC · _(edited 2023-01-19 01:00:00 UTC by Bob \\<The Builder\\>)_\n\nfixed typo")
+	assert.Contains(t, got, "**Bob \\<The Builder\\>** · 2023-01-19 00:01:00 UTC · _(deleted)_\n\nthe evidence")
+
+	buf.Reset()
+	writeMarkdownMessages(&buf, msgs, structures.UserIndex(testHTMLUsers), testMentions, time.UTC, "", false)
+	assert.NotContains(t, buf.String(), "edited")
+	assert.NotContains(t, buf.String(), "the evidence")
+}
+
+func Test_htmlMessages_markers(t *testing.T) {
+	got := htmlMessages([]types.Message{testEdited, testDeleted}, testHTMLUsers, testMentions, time.UTC, true)
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "edited 2023-01-19 01:00:00 UTC by Bob <The Builder>", got[0].Marker)
+		assert.Equal(t, "deleted", got[1].Marker)
+		assert.Equal(t, "Bob <The Builder>", got[1].User)
+		assert.EqualValues(t, "the evidence", got[1].Text)
+	}
+}
@@ -6,15 +6,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"path/filepath"
 	"runtime/trace"
+	"sort"
 
+	"github.com/rusq/slackdump/v2/downloader"
 	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/slack-go/slack"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/rusq/slackdump/v2"
 	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/redact"
 	"github.com/rusq/slackdump/v2/internal/structures"
 	"github.com/rusq/slackdump/v2/internal/structures/files/dl"
 	"github.com/rusq/slackdump/v2/logger"
@@ -30,6 +34,32 @@ type Export struct {
 
 	// options
 	opts Options
+
+	// statistics, conversations are exported one at a time, so no locking is
+	// necessary.
+	nChannels int     // number of exported conversations
+	nMessages int     // number of exported messages, including thread replies
+	errs      []error // errors of the skipped conversations, see Options.ContinueOnError
+
+	pages    []page                      // exported pages for the index, for HTML and Markdown types
+	mentions *structures.MentionResolver // resolves mentions, if enabled
+	pins     map[string][]ExportPin      // pinned items by channel ID, if enabled
+	canvases map[string][]ExportCanvas   // canvases and lists by channel ID, if enabled
+	redactor *redact.Redactor            // redacts the personal information, if enabled
+}
+
+// Stats is the export statistics.
+type Stats struct {
+	Channels int              // number of exported conversations
+	Messages int              // number of exported messages, including replies
+	Files    downloader.Stats // file download statistics
+	Errors   []error          // errors of the conversations, that were skipped, see Options.ContinueOnError
+}
+
+// Stats returns the export statistics.  It should be called after Run
+// returns.
+func (se *Export) Stats() Stats {
+	return Stats{Channels: se.nChannels, Messages: se.nMessages, Files: se.dl.Stats(), Errors: se.errs}
 }
 
 // New creates a new Export instance, that will save export to the
@@ -40,12 +70,30 @@ func New(sd *slackdump.Session, fs fsadapter.FS, cfg Options) *Export {
 	}
 	network.SetLogger(cfg.Logger)
 
+	dlOpts := []downloader.Option{
+		downloader.Workers(cfg.Workers),
+		downloader.SkipExisting(cfg.SkipExistingFiles),
+		downloader.WithFilter(downloader.TypeFilter(cfg.FileTypes)),
+		downloader.MaxFileSize(cfg.MaxFileSize),
+		downloader.MaxTotalBytes(cfg.MaxTotalBytes),
+		downloader.PreserveTimes(cfg.PreserveFileTimes),
+		downloader.DedupByContent(cfg.DedupByContent),
+		downloader.Compress(cfg.Gzip),
+		downloader.BytesPerSec(cfg.DownloadBytesPerSec),
+		downloader.Progress(cfg.OnFileProgress),
+	}
+	// the downloader compresses the files itself, as it skips the ones that
+	// are already compressed.
+	out := fs
+	if cfg.Gzip {
+		out = fsadapter.NewGzip(fs)
+	}
 	se := &Export{
-		fs:   fs,
+		fs:   out,
 		sd:   sd,
 		lg:   cfg.Logger,
 		opts: cfg,
-		dl:   newFileExporter(cfg.Type, fs, sd.Client(), cfg.Logger, cfg.ExportToken),
+		dl:   newFileExporter(cfg.Type, fs, sd.FileClient(), cfg.Logger, cfg.ExportToken, cfg.StripFileURLs, dlOpts...),
 	}
 	return se
 }
@@ -61,12 +109,42 @@ func (se *Export) Run(ctx context.Context) error {
 		se.td(ctx, "error", "GetUsers: %s", err)
 		return err
 	}
+	if se.opts.UserCustomFields {
+		se.l().Printf("fetching the custom profile fields of %d users...", len(users))
+		if err := se.sd.GetUsersCustomFields(ctx, users); err != nil {
+			se.td(ctx, "error", "GetUsersCustomFields: %s", err)
+			return err
+		}
+	}
+	if se.opts.Redact {
+		se.redactor = redact.New(users)
+		users = se.redactor.Users(users)
+	}
 
 	// export channels to channels.json
 	if err := se.messages(ctx, users); err != nil {
 		se.td(ctx, "error", "messages: %s", err)
+		if se.opts.IsFilesEnabled() && ctx.Err() != nil {
+			// the run was cancelled, list the files of the conversations
+			// exported so far.
+			if err := se.saveManifest(); err != nil {
+				se.lg.Printf("error writing the files manifest: %s", err)
+			}
+		}
 		return err
 	}
+
+	if se.opts.IsFilesEnabled() {
+		if err := se.saveManifest(); err != nil {
+			return fmt.Errorf("error writing the files manifest: %w", err)
+		}
+	}
+
+	if se.opts.Redact && se.opts.RedactMap != nil {
+		if err := se.redactor.WriteMap(se.opts.RedactMap); err != nil {
+			return fmt.Errorf("error writing the redaction map: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -84,26 +162,88 @@ func (se *Export) messages(ctx context.Context, users types.Users) error {
 		}()
 	}
 
+	if se.opts.IsFilesEnabled() && se.opts.DownloadAvatars {
+		var err error
+		if users, err = se.dl.Avatars(users); err != nil {
+			return fmt.Errorf("error downloading avatars: %w", err)
+		}
+	}
+
+	if se.opts.UserLocation {
+		loc, err := users.IndexByID().Location(se.sd.CurrentUserID())
+		if err != nil {
+			se.l().Printf("unable to use the time zone of the current user, using the default: %s", err)
+		} else {
+			se.opts.Location = loc
+		}
+	}
+
+	if se.opts.ResolveMentions && se.opts.Type != TMattermost {
+		se.mentions = structures.NewMentionResolver(users.IndexByID(), nil)
+	}
+
 	var chans []slack.Channel
 
 	chans, err := se.exportChannels(ctx, users.IndexByID())
 	if err != nil {
+		if ctx.Err() != nil && len(chans) > 0 {
+			// the run was interrupted, write the index of the conversations
+			// exported so far, so that the partial export is usable.
+			se.l().Printf("export interrupted, writing the index of %d exported conversation(s)", len(chans))
+			if err := se.writeIndex(chans, users); err != nil {
+				se.lg.Printf("error writing the index: %s", err)
+			}
+		}
 		return fmt.Errorf("export error: %w", err)
 	}
+	return se.writeIndex(chans, users)
+}
+
+// WorkspaceFile is the name of the file with the information about the
+// exported workspace, see slackdump.Workspace, in the root of the export.
+const WorkspaceFile = "workspace.json"
+
+// writeIndex writes the index files of the exported channels chans and the
+// users, and the workspace information.
+func (se *Export) writeIndex(chans []slack.Channel, users types.Users) error {
+	chans = se.redactor.Channels(chans)
 
 	idx, err := createIndex(chans, users, se.sd.CurrentUserID())
 	if err != nil {
 		return fmt.Errorf("failed to create an index: %w", err)
 	}
+	idx.addPins(se.pins)
+	idx.addCanvases(se.canvases)
+	if se.sd.EnterpriseID() != "" {
+		idx.addContextTeam(se.sd.TeamID())
+	}
 
 	if err := idx.Marshal(se.fs); err != nil {
 		return err
 	}
+	if err := serializeToFS(se.fs, WorkspaceFile, se.sd.Workspace()); err != nil {
+		return err
+	}
+	switch se.opts.Type {
+	case THTML:
+		if err := se.saveHTMLIndex(); err != nil {
+			return err
+		}
+	case TMarkdown:
+		if err := se.saveMarkdownIndex(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 func (se *Export) exportChannels(ctx context.Context, uidx structures.UserIndex) ([]slack.Channel, error) {
+	if se.opts.List.HasPatterns() {
+		if err := se.resolvePatterns(ctx, se.opts.List); err != nil {
+			return nil, err
+		}
+	}
 	if se.opts.List.HasIncludes() {
 		// if there's an "Include" list, we don't need to retrieve all channels,
 		// only the ones that are specified.
@@ -113,6 +253,19 @@ func (se *Export) exportChannels(ctx context.Context, uidx structures.UserIndex)
 	}
 }
 
+// resolvePatterns fetches the channel list and resolves the channel name
+// patterns of the entity list el to channel IDs.
+func (se *Export) resolvePatterns(ctx context.Context, el *structures.EntityList) error {
+	var chans []slack.Channel
+	if err := se.sd.StreamChannels(ctx, se.opts.ChanTypes(), func(ch slack.Channel) error {
+		chans = append(chans, ch)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("channels: error: %w", err)
+	}
+	return el.ResolvePatterns(chans)
+}
+
 // exclusiveExport exports all channels, excluding ones that are defined in
 // EntityList.  If EntityList has Include channels, they are ignored.
 func (se *Export) exclusiveExport(ctx context.Context, uidx structures.UserIndex, el *structures.EntityList) ([]slack.Channel, error) {
@@ -123,12 +276,20 @@ func (se *Export) exclusiveExport(ctx context.Context, uidx structures.UserIndex
 
 	listIdx := el.Index()
 	// we need the current user to be able to build an index of DMs.
-	if err := se.sd.StreamChannels(ctx, slackdump.AllChanTypes, func(ch slack.Channel) error {
+	if err := se.sd.StreamChannels(ctx, se.opts.ChanTypes(), func(ch slack.Channel) error {
 		if include, ok := listIdx[ch.ID]; ok && !include {
 			trace.Logf(ctx, "info", "skipping %s", ch.ID)
 			se.lg.Printf("skipping: %s", ch.ID)
 			return nil
 		}
+		if !se.opts.Archived.Match(&ch) {
+			se.td(ctx, "info", "skipping %s (archived: %v)", ch.ID, ch.IsArchived)
+			return nil
+		}
+		if ch.IsArchived && se.opts.State.Has(ch.ID) {
+			logger.With(se.l(), "channel", ch.ID).Printf("WARNING: %s (%s) has been archived, skipping", ch.ID, ch.Name)
+			return nil
+		}
 
 		var eg errgroup.Group
 
@@ -151,17 +312,41 @@ func (se *Export) exclusiveExport(ctx context.Context, uidx structures.UserIndex
 			return nil
 		})
 
-		// wait for both to finish
+		// 3. get pins, if requested
+		var pins []ExportPin
+		if se.opts.IncludePins {
+			eg.Go(func() error {
+				var err error
+				pins, err = se.channelPins(ctx, ch)
+				return err
+			})
+		}
+
+		// 4. get canvases, if requested
+		var canvases []ExportCanvas
+		if se.opts.IncludeCanvases {
+			eg.Go(func() error {
+				var err error
+				canvases, err = se.channelCanvases(ctx, ch)
+				return err
+			})
+		}
+
+		// wait for all to finish
 		if err := eg.Wait(); err != nil {
-			return err
+			return se.skipFailed(ch.ID, err)
 		}
 
 		ch.Members = members
+		se.setPins(ch.ID, pins)
+		se.setCanvases(ch.ID, canvases)
 		chans = append(chans, ch)
 		return nil
 
 	}); err != nil {
-		return nil, fmt.Errorf("channels: error: %w", err)
+		// the channels exported in full are returned, so that the index
+		// could be written, if the export was interrupted.
+		return chans, fmt.Errorf("channels: error: %w", err)
 	}
 	se.l().Printf("  out of which exported:  %d", len(chans))
 	return chans, nil
@@ -196,7 +381,22 @@ func (se *Export) inclusiveExport(ctx context.Context, uidx structures.UserIndex
 		}
 		ch, err := se.sd.Client().GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: sl.Channel, IncludeLocale: true, IncludeNumMembers: true})
 		if err != nil {
-			return nil, fmt.Errorf("error getting info for %s: %w", sl, err)
+			if isNotFound(err) && se.opts.State.Has(sl.Channel) {
+				logger.With(se.l(), "channel", sl.Channel).Printf("WARNING: %s has been deleted, skipping", sl.Channel)
+				continue
+			}
+			if err := se.skipFailed(sl.Channel, fmt.Errorf("error getting info for %s: %w", sl, err)); err != nil {
+				return chans, err
+			}
+			continue
+		}
+		if !se.opts.Archived.Match(ch) {
+			se.lg.Printf("skipping: %s (archived: %v)", ch.ID, ch.IsArchived)
+			continue
+		}
+		if ch.IsArchived && se.opts.State.Has(ch.ID) {
+			logger.With(se.l(), "channel", ch.ID).Printf("WARNING: %s (%s) has been archived, skipping", ch.ID, ch.Name)
+			continue
 		}
 
 		var eg errgroup.Group
@@ -218,11 +418,34 @@ func (se *Export) inclusiveExport(ctx context.Context, uidx structures.UserIndex
 			return nil
 		})
 
+		var pins []ExportPin
+		if se.opts.IncludePins {
+			eg.Go(func() error {
+				var err error
+				pins, err = se.channelPins(ctx, *ch)
+				return err
+			})
+		}
+
+		var canvases []ExportCanvas
+		if se.opts.IncludeCanvases {
+			eg.Go(func() error {
+				var err error
+				canvases, err = se.channelCanvases(ctx, *ch)
+				return err
+			})
+		}
+
 		if err := eg.Wait(); err != nil {
-			return nil, err
+			if err := se.skipFailed(ch.ID, err); err != nil {
+				return chans, err
+			}
+			continue
 		}
 
 		ch.Members = members
+		se.setPins(ch.ID, pins)
+		se.setCanvases(ch.ID, canvases)
 
 		chans = append(chans, *ch)
 	}
@@ -235,22 +458,34 @@ func (se *Export) exportConversation(ctx context.Context, userIdx structures.Use
 	ctx, task := trace.NewTask(ctx, "export.conversation")
 	defer task.End()
 
-	messages, err := se.sd.DumpRaw(ctx, ch.ID, se.opts.Oldest, se.opts.Latest, se.dl.ProcessFunc(validName(ch)))
+	ch = se.redactor.Channel(ch)
+	name := se.dirName(ch, userIdx)
+	messages, err := se.dumpRanges(ctx, ch, name)
 	if err != nil {
 		return fmt.Errorf("failed to dump %q (%s): %w", ch.Name, ch.ID, err)
 	}
+	se.opts.State.Update(messages)
+	se.nChannels++
+	se.nMessages += messages.MessageCount()
+	se.mentions.AddChannel(ch)
 	if len(messages.Messages) == 0 {
 		// empty result set
 		return nil
 	}
+	se.redactor.Messages(messages.Messages)
+	switch se.opts.Type {
+	case THTML:
+		return se.saveHTML(name, ch, messages, userIdx)
+	case TMarkdown:
+		return se.saveMarkdown(name, ch, messages, userIdx)
+	}
+	resolveMentions(messages.Messages, se.mentions)
 
 	msgs, err := se.byDate(messages, userIdx)
 	if err != nil {
 		return fmt.Errorf("exportConversation: error: %w", err)
 	}
 
-	name := validName(ch)
-
 	if err := se.saveChannel(name, msgs); err != nil {
 		return err
 	}
@@ -258,6 +493,31 @@ func (se *Export) exportConversation(ctx context.Context, userIdx structures.Use
 	return nil
 }
 
+// dumpRanges dumps the messages of the conversation ch for each of the time
+// ranges of the export, and unions them.  The files are saved into the
+// directory dirName.  In the incremental export, the
+// ranges that were exported by the previous run are skipped.
+func (se *Export) dumpRanges(ctx context.Context, ch slack.Channel, dirName string) (*types.Conversation, error) {
+	ranges := se.opts.ranges()
+	cnv := &types.Conversation{ID: ch.ID, Name: ch.Name}
+	for _, r := range ranges {
+		oldest := se.opts.State.Oldest(ch.ID, r.Start)
+		if !r.End.IsZero() && !oldest.Before(r.End) {
+			continue
+		}
+		c, err := se.sd.DumpRaw(ctx, ch.ID, oldest, r.End, se.dl.ProcessFunc(dirName))
+		if err != nil {
+			return nil, err
+		}
+		cnv.Name = c.Name
+		cnv.Messages = append(cnv.Messages, c.Messages...)
+	}
+	if len(ranges) > 1 {
+		types.SortMessages(cnv.Messages)
+	}
+	return cnv, nil
+}
+
 // validName returns the channel or user name. Following the naming convention
 // described by @niklasdahlheimer in this post (thanks to @Neznakomec for
 // discovering it):
@@ -269,12 +529,44 @@ func validName(ch slack.Channel) string {
 	return ch.Name
 }
 
+// skipFailed records the error err of the conversation channelID and returns
+// nil, so that the export continues with the next conversation, if the
+// Options.ContinueOnError is set, and the error is not fatal.  Otherwise it
+// returns err.
+func (se *Export) skipFailed(channelID string, err error) error {
+	if !se.opts.ContinueOnError || slackdump.IsFatalError(err) {
+		return err
+	}
+	logger.With(se.l(), "channel", channelID).Printf("ERROR: %s (conversation will be skipped): %s", channelID, err)
+	se.errs = append(se.errs, err)
+	return nil
+}
+
+// dirName returns the name of the directory of the conversation ch in the
+// export.  The human-readable exports name the direct messages after the
+// participants, other export types follow the Slack export convention (see
+// validName), that the importers rely on.
+func (se *Export) dirName(ch slack.Channel, userIdx structures.UserIndex) string {
+	if (se.opts.Type == THTML || se.opts.Type == TMarkdown) && (ch.IsIM || ch.IsMpIM) {
+		return dmDirName(ch, userIdx)
+	}
+	return validName(ch)
+}
+
 // saveChannel creates a directory `name` and writes the contents of msgs. for
 // each map key the json file is created, with the name `{key}.json`, and values
-// for that key are serialised to the file in json format.
+// for that key are serialised to the file in json format.  In the incremental
+// export, messages are appended to the existing file, the filesystem must
+// support reading it.
 func (se *Export) saveChannel(channelName string, msgs messagesByDate) error {
 	for date, messages := range msgs {
 		output := filepath.Join(channelName, date+".json")
+		if se.opts.State != nil {
+			var err error
+			if messages, err = se.appendExisting(output, messages); err != nil {
+				return err
+			}
+		}
 		if err := serializeToFS(se.fs, output, messages); err != nil {
 			return err
 		}
@@ -282,14 +574,77 @@ func (se *Export) saveChannel(channelName string, msgs messagesByDate) error {
 	return nil
 }
 
+// fileReader is implemented by the filesystem adapters that are able to read
+// the existing files, i.e. fsadapter.Directory.
+type fileReader interface {
+	ReadFile(name string) ([]byte, error)
+}
+
+// appendExisting returns the messages from the existing file filename,
+// followed by msgs.  Messages of the existing file that have the same
+// timestamp as any of msgs are replaced.  If the file does not exist, msgs
+// are returned as is.  If the filesystem can not read the file, i.e. ZIP or
+// the bucket, it returns errNotReadable, as the file would have been
+// overwritten with only the new messages.
+func (se *Export) appendExisting(filename string, msgs []*ExportMessage) ([]*ExportMessage, error) {
+	fr, ok := se.fs.(fileReader)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errNotReadable, se.fs)
+	}
+	data, err := fr.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return msgs, nil
+		}
+		if errors.Is(err, errors.ErrUnsupported) {
+			return nil, fmt.Errorf("%w: %s", errNotReadable, se.fs)
+		}
+		return nil, err
+	}
+	var existing []*ExportMessage
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", filename, err)
+	}
+	seen := make(map[string]bool, len(msgs))
+	for _, m := range msgs {
+		seen[m.Timestamp] = true
+	}
+	merged := make([]*ExportMessage, 0, len(existing)+len(msgs))
+	for _, m := range existing {
+		if m.Msg == nil || seen[m.Timestamp] {
+			continue
+		}
+		merged = append(merged, m)
+	}
+	merged = append(merged, msgs...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time().Before(merged[j].Time())
+	})
+	return merged, nil
+}
+
+// saveManifest saves the manifest of all files submitted for download to the
+// root of the export.  It should be called once the downloader is stopped.
+func (se *Export) saveManifest() error {
+	m := se.dl.Manifest()
+	if m == nil {
+		m = []dl.ManifestEntry{}
+	}
+	return serializeToFS(se.fs, dl.ManifestFile, m)
+}
+
 // serializeToFS writes the data in json format to provided filesystem adapter.
 func serializeToFS(fs fsadapter.FS, filename string, data any) error {
 	f, err := fs.Create(filename)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	return serialize(f, data)
+	if err := serialize(f, data); err != nil {
+		f.Close()
+		return err
+	}
+	// the file in the bucket is uploaded on close.
+	return f.Close()
 }
 
 func serialize(w io.Writer, data any) error {
@@ -316,3 +671,22 @@ func (se *Export) td(ctx context.Context, category string, fmt string, a ...any)
 	se.l().Debugf(fmt, a...)
 	trace.Logf(ctx, category, fmt, a...)
 }
+
+// isNotFound returns true if the err is the Slack "channel_not_found" error,
+// i.e. if the channel has been deleted.
+func isNotFound(err error) bool {
+	var ser slack.SlackErrorResponse
+	return errors.As(err, &ser) && ser.Err == "channel_not_found"
+}
+
+// resolveMentions replaces the mentions in the text of the messages and their
+// thread replies, using mr.  If mr is nil, messages are not modified.
+func resolveMentions(msgs []types.Message, mr *structures.MentionResolver) {
+	if mr == nil {
+		return
+	}
+	for i := range msgs {
+		msgs[i].Text = mr.Resolve(msgs[i].Text)
+		resolveMentions(msgs[i].ThreadReplies, mr)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
@@ -15,12 +16,16 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/downloader"
 	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/internal/fixtures"
 	"github.com/rusq/slackdump/v2/internal/mocks/mock_dl"
 	"github.com/rusq/slackdump/v2/internal/mocks/mock_fsadapter"
 	"github.com/rusq/slackdump/v2/internal/mocks/mock_io"
+	"github.com/rusq/slackdump/v2/internal/redact"
 	"github.com/rusq/slackdump/v2/internal/structures"
+	fdl "github.com/rusq/slackdump/v2/internal/structures/files/dl"
+	"github.com/rusq/slackdump/v2/logger"
 	"github.com/rusq/slackdump/v2/types"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
@@ -164,6 +169,39 @@ func Test_validName(t *testing.T) {
 	}
 }
 
+func TestExport_dirName(t *testing.T) {
+	users := types.Users{
+		{ID: "U01", Name: "alice", Profile: slack.UserProfile{DisplayName: "Alice"}},
+		{ID: "U02", Name: "bob", RealName: "Bob Smith"},
+		{ID: "U03", Name: "carol", Profile: slack.UserProfile{DisplayName: "carol/c"}},
+	}.IndexByID()
+	var (
+		im      = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{IsIM: true, ID: "D01", User: "U01"}}}
+		mpim    = slack.Channel{GroupConversation: slack.GroupConversation{Name: "mpdm-alice--bob--carol-1", Conversation: slack.Conversation{IsMpIM: true, ID: "G01", NameNormalized: "mpdm-alice--bob--carol-1"}}}
+		channel = slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C01"}}}
+	)
+	tests := []struct {
+		name string
+		typ  ExportType
+		ch   slack.Channel
+		want string
+	}{
+		{"standard im", TStandard, im, "D01"},
+		{"standard mpim", TStandard, mpim, "mpdm-alice--bob--carol-1"},
+		{"html im", THTML, im, "dm-Alice-D01"},
+		{"markdown mpim", TMarkdown, mpim, "gdm-Alice-Bob_Smith-carol_c-G01"},
+		{"html channel", THTML, channel, "general"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			se := &Export{opts: Options{Type: tt.typ}}
+			if got := se.dirName(tt.ch, users); got != tt.want {
+				t.Errorf("Export.dirName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_serializeToFS(t *testing.T) {
 	const (
 		testData = "123"
@@ -360,7 +398,7 @@ func TestExport_exportConversation(t *testing.T) {
 					closeErr: errors.New("close failed"),
 				},
 			},
-			false, // DON'T CARE LALALALALALA
+			true, // the file in the bucket is uploaded on close.
 		},
 	}
 	for _, tt := range tests {
@@ -408,6 +446,257 @@ func TestExport_exportConversation(t *testing.T) {
 			if err := exp.exportConversation(context.Background(), testUserIdx, tt.args.ch); (err != nil) != tt.wantErr {
 				t.Errorf("Export.exportConversation() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if wantCh := boolToInt(tt.mocks.rets.dumpRawErr == nil); exp.nChannels != wantCh {
+				t.Errorf("Export.nChannels = %d, want %d", exp.nChannels, wantCh)
+			}
+			if exp.nChannels > 0 && exp.nMessages != tt.mocks.conv.MessageCount() {
+				t.Errorf("Export.nMessages = %d, want %d", exp.nMessages, tt.mocks.conv.MessageCount())
+			}
+		})
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestExport_exportConversation_reactions(t *testing.T) {
+	msg := fixtures.Load[types.Message](fixtures.ReactionsMessageJSON)
+	want := []slack.ItemReaction{
+		{Name: "+1", Users: []string{"UHSD97ZA5", "U034HM0P7RB"}, Count: 2},
+		{Name: "tada", Users: []string{"U034HM0P7RB"}, Count: 1},
+		{Name: "partyparrot", Users: []string{"UHSD97ZA5"}, Count: 1}, // custom emoji
+	}
+	for _, typ := range []ExportType{TStandard, TMattermost} {
+		t.Run(typ.String(), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dumper := NewMockdumper(ctrl)
+			dl := mock_dl.NewMockExporter(ctrl)
+			dir := t.TempDir()
+			ch := slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01"}, Name: "general"}}
+
+			exp := &Export{
+				sd:   dumper,
+				fs:   fsadapter.NewDirectory(dir),
+				dl:   dl,
+				opts: Options{Type: typ},
+			}
+			dumper.EXPECT().
+				DumpRaw(gomock.Any(), ch.ID, gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&types.Conversation{ID: ch.ID, Messages: []types.Message{msg}}, nil)
+			dl.EXPECT().ProcessFunc(gomock.Any()).Return(nil)
+
+			if err := exp.exportConversation(context.Background(), structures.UserIndex{}, ch); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "general", "2022-02-17.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []ExportMessage
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d messages, want 1", len(got))
+			}
+			assert.Equal(t, want, got[0].Reactions)
 		})
 	}
 }
+
+func TestExport_exportConversation_redact(t *testing.T) {
+	users := types.Users{
+		{ID: "U01", Name: "jsmith", RealName: "John Smith", Profile: slack.UserProfile{Email: "john@example.com"}},
+	}
+	p := redact.Pseudonym("U01")
+
+	ctrl := gomock.NewController(t)
+	dumper := NewMockdumper(ctrl)
+	dl := mock_dl.NewMockExporter(ctrl)
+	dir := t.TempDir()
+	ch := slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "G01", IsMpIM: true}, Name: "mpdm-jsmith--bob-1"}}
+	msg := types.Message{Message: slack.Message{Msg: slack.Msg{User: "U01", Text: "John Smith: mail me at john@example.com", Timestamp: "1645095505.023899"}}}
+
+	exp := &Export{
+		sd:       dumper,
+		fs:       fsadapter.NewDirectory(dir),
+		dl:       dl,
+		opts:     Options{Type: TStandard, Redact: true},
+		redactor: redact.New(users),
+	}
+	dumper.EXPECT().
+		DumpRaw(gomock.Any(), ch.ID, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&types.Conversation{ID: ch.ID, Messages: []types.Message{msg}}, nil)
+	dl.EXPECT().ProcessFunc(gomock.Any()).Return(nil)
+
+	if err := exp.exportConversation(context.Background(), exp.redactor.Users(users).IndexByID(), ch); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "mpdm-"+p+"--bob-1", "2022-02-17.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []ExportMessage
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d messages, want 1", len(got))
+	}
+	assert.Equal(t, p+": mail me at "+redact.Email(p), got[0].Text)
+	assert.Equal(t, p, got[0].UserProfile.RealName)
+	assert.NotContains(t, string(data), "John Smith")
+	assert.NotContains(t, string(data), "john@example.com")
+}
+
+func TestExport_saveManifest(t *testing.T) {
+	entries := []fdl.ManifestEntry{
+		{ID: "F1", Name: "ok.txt", ChannelID: "C01", Timestamp: "1645095505.023899", Path: "general/attachments/F1-ok.txt", Size: 8, Status: downloader.StatusDownloaded},
+		{ID: "F2", Name: "big.bin", ChannelID: "C01", Timestamp: "1645095505.023899", Size: 1 << 30, Status: downloader.StatusSkipped},
+		{ID: "F3", Name: "gone.txt", ChannelID: "C01", Timestamp: "1645095506.023899", Size: 10, Status: downloader.StatusFailed, Error: "not found"},
+	}
+	tests := []struct {
+		name     string
+		manifest []fdl.ManifestEntry
+		want     []fdl.ManifestEntry
+	}{
+		{"all statuses", entries, entries},
+		{"no files", nil, []fdl.ManifestEntry{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dl := mock_dl.NewMockExporter(ctrl)
+			dl.EXPECT().Manifest().Return(tt.manifest)
+			dir := t.TempDir()
+			exp := &Export{fs: fsadapter.NewDirectory(dir), dl: dl}
+
+			if err := exp.saveManifest(); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, fdl.ManifestFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []fdl.ManifestEntry
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExport_dumpRanges(t *testing.T) {
+	var (
+		jan2 = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		jan3 = time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+		mar1 = time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+		mar2 = time.Date(2023, 3, 2, 0, 0, 0, 0, time.UTC)
+
+		msgJan = types.Message{Message: slack.Message{Msg: slack.Msg{Text: "jan", Timestamp: "1672660800.000000"}}}
+		msgMar = types.Message{Message: slack.Message{Msg: slack.Msg{Text: "mar", Timestamp: "1677672000.000000"}}}
+	)
+	ch := slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01"}, Name: "general"}}
+
+	ctrl := gomock.NewController(t)
+	dumper := NewMockdumper(ctrl)
+	dl := mock_dl.NewMockExporter(ctrl)
+	dl.EXPECT().ProcessFunc("general").Return(nil).Times(2)
+	dumper.EXPECT().
+		DumpRaw(gomock.Any(), "C01", jan2, jan3, gomock.Any()).
+		Return(&types.Conversation{ID: "C01", Name: "general", Messages: []types.Message{msgJan}}, nil)
+	dumper.EXPECT().
+		DumpRaw(gomock.Any(), "C01", mar1, mar2, gomock.Any()).
+		Return(&types.Conversation{ID: "C01", Name: "general", Messages: []types.Message{msgMar}}, nil)
+
+	exp := &Export{sd: dumper, dl: dl, opts: Options{DateFilters: []structures.DateFilter{
+		{Start: jan2, End: jan3},
+		{Start: mar1, End: mar2},
+	}}}
+	got, err := exp.dumpRanges(context.Background(), ch, "general")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &types.Conversation{ID: "C01", Name: "general", Messages: []types.Message{msgJan, msgMar}}, got)
+}
+
+func TestExport_skipFailed(t *testing.T) {
+	errNotFound := fmt.Errorf("error getting info for C01: %w", slack.SlackErrorResponse{Err: "channel_not_found"})
+	errAuth := fmt.Errorf("error exporting C01: %w", slack.SlackErrorResponse{Err: "invalid_auth"})
+	tests := []struct {
+		name            string
+		continueOnError bool
+		err             error
+		wantErr         error
+		wantErrs        []error
+	}{
+		{"stops by default", false, errNotFound, errNotFound, nil},
+		{"skips the conversation", true, errNotFound, nil, []error{errNotFound}},
+		{"auth error is fatal", true, errAuth, errAuth, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			se := &Export{lg: logger.Silent, opts: Options{ContinueOnError: tt.continueOnError}}
+			assert.Equal(t, tt.wantErr, se.skipFailed("C01", tt.err))
+			assert.Equal(t, tt.wantErrs, se.errs)
+		})
+	}
+}
+
+func TestExport_messages_interrupted(t *testing.T) {
+	ch := slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01"}, Name: "general"}}
+	msg := types.Message{Message: slack.Message{Msg: slack.Msg{Text: "hello", Timestamp: "1672660800.000000"}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := gomock.NewController(t)
+	dumper := NewMockdumper(ctrl)
+	dl := mock_dl.NewMockExporter(ctrl)
+	dl.EXPECT().ProcessFunc("general").Return(nil)
+	dumper.EXPECT().
+		StreamChannels(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ []string, cb func(ch slack.Channel) error) error {
+			if err := cb(ch); err != nil {
+				return err
+			}
+			// the user presses Ctrl+C before the next channel.
+			cancel()
+			return ctx.Err()
+		})
+	dumper.EXPECT().GetChannelMembers(gomock.Any(), "C01").Return([]string{"U1"}, nil)
+	dumper.EXPECT().
+		DumpRaw(gomock.Any(), "C01", gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&types.Conversation{ID: "C01", Name: "general", Messages: []types.Message{msg}}, nil)
+	dumper.EXPECT().CurrentUserID().Return("U1")
+	dumper.EXPECT().EnterpriseID().Return("")
+	dumper.EXPECT().Workspace().Return(slackdump.Workspace{TeamID: "T01", Team: "Acme", URL: "https://acme.slack.com/"})
+
+	dir := t.TempDir()
+	exp := &Export{sd: dumper, dl: dl, fs: fsadapter.NewDirectory(dir), lg: logger.Silent, opts: Options{Type: TNoDownload, List: &structures.EntityList{}}}
+	if err := exp.messages(ctx, types.Users{{ID: "U1", Name: "alice"}}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("messages() error = %v, want %v", err, context.Canceled)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "channels.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []slack.Channel
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, got, 1, "the index must list the exported channel") {
+		assert.Equal(t, "C01", got[0].ID)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, WorkspaceFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{"team_id": "T01", "team": "Acme", "url": "https://acme.slack.com/"}`, string(data))
+}
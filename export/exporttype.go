@@ -14,6 +14,8 @@ const (
 	TNoDownload ExportType = iota // NoDownload
 	TStandard                     // Standard
 	TMattermost                   // Mattermost
+	THTML                         // HTML
+	TMarkdown                     // Markdown
 )
 
 // Set translates the string value into the ExportType, satisfies flag.Value
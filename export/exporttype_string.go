@@ -11,11 +11,13 @@ func _() {
 	_ = x[TNoDownload-0]
 	_ = x[TStandard-1]
 	_ = x[TMattermost-2]
+	_ = x[THTML-3]
+	_ = x[TMarkdown-4]
 }
 
-const _ExportType_name = "NoDownloadStandardMattermost"
+const _ExportType_name = "NoDownloadStandardMattermostHTMLMarkdown"
 
-var _ExportType_index = [...]uint8{0, 10, 18, 28}
+var _ExportType_index = [...]uint8{0, 10, 18, 28, 32, 40}
 
 func (i ExportType) String() string {
 	if i >= ExportType(len(_ExportType_index)-1) {
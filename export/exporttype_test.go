@@ -15,6 +15,8 @@ func TestExportType_Set(t *testing.T) {
 		{"nodownload", args{"nodownload"}, TNoDownload, false},
 		{"standard", args{"standard"}, TStandard, false},
 		{"mattermost", args{"mattermost"}, TMattermost, false},
+		{"html", args{"HTML"}, THTML, false},
+		{"markdown", args{"markdown"}, TMarkdown, false},
 		{"unknown", args{"gibberish"}, 0, true},
 	}
 	for _, tt := range tests {
@@ -20,9 +20,23 @@ type dumper interface {
 	// GetUsers gets the list of all users from the Slack API.
 	GetUsers(ctx context.Context) (types.Users, error)
 
+	// GetUsersCustomFields fetches the custom profile fields of the users,
+	// and sets them in the user profiles.
+	GetUsersCustomFields(ctx context.Context, us types.Users) error
+
 	// CurrentUserID gets the ID of the user running the tool.
 	CurrentUserID() string
 
+	// EnterpriseID gets the ID of the Enterprise Grid organisation, or an
+	// empty string, if the workspace is not a part of the Enterprise Grid.
+	EnterpriseID() string
+
+	// TeamID gets the ID of the workspace.
+	TeamID() string
+
+	// Workspace gets the information about the workspace.
+	Workspace() slackdump.Workspace
+
 	// StreamChannels gets a list of all channels from the Slack API, and
 	// streams them to the provided callback.
 	StreamChannels(ctx context.Context, chanTypes []string, cb func(ch slack.Channel) error) error
@@ -35,4 +49,10 @@ type dumper interface {
 
 	// GetChannelMembers gets the list of members for a channel.
 	GetChannelMembers(ctx context.Context, channelID string) ([]string, error)
+
+	// GetChannelPins gets the list of items pinned in a channel.
+	GetChannelPins(ctx context.Context, channelID string) ([]slack.Item, error)
+
+	// GetChannelCanvases gets the list of canvases and lists of a channel.
+	GetChannelCanvases(ctx context.Context, channelID string) ([]slack.File, error)
 }
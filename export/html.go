@@ -0,0 +1,184 @@
+package export
+
+// HTML export
+
+import (
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+const (
+	htmlIndexFile = "index.html" // index page in the root of the export
+	htmlTimeFmt   = "2006-01-02 15:04:05 MST"
+)
+
+//go:embed templates/*.html
+var htmlFS embed.FS
+
+var htmlTmpl = template.Must(template.ParseFS(htmlFS, "templates/*.html"))
+
+// htmlChannel is the data for the channel page template.
+type htmlChannel struct {
+	Title    string
+	Topic    string
+	Messages []htmlMessage
+}
+
+type htmlMessage struct {
+	ID        string
+	User      string
+	Avatar    string // path of the downloaded avatar, if any
+	Time      string
+	Marker    string // edit or deletion marker, see editMarker
+	Text      template.HTML
+	Files     []htmlFile
+	Reactions []slack.ItemReaction
+	Replies   []htmlMessage
+}
+
+type htmlFile struct {
+	Name    string
+	URL     string
+	IsImage bool
+}
+
+// saveHTML renders the conversation to the "index.html" page in the channel
+// directory, so that the links to the downloaded attachments, that are
+// relative to the channel directory, are valid.  The page is added to the
+// list of pages for the index.
+func (se *Export) saveHTML(channelName string, ch slack.Channel, conv *types.Conversation, userIdx structures.UserIndex) error {
+	hc := htmlChannel{
+		Title:    pageTitle(ch, userIdx),
+		Topic:    ch.Topic.Value,
+		Messages: htmlMessages(conv.Messages, userIdx, se.mentions, se.opts.Location, se.opts.EditMarkers),
+	}
+	pg := page{
+		Title:    hc.Title,
+		Path:     path.Join(channelName, htmlIndexFile),
+		Messages: conv.MessageCount(),
+	}
+	if err := writeHTML(se.fs, pg.Path, "channel.html", hc); err != nil {
+		return err
+	}
+	se.pages = append(se.pages, pg)
+	return nil
+}
+
+// saveHTMLIndex writes the index page, listing all exported conversations.
+func (se *Export) saveHTMLIndex() error {
+	return writeHTML(se.fs, htmlIndexFile, "index.html", se.sortedPages())
+}
+
+func writeHTML(fs fsadapter.FS, filename string, tmplName string, data any) (err error) {
+	f, err := fs.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error writing %s: %w", filename, cerr)
+		}
+	}()
+	if err := htmlTmpl.ExecuteTemplate(f, tmplName, data); err != nil {
+		return fmt.Errorf("error rendering %s: %w", filename, err)
+	}
+	return nil
+}
+
+// htmlMessages converts the messages and their thread replies to the template
+// data.  Mentions are resolved with mr.  Timestamps are rendered in the
+// location loc, or in UTC, if loc is nil.  If markers is true, the edited and
+// deleted messages are marked.
+func htmlMessages(msgs []types.Message, userIdx structures.UserIndex, mr *structures.MentionResolver, loc *time.Location, markers bool) []htmlMessage {
+	if loc == nil {
+		loc = time.UTC
+	}
+	hms := make([]htmlMessage, 0, len(msgs))
+	for i := range msgs {
+		m := &msgs[i]
+		if markers {
+			m = renderedMessage(m)
+		}
+		hm := htmlMessage{
+			ID:        m.Timestamp,
+			User:      senderName(m, userIdx),
+			Avatar:    senderAvatar(m, userIdx),
+			Text:      renderHTML(m.Text, mr),
+			Reactions: m.Reactions,
+		}
+		if t, err := m.Datetime(); err == nil {
+			hm.Time = t.In(loc).Format(htmlTimeFmt)
+		}
+		if markers {
+			hm.Marker = editMarker(m, userIdx, loc, htmlTimeFmt)
+		}
+		for _, f := range m.Files {
+			hm.Files = append(hm.Files, htmlFile{
+				Name:    f.Name,
+				URL:     nvl(f.URLPrivateDownload, f.URLPrivate),
+				IsImage: strings.HasPrefix(f.Mimetype, "image/"),
+			})
+		}
+		if len(m.ThreadReplies) > 0 {
+			hm.Replies = htmlMessages(m.ThreadReplies, userIdx, mr, loc, markers)
+		}
+		hms = append(hms, hm)
+	}
+	return hms
+}
+
+// renderHTML converts the Slack message text to HTML, replacing the user
+// mentions with the display names of the users, if mr is not nil, and the
+// links with anchors.  All other text is escaped.
+func renderHTML(text string, mr *structures.MentionResolver) template.HTML {
+	var buf strings.Builder
+	last := 0
+	for _, loc := range reSlackEntity.FindAllStringSubmatchIndex(text, -1) {
+		buf.WriteString(escapeText(text[last:loc[0]]))
+		buf.WriteString(htmlEntity(text[loc[2]:loc[3]], mr))
+		last = loc[1]
+	}
+	buf.WriteString(escapeText(text[last:]))
+	return template.HTML(buf.String())
+}
+
+// escapeText escapes the plain Slack text for HTML.  Slack escapes "&", "<"
+// and ">" in the message text, so it is unescaped first to avoid double
+// escaping.
+func escapeText(s string) string {
+	return strings.ReplaceAll(html.EscapeString(html.UnescapeString(s)), "\n", "<br>\n")
+}
+
+// htmlEntity renders the Slack entity (without the angle brackets).
+func htmlEntity(ent string, mr *structures.MentionResolver) string {
+	target, label, _ := strings.Cut(ent, "|")
+	switch {
+	case strings.HasPrefix(target, "@"):
+		name, _ := mr.User(target[1:])
+		return `<span class="mention">@` + html.EscapeString(name) + `</span>`
+	case strings.HasPrefix(target, "#"):
+		name, _ := mr.Channel(target[1:], label)
+		return `<span class="mention">#` + html.EscapeString(name) + `</span>`
+	case strings.HasPrefix(target, "!"):
+		// special mentions, i.e. "!here" or "!subteam^S123|@team"
+		name := strings.TrimPrefix(nvl(label, target[1:]), "@")
+		return `<span class="mention">@` + html.EscapeString(name) + `</span>`
+	default:
+		href := html.UnescapeString(target)
+		if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") && !strings.HasPrefix(href, "mailto:") {
+			return escapeText("<" + ent + ">")
+		}
+		return `<a href="` + html.EscapeString(href) + `">` + escapeText(nvl(label, target)) + `</a>`
+	}
+}
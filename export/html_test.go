@@ -0,0 +1,113 @@
+package export
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+var testHTMLUsers = types.Users{
+	{ID: "U01", Name: "alice", Profile: slack.UserProfile{DisplayName: "Alice", Image72: "avatars/U01-a.png"}},
+	{ID: "U02", Name: "bob", RealName: "Bob <The Builder>"},
+}.IndexByID()
+
+var testMentions = structures.NewMentionResolver(testHTMLUsers, nil)
+
+func Test_renderHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want template.HTML
+	}{
+		{"plain text is escaped", `a &lt;b&gt; & "c"`, `a &lt;b&gt; &amp; &#34;c&#34;`},
+		{"user mention", "hi <@U01>!", `hi <span class="mention">@Alice</span>!`},
+		{"mention name is escaped", "<@U02>", `<span class="mention">@Bob &lt;The Builder&gt;</span>`},
+		{"channel reference", "see <#C01|general>", `see <span class="mention">#general</span>`},
+		{"special mention", "<!here>", `<span class="mention">@here</span>`},
+		{"unknown user", "<@U99>", `<span class="mention">@U99</span>`},
+		{"link with label", "<https://example.com/?a=1&amp;b=2|example>", `<a href="https://example.com/?a=1&amp;b=2">example</a>`},
+		{"link without label", "<https://example.com>", `<a href="https://example.com">https://example.com</a>`},
+		{"javascript links are not rendered", "<javascript:alert(1)>", `&lt;javascript:alert(1)&gt;`},
+		{"newlines", "a\nb", "a<br>\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderHTML(tt.text, testMentions))
+		})
+	}
+}
+
+func Test_htmlMessages(t *testing.T) {
+	msgs := []types.Message{
+		{
+			Message: slack.Message{Msg: slack.Msg{
+				Timestamp: "1674086400.000100",
+				User:      "U01",
+				Text:      "parent",
+				Files:     []slack.File{{Name: "cat.png", Mimetype: "image/png", URLPrivateDownload: "attachments/F1-cat.png"}},
+				Reactions: []slack.ItemReaction{{Name: "+1", Count: 2}},
+			}},
+			ThreadReplies: []types.Message{
+				{Message: slack.Message{Msg: slack.Msg{Timestamp: "1674086460.000200", User: "U02", Text: "reply"}}},
+			},
+		},
+	}
+	loc := time.FixedZone("XYZ", 3600)
+	got := htmlMessages(msgs, testHTMLUsers, testMentions, loc, false)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "Alice", got[0].User)
+		assert.Equal(t, "../avatars/U01-a.png", got[0].Avatar)
+		assert.Equal(t, "2023-01-19 01:00:00 XYZ", got[0].Time)
+		assert.Equal(t, []htmlFile{{Name: "cat.png", URL: "attachments/F1-cat.png", IsImage: true}}, got[0].Files)
+		assert.Len(t, got[0].Reactions, 1)
+		if assert.Len(t, got[0].Replies, 1) {
+			assert.Equal(t, "Bob <The Builder>", got[0].Replies[0].User)
+			assert.Empty(t, got[0].Replies[0].Avatar, "no avatar downloaded")
+		}
+	}
+}
+
+func TestExport_saveHTML(t *testing.T) {
+	dir := t.TempDir()
+	se := &Export{fs: fsadapter.NewDirectory(dir), opts: Options{Type: THTML}, mentions: testMentions}
+
+	var ch slack.Channel
+	ch.ID = "C01"
+	ch.Name = "general"
+	conv := &types.Conversation{ID: "C01", Messages: []types.Message{
+		{Message: slack.Message{Msg: slack.Msg{Timestamp: "1674086400.000100", User: "U01", Text: "hello <@U02>"}}},
+	}}
+	if err := se.saveHTML("general", ch, conv, structures.UserIndex(testHTMLUsers)); err != nil {
+		t.Fatal(err)
+	}
+	if err := se.saveHTMLIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "general", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<h1>#general</h1>", "hello <span class=\"mention\">@Bob &lt;The Builder&gt;</span>", "2023-01-19 00:00:00 UTC"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("channel page does not contain %q", want)
+		}
+	}
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `<a href="general/index.html">#general</a>`) {
+		t.Errorf("index page does not link to the channel page:\n%s", index)
+	}
+}
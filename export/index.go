@@ -16,13 +16,28 @@ import (
 // index is the index of the export archive.  filename tags are used to
 // serialize the structure to JSON files.
 type index struct {
-	Channels []slack.Channel `filename:"channels.json"`
-	Groups   []slack.Channel `filename:"groups.json,omitempty"`
-	MPIMs    []slack.Channel `filename:"mpims.json,omitempty"`
+	Channels []ExportChannel `filename:"channels.json"`
+	Groups   []ExportChannel `filename:"groups.json,omitempty"`
+	MPIMs    []ExportChannel `filename:"mpims.json,omitempty"`
 	DMs      []DM            `filename:"dms.json,omitempty"`
 	Users    []slack.User    `filename:"users.json"`
 }
 
+// ExportChannel is the slack.Channel with additional fields present in the
+// Slack export.
+type ExportChannel struct {
+	slack.Channel
+	Pins []ExportPin `json:"pins,omitempty"`
+	// Canvases are the canvases and lists of the channel, the content is
+	// saved in the separate files.
+	Canvases []ExportCanvas `json:"canvases,omitempty"`
+	// ContextTeamID is the ID of the workspace, that the channel was
+	// exported from.  It is only set for the Enterprise Grid workspaces,
+	// where the channels may be shared between the workspaces of the
+	// organisation.
+	ContextTeamID string `json:"context_team_id,omitempty"`
+}
+
 // DM respresents a direct Message entry in dms.json.
 // Structure is based on this post:
 //
@@ -55,9 +70,9 @@ func createIndex(channels []slack.Channel, users types.Users, currentUserID stri
 
 	var idx = index{
 		Users:    users,
-		Channels: []slack.Channel{},
-		Groups:   []slack.Channel{},
-		MPIMs:    []slack.Channel{},
+		Channels: []ExportChannel{},
+		Groups:   []ExportChannel{},
+		MPIMs:    []ExportChannel{},
 		DMs:      []DM{},
 	}
 
@@ -74,16 +89,55 @@ func createIndex(channels []slack.Channel, users types.Users, currentUserID stri
 			if err != nil {
 				return nil, err
 			}
-			idx.MPIMs = append(idx.MPIMs, *fixed)
+			idx.MPIMs = append(idx.MPIMs, ExportChannel{Channel: *fixed})
 		case ch.IsGroup:
-			idx.Groups = append(idx.Groups, ch)
+			idx.Groups = append(idx.Groups, ExportChannel{Channel: ch})
 		default:
-			idx.Channels = append(idx.Channels, ch)
+			idx.Channels = append(idx.Channels, ExportChannel{Channel: ch})
 		}
 	}
 	return &idx, nil
 }
 
+// addPins adds the pins, keyed by the channel ID, to the channels of the
+// index.
+func (idx *index) addPins(pins map[string][]ExportPin) {
+	if len(pins) == 0 {
+		return
+	}
+	for _, chans := range [][]ExportChannel{idx.Channels, idx.Groups, idx.MPIMs} {
+		for i := range chans {
+			chans[i].Pins = pins[chans[i].ID]
+		}
+	}
+}
+
+// addCanvases adds the canvases, keyed by the channel ID, to the channels of
+// the index.
+func (idx *index) addCanvases(canvases map[string][]ExportCanvas) {
+	if len(canvases) == 0 {
+		return
+	}
+	for _, chans := range [][]ExportChannel{idx.Channels, idx.Groups, idx.MPIMs} {
+		for i := range chans {
+			chans[i].Canvases = canvases[chans[i].ID]
+		}
+	}
+}
+
+// addContextTeam sets the context team ID of all channels of the index to
+// teamID.
+func (idx *index) addContextTeam(teamID string) {
+	if teamID == "" {
+		return
+	}
+	for _, chans := range [][]ExportChannel{idx.Channels, idx.Groups, idx.MPIMs} {
+		for i := range chans {
+			chans[i].ContextTeamID = teamID
+		}
+	}
+}
+
 // Marshal writes the index to the filesystem in a set of files specified in
 // `filename` tags of the structure.
 func (idx *index) Marshal(fs fsadapter.FS) error {
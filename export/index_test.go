@@ -19,7 +19,7 @@ func TestIndex_Marshal(t *testing.T) {
 	}{
 		{
 			"x",
-			index{Channels: []slack.Channel{{IsChannel: true}}},
+			index{Channels: []ExportChannel{{Channel: slack.Channel{IsChannel: true}}}},
 			args{},
 			true,
 		},
@@ -0,0 +1,262 @@
+package export
+
+// Markdown export
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+const (
+	mdIndexFile = "index.md" // index page in the root of the export
+	mdTimeFmt   = "2006-01-02 15:04:05 MST"
+)
+
+// saveMarkdown renders the conversation to the "index.md" file in the channel
+// directory, so that the links to the downloaded attachments, that are
+// relative to the channel directory, are valid.  The page is added to the
+// list of pages for the index.
+func (se *Export) saveMarkdown(channelName string, ch slack.Channel, conv *types.Conversation, userIdx structures.UserIndex) error {
+	pg := page{
+		Title:    pageTitle(ch, userIdx),
+		Path:     path.Join(channelName, mdIndexFile),
+		Messages: conv.MessageCount(),
+	}
+	f, err := se.fs.Create(pg.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // in case of an error, closed below otherwise.
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "# %s\n\n", pg.Title)
+	if ch.Topic.Value != "" {
+		fmt.Fprintf(w, "_%s_\n\n", mdEscape(ch.Topic.Value))
+	}
+	loc := se.opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	writeMarkdownMessages(w, conv.Messages, userIdx, se.mentions, loc, "", se.opts.EditMarkers)
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("error writing %s: %w", pg.Path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", pg.Path, err)
+	}
+	se.pages = append(se.pages, pg)
+	return nil
+}
+
+// saveMarkdownIndex writes the index file, listing all exported
+// conversations.
+func (se *Export) saveMarkdownIndex() error {
+	f, err := se.fs.Create(mdIndexFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // in case of an error, closed below otherwise.
+
+	w := bufio.NewWriter(f)
+	fmt.Fprint(w, "# Slack export\n\n| Conversation | Messages |\n| --- | ---: |\n")
+	for _, pg := range se.sortedPages() {
+		fmt.Fprintf(w, "| [%s](%s) | %d |\n", mdEscape(pg.Title), pg.Path, pg.Messages)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeMarkdownMessages writes the messages and their thread replies to w,
+// resolving the mentions with mr.  Thread replies are written as a block
+// quote.  Each line is prefixed with the prefix.  If markers is true, the
+// edited and deleted messages are marked.
+func writeMarkdownMessages(w io.Writer, msgs []types.Message, userIdx structures.UserIndex, mr *structures.MentionResolver, loc *time.Location, prefix string, markers bool) {
+	for i := range msgs {
+		m := &msgs[i]
+		if markers {
+			m = renderedMessage(m)
+		}
+		var buf strings.Builder
+		fmt.Fprintf(&buf, "**%s**", mdEscape(senderName(m, userIdx)))
+		if t, err := m.Datetime(); err == nil {
+			fmt.Fprintf(&buf, " · %s", t.In(loc).Format(mdTimeFmt))
+		}
+		if markers {
+			if marker := editMarker(m, userIdx, loc, mdTimeFmt); marker != "" {
+				fmt.Fprintf(&buf, " · _(%s)_", mdEscape(marker))
+			}
+		}
+		buf.WriteString("\n\n")
+		if m.Text != "" {
+			buf.WriteString(renderMarkdown(m.Text, mr))
+			buf.WriteString("\n\n")
+		}
+		for _, f := range m.Files {
+			img := ""
+			if strings.HasPrefix(f.Mimetype, "image/") {
+				img = "!"
+			}
+			fmt.Fprintf(&buf, "%s[%s](%s)\n\n", img, mdEscape(f.Name), mdURL(nvl(f.URLPrivateDownload, f.URLPrivate)))
+		}
+		if len(m.Reactions) > 0 {
+			rs := make([]string, 0, len(m.Reactions))
+			for _, r := range m.Reactions {
+				rs = append(rs, fmt.Sprintf(":%s: %d", r.Name, r.Count))
+			}
+			buf.WriteString(strings.Join(rs, " · "))
+			buf.WriteString("\n\n")
+		}
+		writePrefixed(w, buf.String(), prefix)
+		if len(m.ThreadReplies) > 0 {
+			writeMarkdownMessages(w, m.ThreadReplies, userIdx, mr, loc, prefix+"> ", markers)
+		}
+	}
+}
+
+// writePrefixed writes the text s to w, prefixing each line with prefix.
+func writePrefixed(w io.Writer, s string, prefix string) {
+	if prefix == "" {
+		io.WriteString(w, s)
+		return
+	}
+	for _, line := range strings.SplitAfter(s, "\n") {
+		switch line {
+		case "":
+		case "\n":
+			// no trailing spaces on empty lines
+			io.WriteString(w, strings.TrimRight(prefix, " ")+line)
+		default:
+			io.WriteString(w, prefix+line)
+		}
+	}
+}
+
+var (
+	// reMdCode matches the code blocks and inline code, which are left
+	// intact.
+	reMdCode = regexp.MustCompile("(?s)```.*?```|`[^`\n]+`")
+	// reMdBold matches the Slack bold text, i.e. "*bold*".
+	reMdBold = regexp.MustCompile(`(^|[\s(_~])\*([^*\n]+)\*`)
+	// reMdStrike matches the Slack strikethrough text, i.e. "~strike~".
+	reMdStrike = regexp.MustCompile(`(^|[\s(_*])~([^~\n]+)~`)
+)
+
+// renderMarkdown converts the Slack mrkdwn text to CommonMark, replacing the
+// user mentions with the display names of the users and channel references
+// with the channel names, if mr is not nil, and the links with Markdown
+// links.  Code blocks and inline code are left as is.
+func renderMarkdown(text string, mr *structures.MentionResolver) string {
+	var buf strings.Builder
+	last := 0
+	for _, loc := range reMdCode.FindAllStringIndex(text, -1) {
+		buf.WriteString(mdText(text[last:loc[0]], mr))
+		code := html.UnescapeString(text[loc[0]:loc[1]])
+		if strings.HasPrefix(code, "```") {
+			code = mdCodeBlock(code)
+			// fences must be on their own lines.
+			if buf.Len() > 0 && !strings.HasSuffix(buf.String(), "\n") {
+				buf.WriteString("\n")
+			}
+			if loc[1] < len(text) && text[loc[1]] != '\n' {
+				code += "\n"
+			}
+		}
+		buf.WriteString(code)
+		last = loc[1]
+	}
+	buf.WriteString(mdText(text[last:], mr))
+	return buf.String()
+}
+
+// mdCodeBlock puts the fences of the Slack code block on separate lines.
+// Slack allows the code right after the fence, while CommonMark would treat it
+// as the info string.
+func mdCodeBlock(code string) string {
+	code = strings.TrimSuffix(strings.TrimPrefix(code, "```"), "```")
+	return "```\n" + strings.TrimSuffix(strings.TrimPrefix(code, "\n"), "\n") + "\n```"
+}
+
+// mdText converts the text outside of the code blocks.
+func mdText(s string, mr *structures.MentionResolver) string {
+	s = reMdBold.ReplaceAllString(s, "$1**$2**")
+	s = reMdStrike.ReplaceAllString(s, "$1~~$2~~")
+
+	var buf strings.Builder
+	last := 0
+	for _, loc := range reSlackEntity.FindAllStringSubmatchIndex(s, -1) {
+		buf.WriteString(mdPlain(s[last:loc[0]]))
+		buf.WriteString(mdEntity(s[loc[2]:loc[3]], mr))
+		last = loc[1]
+	}
+	buf.WriteString(mdPlain(s[last:]))
+	return mdHardBreaks(buf.String())
+}
+
+// mdPlain converts the plain Slack text.  Slack escapes "&", "<" and ">", the
+// text is unescaped, except for "<", so that it would not be taken for the
+// HTML tag.  ">" is left as is, as in the beginning of the line it starts the
+// quote, same as in Slack.
+func mdPlain(s string) string {
+	return strings.ReplaceAll(html.UnescapeString(s), "<", "&lt;")
+}
+
+// mdHardBreaks adds the trailing backslash to the lines, that are followed by
+// another line of the same paragraph, as Markdown would otherwise join them.
+func mdHardBreaks(s string) string {
+	lines := strings.Split(s, "\n")
+	for i := 0; i < len(lines)-1; i++ {
+		if lines[i] != "" && lines[i+1] != "" {
+			lines[i] += "\\"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mdEntity renders the Slack entity (without the angle brackets).
+func mdEntity(ent string, mr *structures.MentionResolver) string {
+	target, label, _ := strings.Cut(ent, "|")
+	switch {
+	case strings.HasPrefix(target, "@"):
+		name, _ := mr.User(target[1:])
+		return "**@" + mdEscape(name) + "**"
+	case strings.HasPrefix(target, "#"):
+		name, _ := mr.Channel(target[1:], label)
+		return "**#" + mdEscape(name) + "**"
+	case strings.HasPrefix(target, "!"):
+		return "**@" + mdEscape(strings.TrimPrefix(nvl(label, target[1:]), "@")) + "**"
+	default:
+		target = html.UnescapeString(target)
+		if label == "" {
+			return "<" + target + ">"
+		}
+		return "[" + mdPlain(label) + "](" + mdURL(target) + ")"
+	}
+}
+
+// mdEscaper escapes the characters, that have special meaning in Markdown.
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "|", `\|`,
+)
+
+// mdEscape escapes the plain text, i.e. user names, for Markdown.
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+// mdURL makes the URL safe to use in the Markdown link.
+func mdURL(s string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(s)
+}
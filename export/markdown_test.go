@@ -0,0 +1,88 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func Test_renderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"bold and strike", "*bold* and ~gone~ and _it_", "**bold** and ~~gone~~ and _it_"},
+		{"user mention", "hi <@U01>!", "hi **@Alice**!"},
+		{"channel reference", "see <#C01|general>", "see **#general**"},
+		{"special mention", "<!channel>", "**@channel**"},
+		{"link with label", "<https://example.com/?a=1&amp;b=2|example>", "[example](https://example.com/?a=1&b=2)"},
+		{"link without label", "<https://example.com>", "<https://example.com>"},
+		{"escaped text", "a &lt;b&gt; &amp; c", "a &lt;b> & c"},
+		{"line breaks", "a\nb\n\nc", "a\\\nb\n\nc"},
+		{"inline code is intact", "run `*x* <@U01>`", "run `*x* <@U01>`"},
+		{"code block fences", "code:```a &lt; b```done", "code:\n```\na < b\n```\ndone"},
+		{"code block on separate lines", "```\nfoo\n```", "```\nfoo\n```"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderMarkdown(tt.text, testMentions))
+		})
+	}
+}
+
+func TestExport_saveMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	se := &Export{fs: fsadapter.NewDirectory(dir), opts: Options{Type: TMarkdown}, mentions: testMentions}
+
+	var ch slack.Channel
+	ch.ID = "C01"
+	ch.Name = "general"
+	conv := &types.Conversation{ID: "C01", Messages: []types.Message{
+		{
+			Message: slack.Message{Msg: slack.Msg{
+				Timestamp: "1674086400.000100",
+				User:      "U01",
+				Text:      "hello",
+				Files:     []slack.File{{Name: "my cat.png", Mimetype: "image/png", URLPrivateDownload: "attachments/F1-my cat.png"}},
+				Reactions: []slack.ItemReaction{{Name: "+1", Count: 2}},
+			}},
+			ThreadReplies: []types.Message{
+				{Message: slack.Message{Msg: slack.Msg{Timestamp: "1674086460.000200", User: "U02", Text: "reply"}}},
+			},
+		},
+	}}
+	if err := se.saveMarkdown("general", ch, conv, testHTMLUsers); err != nil {
+		t.Fatal(err)
+	}
+	if err := se.saveMarkdownIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "general", "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# #general\n\n" +
+		"**Alice** · 2023-01-19 00:00:00 UTC\n\n" +
+		"hello\n\n" +
+		"![my cat.png](attachments/F1-my%20cat.png)\n\n" +
+		":+1: 2\n\n" +
+		"> **Bob \\<The Builder\\>** · 2023-01-19 00:01:00 UTC\n" +
+		">\n" +
+		"> reply\n" +
+		">\n"
+	assert.Equal(t, want, string(page))
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(index), "| [#general](general/index.md) | 2 |")
+}
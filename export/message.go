@@ -22,7 +22,21 @@ type ExportMessage struct {
 	UserProfile     *ExportUserProfile `json:"user_profile"`
 	ReplyUsersCount int                `json:"reply_users_count"`
 	ReplyUsers      []string           `json:"reply_users"`
-	slackdumpTime   time.Time          `json:"-"`
+
+	// fields of the message_changed and message_deleted events, set if
+	// Options.EditMarkers is set, see setMarkers.
+	SubMessage      *slack.Msg `json:"message,omitempty"`
+	PreviousMessage *slack.Msg `json:"previous_message,omitempty"`
+
+	// fields added by slackdump, not present in slack exports.
+
+	// TimeISO is the message time in ISO-8601 (RFC3339) format, in the time
+	// zone of Options.Location, and TimeUnix is the same in seconds since
+	// epoch.  Both are accurate to a second, the original timestamp, that
+	// identifies the message, is kept in the ts field.
+	TimeISO       string    `json:"time,omitempty"`
+	TimeUnix      int64     `json:"time_unix,omitempty"`
+	slackdumpTime time.Time `json:"-"`
 }
 
 type ExportUserProfile struct {
@@ -48,7 +62,9 @@ func (em ExportMessage) Time() time.Time {
 // newExportMessage creates an export message from a slack message and populates
 // some additional fields.  Slack messages produced by export are much more
 // saturated with information, i.e. contain user profiles and thread stats.
-func newExportMessage(msg *types.Message, users structures.UserIndex) *ExportMessage {
+// The time of the message is populated in the loc time zone, if loc is nil,
+// UTC is used.
+func newExportMessage(msg *types.Message, users structures.UserIndex, loc *time.Location) *ExportMessage {
 	if msg == nil {
 		panic("internal error: msg is nil")
 	}
@@ -56,9 +72,22 @@ func newExportMessage(msg *types.Message, users structures.UserIndex) *ExportMes
 
 	expMsg.UserTeam = msg.Team
 	expMsg.SourceTeam = msg.Team
-	expMsg.slackdumpTime, _ = msg.Datetime()
+	if ts, err := msg.Datetime(); err == nil {
+		if loc == nil {
+			loc = time.UTC
+		}
+		expMsg.slackdumpTime = ts
+		expMsg.TimeISO = ts.In(loc).Format(time.RFC3339)
+		expMsg.TimeUnix = ts.Unix()
+	}
 
-	if user, ok := users[msg.User]; ok && !user.IsBot {
+	user, ok := users[msg.User]
+	if ok && user.Enterprise.EnterpriseID != "" && user.TeamID != "" {
+		// on the Enterprise Grid the user may belong to another workspace
+		// of the organisation than the one the message was posted in.
+		expMsg.UserTeam = user.TeamID
+	}
+	if ok && !user.IsBot {
 		expMsg.UserProfile = &ExportUserProfile{
 			AvatarHash:        "",
 			Image72:           user.Profile.Image72,
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/rusq/slackdump/v2/internal/fixtures"
@@ -40,9 +41,44 @@ func Test_newExportMessage(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := newExportMessage(tt.args.msg, tt.args.users)
+			got := newExportMessage(tt.args.msg, tt.args.users, nil)
 			got.slackdumpTime = time.Time{} // clear for comparison. not saved in fixture.
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
+
+func Test_newExportMessage_grid(t *testing.T) {
+	users := types.Users{
+		{ID: "U01", TeamID: "T02", Enterprise: slack.EnterpriseUser{EnterpriseID: "E01"}},
+		{ID: "U02", TeamID: "T01"},
+	}.IndexByID()
+
+	got := newExportMessage(&types.Message{Message: slack.Message{Msg: slack.Msg{User: "U01", Team: "T01", Timestamp: "1645095600.000100"}}}, users, nil)
+	assert.Equal(t, "T02", got.UserTeam, "grid user from another workspace")
+	assert.Equal(t, "T01", got.SourceTeam)
+
+	got = newExportMessage(&types.Message{Message: slack.Message{Msg: slack.Msg{User: "U02", Team: "T03", Timestamp: "1645095600.000100"}}}, users, nil)
+	assert.Equal(t, "T03", got.UserTeam, "non-grid user")
+}
+
+func Test_newExportMessage_time(t *testing.T) {
+	msg := &types.Message{Message: slack.Message{Msg: slack.Msg{Timestamp: "1609459199.000200"}}}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	got := newExportMessage(msg, nil, nil)
+	assert.Equal(t, "2020-12-31T23:59:59Z", got.TimeISO)
+	assert.Equal(t, int64(1609459199), got.TimeUnix)
+	assert.Equal(t, "1609459199.000200", got.Timestamp)
+
+	got = newExportMessage(msg, nil, tokyo)
+	assert.Equal(t, "2021-01-01T08:59:59+09:00", got.TimeISO)
+	assert.Equal(t, int64(1609459199), got.TimeUnix)
+
+	got = newExportMessage(&types.Message{}, nil, nil)
+	assert.Empty(t, got.TimeISO)
+	assert.Zero(t, got.TimeUnix)
+}
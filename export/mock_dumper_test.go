@@ -66,6 +66,20 @@ func (mr *MockdumperMockRecorder) CurrentUserID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentUserID", reflect.TypeOf((*Mockdumper)(nil).CurrentUserID))
 }
 
+// EnterpriseID mocks base method.
+func (m *Mockdumper) EnterpriseID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnterpriseID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// EnterpriseID indicates an expected call of EnterpriseID.
+func (mr *MockdumperMockRecorder) EnterpriseID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnterpriseID", reflect.TypeOf((*Mockdumper)(nil).EnterpriseID))
+}
+
 // DumpRaw mocks base method.
 func (m *Mockdumper) DumpRaw(ctx context.Context, link string, oldest, latest time.Time, processFn ...slackdump.ProcessFunc) (*types.Conversation, error) {
 	m.ctrl.T.Helper()
@@ -101,6 +115,36 @@ func (mr *MockdumperMockRecorder) GetChannelMembers(ctx, channelID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelMembers", reflect.TypeOf((*Mockdumper)(nil).GetChannelMembers), ctx, channelID)
 }
 
+// GetChannelCanvases mocks base method.
+func (m *Mockdumper) GetChannelCanvases(ctx context.Context, channelID string) ([]slack.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannelCanvases", ctx, channelID)
+	ret0, _ := ret[0].([]slack.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannelCanvases indicates an expected call of GetChannelCanvases.
+func (mr *MockdumperMockRecorder) GetChannelCanvases(ctx, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelCanvases", reflect.TypeOf((*Mockdumper)(nil).GetChannelCanvases), ctx, channelID)
+}
+
+// GetChannelPins mocks base method.
+func (m *Mockdumper) GetChannelPins(ctx context.Context, channelID string) ([]slack.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannelPins", ctx, channelID)
+	ret0, _ := ret[0].([]slack.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannelPins indicates an expected call of GetChannelPins.
+func (mr *MockdumperMockRecorder) GetChannelPins(ctx, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelPins", reflect.TypeOf((*Mockdumper)(nil).GetChannelPins), ctx, channelID)
+}
+
 // GetUsers mocks base method.
 func (m *Mockdumper) GetUsers(ctx context.Context) (types.Users, error) {
 	m.ctrl.T.Helper()
@@ -116,6 +160,20 @@ func (mr *MockdumperMockRecorder) GetUsers(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*Mockdumper)(nil).GetUsers), ctx)
 }
 
+// GetUsersCustomFields mocks base method.
+func (m *Mockdumper) GetUsersCustomFields(ctx context.Context, us types.Users) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersCustomFields", ctx, us)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetUsersCustomFields indicates an expected call of GetUsersCustomFields.
+func (mr *MockdumperMockRecorder) GetUsersCustomFields(ctx, us interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersCustomFields", reflect.TypeOf((*Mockdumper)(nil).GetUsersCustomFields), ctx, us)
+}
+
 // StreamChannels mocks base method.
 func (m *Mockdumper) StreamChannels(ctx context.Context, chanTypes []string, cb func(slack.Channel) error) error {
 	m.ctrl.T.Helper()
@@ -129,3 +187,31 @@ func (mr *MockdumperMockRecorder) StreamChannels(ctx, chanTypes, cb interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamChannels", reflect.TypeOf((*Mockdumper)(nil).StreamChannels), ctx, chanTypes, cb)
 }
+
+// Workspace mocks base method.
+func (m *Mockdumper) Workspace() slackdump.Workspace {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Workspace")
+	ret0, _ := ret[0].(slackdump.Workspace)
+	return ret0
+}
+
+// Workspace indicates an expected call of Workspace.
+func (mr *MockdumperMockRecorder) Workspace() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Workspace", reflect.TypeOf((*Mockdumper)(nil).Workspace))
+}
+
+// TeamID mocks base method.
+func (m *Mockdumper) TeamID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TeamID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// TeamID indicates an expected call of TeamID.
+func (mr *MockdumperMockRecorder) TeamID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TeamID", reflect.TypeOf((*Mockdumper)(nil).TeamID))
+}
@@ -1,20 +1,154 @@
 package export
 
 import (
+	"io"
 	"time"
 
+	"github.com/rusq/slackdump/v2/downloader"
 	"github.com/rusq/slackdump/v2/internal/structures"
 	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
 )
 
 // Options allows to configure slack export options.
 type Options struct {
-	Oldest      time.Time
-	Latest      time.Time
+	Oldest time.Time
+	Latest time.Time
+	// DateFilters, if set, are the disjoint time ranges of the messages to
+	// export, they take precedence over Oldest and Latest.
+	DateFilters []structures.DateFilter
 	Logger      logger.Interface
 	List        *structures.EntityList
 	Type        ExportType
 	ExportToken string
+	// StripFileURLs replaces the private URLs of the files in the exported
+	// messages with the paths of the downloaded files, or removes them, if
+	// the files were not downloaded, so that the export can be shared
+	// without leaking the file URLs.  It can't be used with ExportToken.
+	StripFileURLs bool
+	// ChannelTypes is the list of channel types to export, if the list has
+	// no included channels.  Empty means public and private channels, and
+	// the direct messages, if enabled by IncludeDMs and IncludeGroupDMs.
+	ChannelTypes []string
+	// IncludeDMs adds the direct messages to the export, if ChannelTypes is
+	// empty.  Direct messages are private, so they are only exported, if
+	// asked for.
+	IncludeDMs bool
+	// IncludeGroupDMs adds the group direct messages (multi-party IMs) to
+	// the export, if ChannelTypes is empty.
+	IncludeGroupDMs bool
+	// SkipExistingFiles skips downloading the files that already exist in
+	// the export directory with the expected size.
+	SkipExistingFiles bool
+	// FileTypes is the list of file types to download, see
+	// downloader.TypeFilter.
+	FileTypes []string
+	// MaxFileSize is the maximum size of the file to download, in bytes.
+	// Zero means no limit.
+	MaxFileSize int64
+	// MaxTotalBytes is the maximum number of bytes to download in total, the
+	// files are skipped once it's reached.  Zero means no limit.
+	MaxTotalBytes int64
+	// PreserveFileTimes sets the modification time of the downloaded files to
+	// the time when they were uploaded to Slack.
+	PreserveFileTimes bool
+	// DedupByContent links the files with identical contents to the file
+	// saved first, instead of saving them again.
+	DedupByContent bool
+	// Gzip compresses the exported files with gzip, and the downloaded files,
+	// except the ones that are already compressed, i.e. images and videos.
+	// Compressed files have the ".gz" extension.
+	Gzip bool
+	// Workers is the number of file download workers, zero means
+	// downloader.DefaultWorkers.
+	Workers int
+	// DownloadBytesPerSec limits the file download bandwidth.  Zero means
+	// unlimited.
+	DownloadBytesPerSec int64
+	// OnFileProgress, if set, is called each time a file download completes,
+	// see downloader.ProgressFunc.
+	OnFileProgress downloader.ProgressFunc
+	// DownloadAvatars downloads the custom profile images of the users into
+	// the "avatars" directory, and replaces the profile image URLs in the
+	// exported user records with the paths of the downloaded images.  Users
+	// without the custom profile image are skipped.  It has no effect, if
+	// the file download is disabled.
+	DownloadAvatars bool
+	// UserCustomFields fetches the custom profile fields of the users, and
+	// includes them in the exported user records.  It costs an extra API
+	// call per user.
+	UserCustomFields bool
+	// ResolveMentions replaces the user mentions and channel references in
+	// the message text with the names of the users and channels.  It has no
+	// effect on the Mattermost export, as Mattermost resolves them on import.
+	ResolveMentions bool
+	// ContinueOnError skips the conversations, that failed to export, and
+	// continues with the other ones, the errors are returned in
+	// Stats.Errors.  The errors, that affect all conversations, i.e.
+	// the authentication errors, stop the export regardless.
+	ContinueOnError bool
+	// State, if set, enables the incremental export: only the messages newer
+	// than the ones recorded in the state are fetched, and the state is
+	// updated with the latest exported messages.  Conversations that have
+	// been archived or deleted since the last run are skipped.
+	State *State
+	// IncludePins adds the list of the pinned messages and files to each
+	// channel in the channel files.  It costs an extra Tier-2 request per
+	// channel.
+	IncludePins bool
+	// IncludeCanvases adds the list of the canvases and lists to each
+	// channel in the channel files, and saves their content into the
+	// "canvases" directory, if the file download is enabled.  It costs an
+	// extra Tier-3 request per channel.
+	IncludeCanvases bool
+	// EditMarkers keeps the edit and deletion markers of the messages: the
+	// deleted and the original message of the message_deleted and
+	// message_changed events are kept in the JSON exports, and the HTML and
+	// Markdown exports mark the edited and deleted messages.
+	EditMarkers bool
+	// Archived limits the exported channels to the archived or to the
+	// active ones.  By default, both are exported.
+	Archived types.ArchivedFilter
+	// Location is the time zone for rendering the timestamps in the HTML and
+	// Markdown exports, and of the time field of the messages in the JSON
+	// exports.  If nil, UTC is used.
+	Location *time.Location
+	// UserLocation, if set, overrides the Location with the time zone of the
+	// current user, as set in the Slack profile.  If it is not known,
+	// Location is used.
+	UserLocation bool
+	// Redact replaces the names, emails and phone numbers in the user records
+	// and the message text with the pseudonyms, that are the same for the
+	// user ID across the runs.
+	Redact bool
+	// RedactMap, if set, receives the redaction map in JSON format at the
+	// end of the export, so that the redaction can be reversed.  It must not
+	// be shared along with the export.
+	RedactMap io.Writer
+}
+
+// ChanTypes returns the channel types to export.  The explicitly set
+// ChannelTypes are returned as is.
+func (opt Options) ChanTypes() []string {
+	if len(opt.ChannelTypes) > 0 {
+		return opt.ChannelTypes
+	}
+	types := []string{"public_channel", "private_channel"}
+	if opt.IncludeDMs {
+		types = append(types, "im")
+	}
+	if opt.IncludeGroupDMs {
+		types = append(types, "mpim")
+	}
+	return types
+}
+
+// ranges returns the time ranges of the messages to export.
+func (opt Options) ranges() []structures.DateFilter {
+	if len(opt.DateFilters) > 0 {
+		return opt.DateFilters
+	}
+	return []structures.DateFilter{{Start: opt.Oldest, End: opt.Latest}}
 }
 
 func (opt Options) IsFilesEnabled() bool {
@@ -1,6 +1,7 @@
 package export
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -42,3 +43,27 @@ func TestOptions_IsFilesEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestOptions_ChanTypes(t *testing.T) {
+	tests := []struct {
+		name            string
+		channelTypes    []string
+		includeDMs      bool
+		includeGroupDMs bool
+		want            []string
+	}{
+		{"default is channels only", nil, false, false, []string{"public_channel", "private_channel"}},
+		{"dms", nil, true, false, []string{"public_channel", "private_channel", "im"}},
+		{"group dms", nil, false, true, []string{"public_channel", "private_channel", "mpim"}},
+		{"all", nil, true, true, []string{"public_channel", "private_channel", "im", "mpim"}},
+		{"specified types", []string{"public_channel", "im"}, false, false, []string{"public_channel", "im"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := Options{ChannelTypes: tt.channelTypes, IncludeDMs: tt.includeDMs, IncludeGroupDMs: tt.includeGroupDMs}
+			if got := opt.ChanTypes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Options.ChanTypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
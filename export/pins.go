@@ -0,0 +1,71 @@
+package export
+
+// In this file: the pinned items of the channels.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// pin types, as in the Slack export.
+const (
+	pinTypeMessage = "C"
+	pinTypeFile    = "F"
+)
+
+// ExportPin is the pinned item entry in the "pins" list of the channel, as in
+// the Slack export.
+type ExportPin struct {
+	ID   string `json:"id"`             // message timestamp or file ID
+	Type string `json:"type"`           // "C" for messages, "F" for files
+	User string `json:"user,omitempty"` // author of the pinned item
+}
+
+// newExportPins converts the pinned items to the export pins.  Items other
+// than messages and files are skipped.
+func newExportPins(items []slack.Item) []ExportPin {
+	var pins []ExportPin
+	for _, item := range items {
+		switch {
+		case item.Message != nil:
+			pins = append(pins, ExportPin{ID: item.Message.Timestamp, Type: pinTypeMessage, User: item.Message.User})
+		case item.File != nil:
+			pins = append(pins, ExportPin{ID: item.File.ID, Type: pinTypeFile, User: item.File.User})
+		}
+	}
+	return pins
+}
+
+// channelPins returns the pinned items of the channel ch.  Direct messages are
+// not supported by the export format, and are skipped.  The pins of archived
+// channels, that can not be retrieved, are skipped with a warning.
+func (se *Export) channelPins(ctx context.Context, ch slack.Channel) ([]ExportPin, error) {
+	if ch.IsIM {
+		return nil, nil
+	}
+	items, err := se.sd.GetChannelPins(ctx, ch.ID)
+	if err != nil {
+		if ch.IsArchived || isNotFound(err) {
+			logger.With(se.l(), "channel", ch.ID).Printf("WARNING: unable to get the pins of %s (%s), skipping: %s", ch.ID, ch.Name, err)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting pins for %s: %w", ch.ID, err)
+	}
+	return newExportPins(items), nil
+}
+
+// setPins records the pins of the channel channelID, to be written to the
+// index.
+func (se *Export) setPins(channelID string, pins []ExportPin) {
+	if len(pins) == 0 {
+		return
+	}
+	if se.pins == nil {
+		se.pins = make(map[string][]ExportPin)
+	}
+	se.pins[channelID] = pins
+}
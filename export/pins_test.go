@@ -0,0 +1,146 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func Test_newExportPins(t *testing.T) {
+	items := []slack.Item{
+		slack.NewMessageItem("C01", &slack.Message{Msg: slack.Msg{Timestamp: "1645095600.000100", User: "U01"}}),
+		slack.NewFileItem(&slack.File{ID: "F01", User: "U02"}),
+		slack.NewFileCommentItem(nil, &slack.Comment{ID: "Fc01"}),
+	}
+	want := []ExportPin{
+		{ID: "1645095600.000100", Type: pinTypeMessage, User: "U01"},
+		{ID: "F01", Type: pinTypeFile, User: "U02"},
+	}
+	assert.Equal(t, want, newExportPins(items))
+}
+
+func TestExport_channelPins(t *testing.T) {
+	var (
+		channel  = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01"}, Name: "general"}}
+		archived = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C02"}, Name: "old", IsArchived: true}}
+		im       = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "D01", IsIM: true}}}
+		errPins  = errors.New("pins failed")
+	)
+	tests := []struct {
+		name    string
+		ch      slack.Channel
+		expect  func(d *Mockdumper)
+		want    []ExportPin
+		wantErr bool
+	}{
+		{
+			"ok",
+			channel,
+			func(d *Mockdumper) {
+				d.EXPECT().GetChannelPins(gomock.Any(), "C01").Return([]slack.Item{
+					slack.NewMessageItem("C01", &slack.Message{Msg: slack.Msg{Timestamp: "1645095600.000100", User: "U01"}}),
+				}, nil)
+			},
+			[]ExportPin{{ID: "1645095600.000100", Type: pinTypeMessage, User: "U01"}},
+			false,
+		},
+		{
+			"error",
+			channel,
+			func(d *Mockdumper) {
+				d.EXPECT().GetChannelPins(gomock.Any(), "C01").Return(nil, errPins)
+			},
+			nil,
+			true,
+		},
+		{
+			"archived channel error is skipped",
+			archived,
+			func(d *Mockdumper) {
+				d.EXPECT().GetChannelPins(gomock.Any(), "C02").Return(nil, errPins)
+			},
+			nil,
+			false,
+		},
+		{
+			"deleted channel is skipped",
+			channel,
+			func(d *Mockdumper) {
+				d.EXPECT().GetChannelPins(gomock.Any(), "C01").Return(nil, slack.SlackErrorResponse{Err: "channel_not_found"})
+			},
+			nil,
+			false,
+		},
+		{
+			"direct messages are not requested",
+			im,
+			func(d *Mockdumper) {},
+			nil,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewMockdumper(gomock.NewController(t))
+			tt.expect(d)
+			se := &Export{sd: d, lg: logger.Silent}
+			got, err := se.channelPins(context.Background(), tt.ch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Export.channelPins() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_index_addPins(t *testing.T) {
+	var (
+		pub  = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01"}, Name: "general", Topic: slack.Topic{Value: "news"}, Creator: "U01"}, IsChannel: true}
+		priv = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "G01", IsGroup: true}, Name: "secret", IsArchived: true}}
+		pins = []ExportPin{{ID: "1645095600.000100", Type: pinTypeMessage, User: "U01"}}
+	)
+	idx, err := createIndex([]slack.Channel{pub, priv}, []slack.User{{ID: "U01"}}, "U01")
+	require.NoError(t, err)
+	idx.addPins(map[string][]ExportPin{"C01": pins})
+
+	assert.Equal(t, pins, idx.Channels[0].Pins)
+	assert.Empty(t, idx.Groups[0].Pins)
+
+	data, err := json.Marshal(idx.Channels[0])
+	require.NoError(t, err)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "C01", got["id"])
+	assert.Equal(t, "U01", got["creator"])
+	assert.Equal(t, "news", got["topic"].(map[string]any)["value"])
+	assert.Equal(t, []any{map[string]any{"id": "1645095600.000100", "type": "C", "user": "U01"}}, got["pins"])
+}
+
+func Test_index_addContextTeam(t *testing.T) {
+	var (
+		pub  = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01", IsOrgShared: true}, Name: "general"}, IsChannel: true}
+		priv = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "G01", IsGroup: true}, Name: "secret"}}
+	)
+	idx, err := createIndex([]slack.Channel{pub, priv}, []slack.User{{ID: "U01"}}, "U01")
+	require.NoError(t, err)
+
+	idx.addContextTeam("")
+	data, err := json.Marshal(idx.Channels[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "context_team_id")
+
+	idx.addContextTeam("T01")
+	assert.Equal(t, "T01", idx.Channels[0].ContextTeamID)
+	assert.Equal(t, "T01", idx.Groups[0].ContextTeamID)
+	data, err = json.Marshal(idx.Channels[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"context_team_id":"T01"`)
+}
@@ -0,0 +1,123 @@
+package export
+
+// Helpers, common for the human-readable export formats.
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/internal/structures/files/dl"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// page is the entry of the index page of the human-readable exports.
+type page struct {
+	Title    string // channel name, as displayed
+	Path     string // path of the page, relative to the root of the export
+	Messages int    // number of messages, including thread replies
+}
+
+// sortedPages returns the exported pages, sorted by title.
+func (se *Export) sortedPages() []page {
+	sort.Slice(se.pages, func(i, j int) bool {
+		return se.pages[i].Title < se.pages[j].Title
+	})
+	return se.pages
+}
+
+// pageTitle returns the displayed name of the channel.
+func pageTitle(ch slack.Channel, userIdx structures.UserIndex) string {
+	if ch.IsIM || ch.IsMpIM {
+		if names := dmParticipants(ch, userIdx); len(names) > 0 {
+			return "@" + strings.Join(names, ", @")
+		}
+	}
+	if ch.Name == "" {
+		return ch.ID
+	}
+	return "#" + ch.Name
+}
+
+// dmParticipants returns the display names of the participants of the
+// direct message or the group direct message ch.  The members of the group
+// DM are parsed from its name, if the channel has none.
+func dmParticipants(ch slack.Channel, userIdx structures.UserIndex) []string {
+	if ch.IsIM {
+		return []string{userIdx.DisplayName(ch.User)}
+	}
+	members := ch.Members
+	if len(members) == 0 {
+		users := make([]slack.User, 0, len(userIdx))
+		for _, u := range userIdx {
+			users = append(users, *u)
+		}
+		if fixed, err := structures.FixMpIMmembers(&ch, users); err == nil {
+			members = fixed.Members
+		}
+	}
+	var names []string
+	for _, id := range members {
+		if id != "" {
+			names = append(names, userIdx.DisplayName(id))
+		}
+	}
+	return names
+}
+
+// dmDirName returns the directory name of the direct message or the group
+// direct message ch, made of the display names of the participants, and the
+// conversation ID, that keeps the name unique, i.e. "dm-Alice-D01".
+func dmDirName(ch slack.Channel, userIdx structures.UserIndex) string {
+	prefix := "dm"
+	if ch.IsMpIM {
+		prefix = "gdm"
+	}
+	parts := append([]string{prefix}, dmParticipants(ch, userIdx)...)
+	parts = append(parts, ch.ID)
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, strings.Join(parts, "-"))
+}
+
+// senderName returns the name of the sender of the message.
+func senderName(m *types.Message, userIdx structures.UserIndex) string {
+	if m.User == "" {
+		return nvl(m.Username, m.BotID)
+	}
+	return userIdx.DisplayName(m.User)
+}
+
+// senderAvatar returns the path of the downloaded avatar of the sender of
+// the message, relative to the channel directory, or an empty string, if the
+// avatar was not downloaded.
+func senderAvatar(m *types.Message, userIdx structures.UserIndex) string {
+	u, ok := userIdx[m.User]
+	if !ok || !strings.HasPrefix(u.Profile.Image72, dl.AvatarDir+"/") {
+		return ""
+	}
+	return path.Join("..", u.Profile.Image72)
+}
+
+// reSlackEntity matches the Slack entities in the message text, i.e. user
+// mentions "<@U123>", channel references "<#C123|general>" and links
+// "<https://example.com|example>".
+var reSlackEntity = regexp.MustCompile(`<([^<>]+)>`)
+
+// nvl returns the first non-empty string.
+func nvl(s ...string) string {
+	for _, v := range s {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,252 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/structures"
+)
+
+// SelectionDateFmt is the main date format that is accepted by
+// ParseUserInput, MM/DD/YY, see ParseDateFilter for other formats.
+const SelectionDateFmt = "01/02/06"
+
+// SelectionType is the type of the conversation selection.
+type SelectionType uint8
+
+const (
+	// SelAll selects all conversations.
+	SelAll SelectionType = iota
+	// SelDateRange selects all conversations, limited to the date range.
+	SelDateRange
+	// SelList selects the listed conversations.
+	SelList
+)
+
+// ExportSelection is the conversation selection entered by the user.
+type ExportSelection struct {
+	Type SelectionType
+	// List is the list of conversations for SelList.  For SelDateRange, it
+	// has no conversations, and the DateFilters are set.
+	List *structures.EntityList
+}
+
+// ErrInvalidSelection is returned by ParseUserInput if the input can't be
+// parsed.
+var ErrInvalidSelection = errors.New("invalid conversation selection")
+
+// ParseUserInput parses the conversation selection, that the user has entered.
+// The following inputs are accepted:
+//
+//   - empty string or "ALL" (case-insensitive) selects all conversations;
+//   - single date "MM/DD/YY" selects all conversations on that day, other
+//     date formats are accepted too, see ParseDateFilter;
+//   - date range "MM/DD/YY - MM/DD/YY" selects all conversations in the range,
+//     both dates inclusive, see ParseDateFilter;
+//   - comma separated dates and date ranges select all conversations in any
+//     of them, see ParseDateFilters;
+//   - whitespace separated conversation IDs or URLs, with the same syntax as
+//     the command line arguments, i.e. "^" to exclude, "@" to read from file;
+//   - conversation IDs or URLs, followed by the dates or date ranges, i.e.
+//     "C01234567 01/02/23 - 01/05/23", selects the listed conversations,
+//     limited to the date ranges.  The dates must follow the conversations.
+func ParseUserInput(input string) (ExportSelection, error) {
+	input = strings.TrimSpace(input)
+	if input == "" || strings.EqualFold(input, "all") {
+		return ExportSelection{Type: SelAll, List: new(structures.EntityList)}, nil
+	}
+	convs, dates := splitDates(input)
+	var dfs []structures.DateFilter
+	if dates != "" {
+		var err error
+		if dfs, err = ParseDateFilters(dates); err != nil {
+			return ExportSelection{}, err
+		}
+	}
+	if convs == "" {
+		return ExportSelection{Type: SelDateRange, List: &structures.EntityList{DateFilters: dfs}}, nil
+	}
+	el, err := structures.MakeEntityList(strings.Fields(convs))
+	if err != nil {
+		return ExportSelection{}, fmt.Errorf("%w: %s", ErrInvalidSelection, err)
+	}
+	el.DateFilters = dfs
+	return ExportSelection{Type: SelList, List: el}, nil
+}
+
+// splitDates splits the input into the conversations and the dates, that
+// start with the first whitespace separated field, that looks like a date.
+func splitDates(input string) (convs, dates string) {
+	if isDateInput(input) {
+		return "", input
+	}
+	for i := 1; i < len(input); i++ {
+		if isSpace(input[i-1]) && !isSpace(input[i]) && isDateInput(input[i:]) {
+			return strings.TrimSpace(input[:i]), input[i:]
+		}
+	}
+	return input, ""
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isDateInput returns true if the input looks like a date or a date range,
+// rather than a list of IDs, URLs or name patterns.
+func isDateInput(s string) bool {
+	if s == "" || strings.Contains(s, "://") {
+		return false
+	}
+	return dateInputRe.MatchString(s)
+}
+
+// ParseDateFilters parses the comma separated dates and date ranges, each in
+// the format accepted by ParseDateFilter, i.e. "01/02/23, 03/01/23 - 03/05/23".
+// The overlapping and adjacent ranges are merged, so that the messages would
+// not be fetched twice.  Empty string returns nil.
+func ParseDateFilters(s string) ([]structures.DateFilter, error) {
+	var dfs []structures.DateFilter
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		df, err := ParseDateFilter(part)
+		if err != nil {
+			return nil, err
+		}
+		dfs = append(dfs, df)
+	}
+	return structures.MergeDateFilters(dfs), nil
+}
+
+// ParseDateFilter parses the date or the date range into the date filter.
+// The dates can be entered in any of the dateLayouts, i.e. "01/02/23",
+// "2023-01-02" or "Jan 2 2023".  The range is two dates, separated with a dash,
+// i.e. "01/02/23 - 01/05/23".  Both dates of the range are inclusive, the end
+// date includes the whole day, up to 23:59:59.  Either side of the range can be
+// omitted, i.e. "01/02/23 -" selects everything since that date, and
+// "- 01/05/23" selects everything up to and including that date.  The single
+// date selects the whole day.  Empty string returns an empty filter.
+func ParseDateFilter(s string) (structures.DateFilter, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return structures.DateFilter{}, nil
+	}
+	first, last, isRange, err := splitRange(s)
+	if err != nil {
+		return structures.DateFilter{}, fmt.Errorf("%w: date range %q: %s", ErrInvalidSelection, s, err)
+	}
+	if !isRange {
+		day, err := parseDate(first)
+		if err != nil {
+			return structures.DateFilter{}, fmt.Errorf("%w: invalid date: %s", ErrInvalidSelection, err)
+		}
+		return structures.DateFilter{Start: day, End: day.AddDate(0, 0, 1)}, nil
+	}
+	var df structures.DateFilter
+	if strings.TrimSpace(first) != "" {
+		start, err := parseDate(first)
+		if err != nil {
+			return structures.DateFilter{}, fmt.Errorf("%w: invalid start date: %s", ErrInvalidSelection, err)
+		}
+		df.Start = start
+	}
+	if strings.TrimSpace(last) != "" {
+		end, err := parseDate(last)
+		if err != nil {
+			return structures.DateFilter{}, fmt.Errorf("%w: invalid end date: %s", ErrInvalidSelection, err)
+		}
+		if end.Before(df.Start) {
+			return structures.DateFilter{}, fmt.Errorf("%w: end date %s is before the start date %s", ErrInvalidSelection, end.Format(normDateFmt), df.Start.Format(normDateFmt))
+		}
+		// the end date is inclusive, so the range ends at the start of the
+		// next day.
+		df.End = end.AddDate(0, 0, 1)
+	}
+	if df.IsZero() {
+		return structures.DateFilter{}, fmt.Errorf("%w: date range %q has no dates", ErrInvalidSelection, s)
+	}
+	return df, nil
+}
+
+// splitRange splits the date range s into the start and the end dates.  As
+// the dates may contain dashes themselves, i.e. "2023-01-02", the range
+// separator is either the " - " with spaces around it, or the dash that
+// splits s into two valid dates.  isRange is false, if s is a single date.
+func splitRange(s string) (first, last string, isRange bool, err error) {
+	switch n := strings.Count(s, " - "); {
+	case n > 1:
+		return "", "", false, errors.New("should have the form START - END")
+	case n == 1:
+		first, last, _ = strings.Cut(s, " - ")
+		return first, last, true, nil
+	}
+	if strings.HasPrefix(s, "-") {
+		return "", s[1:], true, nil
+	}
+	if strings.HasSuffix(s, "-") {
+		return s[:len(s)-1], "", true, nil
+	}
+	if _, err := parseDate(s); err == nil {
+		return s, "", false, nil
+	}
+	for i := strings.Index(s, "-"); i >= 0; {
+		if _, err := parseDate(s[:i]); err == nil {
+			if _, err := parseDate(s[i+1:]); err == nil {
+				return s[:i], s[i+1:], true, nil
+			}
+		}
+		next := strings.Index(s[i+1:], "-")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	if strings.Count(s, "-") == 1 {
+		// one of the dates is invalid, split anyway, so that the error
+		// points to it.
+		first, last, _ = strings.Cut(s, "-")
+		return first, last, true, nil
+	}
+	return s, "", false, nil
+}
+
+// dateLayouts are the layouts of the dates, accepted by ParseDateFilter.
+var dateLayouts = []string{
+	SelectionDateFmt,
+	"01/02/2006",
+	"1/2/06",
+	"1/2/2006",
+	"2006-01-02",
+	"2006-1-2",
+	"Jan 2 2006",
+	"January 2 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+// normDateFmt is the format of the dates in the error messages.
+const normDateFmt = "2006-01-02"
+
+// dateInputRe matches the start of the input, that is a date or a date range
+// in any of the dateLayouts.
+var dateInputRe = regexp.MustCompile(`(?i)^(-|\d{1,2}/\d{1,2}/\d{2}|\d{4}-\d{1,2}-\d{1,2}|(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\s+\d{1,2}\s+\d{4}|\d{1,2}\s+(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\s+\d{4})`)
+
+// parseDate parses the date in any of the dateLayouts.  Extra whitespace
+// between the date elements is ignored.
+func parseDate(s string) (time.Time, error) {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return time.Time{}, errors.New("date is empty, expected MM/DD/YY, YYYY-MM-DD or Mon D YYYY")
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a valid date, expected MM/DD/YY, YYYY-MM-DD or Mon D YYYY", s)
+}
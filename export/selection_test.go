@@ -0,0 +1,210 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/internal/structures"
+)
+
+func TestParseUserInput(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name    string
+		input   string
+		want    ExportSelection
+		wantErr bool
+	}{
+		{"empty", "", ExportSelection{Type: SelAll, List: new(structures.EntityList)}, false},
+		{"blank", "   ", ExportSelection{Type: SelAll, List: new(structures.EntityList)}, false},
+		{"ALL", "ALL", ExportSelection{Type: SelAll, List: new(structures.EntityList)}, false},
+		{"all lowercase", "all", ExportSelection{Type: SelAll, List: new(structures.EntityList)}, false},
+		{
+			"date range",
+			"01/02/23 - 01/05/23",
+			ExportSelection{Type: SelDateRange, List: &structures.EntityList{DateFilters: []structures.DateFilter{{Start: date(2023, 1, 2), End: date(2023, 1, 6)}}}},
+			false,
+		},
+		{
+			"date range without spaces",
+			"12/30/22-01/01/23",
+			ExportSelection{Type: SelDateRange, List: &structures.EntityList{DateFilters: []structures.DateFilter{{Start: date(2022, 12, 30), End: date(2023, 1, 2)}}}},
+			false,
+		},
+		{
+			"single date",
+			"02/28/23",
+			ExportSelection{Type: SelDateRange, List: &structures.EntityList{DateFilters: []structures.DateFilter{{Start: date(2023, 2, 28), End: date(2023, 3, 1)}}}},
+			false,
+		},
+		{
+			"bare channel ID",
+			"C4810ACC",
+			ExportSelection{Type: SelList, List: &structures.EntityList{Include: []string{"C4810ACC"}}},
+			false,
+		},
+		{
+			"IDs and exclusions",
+			"C4810ACC  ^C5000000",
+			ExportSelection{Type: SelList, List: &structures.EntityList{Include: []string{"C4810ACC"}, Exclude: []string{"C5000000"}}},
+			false,
+		},
+		{"malformed date", "13/45/23", ExportSelection{}, true},
+		{"malformed end date", "01/01/23 - 01/32/23", ExportSelection{}, true},
+		{
+			"start only",
+			"01/01/23 -",
+			ExportSelection{Type: SelDateRange, List: &structures.EntityList{DateFilters: []structures.DateFilter{{Start: date(2023, 1, 1)}}}},
+			false,
+		},
+		{"end before start", "01/05/23 - 01/01/23", ExportSelection{}, true},
+		{
+			"several date ranges",
+			"03/01/23 - 03/05/23, 01/02/23",
+			ExportSelection{Type: SelDateRange, List: &structures.EntityList{DateFilters: []structures.DateFilter{
+				{Start: date(2023, 1, 2), End: date(2023, 1, 3)},
+				{Start: date(2023, 3, 1), End: date(2023, 3, 6)},
+			}}},
+			false,
+		},
+		{"malformed second range", "01/02/23, 01/32/23", ExportSelection{}, true},
+		{
+			"IDs and date range",
+			"C4810ACC ^C5000000 01/02/23 - 01/05/23, 2023-03-01",
+			ExportSelection{Type: SelList, List: &structures.EntityList{
+				Include: []string{"C4810ACC"},
+				Exclude: []string{"C5000000"},
+				DateFilters: []structures.DateFilter{
+					{Start: date(2023, 1, 2), End: date(2023, 1, 6)},
+					{Start: date(2023, 3, 1), End: date(2023, 3, 2)},
+				},
+			}},
+			false,
+		},
+		{
+			"URL and open date range",
+			"https://ora600.slack.com/archives/CHM82GF99 - Jan 5 2023",
+			ExportSelection{Type: SelList, List: &structures.EntityList{
+				Include:     []string{"CHM82GF99"},
+				DateFilters: []structures.DateFilter{{End: date(2023, 1, 6)}},
+			}},
+			false,
+		},
+		{"ID and malformed date", "C4810ACC 01/32/23", ExportSelection{}, true},
+		{"date before IDs", "01/02/23 C4810ACC", ExportSelection{}, true},
+		{
+			"iso date",
+			"2023-01-02",
+			ExportSelection{Type: SelDateRange, List: &structures.EntityList{DateFilters: []structures.DateFilter{{Start: date(2023, 1, 2), End: date(2023, 1, 3)}}}},
+			false,
+		},
+		{
+			"month name date",
+			"Jan 2 2023 -",
+			ExportSelection{Type: SelDateRange, List: &structures.EntityList{DateFilters: []structures.DateFilter{{Start: date(2023, 1, 2)}}}},
+			false,
+		},
+		{"too many dashes", "01/01/23 - 01/02/23 - 01/03/23", ExportSelection{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUserInput(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUserInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSelection)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseDateFilter(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name    string
+		input   string
+		want    structures.DateFilter
+		wantErr bool
+	}{
+		{"empty", "", structures.DateFilter{}, false},
+		{"range", "01/02/23 - 01/05/23", structures.DateFilter{Start: date(2023, 1, 2), End: date(2023, 1, 6)}, false},
+		{"single day", "01/02/23", structures.DateFilter{Start: date(2023, 1, 2), End: date(2023, 1, 3)}, false},
+		{"start only", "01/02/23 -", structures.DateFilter{Start: date(2023, 1, 2)}, false},
+		{"end only", "- 01/05/23", structures.DateFilter{End: date(2023, 1, 6)}, false},
+		{"no dates", " - ", structures.DateFilter{}, true},
+		{"malformed start", "13/2/2023 -", structures.DateFilter{}, true},
+		{"malformed end", "- 02/30/23", structures.DateFilter{}, true},
+		{"iso date", "2023-01-02", structures.DateFilter{Start: date(2023, 1, 2), End: date(2023, 1, 3)}, false},
+		{"iso range", "2023-01-02 - 2023-01-05", structures.DateFilter{Start: date(2023, 1, 2), End: date(2023, 1, 6)}, false},
+		{"iso range without spaces", "2023-01-02-2023-01-05", structures.DateFilter{Start: date(2023, 1, 2), End: date(2023, 1, 6)}, false},
+		{"iso start only", "2023-01-02 -", structures.DateFilter{Start: date(2023, 1, 2)}, false},
+		{"month name", "Jan 2 2023", structures.DateFilter{Start: date(2023, 1, 2), End: date(2023, 1, 3)}, false},
+		{"month name range", "jan 2 2023 - February  5 2023", structures.DateFilter{Start: date(2023, 1, 2), End: date(2023, 2, 6)}, false},
+		{"day first month name", "2 Jan 2023", structures.DateFilter{Start: date(2023, 1, 2), End: date(2023, 1, 3)}, false},
+		{"four digit year", "01/02/2023", structures.DateFilter{Start: date(2023, 1, 2), End: date(2023, 1, 3)}, false},
+		{"mixed formats", "01/02/23 - 2023-01-05", structures.DateFilter{Start: date(2023, 1, 2), End: date(2023, 1, 6)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDateFilter(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDateFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseDateFilter_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"start", "2023-13-01 - 2023-01-05", "invalid start date"},
+		{"end", "2023-01-01 - Jan 32 2023", "invalid end date"},
+		{"end without spaces", "01/01/23-01/32/23", "invalid end date"},
+		{"single", "Foo 2 2023", "invalid date"},
+		{"reversed", "2023-01-05 - 2023-01-01", "end date 2023-01-01 is before the start date 2023-01-05"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDateFilter(tt.input)
+			assert.ErrorIs(t, err, ErrInvalidSelection)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func Test_isDateInput(t *testing.T) {
+	for input, want := range map[string]bool{
+		"01/02/23":            true,
+		"- 01/02/23":          true,
+		"2023-01-02":          true,
+		"Jan 2 2023":          true,
+		"2 january 2023":      true,
+		"C4810ACC":            false,
+		"2023-*":              false,
+		"janitors":            false,
+		"https://x/archives/": false,
+	} {
+		assert.Equal(t, want, isDateInput(input), input)
+	}
+}
+
+func TestParseUserInput_namePatterns(t *testing.T) {
+	got, err := ParseUserInput(`proj-* /^incident-\d+$/`)
+	assert.NoError(t, err)
+	assert.Equal(t, SelList, got.Type)
+	assert.True(t, got.List.HasPatterns())
+	assert.Len(t, got.List.Patterns, 2)
+}
@@ -0,0 +1,117 @@
+package export
+
+// Incremental export state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// errNotReadable is returned in the incremental export, if the filesystem
+// is not able to read the existing files of the export.
+var errNotReadable = errors.New("incremental export requires the export directory, the existing files can't be read from")
+
+// State is the state of the incremental export.  It records the timestamp of
+// the latest exported message for each conversation, so that the next run
+// would fetch only the messages that are newer.
+type State struct {
+	// Channels maps the conversation ID to the timestamp of the latest
+	// exported message.
+	Channels map[string]string `json:"channels"`
+}
+
+// LoadState loads the export state from the file filename.  If the file does
+// not exist, it returns an empty state, as it is the first run.
+func LoadState(filename string) (*State, error) {
+	st := &State{Channels: make(map[string]string)}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return st, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", filename, err)
+	}
+	if st.Channels == nil {
+		st.Channels = make(map[string]string)
+	}
+	return st, nil
+}
+
+// Save writes the state to the file filename.
+func (st *State) Save(filename string) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+// Has returns true if the conversation with channelID has been exported
+// before.  It is safe to call on a nil State.
+func (st *State) Has(channelID string) bool {
+	if st == nil {
+		return false
+	}
+	_, ok := st.Channels[channelID]
+	return ok
+}
+
+// Oldest returns the time of the latest exported message in the conversation
+// channelID, if it is after oldest, otherwise it returns oldest.
+func (st *State) Oldest(channelID string, oldest time.Time) time.Time {
+	if st == nil {
+		return oldest
+	}
+	last, err := structures.ParseSlackTS(st.Channels[channelID])
+	if err != nil || !last.After(oldest) {
+		return oldest
+	}
+	return last
+}
+
+// Update removes the messages that have been exported by the previous run
+// from conv, and records the timestamp of the latest message.  The API
+// returns the message with the "oldest" timestamp, as the range is
+// inclusive, so it must not be exported twice.
+func (st *State) Update(conv *types.Conversation) {
+	if st == nil {
+		return
+	}
+	channelID := conv.ID
+	last, err := structures.ParseSlackTS(st.Channels[channelID])
+	if err != nil {
+		last = time.Time{}
+	}
+	latest := last
+	msgs := conv.Messages[:0]
+	for _, m := range conv.Messages {
+		t, err := m.Datetime()
+		if err != nil {
+			msgs = append(msgs, m)
+			continue
+		}
+		if !t.After(last) {
+			continue
+		}
+		if t.After(latest) {
+			latest = t
+		}
+		msgs = append(msgs, m)
+	}
+	conv.Messages = msgs
+	if latest.After(last) {
+		if st.Channels == nil {
+			st.Channels = make(map[string]string)
+		}
+		st.Channels[channelID] = structures.FormatSlackTS(latest)
+	}
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func testMsg(ts string, text string) types.Message {
+	return types.Message{Message: slack.Message{Msg: slack.Msg{Timestamp: ts, Text: text}}}
+}
+
+func TestLoadState(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.json")
+
+	st, err := LoadState(filename)
+	require.NoError(t, err, "missing file is the first run")
+	assert.Empty(t, st.Channels)
+
+	st.Channels["C01"] = "1577694990.000400"
+	require.NoError(t, st.Save(filename))
+
+	got, err := LoadState(filename)
+	require.NoError(t, err)
+	assert.Equal(t, st, got)
+
+	require.NoError(t, os.WriteFile(filename, []byte("{"), 0644))
+	_, err = LoadState(filename)
+	assert.Error(t, err)
+}
+
+func TestState_Oldest(t *testing.T) {
+	st := &State{Channels: map[string]string{"C01": "1577694990.000400"}}
+	last := time.Unix(1577694990, 400).UTC()
+
+	assert.Equal(t, last, st.Oldest("C01", time.Time{}))
+	assert.Equal(t, time.Time{}, st.Oldest("C02", time.Time{}), "unknown channel")
+	later := last.Add(time.Hour)
+	assert.Equal(t, later, st.Oldest("C01", later), "oldest is after the last message")
+
+	var nilState *State
+	assert.Equal(t, later, nilState.Oldest("C01", later))
+}
+
+func TestState_Update(t *testing.T) {
+	st := &State{Channels: map[string]string{"C01": "1577694990.000400"}}
+	conv := &types.Conversation{ID: "C01", Messages: []types.Message{
+		testMsg("1577694990.000400", "exported by the previous run"),
+		testMsg("1577694995.000100", "new"),
+		testMsg("1577694993.000100", "also new"),
+	}}
+	st.Update(conv)
+	assert.Len(t, conv.Messages, 2)
+	assert.Equal(t, "new", conv.Messages[0].Text)
+	assert.Equal(t, "1577694995.000100", st.Channels["C01"])
+
+	// no new messages
+	conv = &types.Conversation{ID: "C01", Messages: []types.Message{testMsg("1577694995.000100", "new")}}
+	st.Update(conv)
+	assert.Empty(t, conv.Messages)
+	assert.Equal(t, "1577694995.000100", st.Channels["C01"])
+
+	// new channel
+	st.Update(&types.Conversation{ID: "C02", Messages: []types.Message{testMsg("1577694999.000000", "first")}})
+	assert.Equal(t, "1577694999.000000", st.Channels["C02"])
+}
+
+func TestExport_appendExisting(t *testing.T) {
+	dir := t.TempDir()
+	fs := fsadapter.NewDirectory(dir)
+	se := &Export{fs: fs, opts: Options{State: &State{}}}
+
+	first := messagesByDate{"2019-12-30": {
+		{Msg: &slack.Msg{Timestamp: "1577694990.000400", Text: "one"}},
+		{Msg: &slack.Msg{Timestamp: "1577694991.000400", Text: "two"}},
+	}}
+	require.NoError(t, se.saveChannel("general", first))
+
+	second := messagesByDate{"2019-12-30": {
+		{Msg: &slack.Msg{Timestamp: "1577694991.000400", Text: "two (edited)"}},
+		{Msg: &slack.Msg{Timestamp: "1577694992.000400", Text: "three"}},
+	}}
+	require.NoError(t, se.saveChannel("general", second))
+
+	got, err := se.appendExisting(filepath.Join("general", "2019-12-30.json"), nil)
+	require.NoError(t, err)
+	var texts []string
+	for _, m := range got {
+		texts = append(texts, m.Text)
+	}
+	assert.Equal(t, []string{"one", "two (edited)", "three"}, texts)
+}
+
+func TestExport_appendExisting_zip(t *testing.T) {
+	msgs := messagesByDate{"2019-12-30": {
+		{Msg: &slack.Msg{Timestamp: "1577694990.000400", Text: "one"}},
+	}}
+	t.Run("zip", func(t *testing.T) {
+		zf, err := fsadapter.NewZipFile(filepath.Join(t.TempDir(), "export.zip"))
+		require.NoError(t, err)
+		defer zf.Close()
+
+		se := &Export{fs: zf, opts: Options{State: &State{}}}
+		assert.ErrorIs(t, se.saveChannel("general", msgs), errNotReadable)
+	})
+	t.Run("compressed zip", func(t *testing.T) {
+		zf, err := fsadapter.NewZipFile(filepath.Join(t.TempDir(), "export.zip"))
+		require.NoError(t, err)
+		defer zf.Close()
+
+		se := &Export{fs: fsadapter.NewGzip(zf), opts: Options{State: &State{}}}
+		assert.ErrorIs(t, se.saveChannel("general", msgs), errNotReadable)
+	})
+}
@@ -0,0 +1,121 @@
+package slackdump
+
+// In this file: message filtering.
+
+import (
+	"strings"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// filterMessages removes the messages that are excluded by the session
+// options from msgs, and returns the remaining messages.  It must be called
+// before the process functions, so that the files of the excluded messages
+// are not downloaded.  msgs is modified in place.
+//
+// The messages that start a thread are kept, if they do not match the message
+// or reaction filter, as their replies might, see filterThreads.
+func (sd *Session) filterMessages(msgs []types.Message) []types.Message {
+	return sd.filterMessagesBy(msgs, sd.hasContentFilter())
+}
+
+// filterMessagesBy is filterMessages, that applies the message and reaction
+// filters only if byContent is true, the bots are excluded regardless.
+func (sd *Session) filterMessagesBy(msgs []types.Message, byContent bool) []types.Message {
+	if !sd.options.ExcludeBots && !byContent {
+		return msgs
+	}
+	var kept = msgs[:0]
+	for _, m := range msgs {
+		if sd.options.ExcludeBots && isExcludedBot(m) {
+			continue
+		}
+		if byContent && !sd.matches(m) && !isThreadStart(m) {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+// filterThreads removes the thread parents, that do not match the message
+// or reaction filter, from msgs, once the thread replies are populated.  If
+// the parent has matching replies, either the parent is kept, if the
+// MessageFilterParents option is set, or the replies are returned in place of
+// the parent, so that the matching replies are not lost.
+func (sd *Session) filterThreads(msgs []types.Message) []types.Message {
+	if !sd.hasContentFilter() {
+		return msgs
+	}
+	var kept []types.Message
+	for _, m := range msgs {
+		switch {
+		case sd.matches(m):
+			kept = append(kept, m)
+		case len(m.ThreadReplies) == 0:
+			// the thread parent without matching replies.
+		case sd.options.MessageFilterParents:
+			kept = append(kept, m)
+		default:
+			for _, r := range m.ThreadReplies {
+				if r.SubType == "thread_broadcast" {
+					// broadcasts are also returned with the channel
+					// messages, and are kept on their own.
+					continue
+				}
+				kept = append(kept, r)
+			}
+		}
+	}
+	return kept
+}
+
+// hasContentFilter returns true if the message or reaction filter is set.
+func (sd *Session) hasContentFilter() bool {
+	return sd.msgFilter != nil || len(sd.options.ReactionFilter) > 0
+}
+
+// keepThread returns true if all replies of the thread with the parent
+// message m should be kept, regardless of the filters.
+func (sd *Session) keepThread(m types.Message) bool {
+	return sd.options.ReactionFilterThreads && len(sd.options.ReactionFilter) > 0 && sd.matches(m)
+}
+
+// matches returns true if m matches both the message filter and the reaction
+// filter, the filters that are not set match all messages.
+func (sd *Session) matches(m types.Message) bool {
+	if sd.msgFilter != nil && !sd.msgFilter.MatchString(m.Text) {
+		return false
+	}
+	if len(sd.options.ReactionFilter) > 0 && !hasReaction(m, sd.options.ReactionFilter) {
+		return false
+	}
+	return true
+}
+
+// hasReaction returns true if m has any of the reactions names.  The names
+// are compared without the colons and the skin tone modifiers, so that
+// "thumbsup" matches "thumbsup::skin-tone-2".
+func hasReaction(m types.Message, names []string) bool {
+	for _, r := range m.Reactions {
+		name, _, _ := strings.Cut(r.Name, "::")
+		for _, want := range names {
+			if name == strings.Trim(want, ":") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isExcludedBot returns true if m is the bot message, that should be
+// excluded.  The bot messages that start a thread are kept, so that the
+// replies of the humans are not orphaned.
+func isExcludedBot(m types.Message) bool {
+	return m.IsBotMessage() && !isThreadStart(m)
+}
+
+// isThreadStart returns true if m is the message that started the thread.
+func isThreadStart(m types.Message) bool {
+	return m.IsThreadParent() && m.ThreadTimestamp == m.Timestamp
+}
@@ -0,0 +1,294 @@
+package slackdump
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func testFilterMsg(ts string, fn func(m *slack.Msg)) types.Message {
+	msg := slack.Msg{Timestamp: ts, Text: "message " + ts}
+	fn(&msg)
+	return types.Message{Message: slack.Message{Msg: msg}}
+}
+
+var (
+	humanMsg      = testFilterMsg("1.000001", func(m *slack.Msg) { m.User = "U1" })
+	botIDMsg      = testFilterMsg("1.000002", func(m *slack.Msg) { m.BotID = "B1" })
+	botSubtypeMsg = testFilterMsg("1.000003", func(m *slack.Msg) { m.SubType = slack.MsgSubTypeBotMessage })
+	botParentMsg  = testFilterMsg("1.000004", func(m *slack.Msg) {
+		m.BotID = "B1"
+		m.ThreadTimestamp = "1.000004"
+		m.ReplyCount = 2
+	})
+	botReplyMsg = testFilterMsg("1.000005", func(m *slack.Msg) {
+		m.BotID = "B1"
+		m.ThreadTimestamp = "1.000004"
+	})
+	humanReplyMsg = testFilterMsg("1.000006", func(m *slack.Msg) {
+		m.User = "U1"
+		m.ThreadTimestamp = "1.000004"
+	})
+)
+
+func TestSession_filterMessages(t *testing.T) {
+	msgs := func() []types.Message {
+		return []types.Message{humanMsg, botIDMsg, botSubtypeMsg, botParentMsg, botReplyMsg, humanReplyMsg}
+	}
+	t.Run("bots are kept by default", func(t *testing.T) {
+		sd := Session{options: DefOptions}
+		assert.Equal(t, msgs(), sd.filterMessages(msgs()))
+	})
+	t.Run("bots are excluded", func(t *testing.T) {
+		opts := DefOptions
+		opts.ExcludeBots = true
+		sd := Session{options: opts}
+		assert.Equal(t, []types.Message{humanMsg, botParentMsg, humanReplyMsg}, sd.filterMessages(msgs()))
+	})
+}
+
+func TestSession_dumpChannel_excludeBots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mc := newmockClienter(ctrl)
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+		&slack.GetConversationHistoryResponse{
+			SlackResponse: slack.SlackResponse{Ok: true},
+			Messages:      []slack.Message{humanMsg.Message, botIDMsg.Message, botParentMsg.Message},
+		}, nil)
+	mc.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return(
+		[]slack.Message{botParentMsg.Message, botReplyMsg.Message, humanReplyMsg.Message}, false, "", nil)
+	mockConvInfo(mc, "CHANNEL", "channel_name")
+
+	opts := DefOptions
+	opts.ExcludeBots = true
+	sd := Session{client: mc, options: opts}
+
+	// the process functions, i.e. the file downloader, must only see the
+	// messages that are kept.
+	var seen []string
+	recordFn := func(msgs []types.Message, _ string) (ProcessResult, error) {
+		for _, m := range msgs {
+			seen = append(seen, m.Timestamp)
+			for _, r := range m.ThreadReplies {
+				seen = append(seen, r.Timestamp)
+			}
+		}
+		return ProcessResult{Entity: "seen", Count: len(msgs)}, nil
+	}
+	conv, err := sd.dumpChannel(context.Background(), "CHANNEL", time.Time{}, time.Time{}, recordFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{humanMsg.Timestamp, botParentMsg.Timestamp, humanReplyMsg.Timestamp}, seen)
+	if assert.Len(t, conv.Messages, 2) {
+		assert.Equal(t, humanMsg.Timestamp, conv.Messages[0].Timestamp)
+		assert.Equal(t, []types.Message{humanReplyMsg}, conv.Messages[1].ThreadReplies)
+	}
+}
+
+func TestSession_filterMessages_messageFilter(t *testing.T) {
+	var (
+		match    = testFilterMsg("2.000001", func(m *slack.Msg) { m.Text = "the build is broken" })
+		noMatch  = testFilterMsg("2.000002", func(m *slack.Msg) { m.Text = "lunch?" })
+		parent   = testFilterMsg("2.000003", func(m *slack.Msg) { m.Text = "standup"; m.ThreadTimestamp = "2.000003"; m.ReplyCount = 2 })
+		botMatch = testFilterMsg("2.000004", func(m *slack.Msg) { m.Text = "build failed"; m.BotID = "B1" })
+	)
+	msgs := func() []types.Message {
+		return []types.Message{match, noMatch, parent, botMatch}
+	}
+	t.Run("thread parents are kept until the replies are known", func(t *testing.T) {
+		sd := Session{options: DefOptions, msgFilter: regexp.MustCompile("build")}
+		assert.Equal(t, []types.Message{match, parent, botMatch}, sd.filterMessages(msgs()))
+	})
+	t.Run("combined with the bot filter", func(t *testing.T) {
+		opts := DefOptions
+		opts.ExcludeBots = true
+		sd := Session{options: opts, msgFilter: regexp.MustCompile("build")}
+		assert.Equal(t, []types.Message{match, parent}, sd.filterMessages(msgs()))
+	})
+}
+
+func TestSession_filterThreads(t *testing.T) {
+	var (
+		reply     = testFilterMsg("3.000002", func(m *slack.Msg) { m.Text = "the build is green"; m.ThreadTimestamp = "3.000001" })
+		broadcast = testFilterMsg("3.000003", func(m *slack.Msg) {
+			m.Text = "build fixed"
+			m.ThreadTimestamp = "3.000001"
+			m.SubType = "thread_broadcast"
+		})
+		parent = testFilterMsg("3.000001", func(m *slack.Msg) { m.Text = "standup"; m.ThreadTimestamp = "3.000001"; m.ReplyCount = 3 })
+		empty  = testFilterMsg("3.000004", func(m *slack.Msg) { m.Text = "retro"; m.ThreadTimestamp = "3.000004"; m.ReplyCount = 1 })
+		match  = testFilterMsg("3.000005", func(m *slack.Msg) { m.Text = "build"; m.ThreadTimestamp = "3.000005"; m.ReplyCount = 1 })
+	)
+	parent.ThreadReplies = []types.Message{reply, broadcast}
+	match.ThreadReplies = []types.Message{reply}
+	msgs := func() []types.Message {
+		return []types.Message{parent, empty, match, broadcast}
+	}
+	t.Run("no filter", func(t *testing.T) {
+		sd := Session{options: DefOptions}
+		assert.Equal(t, msgs(), sd.filterThreads(msgs()))
+	})
+	t.Run("replies replace the parent", func(t *testing.T) {
+		sd := Session{options: DefOptions, msgFilter: regexp.MustCompile("build")}
+		assert.Equal(t, []types.Message{reply, match, broadcast}, sd.filterThreads(msgs()))
+	})
+	t.Run("parents are kept", func(t *testing.T) {
+		opts := DefOptions
+		opts.MessageFilterParents = true
+		sd := Session{options: opts, msgFilter: regexp.MustCompile("build")}
+		assert.Equal(t, []types.Message{parent, match, broadcast}, sd.filterThreads(msgs()))
+	})
+}
+
+func TestSession_dumpChannel_messageFilter(t *testing.T) {
+	var (
+		match   = testFilterMsg("4.000001", func(m *slack.Msg) { m.Text = "deploy done" })
+		noMatch = testFilterMsg("4.000002", func(m *slack.Msg) { m.Text = "coffee" })
+		parent  = testFilterMsg("4.000003", func(m *slack.Msg) { m.Text = "release"; m.ThreadTimestamp = "4.000003"; m.ReplyCount = 2 })
+		reply1  = testFilterMsg("4.000004", func(m *slack.Msg) { m.Text = "deploy started"; m.ThreadTimestamp = "4.000003" })
+		reply2  = testFilterMsg("4.000005", func(m *slack.Msg) { m.Text = "ok"; m.ThreadTimestamp = "4.000003" })
+	)
+	ctrl := gomock.NewController(t)
+	mc := newmockClienter(ctrl)
+	mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+		&slack.GetConversationHistoryResponse{
+			SlackResponse: slack.SlackResponse{Ok: true},
+			Messages:      []slack.Message{parent.Message, noMatch.Message, match.Message},
+		}, nil)
+	mc.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return(
+		[]slack.Message{parent.Message, reply1.Message, reply2.Message}, false, "", nil)
+	mockConvInfo(mc, "CHANNEL", "channel_name")
+
+	sd := Session{client: mc, options: DefOptions, msgFilter: regexp.MustCompile("deploy")}
+
+	var seen []string
+	recordFn := func(msgs []types.Message, _ string) (ProcessResult, error) {
+		for _, m := range msgs {
+			seen = append(seen, m.Timestamp)
+		}
+		return ProcessResult{Entity: "seen", Count: len(msgs)}, nil
+	}
+	conv, err := sd.dumpChannel(context.Background(), "CHANNEL", time.Time{}, time.Time{}, recordFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the files processor sees only the matching messages, the matching
+	// reply takes the place of the non-matching parent.
+	assert.Equal(t, []string{reply1.Timestamp, match.Timestamp}, seen)
+	assert.Equal(t, []types.Message{match, reply1}, conv.Messages)
+}
+
+func testReactions(names ...string) []slack.ItemReaction {
+	var rr []slack.ItemReaction
+	for _, n := range names {
+		rr = append(rr, slack.ItemReaction{Name: n, Count: 1, Users: []string{"U1"}})
+	}
+	return rr
+}
+
+func Test_hasReaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		reactions []string
+		names     []string
+		want      bool
+	}{
+		{"no reactions", nil, []string{"bookmark"}, false},
+		{"other reaction", []string{"tada"}, []string{"bookmark"}, false},
+		{"matching reaction", []string{"tada", "bookmark"}, []string{"bookmark"}, true},
+		{"any of the names", []string{"pushpin"}, []string{"bookmark", "pushpin"}, true},
+		{"with colons", []string{"bookmark"}, []string{":bookmark:"}, true},
+		{"skin tone", []string{"thumbsup::skin-tone-2"}, []string{"thumbsup"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testFilterMsg("5.000001", func(m *slack.Msg) { m.Reactions = testReactions(tt.reactions...) })
+			assert.Equal(t, tt.want, hasReaction(m, tt.names))
+		})
+	}
+}
+
+func TestSession_filterMessages_reactionFilter(t *testing.T) {
+	var (
+		bookmarked = testFilterMsg("6.000001", func(m *slack.Msg) { m.Text = "read later"; m.Reactions = testReactions("bookmark") })
+		other      = testFilterMsg("6.000002", func(m *slack.Msg) { m.Text = "build done"; m.Reactions = testReactions("tada") })
+		none       = testFilterMsg("6.000003", func(m *slack.Msg) { m.Text = "build broken" })
+		parent     = testFilterMsg("6.000004", func(m *slack.Msg) { m.ThreadTimestamp = "6.000004"; m.ReplyCount = 1 })
+	)
+	msgs := func() []types.Message {
+		return []types.Message{bookmarked, other, none, parent}
+	}
+	t.Run("messages without the reaction are removed", func(t *testing.T) {
+		opts := DefOptions
+		opts.ReactionFilter = []string{"bookmark"}
+		sd := Session{options: opts}
+		assert.Equal(t, []types.Message{bookmarked, parent}, sd.filterMessages(msgs()))
+	})
+	t.Run("combined with the message filter", func(t *testing.T) {
+		opts := DefOptions
+		opts.ReactionFilter = []string{"bookmark", "tada"}
+		sd := Session{options: opts, msgFilter: regexp.MustCompile("build")}
+		assert.Equal(t, []types.Message{other, parent}, sd.filterMessages(msgs()))
+	})
+}
+
+func TestSession_dumpChannel_reactionFilterThreads(t *testing.T) {
+	var (
+		parent = testFilterMsg("7.000001", func(m *slack.Msg) {
+			m.ThreadTimestamp = "7.000001"
+			m.ReplyCount = 2
+			m.Reactions = testReactions("bookmark")
+		})
+		reply1  = testFilterMsg("7.000002", func(m *slack.Msg) { m.ThreadTimestamp = "7.000001" })
+		reply2  = testFilterMsg("7.000003", func(m *slack.Msg) { m.ThreadTimestamp = "7.000001" })
+		noMatch = testFilterMsg("7.000004", func(m *slack.Msg) {})
+	)
+	tests := []struct {
+		name    string
+		threads bool
+		want    []string
+	}{
+		{"replies are filtered", false, []string{parent.Timestamp}},
+		{"thread is kept", true, []string{parent.Timestamp, reply1.Timestamp, reply2.Timestamp}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mc := newmockClienter(ctrl)
+			mc.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(
+				&slack.GetConversationHistoryResponse{
+					SlackResponse: slack.SlackResponse{Ok: true},
+					Messages:      []slack.Message{noMatch.Message, parent.Message},
+				}, nil)
+			mc.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return(
+				[]slack.Message{parent.Message, reply1.Message, reply2.Message}, false, "", nil)
+			mockConvInfo(mc, "CHANNEL", "channel_name")
+
+			opts := DefOptions
+			opts.ReactionFilter = []string{"bookmark"}
+			opts.ReactionFilterThreads = tt.threads
+			sd := Session{client: mc, options: opts}
+
+			conv, err := sd.dumpChannel(context.Background(), "CHANNEL", time.Time{}, time.Time{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range conv.Messages {
+				got = append(got, m.Timestamp)
+				for _, r := range m.ThreadReplies {
+					got = append(got, r.Timestamp)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package fsadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// Bucket URL schemes.
+const (
+	SchemeS3  = "s3" // Amazon S3 and S3-compatible storage, i.e. s3://bucket/prefix
+	SchemeGCS = "gs" // Google Cloud Storage, i.e. gs://bucket/prefix
+)
+
+// objectStore is the object storage of the cloud provider.
+type objectStore interface {
+	// writer returns the writer, that uploads everything written to it as
+	// the object key of the bucket.  The object is complete, once the writer
+	// is closed without an error.
+	writer(ctx context.Context, key string) io.WriteCloser
+	// close releases the resources of the store.
+	close() error
+}
+
+var _ FSCloser = &Bucket{}
+
+// Bucket is a filesystem adapter for the bucket of the cloud object storage,
+// Amazon S3 or Google Cloud Storage.  The files are uploaded as the objects,
+// named after the file path, prefixed with the prefix of the bucket URL.  The
+// file contents is streamed to the storage as it is written, the large files
+// are uploaded in parts, and nothing is written to the local disk.
+type Bucket struct {
+	store  objectStore
+	url    string // bucket URL, as given
+	prefix string // object name prefix
+}
+
+// IsBucketURL returns true if location is the URL of the bucket, i.e.
+// s3://bucket/prefix or gs://bucket/prefix.
+func IsBucketURL(location string) bool {
+	scheme, _, found := strings.Cut(location, "://")
+	if !found {
+		return false
+	}
+	scheme = strings.ToLower(scheme)
+	return scheme == SchemeS3 || scheme == SchemeGCS
+}
+
+// parseBucketURL returns the scheme, the bucket name and the object name
+// prefix of the bucket URL location.
+func parseBucketURL(location string) (scheme, bucket, prefix string, err error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid bucket URL %q: %w", location, err)
+	}
+	scheme = strings.ToLower(u.Scheme)
+	if scheme != SchemeS3 && scheme != SchemeGCS {
+		return "", "", "", fmt.Errorf("invalid bucket URL %q: unsupported scheme %q", location, u.Scheme)
+	}
+	if u.Host == "" {
+		return "", "", "", fmt.Errorf("invalid bucket URL %q: no bucket name", location)
+	}
+	return scheme, u.Host, strings.Trim(u.Path, "/"), nil
+}
+
+// NewBucket returns a new Bucket filesystem adapter for the bucket URL
+// location.  The credentials are found by the cloud SDKs in the standard
+// way, see newS3Store and newGCSStore.
+func NewBucket(location string) (*Bucket, error) {
+	scheme, bucket, prefix, err := parseBucketURL(location)
+	if err != nil {
+		return nil, err
+	}
+	var store objectStore
+	switch scheme {
+	case SchemeS3:
+		store, err = newS3Store(context.Background(), bucket)
+	case SchemeGCS:
+		store, err = newGCSStore(context.Background(), bucket)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+	return &Bucket{store: store, url: location, prefix: prefix}, nil
+}
+
+func (b *Bucket) String() string {
+	return fmt.Sprintf("<bucket: %s>", b.url)
+}
+
+// key returns the object name for the file name.
+func (b *Bucket) key(name string) string {
+	return path.Join(b.prefix, filepath.ToSlash(filepath.Clean(name)))
+}
+
+// Create creates a new file name in the bucket.  The upload is complete, when
+// the returned writer is closed, Close returns the upload error, if any.
+func (b *Bucket) Create(name string) (io.WriteCloser, error) {
+	return b.objectWriter(b.key(name)), nil
+}
+
+// objectWriter returns the writer, that uploads everything written to it as
+// the object key.
+func (b *Bucket) objectWriter(key string) *objectWriter {
+	return &objectWriter{w: b.store.writer(context.Background(), key)}
+}
+
+// WriteFile uploads data as the file name to the bucket.
+func (b *Bucket) WriteFile(name string, data []byte, _ os.FileMode) error {
+	w := b.objectWriter(b.key(name))
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Close releases the resources of the bucket.  The files must be closed
+// before, as they are uploaded when they are closed.
+func (b *Bucket) Close() error {
+	return b.store.close()
+}
+
+// objectWriter is the writer of the object, that can't be written to once
+// closed, and can be closed more than once.
+type objectWriter struct {
+	w      io.WriteCloser
+	closed atomic.Bool
+}
+
+func (ow *objectWriter) Write(p []byte) (int, error) {
+	if ow.closed.Load() {
+		return 0, errors.New("file already closed")
+	}
+	return ow.w.Write(p)
+}
+
+func (ow *objectWriter) Close() error {
+	if ow.closed.Swap(true) {
+		return nil
+	}
+	return ow.w.Close()
+}
+
+// newBucketZip returns the ZIP filesystem adapter, that uploads the archive
+// to the bucket, the location is the bucket URL of the archive, i.e.
+// s3://bucket/prefix/export.zip.
+func newBucketZip(location string) (*ZIP, error) {
+	b, err := NewBucket(location)
+	if err != nil {
+		return nil, err
+	}
+	if b.prefix == "" {
+		return nil, fmt.Errorf("invalid bucket URL %q: no archive name", location)
+	}
+	return newZipWriter(&bucketObject{objectWriter: b.objectWriter(b.prefix), b: b}, location), nil
+}
+
+// bucketObject is the object writer, that closes the bucket, once the object
+// is closed.
+type bucketObject struct {
+	*objectWriter
+	b *Bucket
+}
+
+func (bo *bucketObject) Close() error {
+	err := bo.objectWriter.Close()
+	if cerr := bo.b.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
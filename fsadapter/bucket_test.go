@@ -0,0 +1,165 @@
+package fsadapter
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore is the in-memory objectStore.
+type fakeStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	err     error
+	closed  bool
+}
+
+func (fs *fakeStore) writer(_ context.Context, key string) io.WriteCloser {
+	return &fakeObject{fs: fs, key: key}
+}
+
+func (fs *fakeStore) close() error {
+	fs.closed = true
+	return nil
+}
+
+// fakeObject stores the object in fakeStore, once closed.
+type fakeObject struct {
+	fs  *fakeStore
+	key string
+	buf bytes.Buffer
+}
+
+func (fo *fakeObject) Write(p []byte) (int, error) {
+	return fo.buf.Write(p)
+}
+
+func (fo *fakeObject) Close() error {
+	if fo.fs.err != nil {
+		return fo.fs.err
+	}
+	fo.fs.mu.Lock()
+	defer fo.fs.mu.Unlock()
+	if fo.fs.objects == nil {
+		fo.fs.objects = make(map[string][]byte)
+	}
+	fo.fs.objects[fo.key] = fo.buf.Bytes()
+	return nil
+}
+
+func TestIsBucketURL(t *testing.T) {
+	tests := []struct {
+		location string
+		want     bool
+	}{
+		{"s3://bucket/prefix", true},
+		{"S3://bucket", true},
+		{"gs://bucket/prefix/export.zip", true},
+		{"https://bucket.s3.amazonaws.com/prefix", false},
+		{"slackdump_20220101_000000", false},
+		{filepath.Join("some", "dir.zip"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsBucketURL(tt.location))
+		})
+	}
+}
+
+func Test_parseBucketURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		location   string
+		wantScheme string
+		wantBucket string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{"s3 with prefix", "s3://bucket/some/prefix/", SchemeS3, "bucket", "some/prefix", false},
+		{"gcs without prefix", "gs://bucket", SchemeGCS, "bucket", "", false},
+		{"zip archive", "s3://bucket/exports/export.zip", SchemeS3, "bucket", "exports/export.zip", false},
+		{"no bucket", "s3:///prefix", "", "", "", true},
+		{"unsupported scheme", "ftp://bucket/prefix", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme, bucket, prefix, err := parseBucketURL(tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBucketURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantScheme, scheme)
+			assert.Equal(t, tt.wantBucket, bucket)
+			assert.Equal(t, tt.wantPrefix, prefix)
+		})
+	}
+}
+
+func TestBucket_Create(t *testing.T) {
+	store := &fakeStore{}
+	b := &Bucket{store: store, url: "s3://bucket/prefix", prefix: "prefix"}
+
+	w, err := b.Create(filepath.Join("C123", "attachments", "file.txt"))
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "hello, ")
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "world")
+	require.NoError(t, err)
+	assert.Empty(t, store.objects, "must not be uploaded before Close")
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, map[string][]byte{"prefix/C123/attachments/file.txt": []byte("hello, world")}, store.objects)
+
+	_, err = w.Write([]byte("more"))
+	assert.Error(t, err, "write after close")
+	assert.NoError(t, w.Close(), "second close is noop")
+}
+
+func TestBucket_WriteFile(t *testing.T) {
+	t.Run("no prefix", func(t *testing.T) {
+		store := &fakeStore{}
+		b := &Bucket{store: store, url: "gs://bucket"}
+		require.NoError(t, b.WriteFile("users.json", []byte("[]"), 0644))
+		assert.Equal(t, map[string][]byte{"users.json": []byte("[]")}, store.objects)
+		require.NoError(t, b.Close())
+		assert.True(t, store.closed)
+	})
+	t.Run("upload error", func(t *testing.T) {
+		b := &Bucket{store: &fakeStore{err: errors.New("denied")}, url: "gs://bucket"}
+		assert.Error(t, b.WriteFile("users.json", []byte("[]"), 0644))
+	})
+}
+
+func Test_newBucketZip(t *testing.T) {
+	s3 := newFakeS3()
+	srv := httptest.NewServer(s3)
+	defer srv.Close()
+	setAWSEnv(t, srv.URL)
+
+	fs, err := New("s3://bucket/exports/export.zip")
+	require.NoError(t, err)
+	assert.Equal(t, "<zip archive: s3://bucket/exports/export.zip>", fs.(interface{ String() string }).String())
+	require.NoError(t, fs.WriteFile("channels.json", []byte("[]"), 0644))
+	require.NoError(t, fs.Close())
+
+	data, _ := s3.object("/bucket/exports/export.zip")
+	require.NotNil(t, data, "archive not uploaded")
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	f, err := zr.Open("channels.json")
+	require.NoError(t, err)
+	got, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("[]"), got)
+
+	_, err = New("s3://bucket.zip")
+	assert.Error(t, err, "no archive name")
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	iofs "io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 var _ FS = Directory{}
@@ -88,7 +90,73 @@ func (fs Directory) WriteFile(name string, data []byte, perm os.FileMode) error
 	return os.WriteFile(node, data, perm)
 }
 
+// Stat returns the FileInfo for the file name within the directory.
+func (fs Directory) Stat(name string) (iofs.FileInfo, error) {
+	node := filepath.Join(fs.dir, name)
+	if err := fs.ensureSubdir(node); err != nil {
+		return nil, fmt.Errorf("Stat: %w", err)
+	}
+	return os.Stat(node)
+}
+
+// ReadFile reads the file name within the directory and returns its contents.
+func (fs Directory) ReadFile(name string) ([]byte, error) {
+	node := filepath.Join(fs.dir, name)
+	if err := fs.ensureSubdir(node); err != nil {
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+	return os.ReadFile(node)
+}
+
+// Open opens the file name within the directory for reading.
+func (fs Directory) Open(name string) (*os.File, error) {
+	node := filepath.Join(fs.dir, name)
+	if err := fs.ensureSubdir(node); err != nil {
+		return nil, fmt.Errorf("Open: %w", err)
+	}
+	return os.Open(node)
+}
+
+// Chtimes changes the access and modification times of the file name within
+// the directory.
+func (fs Directory) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	node := filepath.Join(fs.dir, name)
+	if err := fs.ensureSubdir(node); err != nil {
+		return fmt.Errorf("Chtimes: %w", err)
+	}
+	return os.Chtimes(node, atime, mtime)
+}
+
 // Close is a noop for Directory.
 func (fs Directory) Close() error {
 	return nil
 }
+
+// Link creates newname as a hard link to the oldname file within the
+// directory.  If the hard link can not be created, i.e. if the filesystem
+// does not support it, it creates a relative symbolic link instead.  If
+// newname exists, it is replaced.
+func (fs Directory) Link(oldname, newname string) error {
+	oldNode := filepath.Join(fs.dir, oldname)
+	newNode := filepath.Join(fs.dir, newname)
+	if err := fs.ensureSubdir(oldNode); err != nil {
+		return fmt.Errorf("Link: %w", err)
+	}
+	if err := fs.ensureSubdir(newNode); err != nil {
+		return fmt.Errorf("Link: %w", err)
+	}
+	if err := mkdirAll(filepath.Dir(newNode)); err != nil {
+		return err
+	}
+	if err := os.Remove(newNode); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Link(oldNode, newNode); err == nil {
+		return nil
+	}
+	target, err := filepath.Rel(filepath.Dir(newNode), oldNode)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(target, newNode)
+}
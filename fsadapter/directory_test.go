@@ -1,10 +1,12 @@
 package fsadapter
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -233,3 +235,92 @@ func TestDirectory_WriteFile(t *testing.T) {
 		})
 	}
 }
+
+func TestDirectory_Stat(t *testing.T) {
+	tmpdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "blah.txt"), []byte("blah"), 0640))
+	fs := NewDirectory(tmpdir)
+
+	t.Run("existing file", func(t *testing.T) {
+		fi, err := fs.Stat("blah.txt")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), fi.Size())
+	})
+	t.Run("missing file", func(t *testing.T) {
+		_, err := fs.Stat("missing.txt")
+		assert.True(t, os.IsNotExist(err))
+	})
+	t.Run("outside of base path is an error", func(t *testing.T) {
+		_, err := fs.Stat(filepath.Join("..", "blah.txt"))
+		assert.ErrorIs(t, err, ErrIllegalDir)
+	})
+}
+
+func TestDirectory_ReadFile(t *testing.T) {
+	tmpdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "blah.txt"), []byte("blah"), 0640))
+	fs := NewDirectory(tmpdir)
+
+	data, err := fs.ReadFile("blah.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "blah", string(data))
+
+	_, err = fs.ReadFile("missing.txt")
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = fs.ReadFile(filepath.Join("..", "blah.txt"))
+	assert.ErrorIs(t, err, ErrIllegalDir)
+}
+
+func TestDirectory_Open(t *testing.T) {
+	tmpdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "blah.txt"), []byte("blah"), 0640))
+	fs := NewDirectory(tmpdir)
+
+	f, err := fs.Open("blah.txt")
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	f.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "blah", string(data))
+
+	_, err = fs.Open("missing.txt")
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = fs.Open(filepath.Join("..", "blah.txt"))
+	assert.ErrorIs(t, err, ErrIllegalDir)
+}
+
+func TestDirectory_Chtimes(t *testing.T) {
+	tmpdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "blah.txt"), []byte("blah"), 0640))
+	fs := NewDirectory(tmpdir)
+
+	mtime := time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC)
+	require.NoError(t, fs.Chtimes("blah.txt", mtime, mtime))
+	fi, err := os.Stat(filepath.Join(tmpdir, "blah.txt"))
+	require.NoError(t, err)
+	assert.True(t, mtime.Equal(fi.ModTime()), "got %s", fi.ModTime())
+
+	assert.ErrorIs(t, fs.Chtimes(filepath.Join("..", "blah.txt"), mtime, mtime), ErrIllegalDir)
+}
+
+func TestDirectory_Link(t *testing.T) {
+	tmpdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "blah.txt"), []byte("blah"), 0640))
+	fs := NewDirectory(tmpdir)
+
+	require.NoError(t, fs.Link("blah.txt", filepath.Join("sub", "link.txt")))
+	data, err := os.ReadFile(filepath.Join(tmpdir, "sub", "link.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "blah", string(data))
+
+	// existing file is replaced
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "other.txt"), []byte("other"), 0640))
+	require.NoError(t, fs.Link("blah.txt", "other.txt"))
+	data, err = os.ReadFile(filepath.Join(tmpdir, "other.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "blah", string(data))
+
+	assert.ErrorIs(t, fs.Link("blah.txt", filepath.Join("..", "blah.txt")), ErrIllegalDir)
+}
@@ -23,13 +23,21 @@ type FSCloser interface {
 
 // New returns appropriate filesystem based on the name of the location.
 // Logic is simple:
+//   - if location is the bucket URL, i.e. s3://bucket/prefix or
+//     gs://bucket/prefix, the files are uploaded to the bucket, see Bucket;
 //   - if location has a known extension, the appropriate adapter is returned.
 //   - else: it's a directory.
 //
-// Currently supported extensions: ".zip" (case insensitive)
+// Currently supported extensions: ".zip" (case insensitive), the ZIP archive
+// can be uploaded to the bucket as well.
 func New(location string) (FSCloser, error) {
-	switch strings.ToUpper(filepath.Ext(location)) {
-	case ".ZIP":
+	isZip := strings.EqualFold(filepath.Ext(location), ".zip")
+	switch {
+	case IsBucketURL(location) && isZip:
+		return newBucketZip(location)
+	case IsBucketURL(location):
+		return NewBucket(location)
+	case isZip:
 		return NewZipFile(location)
 	default:
 		return NewDirectory(location), nil
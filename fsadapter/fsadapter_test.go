@@ -10,6 +10,9 @@ import (
 
 func TestNew(t *testing.T) {
 	tmp := t.TempDir()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("STORAGE_EMULATOR_HOST", "localhost:9023")
 	type args struct {
 		name string
 	}
@@ -31,6 +34,24 @@ func TestNew(t *testing.T) {
 			"<zip archive: " + filepath.Join(tmp, "bloop.zip") + ">",
 			false,
 		},
+		{
+			"s3 bucket",
+			args{"s3://bucket/prefix"},
+			"<bucket: s3://bucket/prefix>",
+			false,
+		},
+		{
+			"gcs bucket",
+			args{"gs://bucket"},
+			"<bucket: gs://bucket>",
+			false,
+		},
+		{
+			"invalid bucket url",
+			args{"s3:///prefix"},
+			"",
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -39,6 +60,9 @@ func TestNew(t *testing.T) {
 				t.Errorf("ForFilename() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if err != nil {
+				return
+			}
 			defer got.Close()
 
 			assert.Equal(t, tt.wantString, fmt.Sprint(got))
@@ -0,0 +1,58 @@
+package fsadapter
+
+// In this file: Google Cloud Storage.
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+)
+
+// gcsStore is the Google Cloud Storage bucket.
+type gcsStore struct {
+	client *storage.Client
+	bucket *storage.BucketHandle
+}
+
+// newGCSStore returns the Google Cloud Storage bucket store.  The credentials
+// are the Application Default Credentials: the file, set in the
+// GOOGLE_APPLICATION_CREDENTIALS environment variable, the credentials of
+// "gcloud auth application-default login", or the service account of the
+// Compute Engine.  If STORAGE_EMULATOR_HOST is set, the objects are uploaded
+// to the emulator without the credentials.
+func newGCSStore(ctx context.Context, bucket string) (*gcsStore, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no Google Cloud credentials: %w", err)
+	}
+	return &gcsStore{client: client, bucket: client.Bucket(bucket)}, nil
+}
+
+// writer returns the writer of the object key, the object is uploaded in
+// chunks with the resumable upload.
+func (s *gcsStore) writer(ctx context.Context, key string) io.WriteCloser {
+	return &gcsWriter{w: s.bucket.Object(key).NewWriter(ctx), key: key}
+}
+
+func (s *gcsStore) close() error {
+	return s.client.Close()
+}
+
+// gcsWriter adds the object name to the upload error.
+type gcsWriter struct {
+	w   *storage.Writer
+	key string
+}
+
+func (w *gcsWriter) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+func (w *gcsWriter) Close() error {
+	if err := w.w.Close(); err != nil {
+		return fmt.Errorf("gcs: error uploading %q: %w", w.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package fsadapter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_gcsStore_writer_emulator(t *testing.T) {
+	var (
+		gotPath string
+		gotName string
+		gotBody []byte
+		gotAuth string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// the first part is the object metadata, the second is the media.
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		meta, err := mr.NextPart()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var attrs struct {
+			Name string `json:"name"`
+		}
+		json.NewDecoder(meta).Decode(&attrs)
+		gotName = attrs.Name
+		media, err := mr.NextPart()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		gotBody, _ = io.ReadAll(media)
+		io.WriteString(w, `{"bucket":"bucket","name":"`+attrs.Name+`"}`)
+	}))
+	defer srv.Close()
+
+	t.Setenv("STORAGE_EMULATOR_HOST", strings.TrimPrefix(srv.URL, "http://"))
+	s, err := newGCSStore(context.Background(), "bucket")
+	require.NoError(t, err)
+	defer s.close()
+
+	w := s.writer(context.Background(), "prefix/C123.json")
+	_, err = io.WriteString(w, "data")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "/upload/storage/v1/b/bucket/o", gotPath)
+	assert.Equal(t, "prefix/C123.json", gotName)
+	assert.Equal(t, []byte("data"), gotBody)
+	assert.Empty(t, gotAuth)
+}
+
+func Test_gcsStore_writer_error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"error":{"code":403,"message":"access denied"}}`)
+	}))
+	defer srv.Close()
+
+	t.Setenv("STORAGE_EMULATOR_HOST", strings.TrimPrefix(srv.URL, "http://"))
+	s, err := newGCSStore(context.Background(), "bucket")
+	require.NoError(t, err)
+	defer s.close()
+
+	w := s.writer(context.Background(), "users.json")
+	io.WriteString(w, "[]")
+	err = w.Close()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `gcs: error uploading "users.json"`)
+}
+
+func Test_newGCSStore_noCredentials(t *testing.T) {
+	t.Setenv("STORAGE_EMULATOR_HOST", "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+	_, err := newGCSStore(context.Background(), "bucket")
+	assert.Error(t, err)
+}
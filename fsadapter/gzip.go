@@ -0,0 +1,112 @@
+package fsadapter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+)
+
+// GzipExt is the extension, that Gzip adds to the names of the files.
+const GzipExt = ".gz"
+
+var _ FSCloser = &Gzip{}
+
+// Gzip is a filesystem adapter, that compresses the files with gzip before
+// writing them to the underlying filesystem, and adds GzipExt to their
+// names.  It can wrap any other adapter, i.e. it's possible to have the
+// gzipped files within the ZIP archive.
+type Gzip struct {
+	fs FS
+}
+
+// NewGzip returns a new Gzip filesystem adapter, that writes the compressed
+// files to fs.
+func NewGzip(fs FS) *Gzip {
+	return &Gzip{fs: fs}
+}
+
+func (g *Gzip) String() string {
+	return fmt.Sprintf("<gzip: %s>", g.fs)
+}
+
+// Create creates a new file name with GzipExt appended in the underlying
+// filesystem.  Everything written to the returned writer is compressed.  The
+// caller must call Close to flush the compressed data.
+func (g *Gzip) Create(name string) (io.WriteCloser, error) {
+	f, err := g.fs.Create(name + GzipExt)
+	if err != nil {
+		return nil, err
+	}
+	return NewGzipWriter(f), nil
+}
+
+// WriteFile compresses data and writes it to the file name with GzipExt
+// appended in the underlying filesystem.
+func (g *Gzip) WriteFile(name string, data []byte, perm os.FileMode) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return g.fs.WriteFile(name+GzipExt, buf.Bytes(), perm)
+}
+
+// ReadFile reads the file name with GzipExt appended from the underlying
+// filesystem and returns its decompressed contents.  If the underlying
+// filesystem is not able to read the files, i.e. ZIP, it returns an error
+// that wraps errors.ErrUnsupported.
+func (g *Gzip) ReadFile(name string) ([]byte, error) {
+	fr, ok := g.fs.(interface {
+		ReadFile(name string) ([]byte, error)
+	})
+	if !ok {
+		return nil, &iofs.PathError{Op: "ReadFile", Path: name + GzipExt, Err: errors.ErrUnsupported}
+	}
+	data, err := fr.ReadFile(name + GzipExt)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile: %s: %w", name+GzipExt, err)
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
+
+// Close closes the underlying filesystem, if it can be closed.
+func (g *Gzip) Close() error {
+	if c, ok := g.fs.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// NewGzipWriter returns the writer, that compresses everything written to it
+// with gzip, and writes it to w.  Close flushes the compressed data and closes
+// w.
+func NewGzipWriter(w io.WriteCloser) io.WriteCloser {
+	return &gzipWriter{Writer: gzip.NewWriter(w), f: w}
+}
+
+// gzipWriter is the gzip writer, that closes the underlying file once the
+// compressed data is flushed.
+type gzipWriter struct {
+	*gzip.Writer
+	f io.WriteCloser
+}
+
+func (gw *gzipWriter) Close() error {
+	if err := gw.Writer.Close(); err != nil {
+		gw.f.Close()
+		return err
+	}
+	return gw.f.Close()
+}
@@ -0,0 +1,97 @@
+package fsadapter
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gunzipFile returns the decompressed contents of the gzip file filename.
+func gunzipFile(t *testing.T, filename string) string {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	got, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	return string(got)
+}
+
+func TestGzip_Create(t *testing.T) {
+	tmpdir := t.TempDir()
+	g := NewGzip(NewDirectory(tmpdir))
+
+	w, err := g.Create(filepath.Join("sub", "data.json"))
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "contents")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.NoFileExists(t, filepath.Join(tmpdir, "sub", "data.json"))
+	assert.Equal(t, "contents", gunzipFile(t, filepath.Join(tmpdir, "sub", "data.json.gz")))
+}
+
+func TestGzip_WriteFile(t *testing.T) {
+	tmpdir := t.TempDir()
+	g := NewGzip(NewDirectory(tmpdir))
+
+	require.NoError(t, g.WriteFile("data.json", []byte("contents"), 0644))
+	assert.Equal(t, "contents", gunzipFile(t, filepath.Join(tmpdir, "data.json.gz")))
+}
+
+func TestGzip_ReadFile(t *testing.T) {
+	t.Run("reads the compressed file", func(t *testing.T) {
+		g := NewGzip(NewDirectory(t.TempDir()))
+		require.NoError(t, g.WriteFile("data.json", []byte("contents"), 0644))
+
+		got, err := g.ReadFile("data.json")
+		require.NoError(t, err)
+		assert.Equal(t, "contents", string(got))
+	})
+	t.Run("missing file", func(t *testing.T) {
+		g := NewGzip(NewDirectory(t.TempDir()))
+		_, err := g.ReadFile("data.json")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+	t.Run("not a gzip file", func(t *testing.T) {
+		tmpdir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "data.json.gz"), []byte("plain"), 0644))
+		_, err := NewGzip(NewDirectory(tmpdir)).ReadFile("data.json")
+		assert.Error(t, err)
+	})
+	t.Run("filesystem can not read", func(t *testing.T) {
+		g := NewGzip(NewZIP(zip.NewWriter(io.Discard)))
+		_, err := g.ReadFile("data.json")
+		assert.ErrorIs(t, err, errors.ErrUnsupported)
+	})
+}
+
+func TestGzip_zip(t *testing.T) {
+	zipfile := filepath.Join(t.TempDir(), "test.zip")
+	zf, err := NewZipFile(zipfile)
+	require.NoError(t, err)
+	g := NewGzip(zf)
+	require.NoError(t, g.WriteFile("data.json", []byte("contents"), 0644))
+	require.NoError(t, g.Close())
+
+	zr, err := zip.OpenReader(zipfile)
+	require.NoError(t, err)
+	defer zr.Close()
+	f, err := zr.Open("data.json.gz")
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	got, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(got))
+}
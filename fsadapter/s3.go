@@ -0,0 +1,94 @@
+package fsadapter
+
+// In this file: Amazon S3 object storage.
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	s3DefaultRegion = "us-east-1"
+	// s3PartSize is the size of the part of the multipart upload.  The size
+	// of the object is unknown when the upload starts, so it is limited to
+	// 10000 parts, i.e. 160 GiB.
+	s3PartSize = 16 << 20
+)
+
+// s3Store is the Amazon S3, or S3-compatible, bucket.
+type s3Store struct {
+	tm     *transfermanager.Client
+	bucket string
+}
+
+// newS3Store returns the S3 bucket store.  The configuration and the
+// credentials are loaded by the AWS SDK, the standard way: from the
+// environment, the shared config and credentials files (AWS_PROFILE), SSO,
+// web identity, the ECS task role or the EC2 instance profile.  If the
+// custom endpoint is set, i.e. with AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL
+// for the S3-compatible storage, the bucket is addressed path-style.
+func newS3Store(ctx context.Context, bucket string) (*s3Store, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = s3DefaultRegion
+	}
+	// fail early, if there are no credentials.
+	if _, err := cfg.Credentials.Retrieve(ctx); err != nil {
+		return nil, fmt.Errorf("no AWS credentials: %w", err)
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = o.BaseEndpoint != nil
+	})
+	tm := transfermanager.New(client, func(o *transfermanager.Options) {
+		o.PartSizeBytes = s3PartSize
+	})
+	return &s3Store{tm: tm, bucket: bucket}, nil
+}
+
+func (s *s3Store) writer(ctx context.Context, key string) io.WriteCloser {
+	pr, pw := io.Pipe()
+	w := &pipeWriter{pw: pw, done: make(chan error, 1)}
+	go func() {
+		_, err := s.tm.UploadObject(ctx, &transfermanager.UploadObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+			Body:   pr,
+		})
+		if err != nil {
+			err = fmt.Errorf("s3: error uploading %q: %w", key, err)
+		}
+		// unblocks the writer, if the upload has failed.
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+	return w
+}
+
+func (s *s3Store) close() error {
+	return nil
+}
+
+// pipeWriter is the writing half of the pipe, that is read by the upload
+// running in the background.  Close waits for the upload to complete.
+type pipeWriter struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func (w *pipeWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+func (w *pipeWriter) Close() error {
+	w.pw.Close()
+	return <-w.done
+}
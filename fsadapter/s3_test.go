@@ -0,0 +1,162 @@
+package fsadapter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is the S3-compatible server, that supports PutObject and the
+// multipart upload.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte         // uploaded objects by path
+	parts   map[string]map[int][]byte // uploads in progress by upload ID
+	auth    []string                  // Authorization headers
+	nparts  int                       // number of the uploaded parts
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: make(map[string][]byte), parts: make(map[string]map[int][]byte)}
+}
+
+// object returns the uploaded object, and the last Authorization header.
+func (f *fakeS3) object(path string) ([]byte, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.objects[path], f.auth[len(f.auth)-1]
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	q := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		id := strconv.Itoa(len(f.parts) + 1)
+		f.parts[id] = make(map[int][]byte)
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		f.parts[q.Get("uploadId")][n] = data
+		f.nparts++
+		w.Header().Set("ETag", fmt.Sprintf(`"etag%d"`, n))
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		parts := f.parts[q.Get("uploadId")]
+		nums := make([]int, 0, len(parts))
+		for n := range parts {
+			nums = append(nums, n)
+		}
+		sort.Ints(nums)
+		var obj []byte
+		for _, n := range nums {
+			obj = append(obj, parts[n]...)
+		}
+		f.objects[r.URL.EscapedPath()] = obj
+		delete(f.parts, q.Get("uploadId"))
+		fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"etag"</ETag></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodPut:
+		f.objects[r.URL.EscapedPath()] = data
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// setAWSEnv sets the AWS environment for the tests, so that the credentials
+// and the configuration of the user are not used.
+func setAWSEnv(t *testing.T, endpoint string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_ENDPOINT_URL", endpoint)
+}
+
+func Test_s3Store_writer(t *testing.T) {
+	f := newFakeS3()
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+	setAWSEnv(t, srv.URL)
+
+	s, err := newS3Store(context.Background(), "bucket")
+	require.NoError(t, err)
+	defer s.close()
+
+	t.Run("small object", func(t *testing.T) {
+		w := s.writer(context.Background(), "prefix/C123 test.json")
+		_, err := io.WriteString(w, "data")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		obj, auth := f.object("/bucket/prefix/C123%20test.json")
+		assert.Equal(t, []byte("data"), obj)
+		assert.Contains(t, auth, "Credential=AKIAEXAMPLE/")
+		assert.Contains(t, auth, "/eu-west-1/s3/aws4_request")
+	})
+	t.Run("large object is uploaded in parts", func(t *testing.T) {
+		data := bytes.Repeat([]byte("0123456789abcdef"), s3PartSize/16+1)
+		w := s.writer(context.Background(), "export.zip")
+		// written in small pieces, as the ZIP writer does.
+		for p := data; len(p) > 0; p = p[min(len(p), 32<<10):] {
+			_, err := w.Write(p[:min(len(p), 32<<10)])
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		obj, _ := f.object("/bucket/export.zip")
+		assert.Equal(t, data, obj)
+		f.mu.Lock()
+		assert.Equal(t, 2, f.nparts)
+		f.mu.Unlock()
+	})
+}
+
+func Test_s3Store_writer_error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>")
+	}))
+	defer srv.Close()
+	setAWSEnv(t, srv.URL)
+
+	s, err := newS3Store(context.Background(), "bucket")
+	require.NoError(t, err)
+	w := s.writer(context.Background(), "users.json")
+	_, err = io.WriteString(w, "[]")
+	require.NoError(t, err)
+	err = w.Close()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AccessDenied")
+}
+
+func Test_newS3Store_noCredentials(t *testing.T) {
+	setAWSEnv(t, "http://localhost")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	_, err := newS3Store(context.Background(), "bucket")
+	assert.Error(t, err)
+}
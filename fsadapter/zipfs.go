@@ -21,12 +21,13 @@ var _ FS = &ZIP{}
 type ZIP struct {
 	zw   *zip.Writer
 	mu   sync.Mutex
-	f    *os.File
+	f    io.WriteCloser  // the archive file, if owned by ZIP
+	name string          // name of the archive file
 	seen map[string]bool // seen holds the list of seen directories.
 }
 
 func (z *ZIP) String() string {
-	return fmt.Sprintf("<zip archive: %s>", z.f.Name())
+	return fmt.Sprintf("<zip archive: %s>", z.name)
 }
 
 // NewZIP returns a new ZIP filesystem adapter for a given ZipWriter.
@@ -40,8 +41,13 @@ func NewZipFile(filename string) (*ZIP, error) {
 	if err != nil {
 		return nil, err
 	}
-	zw := zip.NewWriter(f)
-	return &ZIP{zw: zw, f: f, seen: make(map[string]bool)}, nil
+	return newZipWriter(f, filename), nil
+}
+
+// newZipWriter returns a new ZIP filesystem adapter, that writes the archive
+// to w, named name.  Close closes w.
+func newZipWriter(w io.WriteCloser, name string) *ZIP {
+	return &ZIP{zw: zip.NewWriter(w), f: w, name: name, seen: make(map[string]bool)}
 }
 
 // normalizePath reassembles the path in correct format for ZIP file.
@@ -59,6 +65,7 @@ func (z *ZIP) Create(filename string) (io.WriteCloser, error) {
 	z.mu.Lock() // mutex will be unlocked, when the user calls Close.
 	w, err := z.create(filename)
 	if err != nil {
+		z.mu.Unlock()
 		return nil, err
 	}
 	return &syncWriter{w: w, mu: &z.mu}, nil
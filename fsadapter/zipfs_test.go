@@ -13,6 +13,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -46,6 +47,26 @@ func TestZIP_Create(t *testing.T) {
 	})
 }
 
+func TestZIP_Create_error(t *testing.T) {
+	z := NewZIP(zip.NewWriter(io.Discard))
+	// the name is too long for the ZIP format.
+	_, err := z.Create(strings.Repeat("a", 1<<16))
+	require.Error(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w, err := z.Create("ok.txt")
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Create after the failed Create did not return, the lock is held")
+	}
+}
+
 func testsuiteZipFile(t *testing.T, zipFile, filename, content string) {
 	hArc, err := os.Create(zipFile)
 	require.NoError(t, err)
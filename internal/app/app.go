@@ -5,12 +5,17 @@ import (
 	"runtime/trace"
 	"time"
 
+	"github.com/rusq/slackdump/v2"
 	"github.com/rusq/slackdump/v2/auth"
 	"github.com/rusq/slackdump/v2/internal/app/config"
 	"github.com/rusq/slackdump/v2/internal/app/emoji"
+	"github.com/rusq/slackdump/v2/internal/network"
 )
 
-// Run starts the Slackdump.
+// Run starts the Slackdump.  If the summary file is set in cfg, the
+// RunSummary is written to it at the end of the run, even if the run failed.
+// If the notification webhook is set, the RunSummary is POSTed to it, the
+// notification failure does not fail the run.
 func Run(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 	if err := cfg.Validate(); err != nil {
 		return err
@@ -19,14 +24,23 @@ func Run(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 	defer task.End()
 
 	start := time.Now()
+	rs := RunSummary{Started: start}
 
-	var err error
-	if cfg.ExportName != "" {
-		err = Export(ctx, cfg, prov)
-	} else if cfg.Emoji.Enabled {
-		err = emoji.Download(ctx, cfg, prov)
-	} else {
-		err = Dump(ctx, cfg, prov)
+	network.ResetStats()
+	err := run(ctx, cfg, prov, &rs)
+	network.LogStats(ctx)
+	rs.addLimiterStats(network.Stats())
+	rs.finish(time.Since(start), err)
+	if cfg.SummaryFile != "" {
+		if serr := rs.Save(cfg.SummaryFile); serr != nil {
+			cfg.Logger().Printf("error writing the run summary to %q: %s", cfg.SummaryFile, serr)
+			if err == nil {
+				err = serr
+			}
+		}
+	}
+	if cfg.NotifyWebhook != "" {
+		notify(cfg, &rs)
 	}
 	if err != nil {
 		return err
@@ -35,3 +49,36 @@ func Run(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 	cfg.Logger().Printf("completed, time taken: %s", time.Since(start))
 	return nil
 }
+
+// run runs the mode requested in cfg, and populates the summary rs.
+func run(ctx context.Context, cfg config.Params, prov auth.Provider, rs *RunSummary) error {
+	switch {
+	case cfg.DryRun && !cfg.Emoji.Enabled && !cfg.ListFlags.FlagsPresent():
+		rs.Mode = modeDryRun
+		return DryRun(ctx, cfg, prov)
+	case cfg.ExportName != "":
+		return Export(ctx, cfg, prov, rs)
+	case cfg.Emoji.Enabled:
+		rs.Mode = modeEmoji
+		st, err := emoji.Download(ctx, cfg, prov)
+		rs.addEmojiStats(st)
+		return err
+	default:
+		return Dump(ctx, cfg, prov, rs)
+	}
+}
+
+// notify sends the summary rs to the notification webhook, the errors are
+// logged.
+func notify(cfg config.Params, rs *RunSummary) {
+	tr, err := slackdump.HTTPTransport(cfg.Options)
+	if err != nil {
+		cfg.Logger().Printf("WARNING: unable to send the notification: %s", err)
+		return
+	}
+	// the run context may already be cancelled, i.e. if the run was
+	// interrupted, the notification should be sent regardless.
+	if err := rs.Notify(context.Background(), cfg.NotifyWebhook, tr); err != nil {
+		cfg.Logger().Printf("WARNING: unable to send the notification: %s", err)
+	}
+}
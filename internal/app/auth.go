@@ -6,13 +6,12 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"runtime"
 	"runtime/trace"
 
 	"github.com/rusq/slackdump/v2"
 	"github.com/rusq/slackdump/v2/auth"
-	"github.com/rusq/slackdump/v2/auth/browser"
+	"github.com/rusq/slackdump/v2/internal/cookiestore"
 	"github.com/rusq/slackdump/v2/internal/encio"
 )
 
@@ -35,6 +34,12 @@ type SlackCreds struct {
 var (
 	ErrNotTested   = errors.New("warning, EZ-Login 3000 is not tested on this OS, if it doesn't work, use manual login method")
 	ErrUnsupported = errors.New("EZ-Login 3000 is not supported on this OS, please use the manual login method")
+	// ErrAppToken is returned, if the app-level token is provided, as it can't
+	// be used to call the methods that slackdump needs.
+	ErrAppToken = errors.New("app-level tokens (xapp-) can't be used to read the conversations, use the user (xoxp-), bot (xoxb-) or client (xoxc-) token")
+	// ErrCredsDecrypt is returned by InitProvider, if the stored credentials
+	// exist, but can't be decrypted, i.e. if the passphrase is wrong.
+	ErrCredsDecrypt = errors.New("failed to decrypt the stored credentials, check the passphrase, or run with -auth-reset to log in again")
 )
 
 // Type returns the authentication type that should be used for the current
@@ -44,6 +49,9 @@ var (
 // authentication determined is not supported for the current system, it will
 // return ErrUnsupported.
 func (c SlackCreds) Type(ctx context.Context) (auth.Type, error) {
+	if auth.KindOf(c.Token) == auth.TokenApp {
+		return auth.TypeInvalid, ErrAppToken
+	}
 	if !c.IsEmpty() {
 		if isExistingFile(c.Cookie) {
 			return auth.TypeCookieFile, nil
@@ -66,15 +74,16 @@ func (c SlackCreds) IsEmpty() bool {
 }
 
 // AuthProvider returns the appropriate auth Provider depending on the values
-// of the token and cookie.
-func (c SlackCreds) AuthProvider(ctx context.Context, workspace string, browser browser.Browser) (auth.Provider, error) {
+// of the token and cookie.  The browser options opts are used, if the
+// browser authentication is selected.
+func (c SlackCreds) AuthProvider(ctx context.Context, workspace string, opts ...auth.Option) (auth.Provider, error) {
 	authType, err := c.Type(ctx)
 	if err != nil {
 		return nil, err
 	}
 	switch authType {
 	case auth.TypeBrowser:
-		return auth.NewBrowserAuth(ctx, auth.BrowserWithWorkspace(workspace), auth.BrowserWithBrowser(browser))
+		return auth.NewBrowserAuth(ctx, append([]auth.Option{auth.BrowserWithWorkspace(workspace)}, opts...)...)
 	case auth.TypeCookieFile:
 		return auth.NewCookieFileAuth(c.Token, c.Cookie)
 	case auth.TypeValue:
@@ -83,6 +92,21 @@ func (c SlackCreds) AuthProvider(ctx context.Context, workspace string, browser
 	return nil, errors.New("internal error: unsupported auth type")
 }
 
+// CookieFromBrowser returns the value of the Slack "d" cookie from the cookie
+// store of the browser.  The spec is the browser name, optionally followed
+// by the profile directory, i.e. "chrome" or "firefox:/path/to/profile".
+func CookieFromBrowser(spec string) (string, error) {
+	store, err := cookiestore.Parse(spec)
+	if err != nil {
+		return "", err
+	}
+	c, err := store.Cookie(".slack.com", "d")
+	if err != nil {
+		return "", err
+	}
+	return c.Value, nil
+}
+
 func isExistingFile(name string) bool {
 	fi, err := os.Stat(name)
 	return err == nil && !fi.IsDir()
@@ -106,7 +130,7 @@ var filer createOpener = encryptedFile{}
 
 type Credentials interface {
 	IsEmpty() bool
-	AuthProvider(ctx context.Context, workspace string, browser browser.Browser) (auth.Provider, error)
+	AuthProvider(ctx context.Context, workspace string, opts ...auth.Option) (auth.Provider, error)
 }
 
 // InitProvider initialises the auth.Provider depending on provided slack
@@ -124,7 +148,14 @@ type Credentials interface {
 // virtual), even another operating system on the same machine, unless it's a
 // clone of the source operating system on which the credentials storage was
 // created.
-func InitProvider(ctx context.Context, cacheDir string, workspace string, creds Credentials, browser browser.Browser) (auth.Provider, error) {
+//
+// The credentials are stored per workspace profile, see Profiles.  If the
+// workspace is empty, the default profile is used, or the only named one.
+//
+// If the passphrase is set with SetPassphrase, the key is derived
+// from it instead.  If the stored credentials can't be decrypted, it returns
+// ErrCredsDecrypt.
+func InitProvider(ctx context.Context, cacheDir string, workspace string, creds Credentials, opts ...auth.Option) (auth.Provider, error) {
 	ctx, task := trace.NewTask(ctx, "InitProvider")
 	defer task.End()
 
@@ -132,11 +163,17 @@ func InitProvider(ctx context.Context, cacheDir string, workspace string, creds
 		return nil, fmt.Errorf("failed to create cache directory:  %w", err)
 	}
 
-	credsLoc := filepath.Join(cacheDir, credsFile)
+	credsLoc, workspace, err := profileCreds(cacheDir, workspace)
+	if err != nil {
+		return nil, err
+	}
 
 	// try to load the existing credentials, if saved earlier.
 	if creds.IsEmpty() {
 		if prov, err := tryLoad(ctx, credsLoc); err != nil {
+			if errors.Is(err, ErrCredsDecrypt) {
+				return nil, err
+			}
 			trace.Logf(ctx, "warn", "no saved credentials: %s", err)
 		} else {
 			trace.Log(ctx, "info", "loaded saved credentials")
@@ -146,7 +183,7 @@ func InitProvider(ctx context.Context, cacheDir string, workspace string, creds
 
 	// init the authentication provider
 	trace.Log(ctx, "info", "getting credentals from file or browser")
-	provider, err := creds.AuthProvider(ctx, workspace, browser)
+	provider, err := creds.AuthProvider(ctx, workspace, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialise the auth provider: %w", err)
 	}
@@ -158,12 +195,39 @@ func InitProvider(ctx context.Context, cacheDir string, workspace string, creds
 	return provider, nil
 }
 
+// profileCreds returns the credentials file of the workspace profile, and the
+// workspace name, that should be used to log in.  If the workspace is empty,
+// the profile is resolved with resolveProfile.
+func profileCreds(cacheDir string, workspace string) (string, string, error) {
+	if workspace == "" {
+		var err error
+		if workspace, err = resolveProfile(cacheDir); err != nil {
+			return "", "", err
+		}
+	}
+	filename, err := credsFilename(cacheDir, workspace)
+	if err != nil {
+		return "", "", err
+	}
+	if workspace == DefaultProfile {
+		// the workspace will be requested on login.
+		workspace = ""
+	}
+	return filename, workspace, nil
+}
+
 var authTester = slackdump.TestAuth
 
 func tryLoad(ctx context.Context, filename string) (auth.Provider, error) {
 	prov, err := loadCreds(filer, filename)
 	if err != nil {
-		return nil, err
+		if !isExistingFile(filename) {
+			return nil, err
+		}
+		// the file is there, but it's not readable with the current key.
+		if prov, err = migrateCreds(ctx, filename); err != nil {
+			return nil, ErrCredsDecrypt
+		}
 	}
 	// test the loaded credentials
 	if err := authTester(ctx, prov); err != nil {
@@ -194,9 +258,53 @@ func saveCreds(opener createOpener, filename string, p auth.Provider) error {
 	return auth.Save(f, p)
 }
 
-// AuthReset removes the cached credentials.
-func AuthReset(cacheDir string) error {
-	return os.Remove(filepath.Join(cacheDir, credsFile))
+// legacyFilers are the formats of the credentials storage, that the stored
+// credentials are migrated from.
+var legacyFilers = []createOpener{encryptedFile{}, plainFile{}}
+
+// migrateCreds loads the credentials stored in one of the legacy formats,
+// i.e. encrypted with the machine ID key before the passphrase was set, or in
+// plain text, and saves them in the current format.
+func migrateCreds(ctx context.Context, filename string) (auth.Provider, error) {
+	for _, legacy := range legacyFilers {
+		if legacy == filer {
+			continue
+		}
+		prov, err := loadCreds(legacy, filename)
+		if err != nil {
+			continue
+		}
+		if err := saveCreds(filer, filename, prov); err != nil {
+			trace.Logf(ctx, "error", "failed to re-encrypt credentials in %s: %s", filename, err)
+		} else {
+			trace.Logf(ctx, "info", "credentials in %s are re-encrypted", filename)
+		}
+		return prov, nil
+	}
+	return nil, ErrCredsDecrypt
+}
+
+// SetPassphrase sets the passphrase, that the stored credentials are
+// encrypted with, instead of the machine ID.  The credentials stored
+// earlier are re-encrypted with the passphrase on the first use.
+func SetPassphrase(passphrase string) error {
+	key, err := encio.PassphraseKey(passphrase)
+	if err != nil {
+		return err
+	}
+	filer = passphraseFile{key: string(key)}
+	return nil
+}
+
+// AuthReset removes the cached credentials of the workspace profile.  If the
+// workspace is empty, the profile is resolved the same way as in
+// InitProvider.
+func AuthReset(cacheDir string, workspace string) error {
+	filename, _, err := profileCreds(cacheDir, workspace)
+	if err != nil {
+		return err
+	}
+	return os.Remove(filename)
 }
 
 // createOpener is the interface to be able to switch between encrypted file
@@ -215,3 +323,29 @@ func (encryptedFile) Open(filename string) (io.ReadCloser, error) {
 func (encryptedFile) Create(filename string) (io.WriteCloser, error) {
 	return encio.Create(filename)
 }
+
+// passphraseFile is the file encrypted with the passphrase key.  The key is
+// stored as a string to keep the type comparable.
+type passphraseFile struct {
+	key string
+}
+
+func (pf passphraseFile) Open(filename string) (io.ReadCloser, error) {
+	return encio.OpenWithKey(filename, []byte(pf.key))
+}
+
+func (pf passphraseFile) Create(filename string) (io.WriteCloser, error) {
+	return encio.CreateWithKey(filename, []byte(pf.key))
+}
+
+// plainFile is the unencrypted file, it is used to migrate the legacy plain
+// text credentials, and to save the unencrypted redaction map.
+type plainFile struct{}
+
+func (plainFile) Open(filename string) (io.ReadCloser, error) {
+	return os.Open(filename)
+}
+
+func (plainFile) Create(filename string) (io.WriteCloser, error) {
+	return os.Create(filename)
+}
@@ -66,6 +66,7 @@ func TestSlackCreds_Type(t *testing.T) {
 	tests := []test{
 		{"value", fields{Token: "t", Cookie: "c"}, args{context.Background()}, auth.TypeValue, false},
 		{"cookie file", fields{Token: "t", Cookie: testFile}, args{context.Background()}, auth.TypeCookieFile, false},
+		{"app token", fields{Token: "xapp-1-A1-123"}, args{context.Background()}, auth.TypeInvalid, true},
 	}
 	if !isWSL {
 		tests = append(tests, test{"browser", fields{Token: "", Cookie: ""}, args{context.Background()}, auth.TypeBrowser, false})
@@ -149,7 +150,7 @@ func TestInitProvider(t *testing.T) {
 			func(m *mock_app.MockCredentials) {
 				m.EXPECT().IsEmpty().Return(false)
 				m.EXPECT().
-					AuthProvider(gomock.Any(), "wsp", browser.Bfirefox).
+					AuthProvider(gomock.Any(), "wsp", gomock.Any()).
 					Return(storedProv, nil)
 			},
 			nil, //not used in the test
@@ -171,7 +172,7 @@ func TestInitProvider(t *testing.T) {
 			args{context.Background(), testDir, "wsp"},
 			func(m *mock_app.MockCredentials) {
 				m.EXPECT().IsEmpty().Return(true)
-				m.EXPECT().AuthProvider(gomock.Any(), "wsp", browser.Bfirefox).Return(returnedProv, nil)
+				m.EXPECT().AuthProvider(gomock.Any(), "wsp", gomock.Any()).Return(returnedProv, nil)
 			},
 			errors.New("auth test fail"), // auth test fails
 			returnedProv,
@@ -182,7 +183,7 @@ func TestInitProvider(t *testing.T) {
 			args{context.Background(), testDir, "wsp"},
 			func(m *mock_app.MockCredentials) {
 				m.EXPECT().IsEmpty().Return(false)
-				m.EXPECT().AuthProvider(gomock.Any(), "wsp", browser.Bfirefox).Return(nil, errors.New("authProvider failed"))
+				m.EXPECT().AuthProvider(gomock.Any(), "wsp", gomock.Any()).Return(nil, errors.New("authProvider failed"))
 			},
 			nil,
 			nil,
@@ -193,7 +194,7 @@ func TestInitProvider(t *testing.T) {
 			args{context.Background(), testDir, "wsp"},
 			func(m *mock_app.MockCredentials) {
 				m.EXPECT().IsEmpty().Return(false)
-				m.EXPECT().AuthProvider(gomock.Any(), "wsp", browser.Bfirefox).Return(returnedProv, nil)
+				m.EXPECT().AuthProvider(gomock.Any(), "wsp", gomock.Any()).Return(returnedProv, nil)
 			},
 			nil,
 			returnedProv,
@@ -204,7 +205,7 @@ func TestInitProvider(t *testing.T) {
 			args{context.Background(), t.TempDir() + "$", "wsp"},
 			func(m *mock_app.MockCredentials) {
 				m.EXPECT().IsEmpty().Return(false)
-				m.EXPECT().AuthProvider(gomock.Any(), "wsp", browser.Bfirefox).Return(returnedProv, nil)
+				m.EXPECT().AuthProvider(gomock.Any(), "wsp", gomock.Any()).Return(returnedProv, nil)
 			},
 			nil,
 			returnedProv,
@@ -230,7 +231,7 @@ func TestInitProvider(t *testing.T) {
 			tt.expect(mc)
 
 			// test
-			got, err := InitProvider(tt.args.ctx, tt.args.cacheDir, tt.args.workspace, mc, browser.Bfirefox)
+			got, err := InitProvider(tt.args.ctx, tt.args.cacheDir, tt.args.workspace, mc, auth.BrowserWithBrowser(browser.Bfirefox))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("InitProvider() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -305,6 +306,56 @@ func Test_tryLoad(t *testing.T) {
 	}
 }
 
+func Test_tryLoad_passphrase(t *testing.T) {
+	oldFiler, oldTester := filer, authTester
+	defer func() {
+		filer, authTester = oldFiler, oldTester
+	}()
+	authTester = fakeAuthTester(nil)
+
+	testProvider, _ := auth.NewValueAuth("xoxc", "xoxd")
+	credsFile := filepath.Join(t.TempDir(), credsFile)
+
+	// stored with the machine ID key
+	if err := saveCreds(encryptedFile{}, credsFile, testProvider); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPassphrase("secret"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := tryLoad(context.Background(), credsFile)
+	if err != nil {
+		t.Fatalf("tryLoad() unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(got, testProvider) {
+		t.Errorf("tryLoad() = %v, want %v", got, testProvider)
+	}
+	// must be re-encrypted with the passphrase
+	if _, err := loadCreds(filer, credsFile); err != nil {
+		t.Errorf("credentials are not re-encrypted: %s", err)
+	}
+
+	// wrong passphrase
+	if err := SetPassphrase("wrong"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tryLoad(context.Background(), credsFile); !errors.Is(err, ErrCredsDecrypt) {
+		t.Errorf("tryLoad() error = %v, want %v", err, ErrCredsDecrypt)
+	}
+
+	// legacy plain text
+	filer = encryptedFile{}
+	if err := saveCreds(plainFile{}, credsFile, testProvider); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tryLoad(context.Background(), credsFile); err != nil {
+		t.Errorf("tryLoad() unexpected error on the plain text credentials: %s", err)
+	}
+	if _, err := loadCreds(encryptedFile{}, credsFile); err != nil {
+		t.Errorf("credentials are not encrypted: %s", err)
+	}
+}
+
 func Test_loadCreds(t *testing.T) {
 	testProv, _ := auth.NewValueAuth("xoxc", "xoxd")
 	var buf bytes.Buffer
@@ -459,7 +510,7 @@ func TestAuthReset(t *testing.T) {
 		if err := os.WriteFile(testFile, []byte("unit"), 0644); err != nil {
 			t.Fatal(err)
 		}
-		if err := AuthReset(tmpDir); err != nil {
+		if err := AuthReset(tmpDir, ""); err != nil {
 			t.Errorf("AuthReset unexpected error: %s", err)
 		}
 		if fi, err := os.Stat(testFile); !os.IsNotExist(err) || fi != nil {
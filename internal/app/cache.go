@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 
 	"github.com/rusq/dlog"
+
+	"github.com/rusq/slackdump/v2"
 )
 
 const (
@@ -24,3 +26,33 @@ func ucd(ucdFn func() (string, error)) string {
 func CacheDir() string {
 	return ucd(os.UserCacheDir)
 }
+
+// CacheClear removes the stored EZ-Login credentials of all workspace
+// profiles, and the user and channel caches from the cache directory of opts,
+// and returns the list of removed files.  The cache is cleared before logging
+// in, so the user and channel caches of all workspaces are removed.  Other
+// files, i.e. the exported data, are not touched.
+func CacheClear(opts slackdump.Options) ([]string, error) {
+	files, err := slackdump.CacheFiles(opts)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := filepath.Glob(filepath.Join(opts.CacheDir, credsPrefix+"*"+credsExt))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, profiles...)
+	files = append(files, filepath.Join(opts.CacheDir, credsFile))
+
+	var removed []string
+	for _, f := range files {
+		if err := os.Remove(f); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return removed, err
+		}
+		removed = append(removed, f)
+	}
+	return removed, nil
+}
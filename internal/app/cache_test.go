@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/rusq/slackdump/v2"
 )
 
 func TestCacheDir(t *testing.T) {
@@ -58,3 +60,40 @@ func Test_ucd(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheClear(t *testing.T) {
+	dir := t.TempDir()
+	opts := slackdump.DefOptions
+	opts.CacheDir = dir
+
+	keep := []string{"users.json", "export.zip", "channels-T1.json"}
+	remove := []string{credsFile, "provider-acme.bin", "users-T1.cache", "users-T2.cache", "channels-T1.cache"}
+	for _, f := range append(keep, remove...) {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := CacheClear(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != len(remove) {
+		t.Errorf("removed %v, want %v", removed, remove)
+	}
+	for _, f := range remove {
+		if _, err := os.Stat(filepath.Join(dir, f)); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", f)
+		}
+	}
+	for _, f := range keep {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("%s should not be removed: %s", f, err)
+		}
+	}
+
+	removed, err = CacheClear(opts)
+	if err != nil || len(removed) != 0 {
+		t.Errorf("second run: removed %v, err %v, want nothing", removed, err)
+	}
+}
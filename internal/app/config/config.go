@@ -4,12 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"net/url"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/slack-go/slack"
 
 	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/downloader"
 	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/internal/structures"
 	"github.com/rusq/slackdump/v2/logger"
 	"github.com/rusq/slackdump/v2/types"
@@ -18,6 +24,7 @@ import (
 const (
 	OutputTypeJSON = "json"
 	OutputTypeText = "text"
+	OutputTypeCSV  = "csv" // only for the users and channels lists
 )
 
 const (
@@ -37,11 +44,38 @@ type Params struct {
 	Oldest TimeValue // oldest time to dump conversations from
 	Latest TimeValue // latest time to dump conversations to
 
+	RenderTZ TZValue // time zone for rendering the timestamps in human-readable outputs
+
+	StrictDownload bool // fail if any of the files failed to download
+	DryRun         bool // resolve the conversations and exit without fetching any messages
+
+	SummaryFile   string // file to write the run summary to, empty means no summary
+	NotifyWebhook string // URL to POST the run summary to, empty means no notification
+
 	FilenameTemplate string
 
-	ExportName  string            // export file or directory name.
-	ExportType  export.ExportType // export type, see enum for available options.
-	ExportToken string            // token that will be added to all exported files.
+	ExportName    string            // export file or directory name.
+	ExportType    export.ExportType // export type, see enum for available options.
+	ExportToken   string            // token that will be added to all exported files.
+	StripFileURLs bool              // replace the file URLs with the local paths or remove them.
+	StateFile     string            // incremental export state file, empty means full export.
+
+	Gzip bool // compress the output files and the downloaded files with gzip
+
+	ResolveMentions  bool // resolve user mentions and channel references in the exported messages
+	ExportPins       bool // add the pinned items to the exported channels
+	IncludeCanvases  bool // add the canvases and lists to the exported channels
+	EditMarkers      bool // keep the edit and deletion markers of the exported messages
+	ExportAvatars    bool // download the custom profile images of the users
+	UserCustomFields bool // fetch the custom profile fields of the users in the export or the user list
+	IncludeDMs       bool // export the direct messages
+	IncludeGroupDMs  bool // export the group direct messages
+
+	ContinueOnError bool // skip the conversations that failed, and continue with the others
+
+	Redact           bool   // redact the personal information of the users in the export
+	RedactMap        string // file to save the redaction map to, empty means no map
+	RedactMapEncrypt bool   // encrypt the redaction map
 
 	Emoji EmojiParams
 
@@ -55,8 +89,20 @@ type EmojiParams struct {
 
 type Output struct {
 	Filename string
-	Format   string // output format
-	Base     string // base directory or zip file
+	Format   string     // output format
+	Columns  []string   // columns of the text output of the lists, empty means all
+	Base     string     // base directory or zip file
+	Split    SplitValue // period to split the dumped conversations by, empty means no split
+
+	ThreadFiles bool // write the threads to separate files, instead of nesting the replies
+}
+
+// FilenameData is the data, that the file naming template is rendered with.
+type FilenameData struct {
+	types.Conversation
+	// Date is the date of the messages in the file, if the output is split,
+	// see SplitValue.
+	Date string
 }
 
 type Input struct {
@@ -88,11 +134,35 @@ func (in *Input) listProducer(fn func(string) error) error {
 	return nil
 }
 
+// TimeRange returns the time range of the messages to fetch.  If the input
+// list has the date filters set, it returns the range that spans all of
+// them, see TimeRanges.
+func (p *Params) TimeRange() (oldest, latest time.Time) {
+	span := structures.SpanDateFilters(p.TimeRanges())
+	return span.Start, span.End
+}
+
+// TimeRanges returns the disjoint time ranges of the messages to fetch.  If
+// the input list has the date filters set, they take precedence over Oldest
+// and Latest, even if only one side of the range is set.
+func (p *Params) TimeRanges() []structures.DateFilter {
+	if p.Input.List != nil && len(p.Input.List.DateFilters) > 0 {
+		return p.Input.List.DateFilters
+	}
+	return []structures.DateFilter{{Start: time.Time(p.Oldest), End: time.Time(p.Latest)}}
+}
+
 func (out Output) FormatValid() bool {
 	return out.Format != "" && (out.Format == OutputTypeJSON ||
 		out.Format == OutputTypeText)
 }
 
+// ListFormatValid returns true if the format is valid for the users and
+// channels lists, which, in addition to the dump formats, can be output in CSV.
+func (out Output) ListFormatValid() bool {
+	return out.FormatValid() || out.Format == OutputTypeCSV
+}
+
 func (out Output) IsText() bool {
 	return out.Format == OutputTypeText
 }
@@ -100,16 +170,139 @@ func (out Output) IsText() bool {
 type ListFlags struct {
 	Users    bool
 	Channels bool
+
+	GroupByType  bool // group channels by type in the channel list
+	UserPresence bool // fetch the presence of the users in the user list
+
+	// ChannelTypes is the list of channel types to fetch when listing the
+	// channels, or exporting all channels, see slackdump.AllChanTypes.  Empty
+	// means all types.
+	ChannelTypes []string
+
+	// ArchivedOnly and ActiveOnly limit the listed or exported channels to
+	// the archived or to the active ones.  They are mutually exclusive.
+	ArchivedOnly bool
+	ActiveOnly   bool
+}
+
+// ArchivedFilter returns the archived status filter of the channels.
+func (lf ListFlags) ArchivedFilter() types.ArchivedFilter {
+	switch {
+	case lf.ArchivedOnly:
+		return types.ArchivedOnly
+	case lf.ActiveOnly:
+		return types.ActiveOnly
+	default:
+		return types.ArchivedAny
+	}
+}
+
+var errInvalidChanType = errors.New("invalid channel type")
+
+// validateChanTypes checks that all channel types are supported by the API.
+func validateChanTypes(chanTypes []string) error {
+	for _, ct := range chanTypes {
+		var ok bool
+		for _, known := range slackdump.AllChanTypes {
+			if ct == known {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%w: %q, must be one of %v", errInvalidChanType, ct, slackdump.AllChanTypes)
+		}
+	}
+	return nil
 }
 
 func (lf ListFlags) FlagsPresent() bool {
 	return lf.Users || lf.Channels
 }
 
-var ErrNothingToDo = errors.New("no valid input and no list flags specified")
+var (
+	ErrNothingToDo = errors.New("no valid input and no list flags specified")
+	// ErrExcludeOnly is returned, if the conversation list has only the
+	// exclusions, which makes sense only for the export.
+	ErrExcludeOnly = errors.New("only excluded conversations are specified, nothing to dump (exclusions without inclusions are supported in export mode)")
+)
 
 // Validate checks if the command line parameters have valid values.
 func (p *Params) Validate() error {
+	if err := validateChanTypes(p.ListFlags.ChannelTypes); err != nil {
+		return err
+	}
+	if p.ListFlags.ArchivedOnly && p.ListFlags.ActiveOnly {
+		return errors.New("archived only and active only channels can't be requested at the same time")
+	}
+	if p.ListFlags.ArchivedOnly && p.Options.ExcludeArchived {
+		return errors.New("archived only channels can't be requested, when the archived channels are excluded")
+	}
+	if p.Options.MessageLimit < 0 {
+		return errors.New("message limit can't be negative")
+	}
+	if p.Options.ReactionFilterThreads && len(p.Options.ReactionFilter) == 0 {
+		return errors.New("reaction filter threads option requires the reaction filter")
+	}
+	if p.Options.MessageFilter != "" {
+		if _, err := regexp.Compile(p.Options.MessageFilter); err != nil {
+			return fmt.Errorf("invalid message filter %q: %w", p.Options.MessageFilter, err)
+		}
+	}
+
+	if p.Redact || p.RedactMap != "" {
+		if p.ExportName == "" {
+			return errors.New("redaction can only be used in export mode")
+		}
+		if !p.Redact {
+			return errors.New("redaction map requires the redaction to be enabled")
+		}
+	}
+	if p.RedactMapEncrypt && p.RedactMap == "" {
+		return errors.New("redaction map encryption requires the redaction map file")
+	}
+
+	if p.StripFileURLs {
+		if p.ExportName == "" {
+			return errors.New("file URLs can only be stripped in export mode")
+		}
+		if p.ExportToken != "" {
+			return errors.New("file URLs can't be stripped, when the export token is set")
+		}
+	}
+
+	if p.NotifyWebhook != "" {
+		// the URL is not included in the error, as webhook URLs usually
+		// contain the secret token.
+		if u, err := url.Parse(p.NotifyWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("invalid notification webhook URL, must be an http or https URL")
+		}
+	}
+
+	if p.Output.Split != SplitNone && p.ExportName != "" {
+		return errors.New("split can't be used in export mode, the export is always split by day")
+	}
+	if p.Output.ThreadFiles && p.ExportName != "" {
+		return errors.New("thread files can't be used in export mode")
+	}
+	if p.Output.ThreadFiles && p.Options.SkipThreads {
+		return errors.New("thread files can't be used when the threads are skipped")
+	}
+
+	if p.StateFile != "" {
+		if p.ExportName == "" {
+			return errors.New("state file can only be used in export mode")
+		}
+		if p.ExportType == export.THTML || p.ExportType == export.TMarkdown {
+			return fmt.Errorf("incremental export is not supported for the %s export type", p.ExportType)
+		}
+		// the new messages are appended to the files of the previous run,
+		// the ZIP archive and the bucket objects would have been overwritten.
+		if fsadapter.IsBucketURL(p.ExportName) || strings.EqualFold(filepath.Ext(p.ExportName), ".zip") {
+			return errors.New("incremental export requires the export directory, it can't be used with the ZIP archive or the bucket")
+		}
+	}
+
 	if p.ExportName != "" {
 		// slack workspace export mode.
 		return nil
@@ -126,36 +319,90 @@ func (p *Params) Validate() error {
 	if !p.Input.IsValid() && !p.ListFlags.FlagsPresent() {
 		return ErrNothingToDo
 	}
+	if !p.ListFlags.FlagsPresent() && !p.Input.List.HasIncludes() {
+		return ErrExcludeOnly
+	}
+	if p.ListFlags.UserPresence && !p.ListFlags.Users {
+		return errors.New("user presence can only be fetched when listing users")
+	}
+	if p.UserCustomFields && !p.ListFlags.Users {
+		return errors.New("custom profile fields can only be fetched when listing or exporting users")
+	}
 
-	// channels and users listings will be in the text format (if not specified otherwise)
+	// channels and users listings, and the dry run will be in the text format
+	// (if not specified otherwise)
 	if p.Output.Format == "" {
-		if p.ListFlags.FlagsPresent() {
+		if p.ListFlags.FlagsPresent() || p.DryRun {
 			p.Output.Format = OutputTypeText
 		} else {
 			p.Output.Format = OutputTypeJSON
 		}
 	}
 
-	if !p.ListFlags.FlagsPresent() && !p.Output.FormatValid() {
+	if p.ListFlags.FlagsPresent() {
+		if !p.Output.ListFormatValid() {
+			return fmt.Errorf("invalid output type: %q, must use one of %v", p.Output.Format, []string{OutputTypeJSON, OutputTypeText, OutputTypeCSV})
+		}
+		if err := p.validateColumns(); err != nil {
+			return err
+		}
+		if p.ListFlags.UserPresence && p.Output.IsText() && len(p.Output.Columns) == 0 {
+			// show the fetched presence in the default columns.
+			p.Output.Columns = append(append([]string{}, types.UserColumns...), "presence")
+		}
+	} else if !p.Output.FormatValid() {
 		return fmt.Errorf("invalid output type: %q, must use one of %v", p.Output.Format, []string{OutputTypeJSON, OutputTypeText})
 	}
 
 	// validate file naming template
+	if p.Output.Split != SplitNone && !strings.Contains(p.FilenameTemplate, ".Date") {
+		// each date must go to a separate file.
+		p.FilenameTemplate += "-{{.Date}}"
+	}
 	if err := p.compileValidateTemplate(); err != nil {
 		return err
 	}
+	// validate the naming template of the downloaded files
+	if p.Options.FileNameTemplate != "" {
+		if _, err := downloader.ParseNameTemplate(p.Options.FileNameTemplate); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// validateColumns checks that the columns are only selected for the text
+// output, and are valid for the listed entity.
+func (p *Params) validateColumns() error {
+	if len(p.Output.Columns) == 0 {
+		return nil
+	}
+	if !p.Output.IsText() {
+		return fmt.Errorf("columns can only be selected for the %q output", OutputTypeText)
+	}
+	known := types.ChannelColumns
+	if p.ListFlags.Users {
+		known = types.AllUserColumns()
+	}
+	return types.CheckColumns(p.Output.Columns, known)
+}
+
 func (p *Params) CompileTemplates() (*template.Template, error) {
 	return template.New(FilenameTmplName).Parse(p.FilenameTemplate)
 }
 
+// tmplFieldsHint lists the fields that can be used in the file naming
+// template.
+const tmplFieldsHint = "available fields: {{.ID}}, {{.Name}}, and {{.ThreadTS}} and {{.Date}} (with split), which can't be used on their own"
+
+// compileValidateTemplate compiles the file naming template, and renders it
+// against a sample conversation to ensure that it references only the fields
+// that are useful in the filename.
 func (p *Params) compileValidateTemplate() error {
 	tmpl, err := p.CompileTemplates()
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid file naming template %q: %w", p.FilenameTemplate, err)
 	}
 	// are you ready for some filth? Here we go!
 
@@ -168,24 +415,41 @@ func (p *Params) compileValidateTemplate() error {
 
 	// marking all the fields we want with OK, all the rest (the ones we DO NOT
 	// WANT) with NotOK.
-	tc := types.Conversation{
-		Name:     OK,
-		ID:       OK,
-		Messages: []types.Message{{Message: slack.Message{Msg: slack.Msg{Channel: NotOK}}}},
-		ThreadTS: PartialOK,
+	tc := FilenameData{
+		Conversation: types.Conversation{
+			Name:     OK,
+			ID:       OK,
+			Messages: []types.Message{{Message: slack.Message{Msg: slack.Msg{Channel: NotOK}}}},
+			ThreadTS: PartialOK,
+		},
+		Date: NotOK, // only allowed, if the output is split
+	}
+	if p.Output.Split != SplitNone {
+		tc.Date = PartialOK
 	}
 
 	// now we render the template and check for OK/NotOK values in the output.
 	var buf strings.Builder
 	if err := tmpl.ExecuteTemplate(&buf, FilenameTmplName, tc); err != nil {
-		return err
+		return fmt.Errorf("invalid file naming template %q, %s: %w", p.FilenameTemplate, tmplFieldsHint, err)
 	}
 	if strings.Contains(buf.String(), NotOK) || len(buf.String()) == 0 {
-		return fmt.Errorf("invalid fields in the template: %q", p.FilenameTemplate)
+		return fmt.Errorf("invalid fields in the file naming template %q, %s", p.FilenameTemplate, tmplFieldsHint)
 	}
 	if !strings.Contains(buf.String(), OK) {
 		// must contain at least one OK
-		return fmt.Errorf("this does not resolve to anything useful: %q", p.FilenameTemplate)
+		return fmt.Errorf("file naming template %q does not resolve to anything useful, %s", p.FilenameTemplate, tmplFieldsHint)
+	}
+	if p.Output.ThreadFiles {
+		// the threads must not overwrite the file of their channel.
+		tc.ThreadTS = ""
+		var chanBuf strings.Builder
+		if err := tmpl.ExecuteTemplate(&chanBuf, FilenameTmplName, tc); err != nil {
+			return fmt.Errorf("invalid file naming template %q, %s: %w", p.FilenameTemplate, tmplFieldsHint, err)
+		}
+		if chanBuf.String() == buf.String() {
+			return fmt.Errorf("file naming template %q must use {{.ThreadTS}} to write the threads to separate files", p.FilenameTemplate)
+		}
 	}
 	return nil
 }
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/internal/structures"
 )
 
 func TestConfig_compileValidateTemplate(t *testing.T) {
@@ -61,6 +66,31 @@ func TestConfig_compileValidateTemplate(t *testing.T) {
 			fields{FilenameTemplate: ""},
 			true,
 		},
+		{
+			"typo in the field name is not ok",
+			fields{FilenameTemplate: "{{.ID}}-{{.Thread}}"},
+			true,
+		},
+		{
+			"syntax error is not ok",
+			fields{FilenameTemplate: "{{.ID}"},
+			true,
+		},
+		{
+			"date with split is ok",
+			fields{FilenameTemplate: "{{.ID}}-{{.Date}}", Output: Output{Split: SplitMonthly}},
+			false,
+		},
+		{
+			"date without split is not ok",
+			fields{FilenameTemplate: "{{.ID}}-{{.Date}}"},
+			true,
+		},
+		{
+			"just date is not ok",
+			fields{FilenameTemplate: "{{.Date}}", Output: Output{Split: SplitDaily}},
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -79,3 +109,313 @@ func TestConfig_compileValidateTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestParams_TimeRange(t *testing.T) {
+	var (
+		flagFrom = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		flagTo   = time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)
+		dfStart  = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		dfEnd    = time.Date(2023, 1, 6, 0, 0, 0, 0, time.UTC)
+	)
+	tests := []struct {
+		name       string
+		list       *structures.EntityList
+		wantOldest time.Time
+		wantLatest time.Time
+	}{
+		{"no list", nil, flagFrom, flagTo},
+		{"no date filter", &structures.EntityList{Include: []string{"C1"}}, flagFrom, flagTo},
+		{"date filter", &structures.EntityList{DateFilters: []structures.DateFilter{{Start: dfStart, End: dfEnd}}}, dfStart, dfEnd},
+		{"start only", &structures.EntityList{DateFilters: []structures.DateFilter{{Start: dfStart}}}, dfStart, time.Time{}},
+		{"end only", &structures.EntityList{DateFilters: []structures.DateFilter{{End: dfEnd}}}, time.Time{}, dfEnd},
+		{"several filters", &structures.EntityList{DateFilters: []structures.DateFilter{{Start: dfStart, End: dfStart.AddDate(0, 0, 1)}, {Start: dfEnd.AddDate(0, 0, -1), End: dfEnd}}}, dfStart, dfEnd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Params{
+				Input:  Input{List: tt.list},
+				Oldest: TimeValue(flagFrom),
+				Latest: TimeValue(flagTo),
+			}
+			gotOldest, gotLatest := p.TimeRange()
+			if !gotOldest.Equal(tt.wantOldest) {
+				t.Errorf("Params.TimeRange() oldest = %v, want %v", gotOldest, tt.wantOldest)
+			}
+			if !gotLatest.Equal(tt.wantLatest) {
+				t.Errorf("Params.TimeRange() latest = %v, want %v", gotLatest, tt.wantLatest)
+			}
+		})
+	}
+}
+
+// errAny is used in tests, when any error is expected.
+var errAny = errors.New("any error")
+
+func TestParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Params
+		wantErr error
+	}{
+		{
+			"dump with includes",
+			Params{Input: Input{List: &structures.EntityList{Include: []string{"C1"}, Exclude: []string{"C2"}}}, FilenameTemplate: "{{.ID}}"},
+			nil,
+		},
+		{
+			"dump with exclusions only",
+			Params{Input: Input{List: &structures.EntityList{Exclude: []string{"C2"}}}},
+			ErrExcludeOnly,
+		},
+		{
+			"export with exclusions only",
+			Params{ExportName: "export.zip", Input: Input{List: &structures.EntityList{Exclude: []string{"C2"}}}},
+			nil,
+		},
+		{
+			"dump with invalid template",
+			Params{Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, FilenameTemplate: "{{.Thread}}"},
+			errAny,
+		},
+		{
+			"dump with file name template",
+			Params{Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, FilenameTemplate: "{{.ID}}", Options: slackdump.Options{FileNameTemplate: "{{.Timestamp}}_{{.Name}}"}},
+			nil,
+		},
+		{
+			"dump with invalid file name template",
+			Params{Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, FilenameTemplate: "{{.ID}}", Options: slackdump.Options{FileNameTemplate: "{{.Timestamp}}"}},
+			errAny,
+		},
+		{
+			"archived only export",
+			Params{ExportName: "export.zip", ListFlags: ListFlags{ArchivedOnly: true}},
+			nil,
+		},
+		{
+			"archived only and active only",
+			Params{ExportName: "export.zip", ListFlags: ListFlags{ArchivedOnly: true, ActiveOnly: true}},
+			errAny,
+		},
+		{
+			"archived only with archived excluded",
+			Params{ExportName: "export.zip", ListFlags: ListFlags{ArchivedOnly: true}, Options: slackdump.Options{ExcludeArchived: true}},
+			errAny,
+		},
+		{
+			"user custom fields in export",
+			Params{ExportName: "export.zip", UserCustomFields: true},
+			nil,
+		},
+		{
+			"user custom fields with user list",
+			Params{ListFlags: ListFlags{Users: true}, UserCustomFields: true, Input: Input{List: &structures.EntityList{}}, FilenameTemplate: "{{.ID}}"},
+			nil,
+		},
+		{
+			"user custom fields with channel list",
+			Params{ListFlags: ListFlags{Channels: true}, UserCustomFields: true, Input: Input{List: &structures.EntityList{}}, FilenameTemplate: "{{.ID}}"},
+			errAny,
+		},
+		{
+			"strip file urls",
+			Params{ExportName: "export.zip", StripFileURLs: true},
+			nil,
+		},
+		{
+			"strip file urls with export token",
+			Params{ExportName: "export.zip", StripFileURLs: true, ExportToken: "xoxe-token"},
+			errAny,
+		},
+		{
+			"thread files with threads skipped",
+			Params{Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, FilenameTemplate: "{{.ID}}{{ if .ThreadTS}}-{{.ThreadTS}}{{end}}", Output: Output{ThreadFiles: true}, Options: slackdump.Options{SkipThreads: true}},
+			errAny,
+		},
+		{
+			"negative message limit",
+			Params{ExportName: "export.zip", Options: slackdump.Options{MessageLimit: -1}},
+			errAny,
+		},
+		{
+			"reaction filter threads without reaction filter",
+			Params{ExportName: "export.zip", Options: slackdump.Options{ReactionFilterThreads: true}},
+			errAny,
+		},
+		{
+			"thread files",
+			Params{Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, FilenameTemplate: "{{.ID}}{{ if .ThreadTS}}-{{.ThreadTS}}{{end}}", Output: Output{ThreadFiles: true}},
+			nil,
+		},
+		{
+			"thread files without thread ts in the template",
+			Params{Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, FilenameTemplate: "{{.ID}}", Output: Output{ThreadFiles: true}},
+			errAny,
+		},
+		{
+			"thread files in export mode",
+			Params{ExportName: "export.zip", Output: Output{ThreadFiles: true}},
+			errAny,
+		},
+		{
+			"notification webhook",
+			Params{ExportName: "export.zip", NotifyWebhook: "https://hooks.example.com/T0/B0/secret"},
+			nil,
+		},
+		{
+			"notification webhook without scheme",
+			Params{ExportName: "export.zip", NotifyWebhook: "hooks.example.com/T0/B0/secret"},
+			errAny,
+		},
+		{
+			"invalid message filter",
+			Params{ExportName: "export.zip", Options: slackdump.Options{MessageFilter: "(unclosed"}},
+			errAny,
+		},
+		{
+			"invalid channel type",
+			Params{ExportName: "export.zip", ListFlags: ListFlags{ChannelTypes: []string{"public_channel", "dm"}}},
+			errInvalidChanType,
+		},
+		{
+			"nothing to do",
+			Params{Input: Input{List: &structures.EntityList{}}},
+			ErrNothingToDo,
+		},
+		{
+			"csv channel list",
+			Params{ListFlags: ListFlags{Channels: true}, Input: Input{List: &structures.EntityList{}}, Output: Output{Format: OutputTypeCSV}, FilenameTemplate: "{{.ID}}"},
+			nil,
+		},
+		{
+			"user list columns",
+			Params{ListFlags: ListFlags{Users: true}, Input: Input{List: &structures.EntityList{}}, Output: Output{Format: OutputTypeText, Columns: []string{"id", "deleted"}}, FilenameTemplate: "{{.ID}}"},
+			nil,
+		},
+		{
+			"channel columns are not valid for users",
+			Params{ListFlags: ListFlags{Users: true}, Input: Input{List: &structures.EntityList{}}, Output: Output{Format: OutputTypeText, Columns: []string{"id", "arch"}}, FilenameTemplate: "{{.ID}}"},
+			errAny,
+		},
+		{
+			"user list extra columns",
+			Params{ListFlags: ListFlags{Users: true}, Input: Input{List: &structures.EntityList{}}, Output: Output{Format: OutputTypeText, Columns: []string{"name", "tz", "tz_offset"}}, FilenameTemplate: "{{.ID}}"},
+			nil,
+		},
+		{
+			"user presence",
+			Params{ListFlags: ListFlags{Users: true, UserPresence: true}, Input: Input{List: &structures.EntityList{}}, FilenameTemplate: "{{.ID}}"},
+			nil,
+		},
+		{
+			"user presence without the user list",
+			Params{ListFlags: ListFlags{Channels: true, UserPresence: true}, Input: Input{List: &structures.EntityList{}}, FilenameTemplate: "{{.ID}}"},
+			errAny,
+		},
+		{
+			"columns with json output",
+			Params{ListFlags: ListFlags{Channels: true}, Input: Input{List: &structures.EntityList{}}, Output: Output{Format: OutputTypeJSON, Columns: []string{"id"}}, FilenameTemplate: "{{.ID}}"},
+			errAny,
+		},
+		{
+			"csv dump is not supported",
+			Params{Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, Output: Output{Format: OutputTypeCSV}, FilenameTemplate: "{{.ID}}"},
+			errAny,
+		},
+		{
+			"invalid list format",
+			Params{ListFlags: ListFlags{Users: true}, Input: Input{List: &structures.EntityList{}}, Output: Output{Format: "xml"}, FilenameTemplate: "{{.ID}}"},
+			errAny,
+		},
+		{
+			"redacted export",
+			Params{ExportName: "export.zip", Redact: true, RedactMap: "map.json", RedactMapEncrypt: true, Input: Input{List: &structures.EntityList{}}},
+			nil,
+		},
+		{
+			"redaction without export",
+			Params{Redact: true, Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, FilenameTemplate: "{{.ID}}"},
+			errAny,
+		},
+		{
+			"redaction map without redaction",
+			Params{ExportName: "export.zip", RedactMap: "map.json", Input: Input{List: &structures.EntityList{}}},
+			errAny,
+		},
+		{
+			"redaction map encryption without the map",
+			Params{ExportName: "export.zip", Redact: true, RedactMapEncrypt: true, Input: Input{List: &structures.EntityList{}}},
+			errAny,
+		},
+		{
+			"incremental export",
+			Params{ExportName: "export", StateFile: "export.state.json", Input: Input{List: &structures.EntityList{}}},
+			nil,
+		},
+		{
+			"incremental export to zip is not supported",
+			Params{ExportName: "export.ZIP", StateFile: "export.state.json", Input: Input{List: &structures.EntityList{}}},
+			errAny,
+		},
+		{
+			"incremental export to the bucket is not supported",
+			Params{ExportName: "s3://bucket/export", StateFile: "export.state.json", Input: Input{List: &structures.EntityList{}}},
+			errAny,
+		},
+		{
+			"state file without export",
+			Params{StateFile: "export.state.json", Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, FilenameTemplate: "{{.ID}}"},
+			errAny,
+		},
+		{
+			"split dump",
+			Params{Output: Output{Split: SplitDaily}, Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, FilenameTemplate: "{{.ID}}"},
+			nil,
+		},
+		{
+			"split export is not supported",
+			Params{ExportName: "export", Output: Output{Split: SplitDaily}, Input: Input{List: &structures.EntityList{}}},
+			errAny,
+		},
+		{
+			"incremental html export is not supported",
+			Params{ExportName: "export", ExportType: export.THTML, StateFile: "export.state.json", Input: Input{List: &structures.EntityList{}}},
+			errAny,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.Validate()
+			if tt.wantErr == errAny {
+				if err == nil {
+					t.Error("Params.Validate() expected an error")
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Params.Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParams_Validate_presenceColumns(t *testing.T) {
+	p := Params{ListFlags: ListFlags{Users: true, UserPresence: true}, Input: Input{List: &structures.EntityList{}}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"name", "id", "bot", "deleted", "restricted", "presence"}
+	if !reflect.DeepEqual(p.Output.Columns, want) {
+		t.Errorf("Output.Columns = %v, want %v", p.Output.Columns, want)
+	}
+}
+
+func TestParams_Validate_splitTemplate(t *testing.T) {
+	p := Params{Output: Output{Split: SplitMonthly}, Input: Input{List: &structures.EntityList{Include: []string{"C1"}}}, FilenameTemplate: "{{.ID}}"}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{{.ID}}-{{.Date}}"; p.FilenameTemplate != want {
+		t.Errorf("FilenameTemplate = %q, want %q", p.FilenameTemplate, want)
+	}
+}
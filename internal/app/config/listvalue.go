@@ -0,0 +1,30 @@
+package config
+
+import (
+	"flag"
+	"strings"
+)
+
+// ListValue satisfies flag.Value, used for command line parsing of the
+// comma-separated lists, i.e. "jpg,png,gif".  Empty items are dropped.
+type ListValue []string
+
+var _ flag.Value = &ListValue{}
+
+func (l *ListValue) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *ListValue) Set(s string) error {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*l = items
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListValue_Set(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want ListValue
+	}{
+		{"empty", "", nil},
+		{"single", "jpg", ListValue{"jpg"}},
+		{"several", "jpg,png,!mp4", ListValue{"jpg", "png", "!mp4"}},
+		{"spaces and empty items", " jpg, ,png ,", ListValue{"jpg", "png"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l ListValue
+			assert.NoError(t, l.Set(tt.s))
+			assert.Equal(t, tt.want, l)
+			assert.Equal(t, tt.s != "" && len(tt.want) > 0, l.String() != "")
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SizeValue satisfies flag.Value, used for command line parsing of the sizes
+// in bytes, that may have a suffix, i.e. "512K", "50M" or "2GB".  Suffixes are
+// case-insensitive, and are powers of 1024.
+type SizeValue int64
+
+var _ flag.Value = new(SizeValue)
+
+var sizeSuffixes = []struct {
+	suffix string
+	mult   int64
+}{
+	// the order matters, longest suffixes first.
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"TB", 1 << 40},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+	{"B", 1},
+}
+
+func (sv *SizeValue) String() string {
+	if sv == nil || *sv == 0 {
+		return "0"
+	}
+	n := int64(*sv)
+	for _, s := range []struct {
+		suffix string
+		mult   int64
+	}{{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}} {
+		if n%s.mult == 0 {
+			return strconv.FormatInt(n/s.mult, 10) + s.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+func (sv *SizeValue) Set(s string) error {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, suf := range sizeSuffixes {
+		if strings.HasSuffix(str, suf.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, suf.suffix))
+			mult = suf.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %q", s)
+	}
+	if n < 0 {
+		return errors.New("size can't be negative")
+	}
+	if n > (1<<63-1)/mult {
+		return fmt.Errorf("size %q is too large", s)
+	}
+	*sv = SizeValue(n * mult)
+	return nil
+}
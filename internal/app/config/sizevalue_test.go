@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeValue_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    SizeValue
+		wantErr bool
+	}{
+		{"zero", "0", 0, false},
+		{"bytes", "1000", 1000, false},
+		{"bytes suffix", "1000b", 1000, false},
+		{"kilobytes", "512K", 512 << 10, false},
+		{"megabytes", "50M", 50 << 20, false},
+		{"megabytes long suffix lowercase", "50mb", 50 << 20, false},
+		{"gigabytes with space", "2 GB", 2 << 30, false},
+		{"terabytes", "1T", 1 << 40, false},
+		{"empty", "", 0, true},
+		{"negative", "-1M", 0, true},
+		{"fraction", "1.5G", 0, true},
+		{"unknown suffix", "10X", 0, true},
+		{"overflow", "9000000000T", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sv SizeValue
+			err := sv.Set(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SizeValue.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, sv)
+		})
+	}
+}
+
+func TestSizeValue_String(t *testing.T) {
+	tests := []struct {
+		sv   SizeValue
+		want string
+	}{
+		{0, "0"},
+		{1000, "1000"},
+		{512 << 10, "512K"},
+		{50 << 20, "50M"},
+		{3 << 30, "3G"},
+		{(1 << 20) + 1, "1048577"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.sv.String())
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+)
+
+// SplitValue satisfies flag.Value, it is the period, by which the dumped
+// conversations are split into separate files: "daily" or "monthly".  Empty
+// value means no split.
+type SplitValue string
+
+const (
+	SplitNone    SplitValue = ""
+	SplitDaily   SplitValue = "daily"
+	SplitMonthly SplitValue = "monthly"
+)
+
+var _ flag.Value = new(SplitValue)
+
+func (s *SplitValue) String() string {
+	return string(*s)
+}
+
+func (s *SplitValue) Set(v string) error {
+	switch sv := SplitValue(v); sv {
+	case SplitNone, SplitDaily, SplitMonthly:
+		*s = sv
+		return nil
+	}
+	return fmt.Errorf("invalid split period: %q, must be %q or %q", v, SplitDaily, SplitMonthly)
+}
+
+// Layout returns the time layout of the date of the period, that is used in
+// the file names, or an empty string, if there's no split.
+func (s SplitValue) Layout() string {
+	switch s {
+	case SplitDaily:
+		return "2006-01-02"
+	case SplitMonthly:
+		return "2006-01"
+	}
+	return ""
+}
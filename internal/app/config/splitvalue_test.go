@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitValue_Set(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		want       SplitValue
+		wantLayout string
+		wantErr    bool
+	}{
+		{"empty is no split", "", SplitNone, "", false},
+		{"daily", "daily", SplitDaily, "2006-01-02", false},
+		{"monthly", "monthly", SplitMonthly, "2006-01", false},
+		{"invalid", "weekly", SplitNone, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s SplitValue
+			if err := s.Set(tt.s); (err != nil) != tt.wantErr {
+				t.Errorf("SplitValue.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, s)
+			assert.Equal(t, tt.wantLayout, s.Layout())
+		})
+	}
+}
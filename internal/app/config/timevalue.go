@@ -2,11 +2,17 @@ package config
 
 import (
 	"flag"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const timeFmt = "2006-01-02T15:04:05"
 
+// timeNow is the current time function, it's a variable for testing.
+var timeNow = time.Now
+
 // TimeValue satisfies flag.Value, used for command line parsing.
 type TimeValue time.Time
 
@@ -19,14 +25,44 @@ func (tv *TimeValue) String() string {
 	return time.Time(*tv).Format(timeFmt)
 }
 
+// Set sets the time from s, which is either the timestamp in the timeFmt
+// format, or the duration relative to now, i.e. "-7d" or "24h", see
+// parseRelative.
 func (tv *TimeValue) Set(s string) error {
 	if s == "" {
 		return nil
 	}
-	if t, err := time.Parse(timeFmt, s); err != nil {
-		return err
-	} else {
+	if t, err := time.Parse(timeFmt, s); err == nil {
 		*tv = TimeValue(t)
+		return nil
 	}
+	d, err := parseRelative(s)
+	if err != nil {
+		return fmt.Errorf("invalid time %q, expected the timestamp (i.e. 2020-12-31T23:59:59) or the duration (i.e. 7d or 24h)", s)
+	}
+	*tv = TimeValue(timeNow().UTC().Add(-d).Truncate(time.Second))
 	return nil
 }
+
+// parseRelative parses the duration s, that is relative to now.  It accepts
+// the Go durations, i.e. "24h" or "90m", and the days, i.e. "7d".  The sign
+// is optional, and the duration always points to the past, so "-7d" and "7d"
+// both mean 7 days ago.
+func parseRelative(s string) (time.Duration, error) {
+	s = strings.TrimPrefix(s, "-")
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.ParseUint(strings.TrimSuffix(s, "d"), 10, 32)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration: %s", s)
+	}
+	return d, nil
+}
@@ -13,6 +13,10 @@ func tv(t time.Time) *TimeValue {
 }
 
 func TestTimeValue_Set(t *testing.T) {
+	now := time.Date(2023, 3, 31, 12, 0, 0, 500, time.UTC)
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
 	type args struct {
 		s string
 	}
@@ -30,6 +34,48 @@ func TestTimeValue_Set(t *testing.T) {
 			tv(time.Date(2009, 9, 16, 20, 30, 40, 0, time.UTC)),
 			false,
 		},
+		{
+			"relative days",
+			&TimeValue{},
+			args{"-30d"},
+			tv(time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)),
+			false,
+		},
+		{
+			"relative days without sign",
+			&TimeValue{},
+			args{"7d"},
+			tv(time.Date(2023, 3, 24, 12, 0, 0, 0, time.UTC)),
+			false,
+		},
+		{
+			"relative hours",
+			&TimeValue{},
+			args{"12h"},
+			tv(time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)),
+			false,
+		},
+		{
+			"relative minutes with sign",
+			&TimeValue{},
+			args{"-90m"},
+			tv(time.Date(2023, 3, 31, 10, 30, 0, 0, time.UTC)),
+			false,
+		},
+		{
+			"invalid value",
+			&TimeValue{},
+			args{"last week"},
+			&TimeValue{},
+			true,
+		},
+		{
+			"invalid days",
+			&TimeValue{},
+			args{"1.5d"},
+			&TimeValue{},
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
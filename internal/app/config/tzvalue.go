@@ -0,0 +1,58 @@
+package config
+
+import (
+	"flag"
+	"time"
+)
+
+// TZUser is the TZValue, that requests the time zone of the current user,
+// as set in the Slack profile.
+const TZUser = "user"
+
+// TZValue satisfies flag.Value, used for command line parsing of the time zone
+// names, i.e. "Europe/London" or "Local", or TZUser.
+type TZValue struct {
+	loc  *time.Location
+	user bool
+}
+
+var _ flag.Value = &TZValue{}
+
+func (tz *TZValue) String() string {
+	if tz.user {
+		return TZUser
+	}
+	if tz.loc == nil {
+		return ""
+	}
+	return tz.loc.String()
+}
+
+func (tz *TZValue) Set(s string) error {
+	tz.user = s == TZUser
+	if s == "" || tz.user {
+		tz.loc = nil
+		return nil
+	}
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		return err
+	}
+	tz.loc = loc
+	return nil
+}
+
+// IsUser returns true, if the time zone of the current user is requested.
+// The caller should resolve it, and use Location as a fallback.
+func (tz TZValue) IsUser() bool {
+	return tz.user
+}
+
+// Location returns the time zone location.  If the value is not set, or is
+// TZUser, it returns UTC.
+func (tz TZValue) Location() *time.Location {
+	if tz.loc == nil {
+		return time.UTC
+	}
+	return tz.loc
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTZValue_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{"empty is utc", "", "UTC", false},
+		{"utc", "UTC", "UTC", false},
+		{"named zone", "Asia/Tokyo", "Asia/Tokyo", false},
+		{"invalid", "Mars/Olympus_Mons", "UTC", true},
+		{"user falls back to utc", "user", "UTC", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tz TZValue
+			if err := tz.Set(tt.s); (err != nil) != tt.wantErr {
+				t.Errorf("TZValue.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, tz.Location().String())
+		})
+	}
+}
+
+func TestTZValue_Location(t *testing.T) {
+	var tz TZValue
+	assert.Equal(t, time.UTC, tz.Location())
+}
+
+func TestTZValue_IsUser(t *testing.T) {
+	var tz TZValue
+	assert.NoError(t, tz.Set(TZUser))
+	assert.True(t, tz.IsUser())
+	assert.Equal(t, TZUser, tz.String())
+	assert.NoError(t, tz.Set("Asia/Tokyo"))
+	assert.False(t, tz.IsUser())
+}
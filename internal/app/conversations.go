@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/structures"
+)
+
+// ConversationItem is the conversation, offered for the selection in the
+// interactive mode.
+type ConversationItem struct {
+	ID   string
+	Name string // "beautified" name, i.e. #general or @user
+}
+
+// Conversations fetches the conversations of the types set in the
+// cfg.ListFlags, and returns them, sorted by name, to be offered for the
+// selection.  Archived conversations are filtered according to the
+// cfg.ListFlags.
+func Conversations(ctx context.Context, cfg config.Params, prov auth.Provider) ([]ConversationItem, error) {
+	sess, err := slackdump.NewWithOptions(ctx, prov, cfg.Options)
+	if err != nil {
+		return nil, err
+	}
+	return conversationItems(ctx, sessionGetter{sess}, sess.UserIndex, cfg.ListFlags)
+}
+
+// conversationItems returns the conversations, fetched with cg, sorted by
+// name.
+func conversationItems(ctx context.Context, cg conversationGetter, idx structures.UserIndex, lf config.ListFlags) ([]ConversationItem, error) {
+	chans, err := cg.GetChannels(ctx, lf.ChannelTypes...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching channels: %w", err)
+	}
+	chans = chans.FilterArchived(lf.ArchivedFilter())
+	items := make([]ConversationItem, 0, len(chans))
+	for i := range chans {
+		items = append(items, ConversationItem{ID: chans[i].ID, Name: idx.ChannelName(&chans[i])})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func Test_conversationItems(t *testing.T) {
+	im := testChan("D01", "")
+	im.IsIM = true
+	im.User = "U01"
+	chans := types.Channels{
+		testChan("C02", "random"),
+		testChan("C01", "general"),
+		archived(testChan("C03", "old")),
+		im,
+	}
+	for i := range chans {
+		chans[i].NameNormalized = chans[i].Name
+	}
+	idx := structures.UserIndex{"U01": &slack.User{ID: "U01", Name: "bob"}}
+	tests := []struct {
+		name string
+		lf   config.ListFlags
+		want []ConversationItem
+	}{
+		{
+			"all conversations",
+			config.ListFlags{},
+			[]ConversationItem{{"C01", "#general"}, {"C03", "#old"}, {"C02", "#random"}, {"D01", "@bob"}},
+		},
+		{
+			"active only",
+			config.ListFlags{ActiveOnly: true},
+			[]ConversationItem{{"C01", "#general"}, {"C02", "#random"}, {"D01", "@bob"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conversationItems(context.Background(), &fakeGetter{chans: chans}, idx, tt.lf)
+			if err != nil {
+				t.Fatalf("conversationItems() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("conversationItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
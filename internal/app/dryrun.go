@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/trace"
+	"text/tabwriter"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// plan is the list of conversations that would be processed by the dump or
+// export, and the time range of the messages.
+type plan struct {
+	Mode          string       `json:"mode"`
+	Oldest        *time.Time   `json:"oldest,omitempty"`
+	Latest        *time.Time   `json:"latest,omitempty"`
+	Conversations []planTarget `json:"conversations"`
+}
+
+// planTarget is the conversation in the plan.
+type planTarget struct {
+	ID       string `json:"id"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+	Name     string `json:"name"`
+}
+
+// conversationGetter is the subset of the Session methods, that are required
+// to resolve the conversations.
+type conversationGetter interface {
+	GetChannels(ctx context.Context, chanTypes ...string) (types.Channels, error)
+	GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error)
+}
+
+// sessionGetter adapts the Session to the conversationGetter.
+type sessionGetter struct {
+	*slackdump.Session
+}
+
+func (sg sessionGetter) GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	return sg.Client().GetConversationInfoContext(ctx, input)
+}
+
+// DryRun resolves the conversations, that would be dumped or exported, and
+// writes them to the output, without fetching any messages or files.
+func DryRun(ctx context.Context, cfg config.Params, prov auth.Provider) error {
+	ctx, task := trace.NewTask(ctx, "DryRun")
+	defer task.End()
+
+	sess, err := slackdump.NewWithOptions(ctx, prov, cfg.Options)
+	if err != nil {
+		return err
+	}
+
+	p, err := makePlan(ctx, sessionGetter{sess}, cfg)
+	if err != nil {
+		return err
+	}
+
+	f, err := createFile(cfg.Output.Filename, cfg.Gzip)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if cfg.Output.Format == config.OutputTypeJSON {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(p)
+	}
+	return p.ToText(f)
+}
+
+// makePlan resolves the input list of the cfg into the plan.  In export mode,
+// the list without included conversations means all conversations, except the
+// excluded ones.
+func makePlan(ctx context.Context, cg conversationGetter, cfg config.Params) (*plan, error) {
+	p := plan{Mode: modeDump}
+	if cfg.ExportName != "" {
+		p.Mode = modeExport
+	}
+	oldest, latest := cfg.TimeRange()
+	if !oldest.IsZero() {
+		p.Oldest = &oldest
+	}
+	if !latest.IsZero() {
+		p.Latest = &latest
+	}
+
+	list := cfg.Input.List
+	if list == nil {
+		list = new(structures.EntityList)
+	}
+
+	var chans types.Channels
+	if list.HasPatterns() || !list.HasIncludes() {
+		var err error
+		chanTypes := cfg.ListFlags.ChannelTypes
+		if p.Mode == modeExport {
+			chanTypes = export.Options{
+				ChannelTypes:    cfg.ListFlags.ChannelTypes,
+				IncludeDMs:      cfg.IncludeDMs,
+				IncludeGroupDMs: cfg.IncludeGroupDMs,
+			}.ChanTypes()
+		}
+		chans, err = cg.GetChannels(ctx, chanTypes...)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching channels: %w", err)
+		}
+		if err := list.ResolvePatterns(chans); err != nil {
+			return nil, err
+		}
+	}
+
+	idx := list.Index()
+	if !list.HasIncludes() {
+		if p.Mode != modeExport {
+			return nil, config.ErrNothingToDo
+		}
+		for _, ch := range chans.FilterArchived(cfg.ListFlags.ArchivedFilter()) {
+			if include, ok := idx[ch.ID]; ok && !include {
+				continue
+			}
+			p.Conversations = append(p.Conversations, planTarget{ID: ch.ID, Name: ch.Name})
+		}
+		return &p, nil
+	}
+
+	for _, entry := range list.Include {
+		if include, ok := idx[entry]; ok && !include {
+			continue
+		}
+		sl, err := structures.ParseLink(entry)
+		if err != nil {
+			return nil, err
+		}
+		ch, err := cg.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: sl.Channel})
+		if err != nil {
+			return nil, fmt.Errorf("error getting info for %s: %w", sl.Channel, err)
+		}
+		if p.Mode == modeExport && !cfg.ListFlags.ArchivedFilter().Match(ch) {
+			continue
+		}
+		p.Conversations = append(p.Conversations, planTarget{ID: sl.Channel, ThreadTS: sl.ThreadTS, Name: ch.Name})
+	}
+	if len(p.Conversations) == 0 {
+		return nil, errors.New("all conversations are excluded")
+	}
+	return &p, nil
+}
+
+// ToText writes the plan to w in text format.
+func (p *plan) ToText(w io.Writer) error {
+	fmt.Fprintf(w, "Mode:   %s\n", p.Mode)
+	fmt.Fprintf(w, "Oldest: %s\n", fmtPlanTime(p.Oldest))
+	fmt.Fprintf(w, "Latest: %s\n\n", fmtPlanTime(p.Latest))
+
+	const strFormat = "%s\t%s\t%s\n"
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, strFormat, "ID", "Thread", "Name")
+	for _, c := range p.Conversations {
+		thread := "-"
+		if c.ThreadTS != "" {
+			thread = c.ThreadTS
+		}
+		fmt.Fprintf(tw, strFormat, c.ID, thread, c.Name)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d conversation(s) would be processed.\n", len(p.Conversations))
+	return err
+}
+
+func fmtPlanTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
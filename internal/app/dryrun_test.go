@@ -0,0 +1,161 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// fakeGetter is the conversationGetter, that returns the predefined channels.
+type fakeGetter struct {
+	chans types.Channels
+	calls int // number of GetChannels calls
+}
+
+func (fg *fakeGetter) GetChannels(ctx context.Context, chanTypes ...string) (types.Channels, error) {
+	fg.calls++
+	return fg.chans, nil
+}
+
+func (fg *fakeGetter) GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	for _, ch := range fg.chans {
+		if ch.ID == input.ChannelID {
+			return &ch, nil
+		}
+	}
+	return nil, errors.New("channel_not_found")
+}
+
+func testChan(id, name string) slack.Channel {
+	var ch slack.Channel
+	ch.ID = id
+	ch.Name = name
+	return ch
+}
+
+func archived(ch slack.Channel) slack.Channel {
+	ch.IsArchived = true
+	return ch
+}
+
+func Test_makePlan(t *testing.T) {
+	chans := types.Channels{
+		testChan("C01", "general"),
+		testChan("C02", "random"),
+		testChan("C03", "proj-x"),
+		archived(testChan("C04", "old")),
+	}
+	mustList := func(s ...string) *structures.EntityList {
+		el, err := structures.MakeEntityList(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return el
+	}
+	day := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		cfg       config.Params
+		want      *plan
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			"dump of the listed conversations does not list channels",
+			config.Params{Input: config.Input{List: mustList("C02", "C03:1577694990.000400")}},
+			&plan{Mode: modeDump, Conversations: []planTarget{
+				{ID: "C02", Name: "random"},
+				{ID: "C03", ThreadTS: "1577694990.000400", Name: "proj-x"},
+			}},
+			0,
+			false,
+		},
+		{
+			"export of all except excluded",
+			config.Params{ExportName: "x.zip", Input: config.Input{List: mustList("^C02")}},
+			&plan{Mode: modeExport, Conversations: []planTarget{
+				{ID: "C01", Name: "general"},
+				{ID: "C03", Name: "proj-x"},
+				{ID: "C04", Name: "old"},
+			}},
+			1,
+			false,
+		},
+		{
+			"export of archived only",
+			config.Params{ExportName: "x.zip", ListFlags: config.ListFlags{ArchivedOnly: true}},
+			&plan{Mode: modeExport, Conversations: []planTarget{
+				{ID: "C04", Name: "old"},
+			}},
+			1,
+			false,
+		},
+		{
+			"export of the listed active only",
+			config.Params{ExportName: "x.zip", ListFlags: config.ListFlags{ActiveOnly: true}, Input: config.Input{List: mustList("C02", "C04")}},
+			&plan{Mode: modeExport, Conversations: []planTarget{
+				{ID: "C02", Name: "random"},
+			}},
+			0,
+			false,
+		},
+		{
+			"name patterns and date filter",
+			config.Params{Input: config.Input{List: &structures.EntityList{
+				Patterns:    mustList("proj-*").Patterns,
+				DateFilters: []structures.DateFilter{{Start: day}},
+			}}},
+			&plan{Mode: modeDump, Oldest: &day, Conversations: []planTarget{
+				{ID: "C03", Name: "proj-x"},
+			}},
+			1,
+			false,
+		},
+		{
+			"unknown channel",
+			config.Params{Input: config.Input{List: mustList("C99")}},
+			nil,
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fg := &fakeGetter{chans: chans}
+			got, err := makePlan(context.Background(), fg, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("makePlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("makePlan() = %+v, want %+v", got, tt.want)
+			}
+			if fg.calls != tt.wantCalls {
+				t.Errorf("GetChannels calls = %d, want %d", fg.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_plan_ToText(t *testing.T) {
+	day := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	p := plan{Mode: modeExport, Oldest: &day, Conversations: []planTarget{{ID: "C01", Name: "general"}}}
+	var buf bytes.Buffer
+	if err := p.ToText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Mode:   export", "Oldest: 2023-01-31T00:00:00Z", "Latest: -", "C01", "general", "1 conversation(s)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
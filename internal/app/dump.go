@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 	"runtime/trace"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,9 +27,18 @@ type dump struct {
 	cfg  config.Params
 
 	log logger.Interface
+
+	messages int     // number of messages dumped, including thread replies
+	errs     []error // errors of the conversations that were skipped
 }
 
-func Dump(ctx context.Context, cfg config.Params, prov auth.Provider) error {
+// errFailed is returned by the batch run, if some conversations failed, and
+// were skipped, see config.Params.ContinueOnError.
+var errFailed = errors.New("some conversations failed")
+
+// Dump dumps the conversations, or lists the users or channels, depending on
+// cfg.  The dump statistics are recorded in rs.
+func Dump(ctx context.Context, cfg config.Params, prov auth.Provider, rs *RunSummary) error {
 	ctx, task := trace.NewTask(ctx, "runDump")
 	defer task.End()
 
@@ -36,19 +46,47 @@ func Dump(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 	if err != nil {
 		return err
 	}
+	wsp := dm.sess.Workspace()
+	rs.Workspace = &wsp
 
 	if cfg.ListFlags.FlagsPresent() {
+		rs.Mode = modeList
 		err = dm.List(ctx)
 	} else {
+		rs.Mode = modeDump
 		var n int
 		n, err = dm.Dump(ctx)
 		cfg.Logger().Printf("dumped %d item(s)", n)
+		rs.Channels = n
+		rs.Messages = dm.messages
+		for _, e := range dm.errs {
+			rs.addError(e)
+		}
+		if len(dm.errs) > 0 && err == nil {
+			err = fmt.Errorf("%w: %d conversation(s) were skipped, see the log for details", errFailed, len(dm.errs))
+		}
+		rs.addFileStats(dm.sess.DownloadStats())
+		if n := dm.sess.SkippedFiles(); n > 0 {
+			cfg.Logger().Printf("%d file(s) were not downloaded due to -file-types, -max-file-size or -max-total-bytes", n)
+		}
+		if dlErrs := dm.sess.DownloadErrors(); len(dlErrs) > 0 {
+			cfg.Logger().Printf("WARNING: %d file(s) failed to download, see the log for details", len(dlErrs))
+			if cfg.StrictDownload && err == nil {
+				err = dlErrs
+			} else {
+				for _, e := range dlErrs {
+					rs.addError(e)
+				}
+			}
+		}
 	}
 	return err
 }
 
 func newDump(ctx context.Context, cfg config.Params, prov auth.Provider) (*dump, error) {
-	sess, err := slackdump.NewWithOptions(ctx, prov, cfg.Options)
+	opts := cfg.Options
+	opts.CompressFiles = cfg.Gzip
+	sess, err := slackdump.NewWithOptions(ctx, prov, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -70,27 +108,54 @@ func newDump(ctx context.Context, cfg config.Params, prov auth.Provider) (*dump,
 //	|  +- ...
 //	+--<ID>.json - json file with conversation and users
 //	+--<ID>.txt  - formatted conversation in text format, if generateText is true.
-func (app *dump) Dump(ctx context.Context) (int, error) {
+func (app *dump) Dump(ctx context.Context) (total int, err error) {
 	if !app.cfg.Input.IsValid() {
 		return 0, errors.New("no valid input")
 	}
+	if app.cfg.Input.List.HasPatterns() {
+		chans, err := app.sess.GetChannels(ctx, app.cfg.ListFlags.ChannelTypes...)
+		if err != nil {
+			return 0, fmt.Errorf("error fetching channels to match the names: %w", err)
+		}
+		if err := app.cfg.Input.List.ResolvePatterns(chans); err != nil {
+			return 0, err
+		}
+	}
 
 	fs, err := fsadapter.New(app.cfg.Output.Base)
 	if err != nil {
 		return 0, err
 	}
-	defer fs.Close()
+	defer func() {
+		// the archive in the bucket is uploaded on close.
+		if cerr := fs.Close(); cerr != nil {
+			app.log.Printf("error closing %s: %s", fs, cerr)
+			if err == nil {
+				err = fmt.Errorf("error closing %s: %w", fs, cerr)
+			}
+		}
+	}()
+	// the downloader compresses the files itself, as it skips the ones that
+	// are already compressed.
 	app.sess.SetFS(fs)
+	var out fsadapter.FS = fs
+	if app.cfg.Gzip {
+		out = fsadapter.NewGzip(fs)
+	}
 
 	tmpl, err := app.cfg.CompileTemplates()
 	if err != nil {
 		return 0, err
 	}
 
-	total := 0
 	if err := app.cfg.Input.Producer(func(channelID string) error {
-		if err := app.dumpOne(ctx, fs, tmpl, channelID, app.sess.Dump); err != nil {
-			app.log.Printf("error processing: %q (conversation will be skipped): %s", channelID, err)
+		if err := app.dumpOne(ctx, out, tmpl, channelID, app.sess.Dump); err != nil {
+			err = fmt.Errorf("error processing %q: %w", channelID, err)
+			if !app.cfg.ContinueOnError || slackdump.IsFatalError(err) {
+				return err
+			}
+			app.log.Printf("%s (conversation will be skipped)", err)
+			app.errs = append(app.errs, err)
 			return config.ErrSkip
 		}
 		total++
@@ -104,10 +169,11 @@ func (app *dump) Dump(ctx context.Context) (int, error) {
 type dumpFunc func(context.Context, string, time.Time, time.Time, ...slackdump.ProcessFunc) (*types.Conversation, error)
 
 // renderFilename returns the filename that is rendered according to the
-// file naming template.
-func renderFilename(tmpl *template.Template, c *types.Conversation) string {
+// file naming template.  date is the date of the messages, if the output is
+// split, otherwise it's empty.
+func renderFilename(tmpl *template.Template, c *types.Conversation, date string) string {
 	var buf strings.Builder
-	if err := tmpl.ExecuteTemplate(&buf, config.FilenameTmplName, c); err != nil {
+	if err := tmpl.ExecuteTemplate(&buf, config.FilenameTmplName, config.FilenameData{Conversation: *c, Date: date}); err != nil {
 		// this should nevar happen
 		panic(err)
 	}
@@ -117,12 +183,127 @@ func renderFilename(tmpl *template.Template, c *types.Conversation) string {
 // dumpOneChannel dumps just one channel specified by channelInput.  If
 // generateText is true, it will also generate a ID.txt text file.
 func (app *dump) dumpOne(ctx context.Context, fs fsadapter.FS, filetmpl *template.Template, channelInput string, fn dumpFunc) error {
-	cnv, err := fn(ctx, channelInput, time.Time(app.cfg.Oldest), time.Time(app.cfg.Latest))
+	cnv, err := dumpRanges(ctx, channelInput, app.cfg.TimeRanges(), fn)
 	if err != nil {
 		return err
 	}
+	app.messages += cnv.MessageCount()
 
-	return app.writeFiles(fs, renderFilename(filetmpl, cnv), cnv)
+	if app.cfg.Output.ThreadFiles && !cnv.IsThread() {
+		if err := app.writeThreads(fs, filetmpl, cnv); err != nil {
+			return err
+		}
+	}
+	if app.cfg.Output.Split == config.SplitNone {
+		return app.writeFiles(fs, renderFilename(filetmpl, cnv, ""), cnv)
+	}
+	dates, parts, err := splitByDate(cnv, app.cfg.Output.Split.Layout())
+	if err != nil {
+		return err
+	}
+	for _, date := range dates {
+		if err := app.writeFiles(fs, renderFilename(filetmpl, parts[date], date), parts[date]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeThreads writes each thread of the conversation cnv to a separate
+// file, named by the file naming template, and replaces the replies of the
+// thread parent messages in cnv with the name of the thread file.  The thread
+// file has the same format as the dump of a single thread, i.e. the parent
+// message followed by the replies.
+func (app *dump) writeThreads(fs fsadapter.FS, filetmpl *template.Template, cnv *types.Conversation) error {
+	for i := range cnv.Messages {
+		m := &cnv.Messages[i]
+		if len(m.ThreadReplies) == 0 {
+			continue
+		}
+		parent := *m
+		parent.ThreadReplies = nil
+		thread := &types.Conversation{
+			Name:     cnv.Name,
+			ID:       cnv.ID,
+			ThreadTS: m.ThreadTimestamp,
+			Messages: append([]types.Message{parent}, m.ThreadReplies...),
+		}
+		var date string
+		if app.cfg.Output.Split != config.SplitNone {
+			// the thread is never split, see splitByDate.
+			dates, _, err := splitByDate(thread, app.cfg.Output.Split.Layout())
+			if err != nil {
+				return err
+			}
+			date = dates[0]
+		}
+		name := renderFilename(filetmpl, thread, date)
+		if err := app.writeFiles(fs, name, thread); err != nil {
+			return err
+		}
+		m.ThreadReplies = nil
+		m.ThreadFile = name + ".json"
+		if app.cfg.Gzip {
+			m.ThreadFile += fsadapter.GzipExt
+		}
+	}
+	return nil
+}
+
+// dumpRanges dumps the conversation once for each of the time ranges, and
+// unions the messages.  The ranges must be disjoint, see
+// structures.MergeDateFilters, so that the messages are not duplicated.
+func dumpRanges(ctx context.Context, link string, ranges []structures.DateFilter, fn dumpFunc) (*types.Conversation, error) {
+	var cnv *types.Conversation
+	for _, r := range ranges {
+		c, err := fn(ctx, link, r.Start, r.End)
+		if err != nil {
+			return nil, err
+		}
+		if cnv == nil {
+			cnv = c
+			continue
+		}
+		cnv.Messages = append(cnv.Messages, c.Messages...)
+	}
+	if cnv == nil {
+		return nil, errors.New("internal error: no time ranges")
+	}
+	if len(ranges) > 1 {
+		types.SortMessages(cnv.Messages)
+	}
+	return cnv, nil
+}
+
+// splitByDate splits the conversation into parts by the date of the
+// messages, formatted with layout in UTC.  Thread replies stay with their
+// parent message, and the thread dump is never split, it goes to the date of
+// the thread.  It returns the sorted dates, and the parts by date.
+func splitByDate(cnv *types.Conversation, layout string) ([]string, map[string]*types.Conversation, error) {
+	var (
+		dates []string
+		parts = make(map[string]*types.Conversation)
+	)
+	for _, m := range cnv.Messages {
+		ts := m.Timestamp
+		if cnv.ThreadTS != "" {
+			ts = cnv.ThreadTS
+		}
+		t, err := structures.ParseSlackTS(ts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid timestamp of message %q: %w", m.Timestamp, err)
+		}
+		date := t.Format(layout)
+		part, ok := parts[date]
+		if !ok {
+			part = &types.Conversation{Name: cnv.Name, ID: cnv.ID, ThreadTS: cnv.ThreadTS}
+			parts[date] = part
+			dates = append(dates, date)
+		}
+		part.Messages = append(part.Messages, m)
+	}
+	sort.Strings(dates)
+	return dates, parts, nil
 }
 
 // writeFiles writes the conversation to disk.  If text output is set, it will
@@ -139,12 +320,16 @@ func (app *dump) writeFiles(fs fsadapter.FS, name string, cnv *types.Conversatio
 	return nil
 }
 
-func (app *dump) writeJSON(fs fsadapter.FS, filename string, m any) error {
+func (app *dump) writeJSON(fs fsadapter.FS, filename string, m any) (err error) {
 	f, err := fs.Create(filename)
 	if err != nil {
 		return fmt.Errorf("error writing %q: %w", filename, err)
 	}
-	defer f.Close()
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error writing %q: %w", filename, cerr)
+		}
+	}()
 
 	enc := json.NewEncoder(f)
 	enc.SetIndent("", "  ")
@@ -154,15 +339,32 @@ func (app *dump) writeJSON(fs fsadapter.FS, filename string, m any) error {
 	return nil
 }
 
-func (app *dump) writeText(fs fsadapter.FS, filename string, m *types.Conversation) error {
+func (app *dump) writeText(fs fsadapter.FS, filename string, m *types.Conversation) (err error) {
 	app.log.Printf("generating %s", filename)
 	f, err := fs.Create(filename)
 	if err != nil {
 		return fmt.Errorf("error writing %q: %w", filename, err)
 	}
-	defer f.Close()
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error writing %q: %w", filename, cerr)
+		}
+	}()
 
-	return m.ToText(f, app.sess.UserIndex)
+	return m.ToTextIn(f, app.sess.UserIndex, app.location())
+}
+
+// location returns the time zone for the text output.
+func (app *dump) location() *time.Location {
+	if !app.cfg.RenderTZ.IsUser() {
+		return app.cfg.RenderTZ.Location()
+	}
+	loc, err := app.sess.UserIndex.Location(app.sess.CurrentUserID())
+	if err != nil {
+		app.log.Debugf("unable to use the time zone of the current user: %s", err)
+		return app.cfg.RenderTZ.Location()
+	}
+	return loc
 }
 
 // reporter is an interface defining output functions
@@ -170,14 +372,30 @@ type reporter interface {
 	ToText(w io.Writer, ui structures.UserIndex) error
 }
 
+// columnReporter is implemented by the reporters that support the column
+// selection in the text output.
+type columnReporter interface {
+	ToTextColumns(w io.Writer, ui structures.UserIndex, cols []string) error
+}
+
+// csvReporter is implemented by the reporters that support CSV output.
+type csvReporter interface {
+	ToCSV(w io.Writer, ui structures.UserIndex) error
+}
+
 // List lists the supported entities, and writes the output to the output
 // defined in the app.cfg.
-func (app *dump) List(ctx context.Context) error {
-	f, err := createFile(app.cfg.Output.Filename)
+func (app *dump) List(ctx context.Context) (err error) {
+	f, err := createFile(app.cfg.Output.Filename, app.cfg.Gzip)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	defer func() {
+		// the compressed data is flushed on close.
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error writing %q: %w", app.cfg.Output.Filename, cerr)
+		}
+	}()
 
 	app.log.Print("retrieving data...")
 	rep, err := app.fetchEntity(ctx, app.cfg.ListFlags)
@@ -185,35 +403,79 @@ func (app *dump) List(ctx context.Context) error {
 		return err
 	}
 
+	if app.cfg.Output.IsText() {
+		// json and csv outputs are consumed by the scripts, the header would
+		// break them.
+		if err := writeWorkspaceHeader(f, app.sess.Workspace()); err != nil {
+			return err
+		}
+	}
+
 	if err := app.formatEntity(f, rep, app.cfg.Output); err != nil {
 		return err
 	}
 	return nil
 }
 
+// writeWorkspaceHeader writes the header of the text list report with the
+// workspace, that the list came from.
+func writeWorkspaceHeader(w io.Writer, wsp slackdump.Workspace) error {
+	_, err := fmt.Fprintf(w, "Workspace: %s (%s) %s\n\n", wsp.Team, wsp.TeamID, wsp.URL)
+	return err
+}
+
 // createFile creates the file, or opens the Stdout, if the filename is "-".
-// It will return an error, if things go pear-shaped.
-func createFile(filename string) (f io.WriteCloser, err error) {
+// If gz is true, the file is compressed with gzip, and has the ".gz"
+// extension, the Stdout is never compressed.  It will return an error, if
+// things go pear-shaped.
+func createFile(filename string, gz bool) (f io.WriteCloser, err error) {
 	if filename == "-" {
 		f = os.Stdout
 		return
 	}
-	return os.Create(filename)
+	if !gz {
+		return os.Create(filename)
+	}
+	if f, err = os.Create(filename + fsadapter.GzipExt); err != nil {
+		return nil, err
+	}
+	return fsadapter.NewGzipWriter(f), nil
 }
 
 // fetchEntity retrieves the data from the API according to the ListFlags.
 func (dm *dump) fetchEntity(ctx context.Context, listFlags config.ListFlags) (rep reporter, err error) {
 	switch {
 	case listFlags.Channels:
-		rep, err = dm.sess.GetChannels(ctx)
+		var chans types.Channels
+		chans, err = dm.sess.GetChannels(ctx, listFlags.ChannelTypes...)
 		if err != nil {
 			return
 		}
+		chans = chans.FilterArchived(listFlags.ArchivedFilter())
+		if listFlags.GroupByType {
+			rep = chans.GroupByType()
+		} else {
+			rep = chans
+		}
 	case listFlags.Users:
-		rep, err = dm.sess.GetUsers(ctx)
+		var users types.Users
+		users, err = dm.sess.GetUsers(ctx)
 		if err != nil {
 			return
 		}
+		if listFlags.UserPresence {
+			dm.log.Printf("fetching the presence of %d users...", len(users))
+			if err = dm.sess.GetUsersPresence(ctx, users); err != nil {
+				return
+			}
+		}
+		if dm.cfg.UserCustomFields {
+			dm.log.Printf("fetching the custom profile fields of %d users...", len(users))
+			if err = dm.sess.GetUsersCustomFields(ctx, users); err != nil {
+				return
+			}
+		}
+		rep = users
 	default:
 		err = errors.New("nothing to do")
 	}
@@ -224,10 +486,23 @@ func (dm *dump) fetchEntity(ctx context.Context, listFlags config.ListFlags) (re
 func (app *dump) formatEntity(w io.Writer, rep reporter, output config.Output) error {
 	switch output.Format {
 	case config.OutputTypeText:
-		return rep.ToText(w, app.sess.UserIndex)
+		if len(output.Columns) == 0 {
+			return rep.ToText(w, app.sess.UserIndex)
+		}
+		cr, ok := rep.(columnReporter)
+		if !ok {
+			return errors.New("column selection is not supported for this entity")
+		}
+		return cr.ToTextColumns(w, app.sess.UserIndex, output.Columns)
 	case config.OutputTypeJSON:
 		enc := json.NewEncoder(w)
 		return enc.Encode(rep)
+	case config.OutputTypeCSV:
+		cr, ok := rep.(csvReporter)
+		if !ok {
+			return errors.New("CSV output is not supported for this entity")
+		}
+		return cr.ToCSV(w, app.sess.UserIndex)
 	}
 	return errors.New("invalid output format")
 }
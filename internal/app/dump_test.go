@@ -0,0 +1,172 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func testMsg(ts string, replies ...types.Message) types.Message {
+	return types.Message{Message: slack.Message{Msg: slack.Msg{Timestamp: ts}}, ThreadReplies: replies}
+}
+
+func Test_splitByDate(t *testing.T) {
+	var (
+		jan31 = testMsg("1612137599.000100", testMsg("1612137700.000200")) // 2021-01-31 23:59:59 UTC, reply on Feb 1
+		feb01 = testMsg("1612137600.000300")                               // 2021-02-01 00:00:00 UTC
+		feb28 = testMsg("1614470400.000400")                               // 2021-02-28 00:00:00 UTC
+	)
+	t.Run("daily", func(t *testing.T) {
+		cnv := &types.Conversation{Name: "general", ID: "C01", Messages: []types.Message{jan31, feb01, feb28}}
+		dates, parts, err := splitByDate(cnv, config.SplitDaily.Layout())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"2021-01-31", "2021-02-01", "2021-02-28"}; !reflect.DeepEqual(dates, want) {
+			t.Errorf("dates = %v, want %v", dates, want)
+		}
+		want := &types.Conversation{Name: "general", ID: "C01", Messages: []types.Message{jan31}}
+		if !reflect.DeepEqual(parts["2021-01-31"], want) {
+			t.Errorf("thread replies must stay with the parent, got %v", parts["2021-01-31"])
+		}
+	})
+	t.Run("monthly", func(t *testing.T) {
+		cnv := &types.Conversation{ID: "C01", Messages: []types.Message{jan31, feb01, feb28}}
+		dates, parts, err := splitByDate(cnv, config.SplitMonthly.Layout())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"2021-01", "2021-02"}; !reflect.DeepEqual(dates, want) {
+			t.Errorf("dates = %v, want %v", dates, want)
+		}
+		if want := []types.Message{feb01, feb28}; !reflect.DeepEqual(parts["2021-02"].Messages, want) {
+			t.Errorf("messages = %v, want %v", parts["2021-02"].Messages, want)
+		}
+	})
+	t.Run("thread is not split", func(t *testing.T) {
+		cnv := &types.Conversation{ID: "C01", ThreadTS: jan31.Timestamp, Messages: []types.Message{jan31, jan31.ThreadReplies[0]}}
+		dates, parts, err := splitByDate(cnv, config.SplitDaily.Layout())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"2021-01-31"}; !reflect.DeepEqual(dates, want) {
+			t.Errorf("dates = %v, want %v", dates, want)
+		}
+		if got := parts["2021-01-31"].ThreadTS; got != jan31.Timestamp {
+			t.Errorf("ThreadTS = %q, want %q", got, jan31.Timestamp)
+		}
+	})
+	t.Run("no messages", func(t *testing.T) {
+		dates, parts, err := splitByDate(&types.Conversation{ID: "C01"}, config.SplitDaily.Layout())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dates) != 0 || len(parts) != 0 {
+			t.Errorf("expected no parts, got %v", dates)
+		}
+	})
+	t.Run("invalid timestamp", func(t *testing.T) {
+		cnv := &types.Conversation{ID: "C01", Messages: []types.Message{testMsg("bogus")}}
+		if _, _, err := splitByDate(cnv, config.SplitDaily.Layout()); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func Test_renderFilename(t *testing.T) {
+	p := config.Params{FilenameTemplate: "{{.ID}}-{{.Date}}", Output: config.Output{Split: config.SplitMonthly}}
+	tmpl, err := p.CompileTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := renderFilename(tmpl, &types.Conversation{ID: "C01"}, "2021-02"); got != "C01-2021-02" {
+		t.Errorf("renderFilename() = %q, want %q", got, "C01-2021-02")
+	}
+}
+
+func Test_dump_writeThreads(t *testing.T) {
+	var (
+		reply  = testMsg("1612137700.000200")
+		parent = testMsg("1612137599.000100", reply)
+		single = testMsg("1612137600.000300")
+	)
+	parent.ThreadTimestamp = parent.Timestamp
+	p := config.Params{FilenameTemplate: "{{.ID}}{{ if .ThreadTS}}-{{.ThreadTS}}{{end}}", Output: config.Output{ThreadFiles: true}}
+	tmpl, err := p.CompileTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	app := &dump{cfg: p}
+	cnv := &types.Conversation{Name: "general", ID: "C01", Messages: []types.Message{parent, single}}
+	if err := app.writeThreads(fsadapter.NewDirectory(dir), tmpl, cnv); err != nil {
+		t.Fatal(err)
+	}
+
+	wantFile := "C01-1612137599.000100.json"
+	if got := cnv.Messages[0]; got.ThreadFile != wantFile || len(got.ThreadReplies) != 0 {
+		t.Errorf("parent: ThreadFile = %q, replies = %d, want %q and no replies", got.ThreadFile, len(got.ThreadReplies), wantFile)
+	}
+	if got := cnv.Messages[1]; got.ThreadFile != "" {
+		t.Errorf("message without thread must not reference a file, got %q", got.ThreadFile)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, wantFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var thread types.Conversation
+	if err := json.Unmarshal(data, &thread); err != nil {
+		t.Fatal(err)
+	}
+	if thread.ThreadTS != parent.Timestamp || len(thread.Messages) != 2 {
+		t.Fatalf("thread file: ThreadTS = %q, messages = %d, want %q and 2 messages", thread.ThreadTS, len(thread.Messages), parent.Timestamp)
+	}
+	if thread.Messages[0].Timestamp != parent.Timestamp || thread.Messages[1].Timestamp != reply.Timestamp {
+		t.Errorf("thread file must contain the parent followed by the replies, got %v", thread.Messages)
+	}
+}
+
+// uploadFailFS is the filesystem, that fails to close the files, as the
+// bucket does, when the upload fails.
+type uploadFailFS struct {
+	fsadapter.FS
+}
+
+func (uploadFailFS) Create(string) (io.WriteCloser, error) {
+	return uploadFailFile{}, nil
+}
+
+type uploadFailFile struct{}
+
+func (uploadFailFile) Write(p []byte) (int, error) { return len(p), nil }
+func (uploadFailFile) Close() error                { return errors.New("upload failed") }
+
+func Test_dump_writeJSON_closeError(t *testing.T) {
+	app := &dump{}
+	if err := app.writeJSON(uploadFailFS{}, "C01.json", []string{}); err == nil {
+		t.Error("writeJSON() must return the close error")
+	}
+}
+
+func Test_writeWorkspaceHeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeWorkspaceHeader(&buf, slackdump.Workspace{TeamID: "T01", Team: "Acme", URL: "https://acme.slack.com/"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Workspace: Acme (T01) https://acme.slack.com/\n\n"; buf.String() != want {
+		t.Errorf("header = %q, want %q", buf.String(), want)
+	}
+}
@@ -1,6 +1,6 @@
 // Package emoji provides functions to dump the all slack emojis for a workspace.
 // It skips the "alias" emojis, so only original an emoji with an original name
-// is present. If you need to find the alias - lookup the index.json. The
+// is present. If you need to find the alias - lookup the emoji.json. The
 // directory structure is the following:
 //
 //	.
@@ -11,8 +11,17 @@
 //	|  +- baz.png
 //	+- index.json
 //
-// Where index.json contains the emoji index, and *.png files under emojis
-// directory are individual emojis.
+//	+- emoji.json
+//
+// Where index.json contains the emoji index, as returned by the API, and *.png
+// files under emojis directory are individual emojis.  The emoji.json is the
+// manifest that maps each emoji name to the downloaded file, and each alias to
+// the original emoji and its file.  When saving to a directory, the manifest
+// is merged with the one from the previous run, so that subsequent runs don't
+// lose any entries.
+//
+// Each emoji download is retried on rate limit, server and transient network
+// errors, up to the configured number of download retries.
 package emoji
 
 import (
@@ -23,31 +32,58 @@ import (
 	"io"
 	"net/http"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/rusq/dlog"
+	"github.com/slack-go/slack"
+
 	"github.com/rusq/slackdump/v2"
 	"github.com/rusq/slackdump/v2/auth"
 	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/internal/app/config"
+	"github.com/rusq/slackdump/v2/internal/network"
 )
 
 const (
 	numWorkers = 12       // default number of download workers.
 	emojiDir   = "emojis" // directory where all emojis are downloaded.
+
+	// defRetryAfter is the delay before the retry, if the server rate limits
+	// the request, but does not set the Retry-After header.
+	defRetryAfter = 1 * time.Second
 )
 
 var fetchFn = fetchEmoji
 
+// httpClient is the HTTP client used to download the emojis.
+var httpClient = http.DefaultClient
+
+// Stats is the emoji download statistics.
+type Stats struct {
+	Downloaded int // emojis saved
+	Aliases    int // aliases, that are not downloaded
+	Failed     int // emojis that failed to download after all retries
+}
+
 // Download saves all emojis to "emoji" subdirectory of the Output.Base directory
-// or archive.
-func Download(ctx context.Context, cfg config.Params, prov auth.Provider) error {
+// or archive.  It returns the download statistics, that are populated even if
+// the download fails.
+func Download(ctx context.Context, cfg config.Params, prov auth.Provider) (Stats, error) {
 	sess, err := slackdump.NewWithOptions(ctx, prov, cfg.Options)
 	if err != nil {
-		return err
+		return Stats{}, err
 	}
-	return download(ctx, sess, cfg.Output.Base, cfg.Emoji.FailOnError)
+	// emojis are downloaded from the CDN, but through the same proxy.
+	tr, err := slackdump.HTTPTransport(cfg.Options)
+	if err != nil {
+		return Stats{}, err
+	}
+	httpClient = &http.Client{Transport: tr, Timeout: cfg.Options.FileDownloadTimeout}
+	return download(ctx, sess, cfg.Output.Base, cfg.Options.DownloadRetries, cfg.Emoji.FailOnError)
 }
 
 //go:generate mockgen -source emoji.go -destination emoji_mock_test.go -package emoji
@@ -55,37 +91,83 @@ type emojidumper interface {
 	DumpEmojis(ctx context.Context) (map[string]string, error)
 }
 
-func download(ctx context.Context, sess emojidumper, base string, failFast bool) error {
+// download downloads the emojis to base, trying each emoji up to retries
+// times.  If failFast is true, it aborts on the first emoji that fails to
+// download.
+func download(ctx context.Context, sess emojidumper, base string, retries int, failFast bool) (Stats, error) {
 	fsa, err := fsadapter.New(base)
 	if err != nil {
-		return fmt.Errorf("unable to initialise adapter for %s: %w", base, err)
+		return Stats{}, fmt.Errorf("unable to initialise adapter for %s: %w", base, err)
 	}
 	defer fsa.Close()
 
 	emojis, err := sess.DumpEmojis(ctx)
 	if err != nil {
-		return fmt.Errorf("error during emoji dump: %w", err)
+		return Stats{}, fmt.Errorf("error during emoji dump: %w", err)
 	}
 	bIndex, err := json.Marshal(emojis)
 	if err != nil {
-		return fmt.Errorf("error marshalling emoji index: %w", err)
+		return Stats{}, fmt.Errorf("error marshalling emoji index: %w", err)
 	}
 	if err := fsa.WriteFile("index.json", bIndex, 0644); err != nil {
-		return fmt.Errorf("failed writing emoji index: %w", err)
+		return Stats{}, fmt.Errorf("failed writing emoji index: %w", err)
+	}
+
+	failed, st, err := fetch(ctx, fsa, emojis, retries, failFast)
+	if err != nil {
+		return st, err
+	}
+
+	if err := writeManifest(fsa, base, newManifest(emojis, failed)); err != nil {
+		return st, fmt.Errorf("failed writing emoji manifest: %w", err)
 	}
+	return st, nil
+}
 
-	return fetch(ctx, fsa, emojis, failFast)
+// writeManifest writes the manifest m to the fsa.  If fsa is a directory, the
+// manifest is merged with the existing one, and replaced atomically.
+func writeManifest(fsa fsadapter.FS, base string, m manifest) error {
+	if _, isDir := fsa.(fsadapter.Directory); !isDir {
+		// archives are created anew on each run, there's nothing to merge
+		// with.
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return err
+		}
+		return fsa.WriteFile(manifestFile, data, 0644)
+	}
+	filename := filepath.Join(base, manifestFile)
+	existing, err := loadManifest(filename)
+	if err != nil {
+		return err
+	}
+	existing.merge(m)
+	return saveManifest(filename, existing)
 }
 
 // fetch downloads the emojis and saves them to the fsa. It spawns numWorker
-// goroutines for getting the files. It will call fetchFn for each emoji.
-func fetch(ctx context.Context, fsa fsadapter.FS, emojis map[string]string, failFast bool) error {
+// goroutines for getting the files. It will call fetchFn for each emoji,
+// retrying it up to retries times.  It returns the map of emoji names that
+// failed to download to their errors, and the download statistics.
+func fetch(ctx context.Context, fsa fsadapter.FS, emojis map[string]string, retries int, failFast bool) (map[string]error, Stats, error) {
 	lg := dlog.FromContext(ctx)
 
+	// emojis are served by the CDN, the limiter is only needed to pause all
+	// workers, should the server rate limit the requests.
+	lim := network.NewLimiter(network.NoTier, numWorkers, 0)
+
+	ctx, cancel := context.WithCancel(ctx)
 	var (
 		emojiC  = make(chan emoji)
 		resultC = make(chan result)
 	)
+	defer func() {
+		// stop the workers and wait for them to finish, so that nothing is
+		// written to fsa after return, i.e. if failing fast.
+		cancel()
+		for range resultC {
+		}
+	}()
 
 	// Async download pipeline.
 
@@ -106,7 +188,7 @@ func fetch(ctx context.Context, fsa fsadapter.FS, emojis map[string]string, fail
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
 		go func() {
-			worker(ctx, fsa, emojiC, resultC)
+			worker(ctx, fsa, lim, retries, emojiC, resultC)
 			wg.Done()
 		}()
 	}
@@ -119,38 +201,49 @@ func fetch(ctx context.Context, fsa fsadapter.FS, emojis map[string]string, fail
 	// 4. Result processor, receives download results and logs any errors that
 	//    may have occurred.
 	var (
-		total = len(emojis)
-		count = 0
+		total  = len(emojis)
+		count  = 0
+		failed = make(map[string]error)
+		st     Stats
 	)
 	for res := range resultC {
-		if res.err != nil {
+		switch {
+		case res.err != nil:
 			if errors.Is(res.err, context.Canceled) {
-				return res.err
+				return nil, st, res.err
 			}
+			st.Failed++
 			if failFast {
-				return fmt.Errorf("failed: %q: %w", res.name, res.err)
+				return nil, st, fmt.Errorf("failed: %q: %w", res.name, res.err)
 			}
 			lg.Printf("failed: %q: %s", res.name, res.err)
+			failed[res.name] = res.err
+		case res.alias:
+			st.Aliases++
+		default:
+			st.Downloaded++
 		}
 		count++
 		lg.Printf("downloaded % 5d/%d %q", count, total, res.name)
 	}
 
-	return nil
+	return failed, st, nil
 }
 
 // emoji is an array containing name and url of the emoji.
 type emoji [2]string
 
 type result struct {
-	name string
-	err  error
+	name  string
+	alias bool // alias, that was skipped
+	err   error
 }
 
 // worker is the function that runs in a separate goroutine and downloads emoji
 // received from emojiC. The result of the operation is sent to resultC channel.
-// fn is called for each received emoji.
-func worker(ctx context.Context, fsa fsadapter.FS, emojiC <-chan emoji, resultC chan<- result) {
+// fetchFn is called for each received emoji, and retried up to retries times
+// on rate limit and transient errors.
+func worker(ctx context.Context, fsa fsadapter.FS, lim *network.Limiter, retries int, emojiC <-chan emoji, resultC chan<- result) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -160,44 +253,55 @@ func worker(ctx context.Context, fsa fsadapter.FS, emojiC <-chan emoji, resultC
 			if !more {
 				return
 			}
-			if strings.HasPrefix(emoji[1], "alias:") {
-				resultC <- result{name: emoji[0] + "(alias, skipped)"}
+			if strings.HasPrefix(emoji[1], aliasPrefix) {
+				resultC <- result{name: emoji[0] + "(alias, skipped)", alias: true}
 				break
 			}
-			err := fetchFn(ctx, fsa, emojiDir, emoji[0], emoji[1])
+			err := network.WithRetry(ctx, lim, retries, func() error {
+				return fetchFn(ctx, fsa, emojiDir, emoji[0], emoji[1])
+			})
 			resultC <- result{name: emoji[0], err: err}
 		}
 	}
 }
 
 // fetchEmoji downloads one emoji file from uri into the filename dir/name.png
-// within the filesystem adapter fsa.
+// within the filesystem adapter fsa.  The emoji is read into memory and
+// written at once, so that the ZIP archive, which accepts one file at a time,
+// is not held by the worker for the duration of the download.
 func fetchEmoji(ctx context.Context, fsa fsadapter.FS, dir string, name, uri string) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
 	if err != nil {
 		return err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	filename := path.Join(dir, name+".png")
-	wc, err := fsa.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer wc.Close()
-
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("invalid server status code: %d (%s)", resp.StatusCode, resp.Status)
+		return statusError(resp)
 	}
 
-	if _, err := io.Copy(wc, resp.Body); err != nil {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
 		return err
 	}
+	return fsa.WriteFile(path.Join(dir, name+".png"), data, 0644)
+}
 
-	return nil
+// statusError returns the error for the unsuccessful response resp.  The
+// errors are returned as slack errors, so that network.WithRetry retries the
+// rate limited requests and the server errors.
+func statusError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := defRetryAfter
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return &slack.RateLimitedError{RetryAfter: retryAfter}
+	}
+	return slack.StatusCodeError{Code: resp.StatusCode, Status: resp.Status}
 }
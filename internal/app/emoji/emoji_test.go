@@ -1,6 +1,7 @@
 package emoji
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"errors"
@@ -16,7 +17,10 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"golang.org/x/time/rate"
+
 	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/network"
 )
 
 type fetchFunc func(ctx context.Context, fsa fsadapter.FS, dir string, name string, uri string) error
@@ -71,7 +75,7 @@ func Test_fetchEmoji(t *testing.T) {
 			args{context.Background(), "test", "file"},
 			serverOptions{status: http.StatusNotFound, body: nil},
 			true,
-			true,
+			false,
 			nil,
 		},
 	}
@@ -188,7 +192,7 @@ func Test_worker(t *testing.T) {
 			var wg sync.WaitGroup
 			wg.Add(1)
 			go func() {
-				worker(tt.args.ctx, fsa, tt.args.emojiC, resultC)
+				worker(tt.args.ctx, fsa, network.AdaptiveLimiter(rate.NewLimiter(rate.Inf, 1)), 1, tt.args.emojiC, resultC)
 				wg.Done()
 			}()
 			go func() {
@@ -199,8 +203,13 @@ func Test_worker(t *testing.T) {
 			for r := range resultC {
 				results = append(results, r)
 			}
-			if !reflect.DeepEqual(results, tt.wantResult) {
-				t.Errorf("results mismatch:\n\twant=%v\n\tgot =%v", tt.wantResult, results)
+			if len(results) != len(tt.wantResult) {
+				t.Fatalf("results mismatch:\n\twant=%v\n\tgot =%v", tt.wantResult, results)
+			}
+			for i, want := range tt.wantResult {
+				if got := results[i]; got.name != want.name || !errors.Is(got.err, want.err) {
+					t.Errorf("result %d mismatch:\n\twant=%v\n\tgot =%v", i, want, got)
+				}
 			}
 		})
 	}
@@ -220,13 +229,16 @@ func Test_fetch(t *testing.T) {
 		return nil
 	})
 
-	err := fetch(context.Background(), fsa, emojis, true)
+	_, st, err := fetch(context.Background(), fsa, emojis, 1, true)
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
 	if !reflect.DeepEqual(emojis, got) {
 		t.Error("emojis!=got")
 	}
+	if want := (Stats{Downloaded: len(emojis)}); st != want {
+		t.Errorf("stats = %+v, want %+v", st, want)
+	}
 }
 
 func generateEmojis(n int) (ret map[string]string) {
@@ -334,9 +346,190 @@ func Test_download(t *testing.T) {
 			setGlobalFetchFn(tt.fetchFn)
 			sess := NewMockemojidumper(gomock.NewController(t))
 			tt.expect(sess)
-			if err := download(tt.args.ctx, sess, tt.args.output, tt.args.failFast); (err != nil) != tt.wantErr {
+			if _, err := download(tt.args.ctx, sess, tt.args.output, 1, tt.args.failFast); (err != nil) != tt.wantErr {
 				t.Errorf("download() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func Test_download_zip(t *testing.T) {
+	var (
+		hitsMu sync.Mutex
+		hits   = make(map[string]int)
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hitsMu.Lock()
+		hits[r.URL.Path]++
+		hitsMu.Unlock()
+		if r.URL.Path == "/missing.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("png:" + r.URL.Path))
+	})
+	emojisAt := func(url string) map[string]string {
+		return map[string]string{
+			"party":   url + "/party.png",
+			"shrug":   url + "/shrug.png",
+			"tada":    "alias:party",
+			"missing": url + "/missing.png",
+		}
+	}
+	setGlobalFetchFn(fetchEmoji)
+	defer setGlobalFetchFn(emptyFetchFn)
+
+	t.Run("fail fast", func(t *testing.T) {
+		// separate server, so that the requests still in flight after the
+		// failure are finished by Close and don't affect the counts below.
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		sess := NewMockemojidumper(gomock.NewController(t))
+		sess.EXPECT().DumpEmojis(gomock.Any()).Return(emojisAt(server.URL), nil)
+		if _, err := download(context.Background(), sess, filepath.Join(t.TempDir(), "emoji.zip"), 3, true); err == nil {
+			t.Error("download() expected an error")
+		}
+	})
+
+	hitsMu.Lock()
+	hits = make(map[string]int)
+	hitsMu.Unlock()
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	emojis := emojisAt(server.URL)
+
+	sess := NewMockemojidumper(gomock.NewController(t))
+	sess.EXPECT().DumpEmojis(gomock.Any()).Return(emojis, nil)
+	zipname := filepath.Join(t.TempDir(), "emoji.zip")
+	st, err := download(context.Background(), sess, zipname, 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Stats{Downloaded: 2, Aliases: 1, Failed: 1}); st != want {
+		t.Errorf("stats = %+v, want %+v", st, want)
+	}
+
+	zr, err := zip.OpenReader(zipname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	files := make(map[string]bool)
+	for _, f := range zr.File {
+		files[f.Name] = true
+	}
+	want := map[string]bool{
+		"emojis/":          true,
+		"emojis/party.png": true,
+		"emojis/shrug.png": true,
+		"index.json":       true,
+		"emoji.json":       true,
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("archive files = %v, want %v", files, want)
+	}
+	// each emoji is fetched once, aliases are not fetched.
+	if want := map[string]int{"/party.png": 1, "/shrug.png": 1, "/missing.png": 1}; !reflect.DeepEqual(hits, want) {
+		t.Errorf("server hits = %v, want %v", hits, want)
+	}
+}
+
+func Test_fetch_retry(t *testing.T) {
+	network.SetMaxAllowedWaitTime(10 * time.Millisecond)
+	defer network.SetMaxAllowedWaitTime(5 * time.Minute)
+
+	var (
+		hitsMu sync.Mutex
+		hits   = make(map[string]int)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hitsMu.Lock()
+		hits[r.URL.Path]++
+		n := hits[r.URL.Path]
+		hitsMu.Unlock()
+		switch r.URL.Path {
+		case "/flaky.png":
+			if n == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if n == 2 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		case "/down.png":
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		case "/missing.png":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("png:" + r.URL.Path))
+	}))
+	defer server.Close()
+	hitCount := func(path string) int {
+		hitsMu.Lock()
+		defer hitsMu.Unlock()
+		return hits[path]
+	}
+
+	setGlobalFetchFn(fetchEmoji)
+	defer setGlobalFetchFn(emptyFetchFn)
+
+	t.Run("fails then succeeds", func(t *testing.T) {
+		dir := t.TempDir()
+		fsa, _ := fsadapter.New(dir)
+		failed, st, err := fetch(context.Background(), fsa, map[string]string{"flaky": server.URL + "/flaky.png"}, 3, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(failed) != 0 {
+			t.Errorf("failed = %v, want none", failed)
+		}
+		if want := (Stats{Downloaded: 1}); st != want {
+			t.Errorf("stats = %+v, want %+v", st, want)
+		}
+		if n := hitCount("/flaky.png"); n != 3 {
+			t.Errorf("flaky.png fetched %d times, want 3", n)
+		}
+		if _, err := os.Stat(filepath.Join(dir, emojiDir, "flaky.png")); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("fails hard", func(t *testing.T) {
+		fsa, _ := fsadapter.New(t.TempDir())
+		failed, st, err := fetch(context.Background(), fsa, map[string]string{
+			"down":    server.URL + "/down.png",
+			"missing": server.URL + "/missing.png",
+		}, 3, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !errors.Is(failed["down"], network.ErrRetryFailed) {
+			t.Errorf("down: error = %v, want %v", failed["down"], network.ErrRetryFailed)
+		}
+		if failed["missing"] == nil {
+			t.Error("missing: expected an error")
+		}
+		if want := (Stats{Failed: 2}); st != want {
+			t.Errorf("stats = %+v, want %+v", st, want)
+		}
+		// server errors are retried, not found is not.
+		if down, missing := hitCount("/down.png"), hitCount("/missing.png"); down != 3 || missing != 1 {
+			t.Errorf("server hits: down.png=%d, missing.png=%d, want 3 and 1", down, missing)
+		}
+	})
+	t.Run("fails fast", func(t *testing.T) {
+		fsa, _ := fsadapter.New(t.TempDir())
+		_, st, err := fetch(context.Background(), fsa, map[string]string{"missing": server.URL + "/missing.png"}, 3, true)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if st.Failed != 1 {
+			t.Errorf("stats.Failed = %d, want 1", st.Failed)
+		}
+	})
+}
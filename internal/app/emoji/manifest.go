@@ -0,0 +1,151 @@
+package emoji
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// manifestFile is the name of the emoji manifest file.
+const manifestFile = "emoji.json"
+
+const aliasPrefix = "alias:"
+
+// manifest maps the emoji name to its manifest entry.
+type manifest map[string]manifestEntry
+
+// manifestEntry is the single emoji record of the manifest.
+type manifestEntry struct {
+	// URL is the source URL of the emoji, for aliases it's the "alias:target"
+	// value returned by the API.
+	URL string `json:"url"`
+	// Filename is the path of the downloaded emoji file within the base
+	// directory or archive.  For aliases, it's the file of the original
+	// emoji.  It is empty for emojis that failed to download, and for the
+	// aliases of the standard emojis.
+	Filename string `json:"filename,omitempty"`
+	// AliasOf is the name of the original emoji, if this emoji is an alias.
+	AliasOf string `json:"alias_of,omitempty"`
+}
+
+// newManifest creates the manifest for emojis, emojis that are present in
+// failed are recorded without the filename.
+func newManifest(emojis map[string]string, failed map[string]error) manifest {
+	m := make(manifest, len(emojis))
+	for name, uri := range emojis {
+		if strings.HasPrefix(uri, aliasPrefix) {
+			m[name] = manifestEntry{URL: uri, AliasOf: strings.TrimPrefix(uri, aliasPrefix)}
+			continue
+		}
+		ent := manifestEntry{URL: uri}
+		if _, ok := failed[name]; !ok {
+			ent.Filename = path.Join(emojiDir, name+".png")
+		}
+		m[name] = ent
+	}
+	m.resolveAliases()
+	return m
+}
+
+// resolveAliases sets the AliasOf of each alias to the name of the original
+// emoji, following the chains of aliases, and the Filename to the file of the
+// original emoji.  The aliases of the standard emojis, that are not in the
+// manifest, have no file.
+func (m manifest) resolveAliases() {
+	for name, ent := range m {
+		if !strings.HasPrefix(ent.URL, aliasPrefix) {
+			continue
+		}
+		ent.AliasOf = m.original(name)
+		ent.Filename = ""
+		if orig, ok := m[ent.AliasOf]; ok && !strings.HasPrefix(orig.URL, aliasPrefix) {
+			ent.Filename = orig.Filename
+		}
+		m[name] = ent
+	}
+}
+
+// original follows the aliases starting from the emoji name, and returns the
+// name of the original emoji.  If the aliases form a loop, the last alias
+// before the loop is returned.
+func (m manifest) original(name string) string {
+	seen := make(map[string]bool)
+	for {
+		seen[name] = true
+		ent, ok := m[name]
+		if !ok || !strings.HasPrefix(ent.URL, aliasPrefix) {
+			return name
+		}
+		target := strings.TrimPrefix(ent.URL, aliasPrefix)
+		if seen[target] {
+			return name
+		}
+		name = target
+	}
+}
+
+// merge merges the newer manifest into m.  Entries of m that are not present
+// in newer are retained.  If the entry in newer has no filename (i.e. the
+// download has failed this time), but the earlier entry for the same URL had
+// one, the earlier filename is kept.  Aliases are resolved against the merged
+// entries.
+func (m manifest) merge(newer manifest) {
+	for name, ent := range newer {
+		if old, ok := m[name]; ok && ent.Filename == "" && ent.AliasOf == "" && old.URL == ent.URL {
+			ent.Filename = old.Filename
+		}
+		m[name] = ent
+	}
+	m.resolveAliases()
+}
+
+// loadManifest loads the manifest from the file.  If the file does not
+// exist, it returns an empty manifest.
+func loadManifest(filename string) (manifest, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return make(manifest), nil
+		}
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", filename, err)
+	}
+	if m == nil {
+		m = make(manifest)
+	}
+	return m, nil
+}
+
+// saveManifest atomically writes the manifest to filename, by writing it to a
+// temporary file in the same directory first and renaming it afterwards, so
+// that the manifest remains valid even if the process is interrupted.
+func saveManifest(filename string, m manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tf, err := os.CreateTemp(dir, manifestFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tf.Name()) // no-op after the successful rename.
+	if _, err := tf.Write(data); err != nil {
+		tf.Close()
+		return err
+	}
+	if err := tf.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tf.Name(), filename)
+}
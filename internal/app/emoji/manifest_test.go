@@ -0,0 +1,96 @@
+package emoji
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_download_manifestMerge(t *testing.T) {
+	dir := t.TempDir()
+
+	run := func(t *testing.T, fn fetchFunc, emojis map[string]string) {
+		t.Helper()
+		setGlobalFetchFn(fn)
+		sess := NewMockemojidumper(gomock.NewController(t))
+		sess.EXPECT().DumpEmojis(gomock.Any()).Return(emojis, nil)
+		_, err := download(context.Background(), sess, dir, 1, false)
+		require.NoError(t, err)
+	}
+
+	// first run: one emoji and an alias.
+	run(t, emptyFetchFn, map[string]string{
+		"party": "https://emoji.slack.com/party.png",
+		"tada":  "alias:party",
+	})
+	// second run: a new emoji, and party fails to download this time.
+	run(t, func(ctx context.Context, fsa fsadapter.FS, dir, name, uri string) error {
+		if name == "party" {
+			return os.ErrDeadlineExceeded
+		}
+		return nil
+	}, map[string]string{
+		"party": "https://emoji.slack.com/party.png",
+		"shrug": "https://emoji.slack.com/shrug.png",
+	})
+
+	got, err := loadManifest(filepath.Join(dir, manifestFile))
+	require.NoError(t, err)
+	want := manifest{
+		"party": {URL: "https://emoji.slack.com/party.png", Filename: "emojis/party.png"},
+		"tada":  {URL: "alias:party", Filename: "emojis/party.png", AliasOf: "party"},
+		"shrug": {URL: "https://emoji.slack.com/shrug.png", Filename: "emojis/shrug.png"},
+	}
+	assert.Equal(t, want, got)
+
+	// no temporary files left behind.
+	leftovers, err := filepath.Glob(filepath.Join(dir, manifestFile+".*"))
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+func Test_manifest_merge(t *testing.T) {
+	m := manifest{
+		"a": {URL: "https://a", Filename: "emojis/a.png"},
+		"b": {URL: "https://b", Filename: "emojis/b.png"},
+	}
+	m.merge(manifest{
+		"a": {URL: "https://a2"}, // url changed and download failed
+		"b": {URL: "https://b"},  // download failed
+		"c": {URL: "alias:a", AliasOf: "a"},
+	})
+	assert.Equal(t, manifest{
+		"a": {URL: "https://a2"},
+		"b": {URL: "https://b", Filename: "emojis/b.png"},
+		"c": {URL: "alias:a", AliasOf: "a"},
+	}, m)
+}
+
+func Test_newManifest_aliases(t *testing.T) {
+	m := newManifest(map[string]string{
+		"party":  "https://emoji.slack.com/party.png",
+		"tada":   "alias:party",
+		"fiesta": "alias:tada", // alias of an alias
+		"broken": "https://emoji.slack.com/broken.png",
+		"oops":   "alias:broken",
+		"thumbs": "alias:+1", // standard emoji
+		"loop1":  "alias:loop2",
+		"loop2":  "alias:loop1",
+	}, map[string]error{"broken": os.ErrDeadlineExceeded})
+	assert.Equal(t, manifest{
+		"party":  {URL: "https://emoji.slack.com/party.png", Filename: "emojis/party.png"},
+		"tada":   {URL: "alias:party", Filename: "emojis/party.png", AliasOf: "party"},
+		"fiesta": {URL: "alias:tada", Filename: "emojis/party.png", AliasOf: "party"},
+		"broken": {URL: "https://emoji.slack.com/broken.png"},
+		"oops":   {URL: "alias:broken", AliasOf: "broken"},
+		"thumbs": {URL: "alias:+1", AliasOf: "+1"},
+		"loop1":  {URL: "alias:loop2", AliasOf: "loop2"},
+		"loop2":  {URL: "alias:loop1", AliasOf: "loop1"},
+	}, m)
+}
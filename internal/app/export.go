@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"runtime/trace"
-	"time"
 
 	"github.com/rusq/slackdump/v2"
 	"github.com/rusq/slackdump/v2/auth"
@@ -19,11 +19,12 @@ import (
 const defExportType = export.TStandard
 
 // Export performs the full export of slack workspace in slack export compatible
-// format.
-func Export(ctx context.Context, cfg config.Params, prov auth.Provider) error {
+// format.  The export statistics are recorded in rs.
+func Export(ctx context.Context, cfg config.Params, prov auth.Provider, rs *RunSummary) (err error) {
 	ctx, task := trace.NewTask(ctx, "Export")
 	defer task.End()
 
+	rs.Mode = modeExport
 	if cfg.ExportName == "" {
 		return errors.New("export directory or filename not specified")
 	}
@@ -32,6 +33,8 @@ func Export(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 	if err != nil {
 		return err
 	}
+	wsp := sess.Workspace()
+	rs.Workspace = &wsp
 
 	fs, err := fsadapter.New(cfg.ExportName)
 	if err != nil {
@@ -40,30 +43,114 @@ func Export(ctx context.Context, cfg config.Params, prov auth.Provider) error {
 	}
 	defer func() {
 		cfg.Logger().Debugf("Export:  closing file system")
-		if err := fs.Close(); err != nil {
-			cfg.Logger().Printf("Export:  error closing filesystem")
+		// the archive in the bucket is uploaded on close.
+		if cerr := fs.Close(); cerr != nil {
+			cfg.Logger().Printf("Export:  error closing filesystem: %s", cerr)
+			if err == nil {
+				err = fmt.Errorf("failed to close the filesystem: %w", cerr)
+			}
 		}
 	}()
 
 	cfg.Logger().Debugf("Export:  filesystem: %s", fs)
 	cfg.Logger().Printf("Export:  staring export to: %s", fs)
 
-	e := export.New(sess, fs, makeExportOptions(cfg))
-	if err := e.Run(ctx); err != nil {
-		return err
+	expCfg := makeExportOptions(cfg)
+	if cfg.StateFile != "" {
+		state, err := export.LoadState(cfg.StateFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the export state: %w", err)
+		}
+		expCfg.State = state
+	}
+	var redactMap io.WriteCloser
+	if cfg.RedactMap != "" {
+		if redactMap, err = createRedactMap(cfg.RedactMap, cfg.RedactMapEncrypt); err != nil {
+			return fmt.Errorf("failed to create the redaction map: %w", err)
+		}
+		expCfg.RedactMap = redactMap
+	}
+
+	e := export.New(sess, fs, expCfg)
+	err = e.Run(ctx)
+	if redactMap != nil {
+		// the final block of the encrypted map is written on close.
+		if cerr := redactMap.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to write the redaction map: %w", cerr)
+		}
 	}
 
-	return nil
+	st := e.Stats()
+	rs.Channels = st.Channels
+	rs.Messages = st.Messages
+	rs.addFileStats(st.Files)
+	for _, e := range st.Errors {
+		rs.addError(e)
+	}
+
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+	if expCfg.State != nil {
+		// the state is saved only if the export succeeded, so that the
+		// messages of the failed run would be fetched again, or if it ran
+		// out of time or was interrupted, in which case the state has only
+		// the conversations that were exported in full, and the next run
+		// continues from there.  Same goes for the conversations, skipped
+		// due to the errors.
+		if err := expCfg.State.Save(cfg.StateFile); err != nil {
+			return fmt.Errorf("failed to save the export state: %w", err)
+		}
+	}
+	if err == nil && len(st.Errors) > 0 {
+		err = fmt.Errorf("%w: %d conversation(s) were skipped, see the log for details", errFailed, len(st.Errors))
+	}
+	return err
 }
 
 func makeExportOptions(cfg config.Params) export.Options {
+	oldest, latest := cfg.TimeRange()
 	expCfg := export.Options{
-		Oldest:      time.Time(cfg.Oldest),
-		Latest:      time.Time(cfg.Latest),
+		Oldest:      oldest,
+		Latest:      latest,
+		DateFilters: cfg.TimeRanges(),
 		Logger:      cfg.Logger(),
 		List:        cfg.Input.List,
 		Type:        cfg.ExportType,
 		ExportToken: cfg.ExportToken,
+
+		StripFileURLs: cfg.StripFileURLs,
+
+		ResolveMentions: cfg.ResolveMentions,
+		IncludePins:     cfg.ExportPins,
+		IncludeCanvases: cfg.IncludeCanvases,
+		EditMarkers:     cfg.EditMarkers,
+		DownloadAvatars: cfg.ExportAvatars,
+
+		UserCustomFields: cfg.UserCustomFields,
+
+		ChannelTypes:    cfg.ListFlags.ChannelTypes,
+		IncludeDMs:      cfg.IncludeDMs,
+		IncludeGroupDMs: cfg.IncludeGroupDMs,
+		Archived:        cfg.ListFlags.ArchivedFilter(),
+		ContinueOnError: cfg.ContinueOnError,
+
+		Workers:           cfg.Options.Workers,
+		SkipExistingFiles: cfg.Options.SkipExistingFiles,
+		FileTypes:         cfg.Options.FileTypes,
+		MaxFileSize:       cfg.Options.MaxFileSize,
+		MaxTotalBytes:     cfg.Options.MaxTotalBytes,
+		PreserveFileTimes: cfg.Options.PreserveFileTimes,
+		DedupByContent:    cfg.Options.DedupByContent,
+		OnFileProgress:    cfg.Options.OnFileProgress,
+		Gzip:              cfg.Gzip,
+
+		DownloadBytesPerSec: cfg.Options.DownloadBytesPerSec,
+
+		Location:     cfg.RenderTZ.Location(),
+		UserLocation: cfg.RenderTZ.IsUser(),
+
+		Redact: cfg.Redact,
 	}
 	// if files requested, but the type is no-download, we need to switch
 	// export type to the default export type, so that the files would
@@ -73,3 +160,13 @@ func makeExportOptions(cfg config.Params) export.Options {
 	}
 	return expCfg
 }
+
+// createRedactMap creates the redaction map file.  If encrypt is true, the
+// file is encrypted in the same way as the stored credentials, i.e. with the
+// passphrase, if it was set.
+func createRedactMap(filename string, encrypt bool) (io.WriteCloser, error) {
+	if encrypt {
+		return filer.Create(filename)
+	}
+	return plainFile{}.Create(filename)
+}
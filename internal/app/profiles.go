@@ -0,0 +1,109 @@
+package app
+
+// In this file: named authentication profiles.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rusq/slackdump/v2/auth"
+)
+
+const (
+	credsPrefix = "provider-" // prefix of the named profile credentials file
+	credsExt    = ".bin"
+
+	// DefaultProfile is the name of the profile, that is stored in the
+	// credentials file without a workspace name.
+	DefaultProfile = "default"
+)
+
+// reProfile matches the valid profile names, which are Slack workspace names.
+var reProfile = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+var errInvalidProfile = errors.New("invalid workspace name")
+
+// credsFilename returns the credentials file of the workspace profile
+// within cacheDir.  Empty workspace means the default profile.
+func credsFilename(cacheDir string, workspace string) (string, error) {
+	workspace, err := auth.SanitizeWorkspace(strings.ToLower(workspace))
+	if err != nil {
+		return "", err
+	}
+	if workspace == "" || workspace == DefaultProfile {
+		return filepath.Join(cacheDir, credsFile), nil
+	}
+	if !reProfile.MatchString(workspace) {
+		return "", fmt.Errorf("%w: %q", errInvalidProfile, workspace)
+	}
+	return filepath.Join(cacheDir, credsPrefix+workspace+credsExt), nil
+}
+
+// Profiles returns the sorted names of the workspace profiles, that have the
+// stored credentials in cacheDir.  The credentials stored without the
+// workspace name are listed as DefaultProfile.
+func Profiles(cacheDir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(cacheDir, credsPrefix+"*"+credsExt))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, m := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), credsPrefix), credsExt)
+		if reProfile.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	if isExistingFile(filepath.Join(cacheDir, credsFile)) {
+		names = append(names, DefaultProfile)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// resolveProfile returns the profile, that is used, if the workspace is not
+// specified:  the default profile, if it exists, or the only named profile.
+// If there are several named profiles, and no default one, the credentials
+// will be requested, and stored as the default profile.
+func resolveProfile(cacheDir string) (string, error) {
+	names, err := Profiles(cacheDir)
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 1 {
+		return names[0], nil
+	}
+	return DefaultProfile, nil
+}
+
+// ListProfiles writes the list of profiles to w, marking the one that is used
+// if the workspace is not specified.
+func ListProfiles(w io.Writer, cacheDir string) error {
+	names, err := Profiles(cacheDir)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		_, err := fmt.Fprintln(w, "No stored workspaces.")
+		return err
+	}
+	current, err := resolveProfile(cacheDir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		mark := " "
+		if name == current {
+			mark = "*"
+		}
+		if _, err := fmt.Fprintf(w, "%s %s\n", mark, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
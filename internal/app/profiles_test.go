@@ -0,0 +1,83 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_credsFilename(t *testing.T) {
+	tests := []struct {
+		name      string
+		workspace string
+		want      string
+		wantErr   bool
+	}{
+		{"empty is default", "", credsFile, false},
+		{"default", "default", credsFile, false},
+		{"named", "acme", "provider-acme.bin", false},
+		{"url", "https://Acme.slack.com", "provider-acme.bin", false},
+		{"path traversal", "../acme", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := credsFilename("cache", tt.workspace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("credsFilename() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := filepath.Join("cache", tt.want); got != want {
+				t.Errorf("credsFilename() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	touch := func(name string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// single named profile is used by default
+	touch("provider-personal.bin")
+	touch("provider-.bin") // invalid, ignored
+	if got, _ := Profiles(dir); !reflect.DeepEqual(got, []string{"personal"}) {
+		t.Errorf("Profiles() = %v", got)
+	}
+	if got, _ := resolveProfile(dir); got != "personal" {
+		t.Errorf("resolveProfile() = %q, want %q", got, "personal")
+	}
+
+	// several named profiles: default is used
+	touch("provider-acme.bin")
+	if got, _ := resolveProfile(dir); got != DefaultProfile {
+		t.Errorf("resolveProfile() = %q, want %q", got, DefaultProfile)
+	}
+
+	touch(credsFile)
+	if got, _ := Profiles(dir); !reflect.DeepEqual(got, []string{"acme", DefaultProfile, "personal"}) {
+		t.Errorf("Profiles() = %v", got)
+	}
+	var buf strings.Builder
+	if err := ListProfiles(&buf, dir); err != nil {
+		t.Fatal(err)
+	}
+	if want := "  acme\n* default\n  personal\n"; buf.String() != want {
+		t.Errorf("ListProfiles() = %q, want %q", buf.String(), want)
+	}
+
+	// AuthReset removes only the selected profile
+	if err := AuthReset(dir, "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := Profiles(dir); !reflect.DeepEqual(got, []string{DefaultProfile, "personal"}) {
+		t.Errorf("Profiles() after reset = %v", got)
+	}
+}
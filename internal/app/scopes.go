@@ -0,0 +1,124 @@
+package app
+
+// In this file: OAuth scope checks for the bot and user tokens.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+)
+
+// chanTypeScopes maps the channel types to the scope prefixes, i.e.
+// "channels" for "channels:read" and "channels:history".
+var chanTypeScopes = map[string]string{
+	"public_channel":  "channels",
+	"private_channel": "groups",
+	"im":              "im",
+	"mpim":            "mpim",
+}
+
+// requiredScopes returns the sorted list of the OAuth scopes, that are
+// required to run in the mode, set by cfg.
+func requiredScopes(cfg config.Params) []string {
+	set := make(map[string]bool)
+	chanTypes := cfg.ListFlags.ChannelTypes
+	if len(chanTypes) == 0 {
+		chanTypes = []string{"public_channel", "private_channel", "im", "mpim"}
+	}
+	addChanScopes := func(suffix string) {
+		for _, ct := range chanTypes {
+			if prefix, ok := chanTypeScopes[ct]; ok {
+				set[prefix+":"+suffix] = true
+			}
+		}
+	}
+
+	switch {
+	case cfg.Emoji.Enabled:
+		set["emoji:read"] = true
+	case cfg.ListFlags.FlagsPresent():
+		if cfg.ListFlags.Users {
+			set["users:read"] = true
+		}
+		if cfg.ListFlags.Channels {
+			addChanScopes("read")
+		}
+	default:
+		// dump or export
+		addChanScopes("read")
+		addChanScopes("history")
+		if !cfg.Options.NoUserCache {
+			set["users:read"] = true
+		}
+		if cfg.Options.DumpFiles || (cfg.ExportName != "" && cfg.ExportType > export.TNoDownload) {
+			set["files:read"] = true
+		}
+	}
+
+	scopes := make([]string, 0, len(set))
+	for s := range set {
+		scopes = append(scopes, s)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// MissingScopes returns the OAuth scopes, that are required to run in the
+// mode set by cfg, but are not granted to the token.  granted is the list of
+// the scopes reported by Slack for the token.  It returns nil for the tokens,
+// that are not limited by scopes, i.e. client tokens, or if the granted
+// scopes are unknown.
+func MissingScopes(cfg config.Params, token string, granted []string) []string {
+	if !auth.KindOf(token).HasScopes() || len(granted) == 0 {
+		return nil
+	}
+	have := make(map[string]bool, len(granted))
+	for _, s := range granted {
+		have[s] = true
+	}
+	var missing []string
+	for _, s := range requiredScopes(cfg) {
+		if !have[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// scopeErrors are the Slack errors, that are returned when the token lacks the
+// permissions.
+var scopeErrors = []string{"missing_scope", "method_not_allowed_for_token_type", "not_allowed_token_type"}
+
+// ScopeError converts the Slack permission errors into the error that names
+// the missing scopes, if known.  Other errors are returned as is.
+func ScopeError(err error, token string, missing []string) error {
+	var ser slack.SlackErrorResponse
+	if err == nil || !errors.As(err, &ser) {
+		return err
+	}
+	for _, se := range scopeErrors {
+		if ser.Err != se {
+			continue
+		}
+		kind := auth.KindOf(token)
+		if len(missing) == 0 {
+			return fmt.Errorf("the %s token does not have the permissions required for this operation (error: %w)", nvlKind(kind), err)
+		}
+		return fmt.Errorf("the %s token is missing the following scopes: %s, add them to the Slack app and reinstall it (error: %w)", nvlKind(kind), strings.Join(missing, ", "), err)
+	}
+	return err
+}
+
+func nvlKind(k auth.TokenKind) string {
+	if k == auth.TokenUnknown {
+		return "provided"
+	}
+	return string(k)
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/export"
+	"github.com/rusq/slackdump/v2/internal/app/config"
+)
+
+func Test_requiredScopes(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Params
+		want []string
+	}{
+		{
+			"user list",
+			config.Params{ListFlags: config.ListFlags{Users: true}},
+			[]string{"users:read"},
+		},
+		{
+			"public channel list",
+			config.Params{ListFlags: config.ListFlags{Channels: true, ChannelTypes: []string{"public_channel"}}},
+			[]string{"channels:read"},
+		},
+		{
+			"emoji",
+			config.Params{Emoji: config.EmojiParams{Enabled: true}},
+			[]string{"emoji:read"},
+		},
+		{
+			"export of ims with files",
+			config.Params{ExportName: "x.zip", ExportType: export.TStandard, ListFlags: config.ListFlags{ChannelTypes: []string{"im"}}},
+			[]string{"files:read", "im:history", "im:read", "users:read"},
+		},
+		{
+			"dump without users and files",
+			config.Params{ListFlags: config.ListFlags{ChannelTypes: []string{"mpim"}}, Options: slackdump.Options{NoUserCache: true}},
+			[]string{"mpim:history", "mpim:read"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requiredScopes(tt.cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requiredScopes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingScopes(t *testing.T) {
+	cfg := config.Params{ListFlags: config.ListFlags{Users: true, Channels: true, ChannelTypes: []string{"public_channel"}}}
+	granted := []string{"channels:read", "chat:write"}
+
+	if got := MissingScopes(cfg, "xoxb-123", granted); !reflect.DeepEqual(got, []string{"users:read"}) {
+		t.Errorf("MissingScopes() = %v, want [users:read]", got)
+	}
+	if got := MissingScopes(cfg, "xoxc-123", granted); got != nil {
+		t.Errorf("MissingScopes() = %v, want nil for the client token", got)
+	}
+	if got := MissingScopes(cfg, "xoxp-123", nil); got != nil {
+		t.Errorf("MissingScopes() = %v, want nil for the unknown scopes", got)
+	}
+}
+
+func TestScopeError(t *testing.T) {
+	scopeErr := fmt.Errorf("wrapped: %w", slack.SlackErrorResponse{Err: "missing_scope"})
+
+	err := ScopeError(scopeErr, "xoxb-123", []string{"files:read"})
+	if !strings.Contains(err.Error(), "bot token is missing the following scopes: files:read") {
+		t.Errorf("unexpected error message: %s", err)
+	}
+	if !errors.Is(err, scopeErr) {
+		t.Error("original error is not wrapped")
+	}
+
+	other := errors.New("other")
+	if err := ScopeError(other, "xoxb-123", []string{"files:read"}); err != other {
+		t.Errorf("ScopeError() = %v, want the original error", err)
+	}
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/internal/app/emoji"
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+// run modes, as reported in the RunSummary.
+const (
+	modeDump   = "dump"
+	modeList   = "list"
+	modeExport = "export"
+	modeEmoji  = "emoji"
+	modeDryRun = "dry-run"
+)
+
+// notifyTimeout is the timeout of the notification webhook request.
+const notifyTimeout = 10 * time.Second
+
+// RunSummary is the summary of the slackdump run, that is written at the end
+// of the run, if requested.  It is intended to be consumed by scripts, i.e.
+// to alert on failures of the scheduled archiving jobs.
+type RunSummary struct {
+	Mode            string           `json:"mode"`
+	Success         bool             `json:"success"`
+	Started         time.Time        `json:"started"`
+	ElapsedSeconds  float64          `json:"elapsed_seconds"`
+	Channels        int              `json:"channels"`         // conversations processed
+	Messages        int              `json:"messages"`         // messages, including thread replies
+	FilesDownloaded int              `json:"files_downloaded"` // files saved
+	FilesSkipped    int              `json:"files_skipped"`    // files rejected by filters, or external
+	FilesFailed     int              `json:"files_failed"`     // files that failed to download
+	BytesWritten    int64            `json:"bytes_written"`    // bytes of the downloaded files
+	Limiters        []LimiterSummary `json:"limiters,omitempty"`
+	Errors          []string         `json:"errors,omitempty"`
+
+	// Workspace is the workspace, that the data came from, it is empty, if
+	// the run failed before logging in.
+	Workspace *slackdump.Workspace `json:"workspace,omitempty"`
+}
+
+// LimiterSummary is the rate limiter statistics of a tier.
+type LimiterSummary struct {
+	Tier           string  `json:"tier"`
+	Waits          int64   `json:"waits"`           // API calls made
+	DelaySeconds   float64 `json:"delay_seconds"`   // total time waited on the limiter
+	RateLimited    int64   `json:"rate_limited"`    // rate limit errors returned by Slack
+	BackoffSeconds float64 `json:"backoff_seconds"` // total time slept before the retries
+}
+
+// addLimiterStats adds the rate limiter statistics to the summary.
+func (rs *RunSummary) addLimiterStats(stats []network.TierStats) {
+	for _, ts := range stats {
+		rs.Limiters = append(rs.Limiters, LimiterSummary{
+			Tier:           ts.Tier.String(),
+			Waits:          ts.Waits,
+			DelaySeconds:   ts.Delay.Seconds(),
+			RateLimited:    ts.RateLimited,
+			BackoffSeconds: ts.Backoff.Seconds(),
+		})
+	}
+}
+
+// addFileStats adds the file download statistics to the summary.
+func (rs *RunSummary) addFileStats(st downloader.Stats) {
+	rs.FilesDownloaded += st.Saved
+	rs.FilesSkipped += st.Skipped
+	rs.FilesFailed += st.Failed
+	rs.BytesWritten += st.Bytes
+}
+
+// addEmojiStats adds the emoji download statistics to the summary, emojis
+// are accounted as files.
+func (rs *RunSummary) addEmojiStats(st emoji.Stats) {
+	rs.FilesDownloaded += st.Downloaded
+	rs.FilesFailed += st.Failed
+}
+
+// addError adds the error to the summary, nil errors are ignored.
+func (rs *RunSummary) addError(err error) {
+	if err == nil {
+		return
+	}
+	rs.Errors = append(rs.Errors, err.Error())
+}
+
+// finish finalises the summary with the run error err.
+func (rs *RunSummary) finish(elapsed time.Duration, err error) {
+	rs.ElapsedSeconds = elapsed.Seconds()
+	rs.addError(err)
+	rs.Success = err == nil
+}
+
+// Save writes the summary as JSON to the file filename.
+func (rs *RunSummary) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rs); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Notify POSTs the summary as JSON to the webhook URL, using the transport
+// tr.  Any response with the status code other than 2xx is an error.  The
+// returned errors do not contain the URL, as it usually contains the secret
+// token.
+func (rs *RunSummary) Notify(ctx context.Context, webhook string, tr http.RoundTripper) error {
+	data, err := json.Marshal(rs)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	cl := http.Client{Transport: tr}
+	resp, err := cl.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			return uerr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned: %s", resp.Status)
+	}
+	return nil
+}
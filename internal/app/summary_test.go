@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/internal/app/emoji"
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+func TestRunSummary_Save(t *testing.T) {
+	start := time.Date(2023, 1, 31, 12, 0, 0, 0, time.UTC)
+	rs := RunSummary{Mode: modeExport, Started: start, Channels: 2, Messages: 10}
+	rs.Workspace = &slackdump.Workspace{TeamID: "T01", Team: "Acme", URL: "https://acme.slack.com/"}
+	rs.addFileStats(downloader.Stats{Saved: 3, Skipped: 1, Failed: 1, Bytes: 300})
+	rs.addFileStats(downloader.Stats{Saved: 1, Bytes: 100})
+	rs.addLimiterStats([]network.TierStats{{Tier: network.Tier3, Waits: 5, Delay: 2 * time.Second, RateLimited: 1, Backoff: 3 * time.Second}})
+	rs.finish(1500*time.Millisecond, errors.New("boom"))
+
+	filename := filepath.Join(t.TempDir(), "summary.json")
+	if err := rs.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got RunSummary
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := RunSummary{
+		Mode:            modeExport,
+		Success:         false,
+		Started:         start,
+		ElapsedSeconds:  1.5,
+		Channels:        2,
+		Messages:        10,
+		FilesDownloaded: 4,
+		FilesSkipped:    1,
+		FilesFailed:     1,
+		BytesWritten:    400,
+		Limiters:        []LimiterSummary{{Tier: "tier3", Waits: 5, DelaySeconds: 2, RateLimited: 1, BackoffSeconds: 3}},
+		Errors:          []string{"boom"},
+		Workspace:       &slackdump.Workspace{TeamID: "T01", Team: "Acme", URL: "https://acme.slack.com/"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("saved summary = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunSummary_finish(t *testing.T) {
+	var rs RunSummary
+	rs.finish(time.Second, nil)
+	if !rs.Success || len(rs.Errors) != 0 {
+		t.Errorf("finish(nil): Success = %v, Errors = %v", rs.Success, rs.Errors)
+	}
+}
+
+func TestRunSummary_addEmojiStats(t *testing.T) {
+	rs := RunSummary{Mode: modeEmoji}
+	rs.addEmojiStats(emoji.Stats{Downloaded: 10, Aliases: 3, Failed: 2})
+	if rs.FilesDownloaded != 10 || rs.FilesFailed != 2 || rs.FilesSkipped != 0 {
+		t.Errorf("addEmojiStats: downloaded = %d, failed = %d, skipped = %d, want 10, 2, 0", rs.FilesDownloaded, rs.FilesFailed, rs.FilesSkipped)
+	}
+}
+
+func TestRunSummary_Notify(t *testing.T) {
+	rs := RunSummary{Mode: modeExport, Success: true, Channels: 2, Messages: 10}
+	t.Run("posts the summary", func(t *testing.T) {
+		var got RunSummary
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				t.Errorf("method = %s, want POST", r.Method)
+			}
+			if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+				t.Error(err)
+			}
+		}))
+		defer srv.Close()
+		if err := rs.Notify(context.Background(), srv.URL+"/hook", http.DefaultTransport); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, rs) {
+			t.Errorf("posted summary = %+v, want %+v", got, rs)
+		}
+	})
+	t.Run("error status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer srv.Close()
+		if err := rs.Notify(context.Background(), srv.URL, http.DefaultTransport); err == nil {
+			t.Error("expected an error")
+		}
+	})
+	t.Run("connection error does not leak the url", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		webhook := srv.URL + "/secret-token"
+		srv.Close()
+		err := rs.Notify(context.Background(), webhook, http.DefaultTransport)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if strings.Contains(err.Error(), "secret-token") {
+			t.Errorf("error contains the url: %s", err)
+		}
+	})
+}
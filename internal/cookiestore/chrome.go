@@ -0,0 +1,200 @@
+package cookiestore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const chromeDefaultProfile = "Default"
+
+var (
+	prefixV10 = []byte("v10")
+	prefixV11 = []byte("v11")
+	prefixV20 = []byte("v20")
+
+	errUnsupportedEncryption = errors.New("unsupported cookie encryption")
+)
+
+// decrypter decrypts the encrypted_value of the Chrome cookie.
+type decrypter interface {
+	decrypt(data []byte) ([]byte, error)
+}
+
+// chromeCookies reads the cookies of the domain from the Chrome profile.
+func chromeCookies(browser string, profile string, domain string) ([]*http.Cookie, error) {
+	if profile == "" {
+		dir, err := chromeUserDataDir(browser)
+		if err != nil {
+			return nil, err
+		}
+		profile = filepath.Join(dir, chromeDefaultProfile)
+	}
+	filename, err := dbInProfile(profile, filepath.Join("Network", "Cookies"), "Cookies")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", browser, err)
+	}
+	db, closeFn, err := openDB(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer closeFn()
+	dec, err := newDecrypter(browser, profile)
+	if err != nil {
+		return nil, err
+	}
+	return readChrome(db, dec, domain)
+}
+
+// readChrome reads the cookies of the domain from the Chrome cookie
+// database, decrypting the values with dec.
+func readChrome(db *sql.DB, dec decrypter, domain string) ([]*http.Cookie, error) {
+	version := chromeDBVersion(db)
+
+	rows, err := db.Query("SELECT host_key, name, value, encrypted_value, path, expires_utc, is_secure, is_httponly FROM cookies")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cookies []*http.Cookie
+	for rows.Next() {
+		var (
+			c                http.Cookie
+			enc              []byte
+			expires          int64
+			secure, httpOnly int64
+		)
+		if err := rows.Scan(&c.Domain, &c.Name, &c.Value, &enc, &c.Path, &expires, &secure, &httpOnly); err != nil {
+			return nil, err
+		}
+		if !matchDomain(c.Domain, domain) {
+			continue
+		}
+		if c.Value == "" && len(enc) > 0 {
+			plain, err := dec.decrypt(enc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt cookie %q: %w", c.Name, err)
+			}
+			if version >= 24 {
+				// the value is prefixed with the SHA256 of the host.
+				if len(plain) < sha256Sz {
+					return nil, fmt.Errorf("failed to decrypt cookie %q: value is too short", c.Name)
+				}
+				plain = plain[sha256Sz:]
+			}
+			c.Value = string(plain)
+		}
+		c.Expires = chromeTime(expires)
+		c.Secure = secure == 1
+		c.HttpOnly = httpOnly == 1
+		cookies = append(cookies, &c)
+	}
+	return cookies, rows.Err()
+}
+
+const sha256Sz = 32
+
+// chromeDBVersion returns the version of the cookie database from the meta
+// table, or 0, if it's unknown.
+func chromeDBVersion(db *sql.DB) int {
+	var value string
+	if err := db.QueryRow("SELECT value FROM meta WHERE key = 'version'").Scan(&value); err != nil {
+		return 0
+	}
+	version, _ := strconv.Atoi(value)
+	return version
+}
+
+// chromeEpochDelta is the number of seconds between 1601-01-01, the start of
+// the Chrome time, and the Unix epoch.
+const chromeEpochDelta = 11644473600
+
+// chromeTime converts the Chrome time, which is the number of microseconds
+// since 1601-01-01, to time.
+func chromeTime(us int64) time.Time {
+	if us == 0 {
+		return time.Time{}
+	}
+	return time.Unix(us/1e6-chromeEpochDelta, (us%1e6)*1e3).UTC()
+}
+
+// cbcDecrypter decrypts the values encrypted with AES-128-CBC, as on Linux
+// and macOS.  The key is derived from the password, and is initialised on
+// the first use, as obtaining the password may require user interaction.
+type cbcDecrypter struct {
+	iterations int
+	password   map[string]func() ([]byte, error) // password by prefix
+	keys       map[string][]byte
+}
+
+func (d *cbcDecrypter) decrypt(data []byte) ([]byte, error) {
+	if len(data) < 3 {
+		return nil, errUnsupportedEncryption
+	}
+	prefix := string(data[:3])
+	key, ok := d.keys[prefix]
+	if !ok {
+		pwFn, ok := d.password[prefix]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errUnsupportedEncryption, prefix)
+		}
+		pw, err := pwFn()
+		if err != nil {
+			return nil, err
+		}
+		key = pbkdf2.Key(pw, []byte("saltysalt"), d.iterations, 16, sha1.New)
+		if d.keys == nil {
+			d.keys = make(map[string][]byte)
+		}
+		d.keys[prefix] = key
+	}
+	return decryptCBC(key, data[3:])
+}
+
+// decryptCBC decrypts the AES-CBC encrypted data, the IV is 16 spaces.
+func decryptCBC(key []byte, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid ciphertext size")
+	}
+	iv := bytes.Repeat([]byte{' '}, aes.BlockSize)
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+	// PKCS#7 padding
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > aes.BlockSize || pad > len(plain) {
+		return nil, errors.New("invalid padding, wrong key?")
+	}
+	return plain[:len(plain)-pad], nil
+}
+
+// decryptGCM decrypts the AES-256-GCM encrypted data, as on Windows.  The
+// data is the 12 bytes nonce followed by the ciphertext.
+func decryptGCM(key []byte, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("invalid ciphertext size")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
@@ -0,0 +1,125 @@
+// Package cookiestore reads the cookies from the cookie databases of the
+// browsers, installed on the system.  Supported browsers are Chrome,
+// Chromium and Firefox.
+//
+// Firefox stores the cookies unencrypted.  Chrome encrypts the cookie values
+// with the key, that is stored in the system keyring on Linux (Secret
+// Service, accessed with secret-tool), the Keychain on macOS, or protected
+// with DPAPI on Windows.  Retrieving the key may require the user's
+// permission.
+package cookiestore
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Supported browsers.
+const (
+	Chrome   = "chrome"
+	Chromium = "chromium"
+	Firefox  = "firefox"
+)
+
+var (
+	ErrUnsupported = errors.New("unsupported browser")
+	ErrNotFound    = errors.New("cookie database not found")
+)
+
+// Store is the cookie store of the browser.
+type Store struct {
+	// Browser is the browser name, one of Chrome, Chromium or Firefox.
+	Browser string
+	// Profile is the browser profile directory.  If empty, the default
+	// profile is used for Chrome, and the most recently used one for
+	// Firefox.
+	Profile string
+}
+
+// Parse parses the store specification in the form of "browser[:profile]",
+// i.e. "chrome", or "firefox:/home/user/.mozilla/firefox/abcd.default".
+func Parse(spec string) (Store, error) {
+	browser, profile, _ := strings.Cut(spec, ":")
+	s := Store{Browser: strings.ToLower(strings.TrimSpace(browser)), Profile: profile}
+	switch s.Browser {
+	case Chrome, Chromium, Firefox:
+	default:
+		return Store{}, fmt.Errorf("%w: %q, supported: %s, %s, %s", ErrUnsupported, browser, Chrome, Chromium, Firefox)
+	}
+	return s, nil
+}
+
+// Cookies returns the cookies of the domain and its subdomains.
+func (s Store) Cookies(domain string) ([]*http.Cookie, error) {
+	switch s.Browser {
+	case Chrome, Chromium:
+		return chromeCookies(s.Browser, s.Profile, domain)
+	case Firefox:
+		return firefoxCookies(s.Profile, domain)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupported, s.Browser)
+}
+
+// Cookie returns the cookie with the name from the domain.  If there are
+// several cookies with the same name on the domain and its subdomains, the
+// one set on the domain itself is preferred.
+func (s Store) Cookie(domain string, name string) (*http.Cookie, error) {
+	cookies, err := s.Cookies(domain)
+	if err != nil {
+		return nil, err
+	}
+	var found *http.Cookie
+	for _, c := range cookies {
+		if c.Name != name {
+			continue
+		}
+		if found == nil || strings.TrimPrefix(c.Domain, ".") == strings.TrimPrefix(domain, ".") {
+			found = c
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("cookie %q for %s not found in the %s cookie store, are you logged in?", name, domain, s.Browser)
+	}
+	return found, nil
+}
+
+// matchDomain returns true if the cookie host is the domain or its
+// subdomain.
+func matchDomain(host string, domain string) bool {
+	host = strings.TrimPrefix(host, ".")
+	domain = strings.TrimPrefix(domain, ".")
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// newest returns the most recently modified file out of the files, matching
+// the glob patterns.
+func newest(patterns ...string) (string, error) {
+	type file struct {
+		name string
+		fi   os.FileInfo
+	}
+	var files []file
+	for _, p := range patterns {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return "", err
+		}
+		for _, m := range matches {
+			if fi, err := os.Stat(m); err == nil && !fi.IsDir() {
+				files = append(files, file{m, fi})
+			}
+		}
+	}
+	if len(files) == 0 {
+		return "", ErrNotFound
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].fi.ModTime().After(files[j].fi.ModTime())
+	})
+	return files[0].name, nil
+}
@@ -0,0 +1,53 @@
+package cookiestore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+func chromeUserDataDir(browser string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if browser == Chromium {
+		return filepath.Join(dir, "Chromium"), nil
+	}
+	return filepath.Join(dir, "Google", "Chrome"), nil
+}
+
+func firefoxProfilesDirs() []string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(dir, "Firefox", "Profiles")}
+}
+
+// newDecrypter returns the decrypter for Chrome on macOS, the password is
+// stored in the Keychain.
+func newDecrypter(browser string, _ string) (decrypter, error) {
+	return &cbcDecrypter{
+		iterations: 1003,
+		password: map[string]func() ([]byte, error){
+			string(prefixV10): func() ([]byte, error) { return keychainPassword(browser) },
+		},
+	}, nil
+}
+
+// keychainPassword returns the Safe Storage password from the Keychain.
+// macOS may ask the user to allow the access.
+func keychainPassword(browser string) ([]byte, error) {
+	service := "Chrome Safe Storage"
+	if browser == Chromium {
+		service = "Chromium Safe Storage"
+	}
+	out, err := exec.Command("security", "find-generic-password", "-w", "-s", service).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %q from the Keychain: %w", service, err)
+	}
+	return bytes.TrimSpace(out), nil
+}
@@ -0,0 +1,58 @@
+package cookiestore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+func chromeUserDataDir(browser string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if browser == Chromium {
+		return filepath.Join(dir, "chromium"), nil
+	}
+	return filepath.Join(dir, "google-chrome"), nil
+}
+
+func firefoxProfilesDirs() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(home, ".mozilla", "firefox"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox"),
+	}
+}
+
+// newDecrypter returns the decrypter for Chrome on Linux.  The "v10" values
+// are encrypted with the hardcoded password, the "v11" - with the password
+// stored in the keyring.
+func newDecrypter(browser string, _ string) (decrypter, error) {
+	return &cbcDecrypter{
+		iterations: 1,
+		password: map[string]func() ([]byte, error){
+			string(prefixV10): func() ([]byte, error) { return []byte("peanuts"), nil },
+			string(prefixV11): func() ([]byte, error) { return keyringPassword(browser) },
+		},
+	}, nil
+}
+
+// keyringPassword looks up the Chrome Safe Storage password in the Secret
+// Service with secret-tool.
+func keyringPassword(browser string) ([]byte, error) {
+	out, err := exec.Command("secret-tool", "lookup", "application", browser).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the %s password from the keyring with secret-tool: %w", browser, err)
+	}
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%s password not found in the keyring", browser)
+	}
+	return out, nil
+}
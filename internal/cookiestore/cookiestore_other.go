@@ -0,0 +1,40 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package cookiestore
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func chromeUserDataDir(browser string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if browser == Chromium {
+		return filepath.Join(dir, "chromium"), nil
+	}
+	return filepath.Join(dir, "google-chrome"), nil
+}
+
+func firefoxProfilesDirs() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(home, ".mozilla", "firefox")}
+}
+
+// newDecrypter returns the decrypter, that is able to decrypt only the
+// values, encrypted with the hardcoded password, as there is no keyring
+// support on this system.
+func newDecrypter(_ string, _ string) (decrypter, error) {
+	return &cbcDecrypter{
+		iterations: 1,
+		password: map[string]func() ([]byte, error){
+			string(prefixV10): func() ([]byte, error) { return []byte("peanuts"), nil },
+		},
+	}, nil
+}
@@ -0,0 +1,141 @@
+package cookiestore
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    Store
+		wantErr bool
+	}{
+		{"chrome", "chrome", Store{Browser: Chrome}, false},
+		{"case", "FireFox", Store{Browser: Firefox}, false},
+		{"profile", "chromium:/home/user/.config/chromium/Profile 1", Store{Browser: Chromium, Profile: "/home/user/.config/chromium/Profile 1"}, false},
+		{"windows profile", `firefox:C:\Users\user\profile`, Store{Browser: Firefox, Profile: `C:\Users\user\profile`}, false},
+		{"unsupported", "netscape", Store{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_matchDomain(t *testing.T) {
+	tests := []struct {
+		host   string
+		domain string
+		want   bool
+	}{
+		{".slack.com", ".slack.com", true},
+		{"slack.com", ".slack.com", true},
+		{"evilcorp.slack.com", "slack.com", true},
+		{"notslack.com", "slack.com", false},
+		{".example.com", "slack.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchDomain(tt.host, tt.domain))
+		})
+	}
+}
+
+func TestStore_Cookie_firefox(t *testing.T) {
+	s := Store{Browser: Firefox, Profile: filepath.Join("testdata", "firefox", "abcd.default-release")}
+	c, err := s.Cookie(".slack.com", "d")
+	require.NoError(t, err)
+	assert.Equal(t, "xoxd-firefox-cookie", c.Value)
+	assert.Equal(t, time.Unix(1893456000, 0), c.Expires)
+	assert.True(t, c.Secure)
+
+	_, err = s.Cookie(".slack.com", "missing")
+	assert.Error(t, err)
+
+	_, err = Store{Browser: Firefox, Profile: filepath.Join("testdata", "chrome")}.Cookies(".slack.com")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func Test_readChrome(t *testing.T) {
+	db, closeFn, err := openDB(filepath.Join("testdata", "chrome", "Default", "Network", "Cookies"))
+	require.NoError(t, err)
+	defer closeFn()
+	dec := &cbcDecrypter{
+		iterations: 1,
+		password: map[string]func() ([]byte, error){
+			"v10": func() ([]byte, error) { return []byte("peanuts"), nil },
+		},
+	}
+	cookies, err := readChrome(db, dec, ".slack.com")
+	require.NoError(t, err)
+	got := make(map[string]string)
+	for _, c := range cookies {
+		got[c.Name] = c.Value
+	}
+	assert.Equal(t, map[string]string{"d": "xoxd-chrome-cookie", "d-s": "1700000000", "lc": "1"}, got)
+
+	// wrong password
+	dec = &cbcDecrypter{
+		iterations: 1,
+		password: map[string]func() ([]byte, error){
+			"v10": func() ([]byte, error) { return []byte("wrong"), nil },
+		},
+	}
+	_, err = readChrome(db, dec, ".slack.com")
+	assert.Error(t, err)
+}
+
+func Test_chromeTime(t *testing.T) {
+	assert.Equal(t, time.Time{}, chromeTime(0))
+	assert.Equal(t, time.Date(2023, 9, 24, 3, 33, 20, 0, time.UTC), chromeTime(13340000000000000))
+}
+
+func Test_openDB(t *testing.T) {
+	t.Run("uncommitted to the database file", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "cookies.sqlite")
+		// the browser keeps the database open, the changes stay in the
+		// write-ahead log.
+		browser, err := sql.Open("sqlite", filename)
+		require.NoError(t, err)
+		defer browser.Close()
+		browser.SetMaxOpenConns(1)
+		for _, stmt := range []string{
+			"PRAGMA journal_mode=WAL",
+			"PRAGMA wal_autocheckpoint=0",
+			"CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, host TEXT, name TEXT, value TEXT, path TEXT, expiry INTEGER, isSecure INTEGER, isHttpOnly INTEGER)",
+			"INSERT INTO moz_cookies (host, name, value, path, expiry, isSecure, isHttpOnly) VALUES ('.slack.com', 'd', 'xoxd-wal', '/', 1893456000, 1, 1)",
+		} {
+			_, err := browser.Exec(stmt)
+			require.NoError(t, err, stmt)
+		}
+		require.FileExists(t, filename+"-wal")
+
+		db, closeFn, err := openDB(filename)
+		require.NoError(t, err)
+		defer closeFn()
+		cookies, err := readFirefox(db, "slack.com")
+		require.NoError(t, err)
+		require.Len(t, cookies, 1)
+		assert.Equal(t, "xoxd-wal", cookies[0].Value)
+	})
+	t.Run("not a database", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "cookies.sqlite")
+		require.NoError(t, os.WriteFile(filename, []byte("definitely not a database, but long enough to have a header"), 0600))
+		_, _, err := openDB(filename)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,93 @@
+package cookiestore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+func chromeUserDataDir(browser string) (string, error) {
+	dir := os.Getenv("LOCALAPPDATA")
+	if dir == "" {
+		return "", errors.New("%LOCALAPPDATA% is not defined")
+	}
+	if browser == Chromium {
+		return filepath.Join(dir, "Chromium", "User Data"), nil
+	}
+	return filepath.Join(dir, "Google", "Chrome", "User Data"), nil
+}
+
+func firefoxProfilesDirs() []string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(dir, "Mozilla", "Firefox", "Profiles")}
+}
+
+// gcmDecrypter decrypts the values with the key, stored in the "Local State"
+// file, which is protected with DPAPI.
+type gcmDecrypter struct {
+	key []byte
+}
+
+// newDecrypter returns the decrypter for Chrome on Windows.  The key is
+// stored in the "Local State" file in the user data directory, which is the
+// parent of the profile directory.
+func newDecrypter(_ string, profile string) (decrypter, error) {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(profile), "Local State"))
+	if err != nil {
+		return nil, err
+	}
+	var state struct {
+		OSCrypt struct {
+			EncryptedKey string `json:"encrypted_key"`
+		} `json:"os_crypt"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid Local State file: %w", err)
+	}
+	enc, err := base64.StdEncoding.DecodeString(state.OSCrypt.EncryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted key: %w", err)
+	}
+	if !bytes.HasPrefix(enc, []byte("DPAPI")) {
+		return nil, errors.New("invalid encrypted key: missing DPAPI prefix")
+	}
+	key, err := dpapiDecrypt(enc[len("DPAPI"):])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the key: %w", err)
+	}
+	return &gcmDecrypter{key: key}, nil
+}
+
+func (d *gcmDecrypter) decrypt(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, prefixV10), bytes.HasPrefix(data, prefixV11):
+		return decryptGCM(d.key, data[3:])
+	case bytes.HasPrefix(data, prefixV20):
+		return nil, fmt.Errorf("%w: application-bound encryption (v20)", errUnsupportedEncryption)
+	}
+	// values, encrypted by older versions.
+	return dpapiDecrypt(data)
+}
+
+func dpapiDecrypt(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty data")
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, 0, &out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
@@ -0,0 +1,106 @@
+package cookiestore
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const firefoxDB = "cookies.sqlite"
+
+// firefoxCookies reads the cookies of the domain from the Firefox profile.
+func firefoxCookies(profile string, domain string) ([]*http.Cookie, error) {
+	filename, err := firefoxDBFile(profile)
+	if err != nil {
+		return nil, err
+	}
+	db, closeFn, err := openDB(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer closeFn()
+	cookies, err := readFirefox(db, domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	return cookies, nil
+}
+
+// readFirefox reads the cookies of the domain from the Firefox cookie
+// database.
+func readFirefox(db *sql.DB, domain string) ([]*http.Cookie, error) {
+	rows, err := db.Query("SELECT host, name, value, path, expiry, isSecure, isHttpOnly FROM moz_cookies")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cookies []*http.Cookie
+	for rows.Next() {
+		var (
+			c                http.Cookie
+			expiry           int64
+			secure, httpOnly int64
+		)
+		if err := rows.Scan(&c.Domain, &c.Name, &c.Value, &c.Path, &expiry, &secure, &httpOnly); err != nil {
+			return nil, err
+		}
+		if !matchDomain(c.Domain, domain) {
+			continue
+		}
+		c.Expires = firefoxTime(expiry)
+		c.Secure = secure == 1
+		c.HttpOnly = httpOnly == 1
+		cookies = append(cookies, &c)
+	}
+	return cookies, rows.Err()
+}
+
+// firefoxDBFile returns the cookie database of the profile.  If the profile
+// is empty, it returns the most recently used cookie database of all
+// profiles.
+func firefoxDBFile(profile string) (string, error) {
+	if profile != "" {
+		return dbInProfile(profile, firefoxDB)
+	}
+	var patterns []string
+	for _, dir := range firefoxProfilesDirs() {
+		patterns = append(patterns, filepath.Join(dir, "*", firefoxDB))
+	}
+	filename, err := newest(patterns...)
+	if err != nil {
+		return "", fmt.Errorf("firefox: %w", err)
+	}
+	return filename, nil
+}
+
+// firefoxTime converts the expiry value to time.  Newer Firefox versions
+// store it in milliseconds.
+func firefoxTime(expiry int64) time.Time {
+	if expiry > 1e12 {
+		return time.UnixMilli(expiry)
+	}
+	return time.Unix(expiry, 0)
+}
+
+// dbInProfile returns the first of the database files, that exists in the
+// profile directory.  If the profile is a file, it is returned as is.
+func dbInProfile(profile string, names ...string) (string, error) {
+	fi, err := os.Stat(profile)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return profile, nil
+	}
+	for _, name := range names {
+		filename := filepath.Join(profile, name)
+		if fi, err := os.Stat(filename); err == nil && !fi.IsDir() {
+			return filename, nil
+		}
+	}
+	return "", fmt.Errorf("%w in %s", ErrNotFound, profile)
+}
@@ -0,0 +1,69 @@
+package cookiestore
+
+// In this file: opening the cookie databases with the pure Go SQLite driver.
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+)
+
+// openDB opens the copy of the cookie database filename.  The database is
+// copied along with its write-ahead log (the "-wal" file), if it exists, so
+// that the database, that is locked by the running browser, could be read,
+// and the changes, not yet written to the database file, are seen.  The
+// returned function closes the database and removes the copy.
+func openDB(filename string) (*sql.DB, func(), error) {
+	dir, err := os.MkdirTemp("", "slackdump-cookies-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	tmpname := filepath.Join(dir, "cookies.db")
+	if err := copyFile(tmpname, filename); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if err := copyFile(tmpname+"-wal", filename+"-wal"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		cleanup()
+		return nil, nil, err
+	}
+	db, err := sql.Open("sqlite", tmpname)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	// fail early, if the file is not a database.
+	var n int
+	if err := db.QueryRow("SELECT count(*) FROM sqlite_master").Scan(&n); err != nil {
+		db.Close()
+		cleanup()
+		return nil, nil, err
+	}
+	return db, func() { db.Close(); cleanup() }, nil
+}
+
+// copyFile copies the file src to dst.
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Close()
+}
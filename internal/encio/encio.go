@@ -29,11 +29,21 @@ var ErrDecrypt = errors.New("decryption error")
 
 // Open opens an encrypted file container.
 func Open(filename string) (io.ReadCloser, error) {
+	key, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return OpenWithKey(filename, key)
+}
+
+// OpenWithKey opens the file container encrypted with the key, see
+// PassphraseKey.
+func OpenWithKey(filename string, key []byte) (io.ReadCloser, error) {
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
-	r, err := NewReader(f)
+	r, err := NewReaderWithKey(f, key)
 	if err != nil {
 		f.Close()
 		return nil, err
@@ -48,17 +58,22 @@ func Open(filename string) (io.ReadCloser, error) {
 // NewReader wraps the ciphertext reader, and returns the reader that a
 // plaintext can be read from.
 func NewReader(r io.Reader) (io.Reader, error) {
+	key, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return NewReaderWithKey(r, key)
+}
+
+// NewReaderWithKey is the same as NewReader, but uses the provided key
+// instead of the one derived from the machineID.
+func NewReaderWithKey(r io.Reader, key []byte) (io.Reader, error) {
 	var iv [aes.BlockSize]byte
 	if n, err := r.Read(iv[:]); err != nil {
 		return nil, err
 	} else if n != len(iv) {
 		return nil, ErrDecrypt
 	}
-
-	key, err := encryptionKey()
-	if err != nil {
-		return nil, err
-	}
 	return secure.NewReaderWithKey(r, key, iv)
 }
 
@@ -75,11 +90,21 @@ func (rc *readCloser) Close() error {
 
 // Create creates an encrypted file container.
 func Create(filename string) (io.WriteCloser, error) {
+	key, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return CreateWithKey(filename, key)
+}
+
+// CreateWithKey creates the file container encrypted with the key, see
+// PassphraseKey.
+func CreateWithKey(filename string, key []byte) (io.WriteCloser, error) {
 	f, err := os.Create(filename)
 	if err != nil {
 		return nil, err
 	}
-	ew, err := NewWriter(f)
+	ew, err := NewWriterWithKey(f, key)
 	if err != nil {
 		f.Close()
 		return nil, err
@@ -97,6 +122,16 @@ func Create(filename string) (io.WriteCloser, error) {
 // written to the writer is encrypted with the hashed machineID.  WriteCloser
 // must be closed to flush any buffered data.
 func NewWriter(w io.Writer) (io.WriteCloser, error) {
+	key, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterWithKey(w, key)
+}
+
+// NewWriterWithKey is the same as NewWriter, but uses the provided key
+// instead of the one derived from the machineID.
+func NewWriterWithKey(w io.Writer, key []byte) (io.WriteCloser, error) {
 	iv, err := generateIV()
 	if err != nil {
 		return nil, err
@@ -106,11 +141,6 @@ func NewWriter(w io.Writer) (io.WriteCloser, error) {
 		return nil, fmt.Errorf("failed to write the initialisation vector: %w", err)
 	}
 
-	key, err := encryptionKey()
-	if err != nil {
-		return nil, err
-	}
-
 	return secure.NewWriterWithKey(w, key, iv)
 }
 
@@ -146,6 +176,15 @@ func encryptionKey() ([]byte, error) {
 	return secure.DeriveKey([]byte(id), keySz)
 }
 
+// PassphraseKey derives the encryption key from the passphrase.  Unlike the
+// machineID key, it allows to use the encrypted files on another machine.
+func PassphraseKey(passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	return secure.DeriveKey([]byte(passphrase), keySz)
+}
+
 // SetAppID allows to set the appID, that is used to hash the value of
 // machineID.
 func SetAppID(s string) error {
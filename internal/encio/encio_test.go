@@ -82,6 +82,47 @@ func TestCreateOpen(t *testing.T) {
 	}
 }
 
+func TestCreateOpenWithKey(t *testing.T) {
+	testfile := filepath.Join(t.TempDir(), "testfile")
+	key, err := PassphraseKey("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := CreateWithKey(testfile, key)
+	if err != nil {
+		t.Fatalf("error creating a test file: %s", err)
+	}
+	if _, err := f.Write([]byte(plaintext)); err != nil {
+		t.Errorf("error writing test data: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("error while closing R/W file: %s", err)
+	}
+
+	read := func(open func() (io.ReadCloser, error)) string {
+		g, err := open()
+		if err != nil {
+			t.Fatalf("error opening a test file: %s", err)
+		}
+		defer g.Close()
+		var result strings.Builder
+		if _, err := io.Copy(&result, g); err != nil {
+			t.Errorf("error reading encrypted data: %s", err)
+		}
+		return result.String()
+	}
+	if got := read(func() (io.ReadCloser, error) { return OpenWithKey(testfile, key) }); got != plaintext {
+		t.Errorf("invalid decrypted text: want=%q, got=%q", plaintext, got)
+	}
+	if got := read(func() (io.ReadCloser, error) { return Open(testfile) }); got == plaintext {
+		t.Error("file encrypted with the passphrase key is decrypted with the machine key")
+	}
+
+	if _, err := PassphraseKey(""); err == nil {
+		t.Error("expected an error for the empty passphrase")
+	}
+}
+
 func TestSetAppID(t *testing.T) {
 	type args struct {
 		s string
@@ -7,6 +7,8 @@ const TestConversationExportJSON = `{
 		"user": "UHSD97ZA5",
 		"text": "\u003c@UHSD97ZA5\u003e has joined the channel",
 		"ts": "1555493779.000200",
+		"time": "2019-04-17T09:36:19Z",
+		"time_unix": 1555493779,
 		"subtype": "channel_join",
 		"replace_original": false,
 		"delete_original": false,
@@ -26,6 +28,8 @@ const TestConversationExportJSON = `{
 		"user": "ULLLZ6SAH",
 		"text": "\u003c@ULLLZ6SAH\u003e has joined the channel",
 		"ts": "1563609394.000200",
+		"time": "2019-07-20T07:56:34Z",
+		"time_unix": 1563609394,
 		"subtype": "channel_join",
 		"replace_original": false,
 		"delete_original": false,
@@ -44,6 +48,8 @@ const TestConversationExportJSON = `{
 		"user": "UHSD97ZA5",
 		"text": "hello",
 		"ts": "1564023445.000100",
+		"time": "2019-07-25T02:57:25Z",
+		"time_unix": 1564023445,
 		"bot_id": "BKQPUHWF2",
 		"bot_profile": {
 		  "app_id": "AKDB9CUKC",
@@ -77,6 +83,8 @@ const TestConversationExportJSON = `{
 		"user": "UHSD97ZA5",
 		"text": "hello",
 		"ts": "1564023560.000200",
+		"time": "2019-07-25T02:59:20Z",
+		"time_unix": 1564023560,
 		"bot_id": "BKQPUHWF2",
 		"bot_profile": {
 		  "app_id": "AKDB9CUKC",
@@ -113,6 +121,8 @@ const TestConversationExportJSON = `{
 		"user": "UHSD97ZA5",
 		"text": "This ~is a~  Rich Text message test.",
 		"ts": "1577694990.000400",
+		"time": "2019-12-30T08:36:30Z",
+		"time_unix": 1577694990,
 		"thread_ts": "1577694990.000400",
 		"last_read": "1648633700.407619",
 		"subscribed": true,
@@ -185,6 +195,8 @@ const TestConversationExportJSON = `{
 		"user": "UHSD97ZA5",
 		"text": "Test thread reply",
 		"ts": "1638784588.000100",
+		"time": "2021-12-06T09:56:28Z",
+		"time_unix": 1638784588,
 		"thread_ts": "1577694990.000400",
 		"parent_user_id": "UHSD97ZA5",
 		"team": "THY5HTZ8U",
@@ -223,6 +235,8 @@ const TestConversationExportJSON = `{
 		"user": "UHSD97ZA5",
 		"text": "test image",
 		"ts": "1638784627.000300",
+		"time": "2021-12-06T09:57:07Z",
+		"time_unix": 1638784627,
 		"thread_ts": "1577694990.000400",
 		"parent_user_id": "UHSD97ZA5",
 		"files": [
@@ -324,6 +338,8 @@ const TestConversationExportJSON = `{
 		"user": "UHSD97ZA5",
 		"text": "Test message with Html chars \u0026lt; \u0026gt;",
 		"ts": "1645095505.023899",
+		"time": "2022-02-17T10:58:25Z",
+		"time_unix": 1645095505,
 		"team": "THY5HTZ8U",
 		"replace_original": false,
 		"delete_original": false,
@@ -362,6 +378,8 @@ const TestConversationExportJSON = `{
 		"user": "UHSD97ZA5",
 		"text": "30-Mar-2022",
 		"ts": "1648633633.716099",
+		"time": "2022-03-30T09:47:13Z",
+		"time_unix": 1648633633,
 		"team": "THY5HTZ8U",
 		"replace_original": false,
 		"delete_original": false,
@@ -398,6 +416,8 @@ const TestConversationExportJSON = `{
 		"user": "UHSD97ZA5",
 		"text": "Hello from 2022",
 		"ts": "1648633700.407619",
+		"time": "2022-03-30T09:48:20Z",
+		"time_unix": 1648633700,
 		"thread_ts": "1577694990.000400",
 		"parent_user_id": "UHSD97ZA5",
 		"team": "THY5HTZ8U",
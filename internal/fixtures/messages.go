@@ -231,6 +231,8 @@ const (
 			"text": "This ~is a~  Rich Text message test.",
 			"user": "UHSD97ZA5",
 			"ts": "1577694990.000400",
+			"time": "2019-12-30T08:36:30Z",
+			"time_unix": 1577694990,
 			"team": "THY5HTZ8U",
 			"user_team": "THY5HTZ8U",
 			"source_team": "THY5HTZ8U",
@@ -432,4 +434,32 @@ const (
         "thread_ts": "1648085300.726649",
         "parent_user_id": "U034HM0P7RB"
     }`
+
+	// ReactionsMessageJSON is the message with three reactions, one of them
+	// is the custom emoji.
+	ReactionsMessageJSON = `{
+        "client_msg_id": "8b2ef1b4-5d5a-4f43-9f7c-6a3f7e5d2c11",
+        "type": "message",
+        "text": "Ship it!",
+        "user": "UHSD97ZA5",
+        "ts": "1645095600.000100",
+        "team": "THY5HTZ8U",
+        "reactions": [
+            {
+                "name": "+1",
+                "users": ["UHSD97ZA5", "U034HM0P7RB"],
+                "count": 2
+            },
+            {
+                "name": "tada",
+                "users": ["U034HM0P7RB"],
+                "count": 1
+            },
+            {
+                "name": "partyparrot",
+                "users": ["UHSD97ZA5"],
+                "count": 1
+            }
+        ]
+    }`
 )
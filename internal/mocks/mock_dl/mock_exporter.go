@@ -10,6 +10,9 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	slackdump "github.com/rusq/slackdump/v2"
+	downloader "github.com/rusq/slackdump/v2/downloader"
+	dl "github.com/rusq/slackdump/v2/internal/structures/files/dl"
+	slack "github.com/slack-go/slack"
 )
 
 // MockExporter is a mock of Exporter interface.
@@ -35,6 +38,50 @@ func (m *MockExporter) EXPECT() *MockExporterMockRecorder {
 	return m.recorder
 }
 
+// Avatars mocks base method.
+func (m *MockExporter) Avatars(arg0 []slack.User) ([]slack.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Avatars", arg0)
+	ret0, _ := ret[0].([]slack.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Avatars indicates an expected call of Avatars.
+func (mr *MockExporterMockRecorder) Avatars(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Avatars", reflect.TypeOf((*MockExporter)(nil).Avatars), arg0)
+}
+
+// Canvases mocks base method.
+func (m *MockExporter) Canvases(arg0 []slack.File) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Canvases", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Canvases indicates an expected call of Canvases.
+func (mr *MockExporterMockRecorder) Canvases(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Canvases", reflect.TypeOf((*MockExporter)(nil).Canvases), arg0)
+}
+
+// Manifest mocks base method.
+func (m *MockExporter) Manifest() []dl.ManifestEntry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Manifest")
+	ret0, _ := ret[0].([]dl.ManifestEntry)
+	return ret0
+}
+
+// Manifest indicates an expected call of Manifest.
+func (mr *MockExporterMockRecorder) Manifest() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Manifest", reflect.TypeOf((*MockExporter)(nil).Manifest))
+}
+
 // ProcessFunc mocks base method.
 func (m *MockExporter) ProcessFunc(arg0 string) slackdump.ProcessFunc {
 	m.ctrl.T.Helper()
@@ -61,6 +108,20 @@ func (mr *MockExporterMockRecorder) Start(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockExporter)(nil).Start), arg0)
 }
 
+// Stats mocks base method.
+func (m *MockExporter) Stats() downloader.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(downloader.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockExporterMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockExporter)(nil).Stats))
+}
+
 // Stop mocks base method.
 func (m *MockExporter) Stop() {
 	m.ctrl.T.Helper()
@@ -0,0 +1,129 @@
+package network
+
+// In this file: the adaptive rate limiting, that honours the Retry-After
+// of the rate limit errors.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// minRateDivisor limits how far the rate of the limiter can be lowered
+	// after the rate limit errors: base rate / minRateDivisor.
+	minRateDivisor = 8
+	// adaptThreshold is the minimum Retry-After delay, that lowers the rate
+	// of the limiter.  Slack reports the delay in whole seconds, so shorter
+	// delays only pause the limiter.  It is also the minimum recovery period.
+	adaptThreshold = time.Second
+)
+
+// timeNow and sleepFn exist to allow the tests to control the time.
+var (
+	timeNow = time.Now
+	sleepFn = sleepCtx
+)
+
+// Limiter is the rate limiter, that adapts to the rate limit errors.  After
+// Slack responds with the rate limit error, all calls on the limiter in
+// WithRetry are paused for the Retry-After duration, and, if the delay is
+// long enough, the rate of the limiter is halved.  Once there were no rate
+// limit errors for the Retry-After duration, the rate is doubled, until it
+// reaches the initial rate.
+type Limiter struct {
+	*rate.Limiter
+	tier     Tier
+	counters *tierCounters
+
+	mu        sync.Mutex
+	base      rate.Limit    // initial rate, 0 if the rate was not lowered
+	until     time.Time     // calls are paused until this time
+	period    time.Duration // recovery period
+	recoverAt time.Time     // time of the next recovery step
+}
+
+// AdaptiveLimiter returns the adaptive limiter, that wraps the limiter l.
+// The waits on the limiter are accounted as NoTier, see Stats.
+func AdaptiveLimiter(l *rate.Limiter) *Limiter {
+	return newLimiter(l, NoTier)
+}
+
+func newLimiter(l *rate.Limiter, t Tier) *Limiter {
+	return &Limiter{Limiter: l, tier: t, counters: countersFor(t)}
+}
+
+// wait waits until the pause, requested by the rate limit error, ends.
+func (l *Limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	d := l.until.Sub(timeNow())
+	l.mu.Unlock()
+	if d <= 0 {
+		return nil
+	}
+	tracelogf(ctx, "info", "limiter %s: paused, waiting %s", l.tier, d)
+	return sleepFn(ctx, d)
+}
+
+// rateLimited pauses the calls on the limiter for retryAfter and halves its
+// rate.
+func (l *Limiter) rateLimited(ctx context.Context, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := timeNow()
+	if until := now.Add(retryAfter); until.After(l.until) {
+		l.until = until
+	}
+	if retryAfter < adaptThreshold || l.Limit() == rate.Inf {
+		return
+	}
+	if l.base == 0 {
+		l.base = l.Limit()
+	}
+	limit := l.Limit() / 2
+	if min := l.base / minRateDivisor; limit < min {
+		limit = min
+	}
+	l.SetLimit(limit)
+	l.period = retryAfter
+	l.recoverAt = l.until.Add(l.period)
+	tracelogf(ctx, "info", "limiter %s: rate lowered to %.3f/s", l.tier, float64(limit))
+}
+
+// succeeded is called after the successful call, it restores the rate of the
+// limiter, if there were no rate limit errors for the recovery period.
+func (l *Limiter) succeeded(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.base == 0 {
+		return
+	}
+	now := timeNow()
+	if now.Before(l.recoverAt) {
+		return
+	}
+	limit := l.Limit() * 2
+	if limit >= l.base {
+		limit = l.base
+		l.base = 0
+	}
+	l.SetLimit(limit)
+	l.recoverAt = now.Add(l.period)
+	tracelogf(ctx, "info", "limiter %s: rate restored to %.3f/s", l.tier, float64(limit))
+}
+
+// sleepCtx sleeps for the duration d, or until the context is cancelled.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
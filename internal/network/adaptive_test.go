@@ -0,0 +1,128 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// fakeClock replaces timeNow and sleepFn with the fake clock, that advances
+// on each sleep, and records the sleeps.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func setFakeClock(t *testing.T) *fakeClock {
+	t.Helper()
+	c := &fakeClock{now: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)}
+	oldNow, oldSleep := timeNow, sleepFn
+	t.Cleanup(func() { timeNow, sleepFn = oldNow, oldSleep })
+	timeNow = func() time.Time {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.now
+	}
+	sleepFn = func(_ context.Context, d time.Duration) error {
+		c.advance(d)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.sleeps = append(c.sleeps, d)
+		return nil
+	}
+	return c
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestWithRetry_retryAfter(t *testing.T) {
+	clock := setFakeClock(t)
+	ctx := context.Background()
+	lim := NewLimiter(Tier3, 1, 6000)
+	base := lim.Limit()
+
+	// the request is rate limited, and retries run out.
+	if err := WithRetry(ctx, lim, 1, retryFn(1, 30*time.Second, nil)); err != ErrRetryFailed {
+		t.Fatalf("WithRetry() error = %v, want %v", err, ErrRetryFailed)
+	}
+	if got := lim.Limit(); got != base/2 {
+		t.Errorf("limit after the rate limit error = %v, want %v", got, base/2)
+	}
+
+	// the next request must wait for the Retry-After delay.
+	var calls int
+	if err := WithRetry(ctx, lim, 1, func() error { calls++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if want := []time.Duration{30 * time.Second}; len(clock.sleeps) != 1 || clock.sleeps[0] != want[0] {
+		t.Errorf("sleeps = %v, want %v", clock.sleeps, want)
+	}
+	if got := lim.Limit(); got != base/2 {
+		t.Errorf("limit must not recover before the recovery period, got %v, want %v", got, base/2)
+	}
+
+	// no more waits, and the rate recovers after the recovery period.
+	clock.advance(30 * time.Second)
+	if err := WithRetry(ctx, lim, 1, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if len(clock.sleeps) != 1 {
+		t.Errorf("unexpected sleeps: %v", clock.sleeps)
+	}
+	if got := lim.Limit(); got != base {
+		t.Errorf("limit after the recovery = %v, want %v", got, base)
+	}
+}
+
+func TestLimiter_rateLimited(t *testing.T) {
+	setFakeClock(t)
+	ctx := context.Background()
+
+	t.Run("lowest rate", func(t *testing.T) {
+		lim := AdaptiveLimiter(rate.NewLimiter(80, 1))
+		for i := 0; i < 10; i++ {
+			lim.rateLimited(ctx, 2*time.Second)
+		}
+		if got, want := lim.Limit(), rate.Limit(80/minRateDivisor); got != want {
+			t.Errorf("limit = %v, want %v", got, want)
+		}
+	})
+	t.Run("short delay", func(t *testing.T) {
+		lim := AdaptiveLimiter(rate.NewLimiter(80, 1))
+		lim.rateLimited(ctx, 100*time.Millisecond)
+		if got := lim.Limit(); got != 80 {
+			t.Errorf("limit = %v, want 80", got)
+		}
+		if d := lim.until.Sub(timeNow()); d != 100*time.Millisecond {
+			t.Errorf("pause = %s, want 100ms", d)
+		}
+	})
+	t.Run("unlimited", func(t *testing.T) {
+		lim := AdaptiveLimiter(rate.NewLimiter(rate.Inf, 1))
+		lim.rateLimited(ctx, 5*time.Second)
+		if got := lim.Limit(); got != rate.Inf {
+			t.Errorf("limit = %v, want Inf", got)
+		}
+	})
+}
+
+func TestLimiter_wait_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	lim := AdaptiveLimiter(rate.NewLimiter(rate.Inf, 1))
+	lim.rateLimited(ctx, time.Hour)
+	if err := lim.wait(ctx); err != context.Canceled {
+		t.Errorf("wait() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -21,9 +21,9 @@ const (
 )
 
 // NewLimiter returns throttler with rateLimit requests per minute.
-// optionally caller may specify the boost
-func NewLimiter(t Tier, burst uint, boost int) *rate.Limiter {
+// optionally caller may specify the boost.  The waits on the limiter in
+// WithRetry are accounted in the statistics of the tier t, see Stats.
+func NewLimiter(t Tier, burst uint, boost int) *Limiter {
 	callsPerSec := float64(int(t)+boost) / secPerMin
-	l := rate.NewLimiter(rate.Limit(callsPerSec), int(burst))
-	return l
+	return newLimiter(rate.NewLimiter(rate.Limit(callsPerSec), int(burst)), t)
 }
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/slack-go/slack"
-	"golang.org/x/time/rate"
 
 	"github.com/rusq/slackdump/v2/logger"
 )
@@ -43,22 +42,23 @@ var ErrRetryFailed = errors.New("callback was unable to complete without errors
 // WithRetry will run the callback function fn. If the function returns
 // slack.RateLimitedError, it will delay, and then call it again up to
 // maxAttempts times. It will return an error if it runs out of attempts.
-func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func() error) error {
+//
+// The limiter adapts to the rate limit errors: the Retry-After delay pauses
+// all calls that use lim, not only the current one, and the rate of lim is
+// temporarily lowered, see Limiter.
+func WithRetry(ctx context.Context, lim *Limiter, maxAttempts int, fn func() error) error {
 	var ok bool
 	if maxAttempts == 0 {
 		maxAttempts = defNumAttempts
 	}
 	for attempt := 0; attempt < maxAttempts; attempt++ {
-		var err error
-		trace.WithRegion(ctx, "WithRetry.wait", func() {
-			err = lim.Wait(ctx)
-		})
-		if err != nil {
+		if err := traceWait(ctx, lim); err != nil {
 			return err
 		}
 
 		cbErr := fn()
 		if cbErr == nil {
+			lim.succeeded(ctx)
 			ok = true
 			break
 		}
@@ -72,21 +72,32 @@ func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func(
 		switch {
 		case errors.As(cbErr, &rle):
 			tracelogf(ctx, "info", "got rate limited, sleeping %s", rle.RetryAfter)
-			time.Sleep(rle.RetryAfter)
+			lim.counters.addBackoff(rle.RetryAfter, true)
+			lim.rateLimited(ctx, rle.RetryAfter)
 			continue
 		case errors.As(cbErr, &sce):
 			if isRecoverable(sce.Code) {
 				// possibly transient error
 				delay := waitFn(attempt)
 				tracelogf(ctx, "info", "got server error %d, sleeping %s", sce.Code, delay)
+				lim.counters.addBackoff(delay, false)
 				time.Sleep(delay)
 				continue
 			}
+		case isTimeout(cbErr) && ctx.Err() == nil:
+			// the request has hung and was aborted by the HTTP client
+			// timeout, a retry will likely succeed.
+			delay := netWaitFn(attempt)
+			tracelogf(ctx, "info", "got timeout error, sleeping %s", delay)
+			lim.counters.addBackoff(delay, false)
+			time.Sleep(delay)
+			continue
 		case errors.As(cbErr, &ne):
 			if ne.Op == "read" || ne.Op == "write" {
 				// possibly transient error
 				delay := netWaitFn(attempt)
 				tracelogf(ctx, "info", "got network error %s, sleeping %s", ne.Op, delay)
+				lim.counters.addBackoff(delay, false)
 				time.Sleep(delay)
 				continue
 			}
@@ -100,6 +111,13 @@ func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func(
 	return nil
 }
 
+// isTimeout returns true if err is a network timeout, i.e. the timeout of the
+// HTTP client or the transport.
+func isTimeout(err error) bool {
+	var te interface{ Timeout() bool }
+	return errors.As(err, &te) && te.Timeout()
+}
+
 // isRecoverable returns true if the status code is a recoverable error.
 func isRecoverable(statusCode int) bool {
 	return (statusCode >= http.StatusInternalServerError && statusCode <= 599 && statusCode != 501) || statusCode == 408
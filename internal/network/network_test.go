@@ -7,6 +7,8 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -67,7 +69,7 @@ func Test_withRetry(t *testing.T) {
 	t.Parallel()
 	type args struct {
 		ctx         context.Context
-		l           *rate.Limiter
+		l           *Limiter
 		maxAttempts int
 		fn          func() error
 	}
@@ -80,7 +82,7 @@ func Test_withRetry(t *testing.T) {
 		{"no errors",
 			args{
 				context.Background(),
-				rate.NewLimiter(testRateLimit, 1),
+				AdaptiveLimiter(rate.NewLimiter(testRateLimit, 1)),
 				3,
 				func() error {
 					return nil
@@ -92,7 +94,7 @@ func Test_withRetry(t *testing.T) {
 		{"generic error",
 			args{
 				context.Background(),
-				rate.NewLimiter(testRateLimit, 1),
+				AdaptiveLimiter(rate.NewLimiter(testRateLimit, 1)),
 				3,
 				func() error {
 					return errors.New("it was at this moment he knew:  he fucked up")
@@ -104,7 +106,7 @@ func Test_withRetry(t *testing.T) {
 		{"3 retries, no error",
 			args{
 				context.Background(),
-				rate.NewLimiter(testRateLimit, 1),
+				AdaptiveLimiter(rate.NewLimiter(testRateLimit, 1)),
 				3,
 				retryFn(2, 1*time.Millisecond, nil),
 			},
@@ -114,7 +116,7 @@ func Test_withRetry(t *testing.T) {
 		{"3 retries, error on the second attempt",
 			args{
 				context.Background(),
-				rate.NewLimiter(testRateLimit, 1),
+				AdaptiveLimiter(rate.NewLimiter(testRateLimit, 1)),
 				3,
 				retryFn(2, 1*time.Millisecond, errors.New("boo boo")),
 			},
@@ -124,7 +126,7 @@ func Test_withRetry(t *testing.T) {
 		{"rate limiter test 4 lmited attempts, 100 ms each",
 			args{
 				context.Background(),
-				rate.NewLimiter(10.0, 1),
+				AdaptiveLimiter(rate.NewLimiter(10.0, 1)),
 				5,
 				retryFn(4, 1*time.Millisecond, nil),
 			},
@@ -134,7 +136,7 @@ func Test_withRetry(t *testing.T) {
 		{"should honour the value in the rate limit error",
 			args{
 				context.Background(),
-				rate.NewLimiter(1000, 1),
+				AdaptiveLimiter(rate.NewLimiter(1000, 1)),
 				5,
 				retryFn(4, 100*time.Millisecond, nil),
 			},
@@ -144,7 +146,7 @@ func Test_withRetry(t *testing.T) {
 		{"running out of retries",
 			args{
 				context.Background(),
-				rate.NewLimiter(10.0, 1),
+				AdaptiveLimiter(rate.NewLimiter(10.0, 1)),
 				5,
 				retryFn(100, 1*time.Millisecond, nil),
 			},
@@ -155,13 +157,24 @@ func Test_withRetry(t *testing.T) {
 			"network error (#234)",
 			args{
 				context.Background(),
-				rate.NewLimiter(10.0, 1),
+				AdaptiveLimiter(rate.NewLimiter(10.0, 1)),
 				3,
 				errSeqFn(&net.OpError{Op: "read"}, 2, nil),
 			},
 			false,
 			calcExpRunDuration(2),
 		},
+		{
+			"timeout error",
+			args{
+				context.Background(),
+				AdaptiveLimiter(rate.NewLimiter(10.0, 1)),
+				3,
+				errSeqFn(&url.Error{Op: "Post", URL: "https://slack.com/api/conversations.history", Err: os.ErrDeadlineExceeded}, 2, nil),
+			},
+			false,
+			calcExpRunDuration(2),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -207,7 +220,7 @@ func Test500ErrorHandling(t *testing.T) {
 
 			start := time.Now()
 			// Call the client with a retry.
-			err := WithRetry(context.Background(), rate.NewLimiter(1, 1), testRetryCount, func() error {
+			err := WithRetry(context.Background(), AdaptiveLimiter(rate.NewLimiter(1, 1)), testRetryCount, func() error {
 				_, err := client.GetConversationHistory(&slack.GetConversationHistoryParameters{})
 				if err == nil {
 					return errors.New("expected error, got nil")
@@ -243,7 +256,7 @@ func Test500ErrorHandling(t *testing.T) {
 
 		// Call the client with a retry.
 		start := time.Now()
-		err := WithRetry(context.Background(), rate.NewLimiter(1, 1), testRetryCount, func() error {
+		err := WithRetry(context.Background(), AdaptiveLimiter(rate.NewLimiter(1, 1)), testRetryCount, func() error {
 			_, err := client.GetConversationHistory(&slack.GetConversationHistoryParameters{})
 			if err == nil {
 				return errors.New("expected error, got nil")
@@ -0,0 +1,128 @@
+package network
+
+// In this file: the rate limiter statistics.
+
+import (
+	"context"
+	"runtime/trace"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// TierStats is the snapshot of the rate limiter statistics of a tier.
+type TierStats struct {
+	Tier        Tier
+	Waits       int64         // number of limiter waits, i.e. API calls
+	Delay       time.Duration // total time spent waiting on the limiter
+	RateLimited int64         // number of rate limit errors returned by Slack
+	Backoff     time.Duration // total time spent sleeping before the retries
+}
+
+// tierCounters holds the counters of a tier.  The counters are updated by
+// the worker goroutines, so all access must be atomic.
+type tierCounters struct {
+	waits       int64
+	delay       int64 // nanoseconds
+	rateLimited int64
+	backoff     int64 // nanoseconds
+}
+
+// counters holds the counters for the known tiers, the map itself is never
+// modified, so it can be read without locking.
+var counters = map[Tier]*tierCounters{
+	NoTier: {},
+	Tier2:  {},
+	Tier3:  {},
+	Tier4:  {},
+}
+
+// String returns the name of the tier.
+func (t Tier) String() string {
+	switch t {
+	case NoTier:
+		return "none"
+	case Tier2:
+		return "tier2"
+	case Tier3:
+		return "tier3"
+	case Tier4:
+		return "tier4"
+	}
+	return "unknown"
+}
+
+// countersFor returns the counters for the tier t.  The unknown tiers are
+// accounted as NoTier.
+func countersFor(t Tier) *tierCounters {
+	c, ok := counters[t]
+	if !ok {
+		return counters[NoTier]
+	}
+	return c
+}
+
+func (c *tierCounters) addWait(d time.Duration) {
+	atomic.AddInt64(&c.waits, 1)
+	atomic.AddInt64(&c.delay, int64(d))
+}
+
+func (c *tierCounters) addBackoff(d time.Duration, rateLimited bool) {
+	if rateLimited {
+		atomic.AddInt64(&c.rateLimited, 1)
+	}
+	atomic.AddInt64(&c.backoff, int64(d))
+}
+
+// Stats returns the rate limiter statistics for the tiers that had any waits,
+// sorted by tier.
+func Stats() []TierStats {
+	var stats []TierStats
+	for t, c := range counters {
+		ts := TierStats{
+			Tier:        t,
+			Waits:       atomic.LoadInt64(&c.waits),
+			Delay:       time.Duration(atomic.LoadInt64(&c.delay)),
+			RateLimited: atomic.LoadInt64(&c.rateLimited),
+			Backoff:     time.Duration(atomic.LoadInt64(&c.backoff)),
+		}
+		if ts.Waits == 0 {
+			continue
+		}
+		stats = append(stats, ts)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Tier < stats[j].Tier })
+	return stats
+}
+
+// ResetStats resets the rate limiter statistics.
+func ResetStats() {
+	for _, c := range counters {
+		atomic.StoreInt64(&c.waits, 0)
+		atomic.StoreInt64(&c.delay, 0)
+		atomic.StoreInt64(&c.rateLimited, 0)
+		atomic.StoreInt64(&c.backoff, 0)
+	}
+}
+
+// LogStats logs the rate limiter statistics to the trace and to the debug
+// log.
+func LogStats(ctx context.Context) {
+	for _, ts := range Stats() {
+		tracelogf(ctx, "stats", "limiter %s: waits: %d, delay: %s, rate limited: %d, backoff: %s", ts.Tier, ts.Waits, ts.Delay, ts.RateLimited, ts.Backoff)
+	}
+}
+
+// traceWait waits on the limiter l and accounts the wait time.
+func traceWait(ctx context.Context, l *Limiter) error {
+	var err error
+	trace.WithRegion(ctx, "WithRetry.wait", func() {
+		start := time.Now()
+		if err = l.wait(ctx); err != nil {
+			return
+		}
+		err = l.Limiter.Wait(ctx)
+		l.counters.addWait(time.Since(start))
+	})
+	return err
+}
@@ -0,0 +1,82 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestStats(t *testing.T) {
+	ResetStats()
+	defer ResetStats()
+
+	var (
+		ctx  = context.Background()
+		t3   = NewLimiter(Tier3, 100, 6000)
+		t2   = NewLimiter(Tier2, 100, 6000)
+		anon = AdaptiveLimiter(rate.NewLimiter(rate.Inf, 1))
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := WithRetry(ctx, t3, 1, func() error { return nil }); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := WithRetry(ctx, t2, 2, retryFn(1, time.Millisecond, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := WithRetry(ctx, anon, 1, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Stats()
+	if len(got) != 3 {
+		t.Fatalf("Stats() returned %d tiers, want 3: %+v", len(got), got)
+	}
+	want := []struct {
+		tier        Tier
+		waits       int64
+		rateLimited int64
+		backoff     time.Duration
+	}{
+		{Tier2, 2, 1, time.Millisecond},
+		{Tier3, 10, 0, 0},
+		{NoTier, 1, 0, 0},
+	}
+	for i, w := range want {
+		if got[i].Tier != w.tier || got[i].Waits != w.waits || got[i].RateLimited != w.rateLimited || got[i].Backoff != w.backoff {
+			t.Errorf("Stats()[%d] = %+v, want %+v", i, got[i], w)
+		}
+	}
+
+	ResetStats()
+	if got := Stats(); len(got) != 0 {
+		t.Errorf("Stats() after reset = %+v, want empty", got)
+	}
+}
+
+func TestTier_String(t *testing.T) {
+	tests := []struct {
+		t    Tier
+		want string
+	}{
+		{NoTier, "none"},
+		{Tier2, "tier2"},
+		{Tier3, "tier3"},
+		{Tier4, "tier4"},
+		{Tier(42), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.t.String(); got != tt.want {
+			t.Errorf("Tier(%d).String() = %q, want %q", int(tt.t), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+// Package redact replaces the personal information of the users, i.e. the
+// names, emails and phone numbers, in the user records and message text with
+// the pseudonyms.  The pseudonym of a user is derived from the user ID, so it
+// is the same across the runs.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// Domain is the domain of the redacted email addresses.
+const Domain = "redacted.invalid"
+
+// minNameLen is the minimum length of the name to be redacted in the message
+// text, the shorter names would match too many words.
+const minNameLen = 3
+
+// reEmail matches the email addresses in the message text.
+var reEmail = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// Entry is the entry of the redaction map, it holds the original values,
+// replaced with the pseudonym.
+type Entry struct {
+	Pseudonym   string `json:"pseudonym"`
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	RealName    string `json:"real_name,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+}
+
+// Redactor redacts the users and messages.  It is safe for concurrent use.
+// The nil Redactor does not modify the data.
+type Redactor struct {
+	re   *regexp.Regexp    // matches the known values, nil if there are none
+	repl map[string]string // lowercase known value -> replacement
+
+	mu      sync.Mutex
+	entries map[string]*Entry // pseudonym -> entry
+}
+
+// Pseudonym returns the pseudonym for the user ID.
+func Pseudonym(id string) string {
+	return "user-" + hash(id)
+}
+
+// Email returns the redacted email address for the pseudonym.
+func Email(pseudonym string) string {
+	return pseudonym + "@" + Domain
+}
+
+// Phone returns the redacted phone number for the pseudonym.
+func Phone(pseudonym string) string {
+	return "phone-" + strings.TrimPrefix(pseudonym, "user-")
+}
+
+func hash(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:4])
+}
+
+// New creates a new Redactor for the users us.
+func New(us types.Users) *Redactor {
+	r := &Redactor{
+		repl:    make(map[string]string),
+		entries: make(map[string]*Entry, len(us)),
+	}
+	for i := range us {
+		u := &us[i]
+		e := &Entry{
+			Pseudonym:   Pseudonym(u.ID),
+			ID:          u.ID,
+			Name:        u.Name,
+			RealName:    u.RealName,
+			DisplayName: u.Profile.DisplayName,
+			Email:       u.Profile.Email,
+			Phone:       u.Profile.Phone,
+		}
+		r.entries[e.Pseudonym] = e
+
+		for _, name := range []string{u.Name, u.RealName, u.Profile.RealName, u.Profile.RealNameNormalized, u.Profile.DisplayName, u.Profile.DisplayNameNormalized} {
+			if len(name) >= minNameLen {
+				r.add(name, e.Pseudonym)
+			}
+		}
+		if e.Email != "" {
+			r.add(e.Email, Email(e.Pseudonym))
+		}
+		if e.Phone != "" {
+			r.add(e.Phone, Phone(e.Pseudonym))
+		}
+	}
+	r.compile()
+	return r
+}
+
+// add adds the value to the known values, the first replacement wins.
+func (r *Redactor) add(value, replacement string) {
+	value = strings.TrimSpace(value)
+	key := strings.ToLower(value)
+	if value == "" || key == strings.ToLower(replacement) {
+		return
+	}
+	if _, ok := r.repl[key]; !ok {
+		r.repl[key] = replacement
+	}
+}
+
+// compile compiles the regular expression, that matches all known values.
+// The longer values go first, so that the full name is replaced before the
+// first name.
+func (r *Redactor) compile() {
+	if len(r.repl) == 0 {
+		return
+	}
+	values := make([]string, 0, len(r.repl))
+	for v := range r.repl {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	alts := make([]string, len(values))
+	for i, v := range values {
+		// \b only works between the word and non-word characters.
+		alt := regexp.QuoteMeta(v)
+		if isWordChar(v[0]) {
+			alt = `\b` + alt
+		}
+		if isWordChar(v[len(v)-1]) {
+			alt += `\b`
+		}
+		alts[i] = alt
+	}
+	r.re = regexp.MustCompile(`(?i)(?:` + strings.Join(alts, "|") + `)`)
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// Text redacts the names, emails and phone numbers of the users in the text.
+// Unknown email addresses are replaced with the pseudonyms derived from the
+// address.
+func (r *Redactor) Text(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	if r.re != nil {
+		s = r.re.ReplaceAllStringFunc(s, func(v string) string {
+			if rep, ok := r.repl[strings.ToLower(v)]; ok {
+				return rep
+			}
+			// case folding may not round trip for some letters.
+			return "[redacted]"
+		})
+	}
+	return reEmail.ReplaceAllStringFunc(s, func(email string) string {
+		if strings.HasSuffix(strings.ToLower(email), "@"+Domain) {
+			return email
+		}
+		e := r.entry(&Entry{Pseudonym: "email-" + hash(strings.ToLower(email)), Email: email})
+		return Email(e.Pseudonym)
+	})
+}
+
+// entry returns the entry with the same pseudonym as e, adding e to the map,
+// if it's not there yet.
+func (r *Redactor) entry(e *Entry) *Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.entries[e.Pseudonym]; ok {
+		return existing
+	}
+	r.entries[e.Pseudonym] = e
+	return e
+}
+
+// User returns the copy of the user u with the personal information
+// replaced by the pseudonym.
+func (r *Redactor) User(u slack.User) slack.User {
+	if r == nil {
+		return u
+	}
+	p := Pseudonym(u.ID)
+	u.Name = p
+	u.RealName = p
+	u.Profile.RealName = p
+	u.Profile.RealNameNormalized = p
+	u.Profile.DisplayName = p
+	u.Profile.DisplayNameNormalized = p
+	u.Profile.FirstName = p
+	u.Profile.LastName = ""
+	if u.Profile.Email != "" {
+		u.Profile.Email = Email(p)
+	}
+	if u.Profile.Phone != "" {
+		u.Profile.Phone = Phone(p)
+	}
+	u.Profile.StatusText = r.Text(u.Profile.StatusText)
+	u.Profile.SetFieldsMap(nil) // custom profile fields may contain anything
+	return u
+}
+
+// Users returns the redacted copy of the users us.
+func (r *Redactor) Users(us types.Users) types.Users {
+	if r == nil {
+		return us
+	}
+	ret := make(types.Users, len(us))
+	for i := range us {
+		ret[i] = r.User(us[i])
+	}
+	return ret
+}
+
+// Messages redacts the text of the messages and their thread replies in
+// place.  The rich text blocks duplicate the message text, so they are
+// removed.
+func (r *Redactor) Messages(msgs []types.Message) {
+	if r == nil {
+		return
+	}
+	for i := range msgs {
+		m := &msgs[i]
+		m.Text = r.Text(m.Text)
+		m.Username = r.Text(m.Username)
+		m.Blocks = slack.Blocks{}
+		for j := range m.Attachments {
+			a := &m.Attachments[j]
+			a.Text = r.Text(a.Text)
+			a.Fallback = r.Text(a.Fallback)
+			a.Pretext = r.Text(a.Pretext)
+			a.Title = r.Text(a.Title)
+			a.AuthorName = r.Text(a.AuthorName)
+		}
+		r.Messages(m.ThreadReplies)
+	}
+}
+
+// Channel returns the copy of the channel ch with the user names redacted in
+// the names of the group conversations, and in the purpose and topic.
+func (r *Redactor) Channel(ch slack.Channel) slack.Channel {
+	if r == nil {
+		return ch
+	}
+	if ch.IsMpIM {
+		ch.Name = r.Text(ch.Name)
+		ch.NameNormalized = r.Text(ch.NameNormalized)
+	}
+	ch.Purpose.Value = r.Text(ch.Purpose.Value)
+	ch.Topic.Value = r.Text(ch.Topic.Value)
+	return ch
+}
+
+// Channels returns the redacted copy of the channels chans.
+func (r *Redactor) Channels(chans []slack.Channel) []slack.Channel {
+	if r == nil {
+		return chans
+	}
+	ret := make([]slack.Channel, len(chans))
+	for i := range chans {
+		ret[i] = r.Channel(chans[i])
+	}
+	return ret
+}
+
+// Map returns the redaction map, sorted by pseudonym.
+func (r *Redactor) Map() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := make([]Entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		m = append(m, *e)
+	}
+	sort.Slice(m, func(i, j int) bool { return m[i].Pseudonym < m[j].Pseudonym })
+	return m
+}
+
+// WriteMap writes the redaction map to w in JSON format.
+func (r *Redactor) WriteMap(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Map())
+}
+
+// ReadMap reads the redaction map, written by WriteMap, from rd.
+func ReadMap(rd io.Reader) ([]Entry, error) {
+	var m []Entry
+	if err := json.NewDecoder(rd).Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,154 @@
+package redact
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+var testUsers = types.Users{
+	{
+		ID:       "U01",
+		Name:     "jsmith",
+		RealName: "John Smith",
+		Profile:  slack.UserProfile{RealName: "John Smith", DisplayName: "Johnny", Email: "john@example.com", Phone: "+1 555 0100"},
+	},
+	{
+		ID:       "U02",
+		Name:     "al",
+		RealName: "Al",
+		Profile:  slack.UserProfile{RealName: "Al", Email: "al@example.com"},
+	},
+}
+
+func TestPseudonym(t *testing.T) {
+	assert.Equal(t, Pseudonym("U01"), Pseudonym("U01"), "must be stable")
+	assert.NotEqual(t, Pseudonym("U01"), Pseudonym("U02"))
+	assert.Regexp(t, `^user-[0-9a-f]{8}$`, Pseudonym("U01"))
+}
+
+func TestRedactor_Text(t *testing.T) {
+	r := New(testUsers)
+	p1, p2 := Pseudonym("U01"), Pseudonym("U02")
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"full name", "ask John Smith", "ask " + p1},
+		{"case insensitive", "ask john smith", "ask " + p1},
+		{"display name", "thanks, Johnny!", "thanks, " + p1 + "!"},
+		{"username", "@jsmith said", "@" + p1 + " said"},
+		{"email", "write to <mailto:john@example.com|john@example.com>", "write to <mailto:" + Email(p1) + "|" + Email(p1) + ">"},
+		{"phone", "call +1 555 0100 now", "call " + Phone(p1) + " now"},
+		{"part of a word", "Johnnyboy and Smithson", "Johnnyboy and Smithson"},
+		{"short name is not replaced", "Al said also", "Al said also"},
+		{"email of the user with the short name", "al@example.com", Email(p2)},
+		{"unknown email", "bob@example.org", Email("email-" + hash("bob@example.org"))},
+		{"redacted email is kept", Email(p1), Email(p1)},
+		{"nothing to redact", "hello world", "hello world"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Text(tt.s))
+		})
+	}
+}
+
+func TestRedactor_Users(t *testing.T) {
+	r := New(testUsers)
+	got := r.Users(testUsers)
+	p := Pseudonym("U01")
+	assert.Equal(t, "U01", got[0].ID)
+	assert.Equal(t, p, got[0].Name)
+	assert.Equal(t, p, got[0].RealName)
+	assert.Equal(t, p, got[0].Profile.RealName)
+	assert.Equal(t, p, got[0].Profile.DisplayName)
+	assert.Equal(t, Email(p), got[0].Profile.Email)
+	assert.Equal(t, Phone(p), got[0].Profile.Phone)
+	assert.Equal(t, "", got[1].Profile.Phone, "empty values stay empty")
+	assert.Equal(t, "John Smith", testUsers[0].RealName, "original must not be modified")
+}
+
+func TestRedactor_Messages(t *testing.T) {
+	r := New(testUsers)
+	p := Pseudonym("U01")
+	msgs := []types.Message{
+		{
+			Message: slack.Message{Msg: slack.Msg{
+				Text:        "John Smith joined",
+				Blocks:      slack.Blocks{BlockSet: []slack.Block{slack.NewDividerBlock()}},
+				Attachments: []slack.Attachment{{Text: "by john@example.com"}},
+			}},
+			ThreadReplies: []types.Message{
+				{Message: slack.Message{Msg: slack.Msg{Text: "hi Johnny"}}},
+			},
+		},
+	}
+	r.Messages(msgs)
+	assert.Equal(t, p+" joined", msgs[0].Text)
+	assert.Empty(t, msgs[0].Blocks.BlockSet)
+	assert.Equal(t, "by "+Email(p), msgs[0].Attachments[0].Text)
+	assert.Equal(t, "hi "+p, msgs[0].ThreadReplies[0].Text)
+}
+
+func TestRedactor_Channel(t *testing.T) {
+	r := New(testUsers)
+	p := Pseudonym("U01")
+	ch := slack.Channel{}
+	ch.Name = "mpdm-jsmith--bob-1"
+	ch.IsMpIM = true
+	ch.Purpose.Value = "Group messaging with: @jsmith @bob"
+	got := r.Channel(ch)
+	assert.Equal(t, "mpdm-"+p+"--bob-1", got.Name)
+	assert.Equal(t, "Group messaging with: @"+p+" @bob", got.Purpose.Value)
+
+	pub := slack.Channel{}
+	pub.Name = "jsmith-project"
+	assert.Equal(t, "jsmith-project", r.Channel(pub).Name, "only the group conversation names are redacted")
+}
+
+func TestRedactor_nil(t *testing.T) {
+	var r *Redactor
+	assert.Equal(t, "John Smith", r.Text("John Smith"))
+	assert.Equal(t, testUsers, r.Users(testUsers))
+	msgs := []types.Message{{Message: slack.Message{Msg: slack.Msg{Text: "John Smith"}}}}
+	r.Messages(msgs)
+	assert.Equal(t, "John Smith", msgs[0].Text)
+}
+
+func TestRedactor_WriteMap(t *testing.T) {
+	r := New(testUsers)
+	_ = r.Text("bob@example.org")
+
+	var buf bytes.Buffer
+	if err := r.WriteMap(&buf); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadMap(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, r.Map(), m)
+	assert.Len(t, m, 3)
+	var found bool
+	for _, e := range m {
+		if e.Pseudonym == Pseudonym("U01") {
+			found = true
+			assert.Equal(t, Entry{Pseudonym: Pseudonym("U01"), ID: "U01", Name: "jsmith", RealName: "John Smith", DisplayName: "Johnny", Email: "john@example.com", Phone: "+1 555 0100"}, e)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestRedactor_User_customFields(t *testing.T) {
+	u := testUsers[0]
+	u.Profile.SetFieldsMap(map[string]slack.UserProfileCustomField{"Xf01": {Value: "Platform", Label: "Team"}})
+	got := New(testUsers).User(u)
+	assert.Equal(t, 0, got.Profile.Fields.Len())
+	assert.Equal(t, 1, u.Profile.Fields.Len(), "original must not be modified")
+}
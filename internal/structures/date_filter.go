@@ -0,0 +1,77 @@
+package structures
+
+import (
+	"sort"
+	"time"
+)
+
+// DateFilter limits the conversation messages to the time range.  Zero Start
+// or End means that the range is open on that side.
+type DateFilter struct {
+	Start time.Time // messages posted at or after Start
+	End   time.Time // messages posted before End
+}
+
+// IsZero returns true if the filter is not set.
+func (df DateFilter) IsZero() bool {
+	return df.Start.IsZero() && df.End.IsZero()
+}
+
+// endsBefore returns true if the range df ends before t.  Range, that is
+// open at the end, never ends.
+func (df DateFilter) endsBefore(t time.Time) bool {
+	return !df.End.IsZero() && df.End.Before(t)
+}
+
+// MergeDateFilters merges the overlapping and adjacent date ranges, so that
+// the messages would be fetched once.  It returns the disjoint ranges, sorted
+// by the start date.  Empty filters are dropped, unless all filters are
+// empty, in which case it returns nil.
+func MergeDateFilters(dfs []DateFilter) []DateFilter {
+	var sorted []DateFilter
+	for _, df := range dfs {
+		if !df.IsZero() {
+			sorted = append(sorted, df)
+		}
+	}
+	if len(sorted) == 0 {
+		return nil
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		// zero Start is the earliest.
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+	merged := []DateFilter{sorted[0]}
+	for _, df := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if last.endsBefore(df.Start) {
+			merged = append(merged, df)
+			continue
+		}
+		if last.End.IsZero() || df.End.IsZero() {
+			last.End = time.Time{}
+		} else if df.End.After(last.End) {
+			last.End = df.End
+		}
+	}
+	return merged
+}
+
+// SpanDateFilters returns the date range, that covers all ranges of dfs.
+func SpanDateFilters(dfs []DateFilter) DateFilter {
+	if len(dfs) == 0 {
+		return DateFilter{}
+	}
+	span := dfs[0]
+	for _, df := range dfs[1:] {
+		if df.Start.IsZero() || df.Start.Before(span.Start) {
+			span.Start = df.Start
+		}
+		if span.End.IsZero() || df.End.IsZero() {
+			span.End = time.Time{}
+		} else if df.End.After(span.End) {
+			span.End = df.End
+		}
+	}
+	return span
+}
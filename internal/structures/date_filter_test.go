@@ -0,0 +1,68 @@
+package structures
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeDateFilters(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		dfs  []DateFilter
+		want []DateFilter
+	}{
+		{"nil", nil, nil},
+		{"empty filters are dropped", []DateFilter{{}, {}}, nil},
+		{"single", []DateFilter{{Start: day(1), End: day(2)}}, []DateFilter{{Start: day(1), End: day(2)}}},
+		{
+			"disjoint are sorted",
+			[]DateFilter{{Start: day(10), End: day(12)}, {Start: day(1), End: day(3)}},
+			[]DateFilter{{Start: day(1), End: day(3)}, {Start: day(10), End: day(12)}},
+		},
+		{
+			"adjacent",
+			[]DateFilter{{Start: day(1), End: day(3)}, {Start: day(3), End: day(5)}},
+			[]DateFilter{{Start: day(1), End: day(5)}},
+		},
+		{
+			"overlapping",
+			[]DateFilter{{Start: day(4), End: day(8)}, {Start: day(1), End: day(5)}},
+			[]DateFilter{{Start: day(1), End: day(8)}},
+		},
+		{
+			"contained",
+			[]DateFilter{{Start: day(1), End: day(10)}, {Start: day(3), End: day(5)}},
+			[]DateFilter{{Start: day(1), End: day(10)}},
+		},
+		{
+			"open start",
+			[]DateFilter{{Start: day(3), End: day(5)}, {End: day(4)}},
+			[]DateFilter{{End: day(5)}},
+		},
+		{
+			"open end",
+			[]DateFilter{{Start: day(3)}, {Start: day(1), End: day(2)}, {Start: day(5), End: day(8)}},
+			[]DateFilter{{Start: day(1), End: day(2)}, {Start: day(3)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MergeDateFilters(tt.dfs))
+		})
+	}
+}
+
+func TestSpanDateFilters(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	assert.Equal(t, DateFilter{}, SpanDateFilters(nil))
+	assert.Equal(t, DateFilter{Start: day(1), End: day(12)}, SpanDateFilters([]DateFilter{{Start: day(1), End: day(3)}, {Start: day(10), End: day(12)}}))
+	assert.Equal(t, DateFilter{End: day(12)}, SpanDateFilters([]DateFilter{{End: day(3)}, {Start: day(10), End: day(12)}}))
+	assert.Equal(t, DateFilter{Start: day(1)}, SpanDateFilters([]DateFilter{{Start: day(1), End: day(3)}, {Start: day(10)}}))
+}
@@ -2,6 +2,7 @@ package structures
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"os"
 	"sort"
@@ -25,6 +26,13 @@ const (
 type EntityList struct {
 	Include []string
 	Exclude []string
+	// DateFilters, if set, limit the messages of the listed conversations to
+	// the date ranges.  The ranges are disjoint and sorted, see
+	// MergeDateFilters.
+	DateFilters []DateFilter
+	// Patterns are the channel name patterns, that are resolved to channel
+	// IDs by ResolvePatterns, once the channel names are available.
+	Patterns []NamePattern
 }
 
 func HasExcludePrefix(s string) bool {
@@ -39,23 +47,30 @@ func hasFilePrefix(s string) bool {
 func MakeEntityList(entities []string) (*EntityList, error) {
 	var el EntityList
 
-	index, err := buildEntityIndex(entities)
+	index, patterns, err := buildEntityIndex(entities)
 	if err != nil {
 		return nil, err
 	}
 	el.fromIndex(index)
+	el.Patterns = patterns
 
 	return &el, nil
 }
 
-// MakeEntityList creates an EntityList from a slice of IDs or URLs (entites).
+// LoadEntityList creates an EntityList from the file, that contains one ID
+// or URL per line.  Blank lines and comments, starting with "#", are ignored.
+// Lines may have DOS (CRLF) line endings.
 func LoadEntityList(filename string) (*EntityList, error) {
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
-	return readEntityList(f, maxFileEntries)
+	el, err := readEntityList(f, maxFileEntries)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return el, nil
 }
 
 // readEntityList is a rather naïve implementation that reads the entire file up
@@ -77,8 +92,8 @@ func readEntityList(r io.Reader, maxEntries int) (*EntityList, error) {
 		} else if err != nil {
 			return nil, err
 		}
-		line = strings.TrimSpace(line)
-		if line == "" || line[0] == '#' {
+		line = stripComment(line)
+		if line == "" {
 			if exit {
 				break
 			}
@@ -95,6 +110,22 @@ func readEntityList(r io.Reader, maxEntries int) (*EntityList, error) {
 	return MakeEntityList(elements)
 }
 
+// stripComment removes the comment from the line, and trims the spaces.  The
+// comment starts with "#" at the beginning of the line, or after the
+// whitespace, i.e. "C123  # general".
+func stripComment(line string) string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "#") {
+		return ""
+	}
+	if idx := strings.IndexAny(line, " \t"); idx >= 0 {
+		if c := strings.Index(line[idx:], "#"); c >= 0 {
+			line = line[:idx+c]
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
 func (el *EntityList) fromIndex(index map[string]bool) {
 	for ent, include := range index {
 		if include {
@@ -120,22 +151,29 @@ func (el *EntityList) Index() map[string]bool {
 	return idx
 }
 
+// HasIncludes returns true if the list has included entities or include name
+// patterns.
 func (el *EntityList) HasIncludes() bool {
-	return len(el.Include) > 0
+	return len(el.Include) > 0 || el.hasPatterns(false)
 }
 
+// HasExcludes returns true if the list has excluded entities or exclude name
+// patterns.
 func (el *EntityList) HasExcludes() bool {
-	return len(el.Exclude) > 0
+	return len(el.Exclude) > 0 || el.hasPatterns(true)
 }
 
 func (el *EntityList) IsEmpty() bool {
-	return len(el.Include)+len(el.Exclude) == 0
+	return len(el.Include)+len(el.Exclude)+len(el.Patterns) == 0
 }
 
-func buildEntityIndex(entities []string) (map[string]bool, error) {
+// buildEntityIndex builds the index of entities, and returns the channel name
+// patterns separately, as they can't be resolved without the channel list.
+func buildEntityIndex(entities []string) (map[string]bool, []NamePattern, error) {
 	var index = make(map[string]bool, len(entities))
 	var excluded []string
 	var files []string
+	var patterns []NamePattern
 	// add all included items
 	for _, ent := range entities {
 		if ent == "" {
@@ -147,9 +185,17 @@ func buildEntityIndex(entities []string) (map[string]bool, error) {
 			if trimmed == "" {
 				continue
 			}
+			if isNamePattern(trimmed) {
+				np, err := newNamePattern(trimmed, true)
+				if err != nil {
+					return nil, nil, err
+				}
+				patterns = append(patterns, np)
+				continue
+			}
 			sl, err := ParseLink(trimmed)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			excluded = append(excluded, sl.String())
 		case hasFilePrefix(ent):
@@ -158,10 +204,16 @@ func buildEntityIndex(entities []string) (map[string]bool, error) {
 				continue
 			}
 			files = append(files, trimmed)
+		case isNamePattern(ent):
+			np, err := newNamePattern(ent, false)
+			if err != nil {
+				return nil, nil, err
+			}
+			patterns = append(patterns, np)
 		default:
 			sl, err := ParseLink(ent)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			index[sl.String()] = true
 		}
@@ -170,8 +222,9 @@ func buildEntityIndex(entities []string) (map[string]bool, error) {
 	for _, file := range files {
 		el, err := LoadEntityList(file)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		patterns = append(patterns, el.Patterns...)
 		for ent, include := range el.Index() {
 			if include {
 				index[ent] = true
@@ -183,5 +236,5 @@ func buildEntityIndex(entities []string) (map[string]bool, error) {
 	for _, ent := range excluded {
 		index[ent] = false
 	}
-	return index, nil
+	return index, patterns, nil
 }
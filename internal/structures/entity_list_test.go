@@ -184,6 +184,33 @@ func Test_readEntityList(t *testing.T) {
 			&EntityList{Exclude: []string{"CHM82GF99" + linkSep + "1577694990.000400"}},
 			false,
 		},
+		{
+			"CRLF line endings",
+			args{strings.NewReader("C123\r\n#C555\r\n^C321\r\n\r\nC777\r\n"), maxFileEntries},
+			&EntityList{
+				Include: []string{"C123", "C777"},
+				Exclude: []string{"C321"},
+			},
+			false,
+		},
+		{
+			"duplicate entries",
+			args{strings.NewReader("C123\nC555\nC123\n^C321\n^C321\n"), maxFileEntries},
+			&EntityList{
+				Include: []string{"C123", "C555"},
+				Exclude: []string{"C321"},
+			},
+			false,
+		},
+		{
+			"trailing comments",
+			args{strings.NewReader("C123  # general\n^C321\t# random\n"), maxFileEntries},
+			&EntityList{
+				Include: []string{"C123"},
+				Exclude: []string{"C321"},
+			},
+			false,
+		},
 		{
 			"empty file",
 			args{strings.NewReader(""), maxFileEntries},
@@ -449,7 +476,7 @@ func Test_buildEntityIndex(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := buildEntityIndex(tt.args.entities)
+			got, _, err := buildEntityIndex(tt.args.entities)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("buildEntityIndex() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -472,3 +499,29 @@ func mkTestFile(dir string, content string) string {
 	}
 	return f.Name()
 }
+
+func TestMakeEntityList_file(t *testing.T) {
+	dir := t.TempDir()
+	filename := dir + string(os.PathSeparator) + "channels.txt"
+	if err := os.WriteFile(filename, []byte("# my channels\r\nC123\r\nC555\r\n^C321\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("file and inline entries are merged", func(t *testing.T) {
+		got, err := MakeEntityList([]string{"C777", "@" + filename, "C123", "^C555"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &EntityList{
+			Include: []string{"C123", "C777"},
+			Exclude: []string{"C321", "C555"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("MakeEntityList() = %v, want %v", got, want)
+		}
+	})
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := MakeEntityList([]string{"@" + filename + ".missing"}); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
@@ -57,6 +57,21 @@ func Update(msgs []types.Message, addr Addr, fn func(*slack.File) error) error {
 	return nil
 }
 
+// Message returns the message that contains the file at address addr, or nil,
+// if the address references out of range.
+func Message(msgs []types.Message, addr Addr) *types.Message {
+	if addr.idxParMsg != Root {
+		if addr.idxParMsg < 0 || len(msgs) <= addr.idxParMsg {
+			return nil
+		}
+		return Message(msgs[addr.idxParMsg].ThreadReplies, Addr{idxMsg: addr.idxMsg, idxParMsg: Root, idxFile: addr.idxFile})
+	}
+	if addr.idxMsg < 0 || len(msgs) <= addr.idxMsg {
+		return nil
+	}
+	return &msgs[addr.idxMsg]
+}
+
 // Extract scans the message slice msgs, and calls fn for each file it
 // finds. fn is called with the copy of the file and the files' address in the
 // provided message slice.  idxParentMsg is the index of the parent message (for
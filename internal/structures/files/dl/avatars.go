@@ -0,0 +1,83 @@
+package dl
+
+// user avatars
+
+import (
+	"errors"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/downloader"
+)
+
+// AvatarDir is the directory in the root of the export, where the user
+// avatars are saved.
+const AvatarDir = "avatars"
+
+// Avatars submits the custom profile images of the users for download into
+// the AvatarDir directory, and returns the copy of users, where the profile
+// image URLs of the submitted users are replaced with the path of the
+// downloaded image, relative to the root of the export.  Users without the
+// custom profile image are returned as is.  Downloader must be started.
+func (bd *base) Avatars(users []slack.User) ([]slack.User, error) {
+	ret := make([]slack.User, len(users))
+	copy(ret, users)
+	for i := range ret {
+		f, ok := avatarFile(&ret[i])
+		if !ok {
+			continue
+		}
+		filename, err := bd.dl.DownloadFile(AvatarDir, f)
+		if errors.Is(err, downloader.ErrSkipped) {
+			bd.l.Debugf("skipped avatar of %s", ret[i].ID)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		setAvatar(&ret[i].Profile, filename)
+	}
+	return ret, nil
+}
+
+// avatarFile returns the file for the custom profile image of the user u.
+// The image_original is only set by Slack for the custom images, the
+// default ones are the generated avatars, which are not worth saving.
+func avatarFile(u *slack.User) (slack.File, bool) {
+	p := &u.Profile
+	if p.ImageOriginal == "" {
+		return slack.File{}, false
+	}
+	src := p.Image192
+	if src == "" {
+		src = p.ImageOriginal
+	}
+	uri, err := url.Parse(src)
+	if err != nil || uri.Path == "" {
+		return slack.File{}, false
+	}
+	name := path.Base(uri.Path)
+	if name == "/" || name == "." {
+		return slack.File{}, false
+	}
+	// file type and mime type are set, so that the type filters of the
+	// downloader apply to the avatars the same way as to the other images.
+	ext := path.Ext(name)
+	return slack.File{
+		ID:                 u.ID,
+		Name:               name,
+		Filetype:           strings.TrimPrefix(ext, "."),
+		Mimetype:           mime.TypeByExtension(ext),
+		URLPrivateDownload: src,
+	}, true
+}
+
+// setAvatar replaces all profile image URLs of p with the filename.
+func setAvatar(p *slack.UserProfile, filename string) {
+	for _, img := range []*string{&p.Image24, &p.Image32, &p.Image48, &p.Image72, &p.Image192, &p.Image512, &p.ImageOriginal} {
+		*img = filename
+	}
+}
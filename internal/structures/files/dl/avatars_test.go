@@ -0,0 +1,71 @@
+package dl
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func Test_base_Avatars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dc := mock_downloader.NewMockDownloader(ctrl)
+	dc.EXPECT().GetFile("https://avatars.slack-edge.com/2023-01-01/abc_192.jpg", gomock.Any()).DoAndReturn(func(_ string, w io.Writer) error {
+		_, err := io.WriteString(w, "jpeg")
+		return err
+	})
+
+	bd := base{
+		dl: downloader.New(dc, fsadapter.NewDirectory(t.TempDir()), downloader.Logger(logger.Silent)),
+		l:  logger.Silent,
+		m:  new(manifest),
+	}
+	users := []slack.User{
+		{ID: "U01", Profile: slack.UserProfile{
+			Image72:       "https://avatars.slack-edge.com/2023-01-01/abc_72.jpg",
+			Image192:      "https://avatars.slack-edge.com/2023-01-01/abc_192.jpg",
+			ImageOriginal: "https://avatars.slack-edge.com/2023-01-01/abc_original.jpg",
+		}},
+		{ID: "U02", Profile: slack.UserProfile{
+			Image72: "https://secure.gravatar.com/avatar/123.jpg?s=72&d=https%3A%2F%2Fa.slack-edge.com%2Fdf10d%2Fimg%2Favatars%2Fava_0001-72.png",
+		}},
+	}
+
+	bd.Start(context.Background())
+	got, err := bd.Avatars(users)
+	bd.Stop()
+	require.NoError(t, err)
+
+	assert.Equal(t, "avatars/U01-abc_192.jpg", got[0].Profile.Image72)
+	assert.Equal(t, "avatars/U01-abc_192.jpg", got[0].Profile.ImageOriginal)
+	assert.Equal(t, users[1], got[1], "default avatar must be skipped")
+	assert.Equal(t, "https://avatars.slack-edge.com/2023-01-01/abc_72.jpg", users[0].Profile.Image72, "input must not be modified")
+	assert.Equal(t, 1, bd.Stats().Saved)
+}
+
+func Test_avatarFile(t *testing.T) {
+	u := slack.User{ID: "U01", Profile: slack.UserProfile{
+		ImageOriginal: "https://avatars.slack-edge.com/2023-01-01/abc_original.png",
+	}}
+	f, ok := avatarFile(&u)
+	assert.True(t, ok)
+	assert.Equal(t, slack.File{
+		ID:                 "U01",
+		Name:               "abc_original.png",
+		Filetype:           "png",
+		Mimetype:           "image/png",
+		URLPrivateDownload: "https://avatars.slack-edge.com/2023-01-01/abc_original.png",
+	}, f)
+
+	_, ok = avatarFile(&slack.User{ID: "U02"})
+	assert.False(t, ok, "no custom image")
+}
@@ -3,6 +3,7 @@ package dl
 import (
 	"context"
 
+	"github.com/rusq/slackdump/v2/downloader"
 	"github.com/rusq/slackdump/v2/logger"
 )
 
@@ -11,7 +12,9 @@ const entFiles = "files"
 type base struct {
 	dl    exportDownloader
 	token string // token is the token that will be appended to each file URL.
+	strip bool   // strip removes the Slack URLs of the files, that were not downloaded.
 	l     logger.Interface
+	m     *manifest // files submitted for download
 }
 
 func (bd *base) Start(ctx context.Context) {
@@ -21,3 +24,14 @@ func (bd *base) Start(ctx context.Context) {
 func (bd *base) Stop() {
 	bd.dl.Stop()
 }
+
+// Stats returns the file download statistics.
+func (bd *base) Stats() downloader.Stats {
+	return bd.dl.Stats()
+}
+
+// Manifest returns the manifest of all files submitted for download,
+// including the skipped and the failed ones.
+func (bd *base) Manifest() []ManifestEntry {
+	return bd.m.resolve(bd.dl.Records())
+}
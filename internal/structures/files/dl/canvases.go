@@ -0,0 +1,40 @@
+package dl
+
+// channel canvases and lists
+
+import (
+	"errors"
+	"path"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/downloader"
+)
+
+// CanvasDir is the directory in the root of the export, where the canvases
+// and lists of the channels are saved.
+const CanvasDir = "canvases"
+
+// Canvases submits the content of the canvases and lists ff for download into
+// the CanvasDir directory, and returns the paths of the downloaded files,
+// relative to the root of the export, in the same order as ff.  The path of
+// the skipped canvas is empty.  The content of the canvases is saved with the
+// ".html" extension.  Downloader must be started.
+func (bd *base) Canvases(ff []slack.File) ([]string, error) {
+	paths := make([]string, len(ff))
+	for i := range ff {
+		f := ff[i]
+		if downloader.IsCanvas(&f) && path.Ext(f.Name) == "" {
+			f.Name += ".html"
+		}
+		filename, err := bd.dl.DownloadFile(CanvasDir, f)
+		if errors.Is(err, downloader.ErrSkipped) {
+			bd.l.Debugf("skipped canvas %s", ff[i].ID)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		paths[i] = filename
+	}
+	return paths, nil
+}
@@ -0,0 +1,47 @@
+package dl
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+func Test_base_Canvases(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dc := mock_downloader.NewMockDownloader(ctrl)
+	dc.EXPECT().GetFile("https://files.slack.com/files-pri/T01-F01/canvas", gomock.Any()).DoAndReturn(func(_ string, w io.Writer) error {
+		_, err := io.WriteString(w, "<h1>Roadmap</h1>")
+		return err
+	})
+
+	bd := base{
+		dl: downloader.New(dc, fsadapter.NewDirectory(t.TempDir()),
+			downloader.Logger(logger.Silent),
+			downloader.WithFilter(downloader.TypeFilter([]string{"quip"})),
+		),
+		l: logger.Silent,
+		m: new(manifest),
+	}
+	ff := []slack.File{
+		{ID: "F01", Name: "canvas", Filetype: "quip", Mimetype: "application/vnd.slack-docs", URLPrivate: "https://files.slack.com/files-pri/T01-F01/canvas"},
+		{ID: "F02", Name: "list", Filetype: "list", URLPrivate: "https://files.slack.com/files-pri/T01-F02/list"},
+	}
+
+	bd.Start(context.Background())
+	got, err := bd.Canvases(ff)
+	bd.Stop()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"canvases/F01-canvas.html", ""}, got, "list must be skipped by the type filter")
+	assert.Equal(t, 1, bd.Stats().Saved)
+}
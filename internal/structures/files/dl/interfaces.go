@@ -6,6 +6,7 @@ import (
 	"github.com/slack-go/slack"
 
 	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/downloader"
 )
 
 // Exporter is the file exporter interface.
@@ -18,6 +19,20 @@ type Exporter interface {
 	// download is disabled, it should silently ignore the error and return
 	// nil.
 	ProcessFunc(channelName string) slackdump.ProcessFunc
+	// Stats returns the file download statistics.  It should be called after
+	// Stop.
+	Stats() downloader.Stats
+	// Manifest returns the manifest of all files submitted for download,
+	// with their download status.  It should be called after Stop.
+	Manifest() []ManifestEntry
+	// Avatars submits the custom profile images of the users for download
+	// and returns the users with the profile image URLs pointing to the
+	// downloaded images.
+	Avatars(users []slack.User) ([]slack.User, error)
+	// Canvases submits the content of the canvases and lists for download,
+	// and returns the paths of the downloaded files, the path of the
+	// skipped canvas is empty.
+	Canvases(ff []slack.File) ([]string, error)
 	StartStopper
 }
 
@@ -30,5 +45,7 @@ type StartStopper interface {
 // for mocking in tests.
 type exportDownloader interface {
 	DownloadFile(dir string, f slack.File) (string, error)
+	Stats() downloader.Stats
+	Records() []downloader.Record
 	StartStopper
 }
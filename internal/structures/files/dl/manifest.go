@@ -0,0 +1,109 @@
+package dl
+
+// files manifest
+
+import (
+	"path"
+	"sync"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/internal/structures/files"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// ManifestFile is the name of the files manifest in the export.
+const ManifestFile = "files-manifest.json"
+
+// ManifestEntry is the record of the single file in the files manifest.
+type ManifestEntry struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	ChannelID   string                `json:"channel_id"`
+	ChannelName string                `json:"channel_name,omitempty"`
+	Timestamp   string                `json:"ts"`
+	Path        string                `json:"path,omitempty"` // empty, if the file was not saved
+	Size        int64                 `json:"size"`
+	Status      downloader.FileStatus `json:"status"`
+	Error       string                `json:"error,omitempty"`
+	// CompressedSize is the size of the file on the filesystem, if it was
+	// compressed with gzip, Size is the original size then.
+	CompressedSize int64 `json:"compressed_size,omitempty"`
+}
+
+// manifest accumulates the entries of the files, that were submitted for
+// download.  The status of the submitted files is not known until the
+// downloader is stopped.
+type manifest struct {
+	mu      sync.Mutex
+	entries []ManifestEntry
+	seen    map[string]bool // entries by file ID and directory
+}
+
+// add adds the file, that was submitted for download to the directory dir,
+// to the manifest.  filePath is the path returned by the downloader, status
+// should be set, if it is already known, i.e. if the file was skipped.  Files
+// that were already added for the same directory are ignored, same as
+// downloader ignores them.
+func (m *manifest) add(dir string, f slack.File, channelID, channelName, ts, filePath string, status downloader.FileStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := path.Join(dir, f.ID)
+	if m.seen[key] {
+		return
+	}
+	if m.seen == nil {
+		m.seen = make(map[string]bool)
+	}
+	m.seen[key] = true
+	m.entries = append(m.entries, ManifestEntry{
+		ID:          f.ID,
+		Name:        f.Name,
+		ChannelID:   channelID,
+		ChannelName: channelName,
+		Timestamp:   ts,
+		Path:        filePath,
+		Size:        int64(f.Size),
+		Status:      status,
+	})
+}
+
+// resolve returns the manifest entries with the status of the submitted
+// files taken from the download records.
+func (m *manifest) resolve(recs []downloader.Record) []ManifestEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.entries) == 0 {
+		return nil
+	}
+	byPath := make(map[string]downloader.Record, len(recs))
+	for _, r := range recs {
+		byPath[r.Path] = r
+	}
+	ret := make([]ManifestEntry, len(m.entries))
+	for i, e := range m.entries {
+		if e.Status == "" {
+			if r, ok := byPath[e.Path]; ok {
+				e.Status, e.Size, e.Error, e.CompressedSize = r.Status, r.Size, r.Error, r.CompressedSize
+			} else {
+				// the downloader was stopped before it got to the file.
+				e.Status, e.Error = downloader.StatusFailed, "download did not complete"
+			}
+		}
+		if e.Status != downloader.StatusDownloaded {
+			e.Path = ""
+		}
+		ret[i] = e
+	}
+	return ret
+}
+
+// messageTS returns the timestamp of the message that contains the file at
+// address addr.
+func messageTS(msgs []types.Message, addr files.Addr) string {
+	if m := files.Message(msgs, addr); m != nil {
+		return m.Timestamp
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package dl
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/downloader"
+)
+
+func Test_manifest_resolve(t *testing.T) {
+	var m manifest
+	m.add("general/attachments", slack.File{ID: "F1", Name: "ok.txt", Size: 8}, "C01", "general", "1.1", "general/attachments/F1-ok.txt", "")
+	m.add("general/attachments", slack.File{ID: "F1", Name: "ok.txt", Size: 8}, "C01", "general", "1.2", "general/attachments/F1-ok.txt", "")
+	m.add("general/attachments", slack.File{ID: "F2", Name: "big.bin", Size: 100}, "C01", "general", "2.1", "", downloader.StatusSkipped)
+	m.add("general/attachments", slack.File{ID: "F3", Name: "gone.txt", Size: 10}, "C01", "general", "3.1", "general/attachments/F3-gone.txt", "")
+	m.add("general/attachments", slack.File{ID: "F4", Name: "late.txt", Size: 10}, "C01", "general", "4.1", "general/attachments/F4-late.txt", "")
+	m.add("general/attachments", slack.File{ID: "F5", Name: "log.txt", Size: 1000}, "C01", "general", "5.1", "general/attachments/F5-log.txt.gz", "")
+
+	got := m.resolve([]downloader.Record{
+		{ID: "F3", Path: "general/attachments/F3-gone.txt", Size: 10, Status: downloader.StatusFailed, Error: "not found"},
+		{ID: "F1", Path: "general/attachments/F1-ok.txt", Size: 8, Status: downloader.StatusDownloaded},
+		{ID: "F5", Path: "general/attachments/F5-log.txt.gz", Size: 1000, CompressedSize: 42, Status: downloader.StatusDownloaded},
+	})
+	want := []ManifestEntry{
+		{ID: "F1", Name: "ok.txt", ChannelID: "C01", ChannelName: "general", Timestamp: "1.1", Path: "general/attachments/F1-ok.txt", Size: 8, Status: downloader.StatusDownloaded},
+		{ID: "F2", Name: "big.bin", ChannelID: "C01", ChannelName: "general", Timestamp: "2.1", Size: 100, Status: downloader.StatusSkipped},
+		{ID: "F3", Name: "gone.txt", ChannelID: "C01", ChannelName: "general", Timestamp: "3.1", Size: 10, Status: downloader.StatusFailed, Error: "not found"},
+		{ID: "F4", Name: "late.txt", ChannelID: "C01", ChannelName: "general", Timestamp: "4.1", Size: 10, Status: downloader.StatusFailed, Error: "download did not complete"},
+		{ID: "F5", Name: "log.txt", ChannelID: "C01", ChannelName: "general", Timestamp: "5.1", Path: "general/attachments/F5-log.txt.gz", Size: 1000, CompressedSize: 42, Status: downloader.StatusDownloaded},
+	}
+	assert.Equal(t, want, got)
+}
@@ -4,6 +4,7 @@ package dl
 
 import (
 	"errors"
+	"path"
 	"path/filepath"
 
 	"github.com/slack-go/slack"
@@ -22,24 +23,30 @@ type Mattermost struct {
 
 // NewMattermost returns the dl, that downloads the files into
 // the __uploads directory, so that it could be transformed into bulk import
-// by mmetl and imported into mattermost with mmctl import bulk.
-func NewMattermost(fs fsadapter.FS, cl *slack.Client, l logger.Interface, token string) *Mattermost {
+// by mmetl and imported into mattermost with mmctl import bulk.  If strip is
+// true, the URLs of the downloaded files are replaced with the paths in the
+// __uploads directory, and the URLs of the other files are removed.  opts are
+// passed to the downloader.
+func NewMattermost(fs fsadapter.FS, cl *slack.Client, l logger.Interface, token string, strip bool, opts ...downloader.Option) *Mattermost {
+	opts = append([]downloader.Option{downloader.Logger(l), downloader.WithNameFunc(
+		func(f *slack.File) string {
+			return f.Name
+		},
+	)}, opts...)
 	return &Mattermost{
 		base: base{
 			l:     l,
 			token: token,
-			dl: downloader.New(cl, fs, downloader.Logger(l), downloader.WithNameFunc(
-				func(f *slack.File) string {
-					return f.Name
-				},
-			)),
+			strip: strip,
+			dl:    downloader.New(cl, fs, opts...),
+			m:     new(manifest),
 		},
 	}
 }
 
 // ProcessFunc returns the ProcessFunc that downloads the files into the
 // __uploads directory in the root of the download filesystem.
-func (md *Mattermost) ProcessFunc(_ string) slackdump.ProcessFunc {
+func (md *Mattermost) ProcessFunc(channelName string) slackdump.ProcessFunc {
 	const (
 		baseDir = "__uploads"
 	)
@@ -47,11 +54,23 @@ func (md *Mattermost) ProcessFunc(_ string) slackdump.ProcessFunc {
 		total := 0
 		if err := files.Extract(msgs, files.Root, func(file slack.File, addr files.Addr) error {
 			filedir := filepath.Join(baseDir, file.ID)
-			_, err := md.dl.DownloadFile(filedir, file)
-			if err != nil {
+			ts := messageTS(msgs, addr)
+			switch filename, err := md.dl.DownloadFile(filedir, file); {
+			case errors.Is(err, downloader.ErrSkipped):
+				md.m.add(filedir, file, channelID, channelName, ts, "", downloader.StatusSkipped)
+				md.l.Debugf("skipped: %s", file.Name)
+				if md.strip {
+					return files.Update(msgs, addr, files.UpdatePathFn(""))
+				}
+			case err != nil:
 				return err
+			default:
+				md.m.add(filedir, file, channelID, channelName, ts, filename, "")
+				total++
+				if md.strip {
+					return files.Update(msgs, addr, files.UpdatePathFn(path.Join(baseDir, file.ID, path.Base(filename))))
+				}
 			}
-			total++
 			if md.token != "" {
 				return files.Update(msgs, addr, files.UpdateTokenFn(md.token))
 			}
@@ -1,6 +1,6 @@
 package dl
 
-// no download, but update the token if required.
+// no download, but update the token or strip the URLs if required.
 
 import (
 	"context"
@@ -8,12 +8,14 @@ import (
 	"github.com/slack-go/slack"
 
 	"github.com/rusq/slackdump/v2"
+	"github.com/rusq/slackdump/v2/downloader"
 	"github.com/rusq/slackdump/v2/internal/structures/files"
 	"github.com/rusq/slackdump/v2/types"
 )
 
 // Nothing does not download any files, it just updates the link adding
-// a token query parameter, if the token is set.
+// a token query parameter, if the token is set, or removes it, if strip is
+// set.
 type Nothing struct {
 	base
 }
@@ -24,18 +26,35 @@ func (Nothing) Start(context.Context) {}
 // Stop does nothing.
 func (Nothing) Stop() {}
 
+// Stats returns empty statistics, as no files are downloaded.
+func (Nothing) Stats() downloader.Stats { return downloader.Stats{} }
+
+// Manifest returns nil, as no files are downloaded.
+func (Nothing) Manifest() []ManifestEntry { return nil }
+
+// Avatars returns the users as is, as no files are downloaded.
+func (Nothing) Avatars(users []slack.User) ([]slack.User, error) { return users, nil }
+
+// Canvases returns the empty paths, as no files are downloaded.
+func (Nothing) Canvases(ff []slack.File) ([]string, error) { return make([]string, len(ff)), nil }
+
 // NewFileUpdater returns an fileExporter that does not download any files,
 // but updates the link adding a token query parameter, if the token is set.
-func NewFileUpdater(token string) Nothing {
+// If strip is true, the links are removed instead.
+func NewFileUpdater(token string, strip bool) Nothing {
 	return Nothing{base: base{
 		token: token,
+		strip: strip,
 	}}
 }
 
 // ProcessFunc returns the [slackdump.ProcessFunc] that updates the file link
-// adding a token query parameter.
+// adding a token query parameter, or removes it.
 func (u Nothing) ProcessFunc(_ string) slackdump.ProcessFunc {
-	if u.token == "" {
+	update := files.UpdateTokenFn(u.token)
+	if u.strip {
+		update = files.UpdatePathFn("")
+	} else if u.token == "" {
 		// return dummy function, if the token is empty.
 		return func(msg []types.Message, channelID string) (slackdump.ProcessResult, error) {
 			return slackdump.ProcessResult{}, nil
@@ -44,7 +63,7 @@ func (u Nothing) ProcessFunc(_ string) slackdump.ProcessFunc {
 	return func(msgs []types.Message, channelID string) (slackdump.ProcessResult, error) {
 		total := 0
 		if err := files.Extract(msgs, files.Root, func(file slack.File, addr files.Addr) error {
-			return files.Update(msgs, addr, files.UpdateTokenFn(u.token))
+			return files.Update(msgs, addr, update)
 		}); err != nil {
 			return slackdump.ProcessResult{}, err
 		}
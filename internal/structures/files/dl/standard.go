@@ -22,13 +22,17 @@ type Std struct {
 }
 
 // NewStd returns standard dl, which downloads files into
-// "channel_id/attachments" directory.
-func NewStd(fs fsadapter.FS, cl *slack.Client, l logger.Interface, token string) *Std {
+// "channel_id/attachments" directory.  If strip is true, the URLs of the
+// files, that were not downloaded, are removed.  opts are passed to the
+// downloader.
+func NewStd(fs fsadapter.FS, cl *slack.Client, l logger.Interface, token string, strip bool, opts ...downloader.Option) *Std {
 	return &Std{
 		base: base{
-			dl:    downloader.New(cl, fs, downloader.Logger(l)),
+			dl:    downloader.New(cl, fs, append([]downloader.Option{downloader.Logger(l)}, opts...)...),
 			l:     l,
 			token: token,
+			strip: strip,
+			m:     new(manifest),
 		}}
 }
 
@@ -46,9 +50,23 @@ func (d *Std) ProcessFunc(channelName string) slackdump.ProcessFunc {
 		total := 0
 		if err := files.Extract(msg, files.Root, func(file slack.File, addr files.Addr) error {
 			filename, err := d.dl.DownloadFile(dir, file)
-			if err != nil {
+			ts := messageTS(msg, addr)
+			if errors.Is(err, downloader.ErrSkipped) {
+				d.m.add(dir, file, channelID, channelName, ts, "", downloader.StatusSkipped)
+				// the file is not downloaded, so the URL stays as is, unless
+				// it should be stripped.
+				d.l.Debugf("skipped: %s", file.Name)
+				if d.strip {
+					return files.Update(msg, addr, files.UpdatePathFn(""))
+				}
+				if d.token != "" {
+					return files.Update(msg, addr, files.UpdateTokenFn(d.token))
+				}
+				return nil
+			} else if err != nil {
 				return err
 			}
+			d.m.add(dir, file, channelID, channelName, ts, filename, "")
 			d.l.Debugf("submitted for download: %s", file.Name)
 			total++
 			if d.token != "" {
@@ -0,0 +1,70 @@
+package dl
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
+	"github.com/rusq/slackdump/v2/logger"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestStd_ProcessFunc_strip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dc := mock_downloader.NewMockDownloader(ctrl)
+	dc.EXPECT().GetFile("https://files.slack.com/files-pri/T01-F01/download/small.txt", gomock.Any()).DoAndReturn(func(_ string, w io.Writer) error {
+		_, err := io.WriteString(w, "small")
+		return err
+	})
+
+	d := &Std{base: base{
+		dl:    downloader.New(dc, fsadapter.NewDirectory(t.TempDir()), downloader.Logger(logger.Silent), downloader.MaxFileSize(100)),
+		l:     logger.Silent,
+		m:     new(manifest),
+		strip: true,
+	}}
+	msgs := []types.Message{{Message: slack.Message{Msg: slack.Msg{Files: []slack.File{
+		{
+			ID:                 "F01",
+			Name:               "small.txt",
+			Size:               5,
+			URLPrivate:         "https://files.slack.com/files-pri/T01-F01/small.txt",
+			URLPrivateDownload: "https://files.slack.com/files-pri/T01-F01/download/small.txt",
+		},
+		{
+			ID:                 "F02",
+			Name:               "large.bin",
+			Size:               1000,
+			URLPrivate:         "https://files.slack.com/files-pri/T01-F02/large.bin",
+			URLPrivateDownload: "https://files.slack.com/files-pri/T01-F02/download/large.bin",
+		},
+	}}}}}
+
+	d.Start(context.Background())
+	_, err := d.ProcessFunc("C01")(msgs, "C01")
+	d.Stop()
+	require.NoError(t, err)
+
+	assert.Equal(t, "attachments/F01-small.txt", msgs[0].Files[0].URLPrivate)
+	assert.Equal(t, "attachments/F01-small.txt", msgs[0].Files[0].URLPrivateDownload)
+	assert.Equal(t, "", msgs[0].Files[1].URLPrivate, "skipped file URL must be removed")
+	assert.Equal(t, "", msgs[0].Files[1].URLPrivateDownload, "skipped file URL must be removed")
+}
+
+func TestNothing_ProcessFunc_strip(t *testing.T) {
+	msgs := []types.Message{{Message: slack.Message{Msg: slack.Msg{Files: []slack.File{
+		{ID: "F01", URLPrivate: "https://files.slack.com/files-pri/T01-F01/a.txt", URLPrivateDownload: "https://files.slack.com/files-pri/T01-F01/download/a.txt"},
+	}}}}}
+	_, err := NewFileUpdater("", true).ProcessFunc("C01")(msgs, "C01")
+	require.NoError(t, err)
+	assert.Equal(t, "", msgs[0].Files[0].URLPrivate)
+	assert.Equal(t, "", msgs[0].Files[0].URLPrivateDownload)
+}
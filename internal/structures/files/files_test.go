@@ -9,6 +9,7 @@ import (
 	"github.com/slack-go/slack"
 
 	"github.com/rusq/slackdump/v2/internal/fixtures"
+	"github.com/rusq/slackdump/v2/types"
 )
 
 func Test_addToken(t *testing.T) {
@@ -134,6 +135,33 @@ func Test_callForEach(t *testing.T) {
 	})
 }
 
+func TestExtract(t *testing.T) {
+	// the thread parent has the replies metadata, but the replies were not
+	// fetched, i.e. with Options.SkipThreads.
+	msgs := []types.Message{
+		{Message: slack.Message{Msg: slack.Msg{
+			Timestamp:       "1.1",
+			ThreadTimestamp: "1.1",
+			ReplyCount:      3,
+			Files:           []slack.File{{ID: "F1"}},
+		}}},
+		{
+			Message:       slack.Message{Msg: slack.Msg{Timestamp: "2.1", ThreadTimestamp: "2.1", ReplyCount: 1}},
+			ThreadReplies: []types.Message{{Message: slack.Message{Msg: slack.Msg{Timestamp: "2.2", Files: []slack.File{{ID: "F2"}}}}}},
+		},
+	}
+	var got []string
+	if err := Extract(msgs, Root, func(file slack.File, _ Addr) error {
+		got = append(got, file.ID)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"F1", "F2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract() files = %v, want %v", got, want)
+	}
+}
+
 const fileTokenPlaceholder = `{
 	"id": "F02PM6A1AUA",
 	"created": 1638784624,
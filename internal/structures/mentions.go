@@ -0,0 +1,91 @@
+package structures
+
+import (
+	"regexp"
+
+	"github.com/slack-go/slack"
+)
+
+// reMention matches the user mentions "<@U123>" and channel references
+// "<#C123|general>" in the message text.
+var reMention = regexp.MustCompile(`<([@#])([A-Z0-9]+)(?:\|([^<>]*))?>`)
+
+// MentionResolver resolves the user mentions and the channel references in
+// the message text to the display names of the users and the channel names.
+// The nil MentionResolver does not resolve anything.
+type MentionResolver struct {
+	users    UserIndex
+	channels map[string]string // channel ID -> name
+}
+
+// NewMentionResolver creates a new MentionResolver, that uses users to
+// resolve the user mentions, and chans to resolve the channel references,
+// that have no name in the text.
+func NewMentionResolver(users UserIndex, chans []slack.Channel) *MentionResolver {
+	mr := &MentionResolver{users: users, channels: make(map[string]string, len(chans))}
+	for i := range chans {
+		mr.AddChannel(chans[i])
+	}
+	return mr
+}
+
+// AddChannel adds the channel to the channel names index.  It is not safe
+// for concurrent use.
+func (mr *MentionResolver) AddChannel(ch slack.Channel) {
+	if mr == nil || ch.Name == "" {
+		return
+	}
+	mr.channels[ch.ID] = ch.Name
+}
+
+// User returns the display name of the user with the given ID, and true, if
+// the user is known.  Otherwise it returns the ID and false.
+func (mr *MentionResolver) User(id string) (string, bool) {
+	if mr == nil {
+		return id, false
+	}
+	u, ok := mr.users[id]
+	if !ok || u == nil {
+		return id, false
+	}
+	return nvl(u.Profile.DisplayName, u.RealName, u.Name, id), true
+}
+
+// Channel returns the name of the channel with the given ID, and true, if the
+// channel is known, or the label, that is included in the channel reference,
+// is not empty.  Otherwise it returns the ID and false.
+func (mr *MentionResolver) Channel(id string, label string) (string, bool) {
+	if mr == nil {
+		return id, false
+	}
+	if name, ok := mr.channels[id]; ok {
+		return name, true
+	}
+	if label != "" {
+		return label, true
+	}
+	return id, false
+}
+
+// Resolve replaces the user mentions and channel references in the message
+// text with "@name" and "#name" respectively.  Mentions of the unknown users
+// and channels are left as is.
+func (mr *MentionResolver) Resolve(text string) string {
+	if mr == nil {
+		return text
+	}
+	return reMention.ReplaceAllStringFunc(text, func(m string) string {
+		sm := reMention.FindStringSubmatch(m)
+		switch sm[1] {
+		case "@":
+			if name, ok := mr.User(sm[2]); ok {
+				return "@" + name
+			}
+		case "#":
+			if name, ok := mr.Channel(sm[2], sm[3]); ok {
+				return "#" + name
+			}
+		}
+		return m
+	})
+}
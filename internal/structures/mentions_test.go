@@ -0,0 +1,41 @@
+package structures
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestMentionResolver_Resolve(t *testing.T) {
+	users := NewUserIndex([]slack.User{
+		{ID: "U01", Name: "jdoe", Profile: slack.UserProfile{DisplayName: "John"}},
+		{ID: "U02", Name: "asmith"},
+	})
+	var ch slack.Channel
+	ch.ID = "C01"
+	ch.Name = "general"
+	mr := NewMentionResolver(users, []slack.Channel{ch})
+
+	tests := []struct {
+		name string
+		mr   *MentionResolver
+		text string
+		want string
+	}{
+		{"user with display name", mr, "hi <@U01>", "hi @John"},
+		{"user without display name", mr, "<@U02>, <@U01>", "@asmith, @John"},
+		{"unknown user is left as is", mr, "hi <@U99>", "hi <@U99>"},
+		{"channel with label", mr, "see <#C02|random>", "see #random"},
+		{"channel from the index", mr, "see <#C01>", "see #general"},
+		{"unknown channel is left as is", mr, "see <#C99>", "see <#C99>"},
+		{"links are left as is", mr, "<https://example.com|x>", "<https://example.com|x>"},
+		{"nil resolver", nil, "hi <@U01>", "hi <@U01>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mr.Resolve(tt.text); got != tt.want {
+				t.Errorf("MentionResolver.Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
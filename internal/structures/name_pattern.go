@@ -0,0 +1,120 @@
+package structures
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// ErrNoMatch is returned by ResolvePatterns, if the list has no included
+// channels after resolving the name patterns.
+var ErrNoMatch = errors.New("no channels match the name patterns")
+
+// NamePattern matches the channel names.  It is either a regular expression,
+// wrapped in slashes, i.e. "/^incident-\d+$/", or a glob, i.e. "proj-*".
+type NamePattern struct {
+	Pattern string // pattern, as specified by the user
+	Exclude bool   // true, if matching channels should be excluded
+
+	match func(name string) bool
+}
+
+// isNamePattern returns true if the entity looks like a channel name pattern,
+// rather than the ID or URL.
+func isNamePattern(s string) bool {
+	if strings.Contains(s, "://") {
+		// URLs may contain "?" in the query.
+		return false
+	}
+	return isRegexPattern(s) || strings.ContainsAny(s, "*?")
+}
+
+func isRegexPattern(s string) bool {
+	return len(s) > 2 && strings.HasPrefix(s, "/") && strings.HasSuffix(s, "/")
+}
+
+// newNamePattern compiles the pattern s.
+func newNamePattern(s string, exclude bool) (NamePattern, error) {
+	np := NamePattern{Pattern: s, Exclude: exclude}
+	if isRegexPattern(s) {
+		re, err := regexp.Compile(s[1 : len(s)-1])
+		if err != nil {
+			return NamePattern{}, fmt.Errorf("invalid regular expression %q: %w", s, err)
+		}
+		np.match = re.MatchString
+		return np, nil
+	}
+	if _, err := path.Match(s, ""); err != nil {
+		return NamePattern{}, fmt.Errorf("invalid pattern %q: %w", s, err)
+	}
+	np.match = func(name string) bool {
+		ok, _ := path.Match(s, name)
+		return ok
+	}
+	return np, nil
+}
+
+// Match returns true if the channel name matches the pattern.
+func (np NamePattern) Match(name string) bool {
+	if np.match == nil || name == "" {
+		return false
+	}
+	return np.match(name)
+}
+
+// HasPatterns returns true if the list has any name patterns, that should be
+// resolved with ResolvePatterns before the list is used.
+func (el *EntityList) HasPatterns() bool {
+	return len(el.Patterns) > 0
+}
+
+func (el *EntityList) hasPatterns(exclude bool) bool {
+	for _, np := range el.Patterns {
+		if np.Exclude == exclude {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolvePatterns matches the names of the channels chans against the name
+// patterns, and adds the IDs of the matching channels to the Include or
+// Exclude list.  Channels, that are explicitly excluded by ID, are not
+// included by the patterns, and the exclude patterns take precedence over the
+// explicit inclusion.  Patterns are cleared once resolved.  It returns
+// ErrNoMatch, if there were include patterns, but nothing is included as a
+// result.
+func (el *EntityList) ResolvePatterns(chans []slack.Channel) error {
+	if !el.HasPatterns() {
+		return nil
+	}
+	hadIncludes := el.hasPatterns(false)
+	idx := el.Index()
+	for _, exclude := range []bool{false, true} {
+		for _, np := range el.Patterns {
+			if np.Exclude != exclude {
+				continue
+			}
+			for _, ch := range chans {
+				if !np.Match(ch.Name) {
+					continue
+				}
+				if exclude {
+					idx[ch.ID] = false
+				} else if _, ok := idx[ch.ID]; !ok {
+					idx[ch.ID] = true
+				}
+			}
+		}
+	}
+	el.Include, el.Exclude, el.Patterns = nil, nil, nil
+	el.fromIndex(idx)
+	if hadIncludes && !el.HasIncludes() {
+		return ErrNoMatch
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package structures
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func Test_isNamePattern(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"proj-*", true},
+		{"incident-?", true},
+		{"/^incident-\\d+$/", true},
+		{"C4810ACC", false},
+		{"https://fake.slack.com/archives/CHM82GF99/p1577694990000400?thread_ts=1577694990.000400", false},
+		{"/", false},
+		{"//", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := isNamePattern(tt.s); got != tt.want {
+				t.Errorf("isNamePattern() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamePattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"proj-*", "proj-alpha", true},
+		{"proj-*", "project", false},
+		{"team-?", "team-a", true},
+		{"team-?", "team-ab", false},
+		{"/^incident-\\d+$/", "incident-42", true},
+		{"/^incident-\\d+$/", "incident-review", false},
+		{"proj-*", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			np, err := newNamePattern(tt.pattern, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := np.Match(tt.name); got != tt.want {
+				t.Errorf("NamePattern.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newNamePattern_invalid(t *testing.T) {
+	for _, s := range []string{"/incident-(/", "proj-[*"} {
+		if _, err := newNamePattern(s, false); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestEntityList_ResolvePatterns(t *testing.T) {
+	chans := []slack.Channel{
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}, Name: "proj-alpha"}},
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C2"}, Name: "proj-beta"}},
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C3"}, Name: "incident-42"}},
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C4"}, Name: "general"}},
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "D5"}}},
+	}
+	tests := []struct {
+		name     string
+		entities []string
+		want     *EntityList
+		wantErr  error
+	}{
+		{
+			"glob and regex",
+			[]string{"proj-*", "/^incident-\\d+$/"},
+			&EntityList{Include: []string{"C1", "C2", "C3"}},
+			nil,
+		},
+		{
+			"pattern and ID",
+			[]string{"proj-*", "C4"},
+			&EntityList{Include: []string{"C1", "C2", "C4"}},
+			nil,
+		},
+		{
+			"explicit exclusion wins over include pattern",
+			[]string{"proj-*", "^C2"},
+			&EntityList{Include: []string{"C1"}, Exclude: []string{"C2"}},
+			nil,
+		},
+		{
+			"exclude pattern wins over explicit inclusion",
+			[]string{"C1", "C4", "^proj-*"},
+			&EntityList{Include: []string{"C4"}, Exclude: []string{"C1", "C2"}},
+			nil,
+		},
+		{
+			"exclude pattern only",
+			[]string{"^/^proj-/"},
+			&EntityList{Exclude: []string{"C1", "C2"}},
+			nil,
+		},
+		{
+			"nothing matches",
+			[]string{"nope-*"},
+			&EntityList{},
+			ErrNoMatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			el, err := MakeEntityList(tt.entities)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !el.HasPatterns() {
+				t.Fatal("expected patterns")
+			}
+			if err := el.ResolvePatterns(chans); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResolvePatterns() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(el, tt.want) {
+				t.Errorf("ResolvePatterns() = %+v, want %+v", el, tt.want)
+			}
+		})
+	}
+}
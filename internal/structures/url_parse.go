@@ -56,11 +56,11 @@ var linkRe = regexp.MustCompile(`^[A-Za-z]{1}[A-Za-z0-9]+(:[0-9]+\.[0-9]+)?$`)
 // It returns the SlackLink or error.
 func ParseLink(link string) (SlackLink, error) {
 	if IsURL(link) {
-		sl, err := ParseURL(link)
+		id, ts, err := ParsePermalink(link)
 		if err != nil {
 			return SlackLink{}, err
 		}
-		return *sl, nil
+		return SlackLink{Channel: id, ThreadTS: ts}, nil
 	}
 	if !linkRe.MatchString(link) {
 		return SlackLink{}, fmt.Errorf("%w: %q", ErrInvalidLink, link)
@@ -114,6 +114,85 @@ func ParseURL(slackURL string) (*SlackLink, error) {
 	return &ui, nil
 }
 
+// channelIDRe matches the conversation ID.
+var channelIDRe = regexp.MustCompile(`^[A-Z][A-Z0-9]+$`)
+
+// ParsePermalink parses the Slack permalink and returns the channel ID, and
+// the thread timestamp, if the link points to a message or a thread.  The
+// link can be on the workspace (acme.slack.com), Enterprise Grid
+// (acme.enterprise.slack.com) or app.slack.com host, and can have one of the
+// following forms:
+//
+//   - /archives/C01234567[/p1577694990000400]  - conversation or message;
+//   - /messages/C01234567[/p1577694990000400]  - legacy conversation or
+//     message link;
+//   - /client/T01234567/C01234567[/thread/C01234567-1577694990.000400] - web
+//     client link to the conversation or the thread.
+//
+// If the link has the thread_ts query parameter, i.e. the link to the thread
+// reply, threadTS is the timestamp of the thread parent.
+func ParsePermalink(link string) (channelID, threadTS string, err error) {
+	if link == "" {
+		return "", "", ErrNoURL
+	}
+	uri, err := url.Parse(link)
+	if err != nil {
+		return "", "", fmt.Errorf("error parsing URL %q: %w", link, err)
+	}
+	host := strings.ToLower(uri.Hostname())
+	if !strings.EqualFold(uri.Scheme, "https") || !strings.HasSuffix(host, ".slack.com") {
+		return "", "", ErrNotSlackURL
+	}
+
+	parts := strings.Split(strings.Trim(uri.Path, "/"), "/")
+	switch strings.ToLower(parts[0]) {
+	case "archives", "messages":
+		// /archives/C01234567[/p1577694990000400]
+		if len(parts) < 2 || len(parts) > 3 {
+			return "", "", ErrUnsupportedURL
+		}
+		channelID = parts[1]
+		if len(parts) == 3 {
+			ts, err := ParseThreadID(parts[2])
+			if err != nil {
+				return "", "", ErrUnsupportedURL
+			}
+			threadTS = FormatSlackTS(ts)
+		}
+	case "client":
+		// /client/T01234567/C01234567[/thread/C01234567-1577694990.000400]
+		if len(parts) != 3 && len(parts) != 5 {
+			return "", "", ErrUnsupportedURL
+		}
+		channelID = parts[2]
+		if len(parts) == 5 {
+			id, ts, found := strings.Cut(parts[4], "-")
+			if !strings.EqualFold(parts[3], "thread") || !found || id != channelID {
+				return "", "", ErrUnsupportedURL
+			}
+			if _, err := ParseSlackTS(ts); err != nil {
+				return "", "", ErrUnsupportedURL
+			}
+			threadTS = ts
+		}
+	default:
+		return "", "", ErrUnsupportedURL
+	}
+	if !channelIDRe.MatchString(channelID) {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidLink, link)
+	}
+
+	// the permalink of a reply points to the reply, and has the parent in
+	// the thread_ts.
+	if ts := uri.Query().Get("thread_ts"); ts != "" {
+		if _, err := ParseSlackTS(ts); err != nil {
+			return "", "", fmt.Errorf("invalid thread_ts in URL %q: %w", link, err)
+		}
+		threadTS = ts
+	}
+	return channelID, threadTS, nil
+}
+
 // Sample: https://ora600.slack.com/archives/CHM82GF99/p1577694990000400
 //
 // > Your workspace URL can only contain lowercase letters, numbers and dashes
@@ -1,6 +1,7 @@
 package structures
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -254,6 +255,12 @@ func TestParseLink(t *testing.T) {
 			SlackLink{Channel: sampleChannelID, ThreadTS: "1577694990.000400"},
 			false,
 		},
+		{
+			"web client thread URL",
+			args{"https://app.slack.com/client/T01234567/" + sampleChannelID + "/thread/" + sampleChannelID + "-1577694990.000400"},
+			SlackLink{Channel: sampleChannelID, ThreadTS: "1577694990.000400"},
+			false,
+		},
 		{
 			"invalid URL",
 			args{"https://example.com"},
@@ -274,3 +281,55 @@ func TestParseLink(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePermalink(t *testing.T) {
+	tests := []struct {
+		name        string
+		link        string
+		wantChannel string
+		wantTS      string
+		wantErr     error
+	}{
+		{"channel", sampleChannelURL, sampleChannelID, "", nil},
+		{"message", sampleThreadURL, sampleChannelID, "1577694990.000400", nil},
+		{"trailing slash", sampleChannelURL + "/", sampleChannelID, "", nil},
+		{"dash in workspace name", sampleThreadWDashURL, sampleChannelID, "1577694990.000400", nil},
+		{
+			"thread reply",
+			"https://acme.slack.com/archives/CHM82GF99/p1577694990000400?thread_ts=1577694900.000100&cid=CHM82GF99",
+			sampleChannelID, "1577694900.000100", nil,
+		},
+		{"legacy messages link", "https://acme.slack.com/messages/CHM82GF99/", sampleChannelID, "", nil},
+		{"legacy message link", "https://acme.slack.com/messages/CHM82GF99/p1577694990000400", sampleChannelID, "1577694990.000400", nil},
+		{"enterprise workspace", "https://acme.enterprise.slack.com/archives/CHM82GF99/p1577694990000400", sampleChannelID, "1577694990.000400", nil},
+		{"app host archives", "https://app.slack.com/archives/CHM82GF99", sampleChannelID, "", nil},
+		{"app client channel", "https://app.slack.com/client/T01234567/CHM82GF99", sampleChannelID, "", nil},
+		{"app client thread", "https://app.slack.com/client/T01234567/CHM82GF99/thread/CHM82GF99-1577694990.000400", sampleChannelID, "1577694990.000400", nil},
+		{"app client thread of another channel", "https://app.slack.com/client/T01234567/CHM82GF99/thread/C01-1577694990.000400", "", "", ErrUnsupportedURL},
+		{"app client other view", "https://app.slack.com/client/T01234567/CHM82GF99/details", "", "", ErrUnsupportedURL},
+		{"empty", "", "", "", ErrNoURL},
+		{"not slack", "https://example.com/archives/CHM82GF99", "", "", ErrNotSlackURL},
+		{"lookalike host", "https://evilslack.com/archives/CHM82GF99", "", "", ErrNotSlackURL},
+		{"not https", "http://acme.slack.com/archives/CHM82GF99", "", "", ErrNotSlackURL},
+		{"extra data", sampleThreadURL + "/xxxx", "", "", ErrUnsupportedURL},
+		{"malformed message id", "https://acme.slack.com/archives/CHM82GF99/1577694990000400", "", "", ErrUnsupportedURL},
+		{"invalid channel id", "https://acme.slack.com/archives/general", "", "", ErrInvalidLink},
+		{"unsupported path", "https://acme.slack.com/team/U01234567", "", "", ErrUnsupportedURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotChannel, gotTS, err := ParsePermalink(tt.link)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParsePermalink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotChannel != tt.wantChannel || gotTS != tt.wantTS {
+				t.Errorf("ParsePermalink() = (%q, %q), want (%q, %q)", gotChannel, gotTS, tt.wantChannel, tt.wantTS)
+			}
+		})
+	}
+	t.Run("invalid thread_ts", func(t *testing.T) {
+		if _, _, err := ParsePermalink(sampleThreadURL + "?thread_ts=abc"); err == nil {
+			t.Error("ParsePermalink() expected an error")
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package structures
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/slack-go/slack"
 )
@@ -93,6 +95,20 @@ func (idx UserIndex) IsDeleted(id string) bool {
 	return thisUser.Deleted
 }
 
+// Location returns the time zone of the user from the user profile.  It
+// returns an error, if the user is not in the index, or the time zone is not
+// set or unknown.
+func (idx UserIndex) Location(id string) (*time.Location, error) {
+	thisUser, ok := idx[id]
+	if !ok {
+		return nil, fmt.Errorf("user %s not found", id)
+	}
+	if thisUser.TZ == "" {
+		return nil, fmt.Errorf("user %s has no time zone set", id)
+	}
+	return time.LoadLocation(thisUser.TZ)
+}
+
 // ChannelName return the "beautified" name of the channel.
 func (idx UserIndex) ChannelName(channel *slack.Channel) (who string) {
 	switch {
@@ -45,6 +45,36 @@ func TestUserIndex_IsDeleted(t *testing.T) {
 
 }
 
+func TestUserIndex_Location(t *testing.T) {
+	idx := UserIndex{
+		"U01": {ID: "U01", TZ: "Asia/Tokyo"},
+		"U02": {ID: "U02"},
+		"U03": {ID: "U03", TZ: "Mars/Olympus_Mons"},
+	}
+	tests := []struct {
+		name    string
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{"ok", "U01", "Asia/Tokyo", false},
+		{"no time zone", "U02", "", true},
+		{"unknown time zone", "U03", "", true},
+		{"not found", "U04", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := idx.Location(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UserIndex.Location() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("UserIndex.Location() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_nvl(t *testing.T) {
 	type args struct {
 		s  string
@@ -0,0 +1,144 @@
+// Package update checks if there is a newer release of slackdump available.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefTimeout is the default timeout of the update check.
+const DefTimeout = 5 * time.Second
+
+// ErrDevBuild is returned when the current version is not a release version,
+// i.e. "dev", and can't be compared with the latest release.
+var ErrDevBuild = errors.New("not a release build, unable to compare versions")
+
+var (
+	// latestURL is the GitHub API endpoint of the latest release.
+	latestURL = "https://api.github.com/repos/rusq/slackdump/releases/latest"
+	// httpClient is the client used to query the GitHub API.
+	httpClient = http.DefaultClient
+)
+
+// Release is the GitHub release.
+type Release struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"` // release notes
+}
+
+// Result is the result of the update check.
+type Result struct {
+	Current   string  // current version
+	Latest    Release // latest release
+	Available bool    // true if the latest release is newer than the current
+}
+
+// String returns the message for the user.
+func (r Result) String() string {
+	if !r.Available {
+		return fmt.Sprintf("Slackdump %s is the latest version.", r.Current)
+	}
+	return fmt.Sprintf("A newer version of Slackdump is available: %s (current: %s)\nChangelog: %s", r.Latest.TagName, r.Current, r.Latest.HTMLURL)
+}
+
+// Check queries the GitHub API for the latest release and compares it with
+// the current version.  If the context has no deadline, DefTimeout is used.
+func Check(ctx context.Context, current string) (Result, error) {
+	cur, ok := parseVersion(current)
+	if !ok {
+		return Result{}, ErrDevBuild
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefTimeout)
+		defer cancel()
+	}
+	rel, err := latest(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	lat, ok := parseVersion(rel.TagName)
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected release version: %q", rel.TagName)
+	}
+	return Result{Current: current, Latest: rel, Available: cur.less(lat)}, nil
+}
+
+// latest returns the latest release.
+func latest(ctx context.Context) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestURL, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Release{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("unexpected response from GitHub: %s", resp.Status)
+	}
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return Release{}, fmt.Errorf("error decoding the release: %w", err)
+	}
+	return rel, nil
+}
+
+// semver is the parsed version, pre is the pre-release suffix, i.e. "next"
+// for the snapshot builds.
+type semver struct {
+	num [3]int
+	pre string
+}
+
+// parseVersion parses the version in "v1.2.3[-pre]" format, the "v" prefix
+// is optional, as well as the minor and patch numbers.
+func parseVersion(s string) (semver, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	var v semver
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		if s[i] == '-' {
+			v.pre = s[i+1:]
+		}
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > len(v.num) {
+		return semver{}, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		v.num[i] = n
+	}
+	return v, true
+}
+
+// less returns true if v is older than w.  The pre-release versions are older
+// than the release, and are compared lexically between themselves.
+func (v semver) less(w semver) bool {
+	for i := range v.num {
+		if v.num[i] != w.num[i] {
+			return v.num[i] < w.num[i]
+		}
+	}
+	switch {
+	case v.pre == w.pre:
+		return false
+	case v.pre == "":
+		return false
+	case w.pre == "":
+		return true
+	}
+	return v.pre < w.pre
+}
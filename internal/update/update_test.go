@@ -0,0 +1,134 @@
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		want   semver
+		wantOk bool
+	}{
+		{"release", "2.3.0", semver{num: [3]int{2, 3, 0}}, true},
+		{"v prefix", "v2.3.1", semver{num: [3]int{2, 3, 1}}, true},
+		{"snapshot", "2.3.1-next", semver{num: [3]int{2, 3, 1}, pre: "next"}, true},
+		{"build metadata", "v2.3.1+abc", semver{num: [3]int{2, 3, 1}}, true},
+		{"short", "v2", semver{num: [3]int{2, 0, 0}}, true},
+		{"dev", "dev", semver{}, false},
+		{"empty", "", semver{}, false},
+		{"too long", "1.2.3.4", semver{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseVersion(tt.s)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_semver_less(t *testing.T) {
+	tests := []struct {
+		v, w string
+		want bool
+	}{
+		{"2.3.0", "v2.3.1", true},
+		{"2.3.1", "v2.3.1", false},
+		{"2.4.0", "v2.3.9", false},
+		{"1.9.9", "v2.0.0", true},
+		{"2.3.1-next", "v2.3.1", true},
+		{"2.3.1", "v2.3.1-rc1", false},
+		{"2.3.1-rc1", "v2.3.1-rc2", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v+"<"+tt.w, func(t *testing.T) {
+			v, _ := parseVersion(tt.v)
+			w, _ := parseVersion(tt.w)
+			assert.Equal(t, tt.want, v.less(w))
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	const body = `{"tag_name":"v2.3.1","html_url":"https://github.com/rusq/slackdump/releases/tag/v2.3.1"}`
+	tests := []struct {
+		name    string
+		current string
+		status  int
+		want    Result
+		wantErr bool
+	}{
+		{
+			"newer available",
+			"2.3.0",
+			http.StatusOK,
+			Result{
+				Current:   "2.3.0",
+				Latest:    Release{TagName: "v2.3.1", HTMLURL: "https://github.com/rusq/slackdump/releases/tag/v2.3.1"},
+				Available: true,
+			},
+			false,
+		},
+		{
+			"up to date",
+			"2.3.1",
+			http.StatusOK,
+			Result{
+				Current: "2.3.1",
+				Latest:  Release{TagName: "v2.3.1", HTMLURL: "https://github.com/rusq/slackdump/releases/tag/v2.3.1"},
+			},
+			false,
+		},
+		{"dev build", "dev", http.StatusOK, Result{}, true},
+		{"api error", "2.3.0", http.StatusForbidden, Result{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(body))
+			}))
+			defer srv.Close()
+			defer func(s string) { latestURL = s }(latestURL)
+			latestURL = srv.URL
+
+			got, err := Check(context.Background(), tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheck_timeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+	defer func(s string) { latestURL = s }(latestURL)
+	latestURL = srv.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := Check(ctx, "2.3.0"); err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Check took too long: %s", d)
+	}
+}
@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log levels of the JSON logger.
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// levelPrefixes are the message prefixes, that set the level of the Print
+// messages, the prefix is removed from the message.
+var levelPrefixes = []struct {
+	prefix string
+	level  string
+}{
+	{"WARNING:", LevelWarn},
+	{"ERROR:", LevelError},
+}
+
+// FieldLogger is the logger, that supports the structured fields.
+type FieldLogger interface {
+	Interface
+	// With returns the logger, that adds the key-value pairs kv to each
+	// message.
+	With(kv ...any) Interface
+}
+
+// With returns the logger, that adds the key-value pairs kv to each message,
+// if l supports the structured fields, otherwise it returns l.  The text
+// loggers ignore the fields, so the message itself should contain the
+// values.
+func With(l Interface, kv ...any) Interface {
+	if fl, ok := l.(FieldLogger); ok {
+		return fl.With(kv...)
+	}
+	return l
+}
+
+// JSON is the logger, that writes one JSON object per line with the
+// timestamp, level, message and the structured fields.
+type JSON struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	debug  bool
+	fields []any
+	now    func() time.Time
+}
+
+var _ FieldLogger = &JSON{}
+
+// NewJSON returns the JSON logger, that writes to w.  If debug is false,
+// the debug messages are discarded.
+func NewJSON(w io.Writer, debug bool) *JSON {
+	return &JSON{mu: new(sync.Mutex), w: w, debug: debug, now: time.Now}
+}
+
+func (j *JSON) Debug(a ...any) {
+	if j.debug {
+		j.log(LevelDebug, fmt.Sprint(a...))
+	}
+}
+
+func (j *JSON) Debugf(format string, a ...any) {
+	if j.debug {
+		j.log(LevelDebug, fmt.Sprintf(format, a...))
+	}
+}
+
+func (j *JSON) Print(a ...any) {
+	j.print(fmt.Sprint(a...))
+}
+
+func (j *JSON) Printf(format string, a ...any) {
+	j.print(fmt.Sprintf(format, a...))
+}
+
+func (j *JSON) Println(a ...any) {
+	j.print(fmt.Sprintln(a...))
+}
+
+// With returns the logger, that adds the key-value pairs kv to each
+// message, in addition to the fields of j.
+func (j *JSON) With(kv ...any) Interface {
+	clone := *j
+	clone.fields = append(append([]any(nil), j.fields...), kv...)
+	return &clone
+}
+
+// Writer returns the writer, that logs each line written to it as an info
+// message.  It allows to redirect the output of the standard logger, with
+// the flags set to 0, to j.
+func (j *JSON) Writer() io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+			j.print(string(line))
+		}
+		return len(p), nil
+	})
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func (j *JSON) print(msg string) {
+	level := LevelInfo
+	trimmed := strings.TrimSpace(msg)
+	for _, lp := range levelPrefixes {
+		if strings.HasPrefix(trimmed, lp.prefix) {
+			level = lp.level
+			msg = strings.TrimPrefix(trimmed, lp.prefix)
+			break
+		}
+	}
+	j.log(level, msg)
+}
+
+func (j *JSON) log(level string, msg string) {
+	entry := make(map[string]any, 3+len(j.fields)/2)
+	for i := 0; i+1 < len(j.fields); i += 2 {
+		entry[fmt.Sprint(j.fields[i])] = fieldValue(j.fields[i+1])
+	}
+	entry["time"] = j.now().Format(time.RFC3339Nano)
+	entry["level"] = level
+	entry["msg"] = strings.TrimSpace(msg)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		data, _ = json.Marshal(map[string]any{"time": entry["time"], "level": LevelError, "msg": fmt.Sprintf("failed to marshal the log entry: %s", err)})
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.w.Write(append(data, '\n'))
+}
+
+// fieldValue returns the value, that can be marshalled to JSON.
+func fieldValue(v any) any {
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJSON(debug bool) (*JSON, *bytes.Buffer) {
+	var buf bytes.Buffer
+	j := NewJSON(&buf, debug)
+	j.now = func() time.Time { return time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return j, &buf
+}
+
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var ee []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var e map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &e), line)
+		ee = append(ee, e)
+	}
+	return ee
+}
+
+func TestJSON(t *testing.T) {
+	j, buf := testJSON(false)
+	j.Printf("messages fetch complete, total: %d", 42)
+	j.Debugf("discarded")
+	j.Println("WARNING: C01 has been archived, skipping")
+	With(j, "file", "a.png", "bytes", 1024, "error", errors.New("boom")).Print("ERROR: failed")
+
+	assert.Equal(t, []map[string]any{
+		{"time": "2023-01-02T03:04:05Z", "level": "info", "msg": "messages fetch complete, total: 42"},
+		{"time": "2023-01-02T03:04:05Z", "level": "warn", "msg": "C01 has been archived, skipping"},
+		{"time": "2023-01-02T03:04:05Z", "level": "error", "msg": "failed", "file": "a.png", "bytes": float64(1024), "error": "boom"},
+	}, entries(t, buf))
+}
+
+func TestJSON_With(t *testing.T) {
+	j, buf := testJSON(true)
+	ch := With(j, "channel", "C01")
+	With(ch, "thread", "123.456").Debug("thread")
+	ch.Debug("channel")
+
+	ee := entries(t, buf)
+	require.Len(t, ee, 2)
+	assert.Equal(t, "123.456", ee[0]["thread"])
+	assert.Equal(t, "debug", ee[0]["level"])
+	assert.Equal(t, "C01", ee[1]["channel"])
+	assert.NotContains(t, ee[1], "thread", "fields must not leak to the parent logger")
+}
+
+func TestJSON_Writer(t *testing.T) {
+	j, buf := testJSON(false)
+	std := log.New(j.Writer(), "", 0)
+	std.Print("first\nsecond")
+
+	ee := entries(t, buf)
+	require.Len(t, ee, 2)
+	assert.Equal(t, "first", ee[0]["msg"])
+	assert.Equal(t, "second", ee[1]["msg"])
+}
+
+func TestWith_textLogger(t *testing.T) {
+	assert.Equal(t, Interface(Silent), With(Silent, "channel", "C01"), "text logger must be returned as is")
+}
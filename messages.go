@@ -10,10 +10,10 @@ import (
 	"time"
 
 	"github.com/slack-go/slack"
-	"golang.org/x/time/rate"
 
 	"github.com/rusq/slackdump/v2/internal/network"
 	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
 	"github.com/rusq/slackdump/v2/types"
 )
 
@@ -33,7 +33,7 @@ func (sd *Session) Dump(ctx context.Context, link string, oldest, latest time.Ti
 		return nil, err
 	}
 	if sd.options.DumpFiles {
-		fn, cancelFn, err := sd.newFileProcessFn(ctx, sl.Channel, sd.limiter(network.NoTier))
+		fn, cancelFn, err := sd.newFileProcessFn(ctx, sl.Channel, sd.downloadLimiter())
 		if err != nil {
 			return nil, err
 		}
@@ -88,20 +88,72 @@ func (sd *Session) dumpChannel(ctx context.Context, channelID string, oldest, la
 
 	trace.Logf(ctx, "info", "channelID: %q, oldest: %s, latest: %s", channelID, oldest, latest)
 
+	// the name is fetched first, so that the progress messages are legible.
+	name, err := sd.getChannelName(ctx, sd.limiter(network.Tier3), channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	// if the previous dump was interrupted, continue from the checkpoint.
+	cursor, messages, err := sd.cps.resume(channelID, oldest, latest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume from the checkpoint: %w", err)
+	}
+	if cursor != "" {
+		logger.With(sd.l(), "channel", channelID).Printf("resuming from the checkpoint, messages fetched before: %d", len(messages))
+	}
+	if err := sd.streamChannel(ctx, channelID, name, cursor, len(messages), oldest, latest, func(chunk []types.Message, next string) error {
+		messages = append(messages, chunk...)
+		if next == "" {
+			return nil
+		}
+		if err := sd.cps.save(channelID, chunk, next, oldest, latest); err != nil {
+			// the dump can continue without the checkpoint.
+			logger.With(sd.l(), "channel", channelID).Printf("WARNING: failed to save the checkpoint: %s", err)
+		}
+		return nil
+	}, processFn...); err != nil {
+		return nil, err
+	}
+	if err := sd.cps.remove(channelID); err != nil {
+		logger.With(sd.l(), "channel", channelID).Printf("WARNING: failed to remove the checkpoint: %s", err)
+	}
+
+	types.SortMessages(messages)
+
+	return &types.Conversation{Name: name, Messages: messages, ID: channelID}, nil
+}
+
+// pageFunc is called by streamChannel for each page of messages, once the
+// threads and the process functions were applied to it.  next is the cursor
+// of the next page, it is empty on the last page.
+type pageFunc func(chunk []types.Message, next string) error
+
+// streamChannel fetches messages from the conversation identified by
+// channelID, starting at the cursor, and calls pageFn for each page returned
+// by the API.  processFn will be called on each page before pageFn.  The
+// messages are not retained between the pages.  name is the channel name for
+// the progress reporting, it may be empty.  fetched is the number of messages
+// fetched before, i.e. restored from the checkpoint, they count towards the
+// message limit.
+func (sd *Session) streamChannel(ctx context.Context, channelID string, name string, cursor string, fetched int, oldest, latest time.Time, pageFn pageFunc, processFn ...ProcessFunc) error {
 	var (
 		// slack rate limits are per method, so we're safe to use different limiters for different mehtods.
 		convLimiter   = sd.limiter(network.Tier3)
 		threadLimiter = sd.limiter(network.Tier3)
 	)
 
-	// add thread dumper.  It should go first, because it populates message
+	// thread dumper.  It should go first, because it populates message
 	// chunk with thread messages.
-	pfns := append([]ProcessFunc{sd.newThreadProcessFn(ctx, threadLimiter, oldest, latest)}, processFn...)
+	var threadFns []ProcessFunc
+	if !sd.options.SkipThreads {
+		threadFns = append(threadFns, sd.newThreadProcessFn(ctx, threadLimiter, oldest, latest))
+	}
 
 	var (
-		messages   []types.Message
-		cursor     string
 		fetchStart = time.Now()
+		total      = fetched
+		pr         = sd.newMsgProgress(channelID, name)
 	)
 	for i := 1; ; i++ {
 		var (
@@ -125,47 +177,70 @@ func (sd *Session) dumpChannel(ctx context.Context, channelID string, oldest, la
 			}
 			return nil
 		}); err != nil {
-			return nil, err
+			return err
 		}
 		if !resp.Ok {
 			trace.Logf(ctx, "error", "not ok, api error=%s", resp.Error)
-			return nil, fmt.Errorf("response not ok, slack error: %s", resp.Error)
+			return fmt.Errorf("response not ok, slack error: %s", resp.Error)
 		}
 
-		chunk := types.ConvertMsgs(resp.Messages)
+		chunk := sd.filterMessages(types.ConvertMsgs(resp.Messages))
+		// the messages are returned newest first, so that the limit keeps the
+		// latest ones.  It is applied before the threads are fetched, so that
+		// the replies of the dropped messages are not fetched.
+		limitReached := false
+		if lim := sd.options.MessageLimit; lim > 0 && total+len(chunk) >= lim {
+			if total < lim {
+				chunk = chunk[:lim-total]
+			} else {
+				chunk = chunk[:0]
+			}
+			limitReached = true
+		}
 
-		results, err := runProcessFuncs(chunk, channelID, pfns...)
+		results, err := runProcessFuncs(chunk, channelID, threadFns...)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		// the threads are filtered once the replies are known, before the
+		// files of the messages are processed.
+		chunk = sd.filterThreads(chunk)
+		prs, err := runProcessFuncs(chunk, channelID, processFn...)
+		if err != nil {
+			return err
 		}
+		results = append(results, prs...)
 
-		messages = append(messages, chunk...)
+		total += len(chunk)
 
-		sd.l().Printf("messages request #%5d, fetched: %4d (%s), total: %8d (speed: %6.2f/sec, avg: %6.2f/sec)\n",
-			i, len(resp.Messages), results, len(messages),
+		pr.l.Debugf("%s: messages request #%5d, fetched: %4d (%s), total: %8d (speed: %6.2f/sec, avg: %6.2f/sec)\n",
+			pr.label, i, len(resp.Messages), results, total,
 			float64(len(resp.Messages))/float64(time.Since(reqStart).Seconds()),
-			float64(len(messages))/float64(time.Since(fetchStart).Seconds()),
+			float64(total)/float64(time.Since(fetchStart).Seconds()),
 		)
 
-		if !resp.HasMore {
-			sd.l().Printf("messages fetch complete, total: %d", len(messages))
-			break
+		last := !resp.HasMore || limitReached
+		pr.report(i, total, last)
+
+		if last {
+			if err := pageFn(chunk, ""); err != nil {
+				return err
+			}
+			if limitReached && resp.HasMore {
+				pr.l.Printf("%s: message limit of %d reached, the older messages are not fetched", pr.label, sd.options.MessageLimit)
+			}
+			pr.l.Printf("%s: messages fetch complete, total: %d", pr.label, total)
+			return nil
 		}
 
 		cursor = resp.ResponseMetaData.NextCursor
+		if err := pageFn(chunk, cursor); err != nil {
+			return err
+		}
 	}
-
-	types.SortMessages(messages)
-
-	name, err := sd.getChannelName(ctx, sd.limiter(network.Tier3), channelID)
-	if err != nil {
-		return nil, err
-	}
-
-	return &types.Conversation{Name: name, Messages: messages, ID: channelID}, nil
 }
 
-func (sd *Session) getChannelName(ctx context.Context, l *rate.Limiter, channelID string) (string, error) {
+func (sd *Session) getChannelName(ctx context.Context, l *network.Limiter, channelID string) (string, error) {
 	// get channel name
 	var ci *slack.Channel
 	if err := network.WithRetry(ctx, l, sd.options.Tier3Retries, func() error {
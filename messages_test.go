@@ -9,7 +9,6 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
-	"golang.org/x/time/rate"
 
 	"github.com/rusq/slackdump/v2/internal/fixtures"
 	"github.com/rusq/slackdump/v2/internal/network"
@@ -72,6 +71,16 @@ func TestSession_DumpMessages(t *testing.T) {
 		ctx       context.Context
 		channelID string
 	}
+	noThreads := DefOptions
+	noThreads.SkipThreads = true
+	limited := DefOptions
+	limited.MessageLimit = 2
+	threadParent := types.Message{Message: slack.Message{Msg: slack.Msg{
+		Timestamp:       "1643425514.000100",
+		ThreadTimestamp: "1643425514.000100",
+		ReplyCount:      2,
+		Text:            "thread parent",
+	}}}
 	tests := []struct {
 		name     string
 		fields   fields
@@ -113,6 +122,62 @@ func TestSession_DumpMessages(t *testing.T) {
 				}},
 			false,
 		},
+		{
+			"threads are skipped",
+			fields{options: noThreads},
+			args{context.Background(), "CHANNEL"},
+			func(c *mockClienter) {
+				c.EXPECT().GetConversationHistoryContext(
+					gomock.Any(),
+					gomock.Any(),
+				).Return(
+					&slack.GetConversationHistoryResponse{
+						SlackResponse: slack.SlackResponse{Ok: true},
+						Messages:      []slack.Message{threadParent.Message},
+					},
+					nil)
+				// no conversations.replies calls are expected.
+				mockConvInfo(c, "CHANNEL", "channel_name")
+			},
+			&types.Conversation{
+				Name:     "channel_name",
+				ID:       "CHANNEL",
+				Messages: []types.Message{threadParent},
+			},
+			false,
+		},
+		{
+			"message limit",
+			fields{options: limited},
+			args{context.Background(), "CHANNEL"},
+			func(c *mockClienter) {
+				// the second page is not requested.
+				c.EXPECT().GetConversationHistoryContext(
+					gomock.Any(),
+					gomock.Any(),
+				).Return(
+					&slack.GetConversationHistoryResponse{
+						HasMore:       true,
+						SlackResponse: slack.SlackResponse{Ok: true},
+						ResponseMetaData: struct {
+							NextCursor string "json:\"next_cursor\""
+						}{"cur"},
+						Messages: []slack.Message{
+							testMsg3.Message,
+							testMsg2.Message,
+							testMsg1.Message,
+						},
+					},
+					nil)
+				mockConvInfo(c, "CHANNEL", "channel_name")
+			},
+			&types.Conversation{
+				Name:     "channel_name",
+				ID:       "CHANNEL",
+				Messages: []types.Message{testMsg2, testMsg3},
+			},
+			false,
+		},
 		{
 			"channelID is empty",
 			fields{options: DefOptions},
@@ -191,6 +256,7 @@ func TestSession_DumpMessages(t *testing.T) {
 						SlackResponse: slack.SlackResponse{Ok: false},
 					},
 					nil)
+				mockConvInfo(c, "CHANNEL", "channel_name")
 			},
 			nil,
 			true,
@@ -206,6 +272,7 @@ func TestSession_DumpMessages(t *testing.T) {
 				).Return(
 					nil,
 					errors.New("bleep bloop gtfo"))
+				mockConvInfo(c, "CHANNEL", "channel_name")
 			},
 			nil,
 			true,
@@ -371,7 +438,7 @@ func TestSession_getChannelName(t *testing.T) {
 	}
 	type args struct {
 		ctx       context.Context
-		l         *rate.Limiter
+		l         *network.Limiter
 		channelID string
 	}
 	tests := []struct {
@@ -453,6 +520,10 @@ func TestMessage_IsBotMessage(t *testing.T) {
 			fixtures.Load[types.Message](fixtures.BotMessageThreadParentJSON),
 			true,
 		},
+		{"bot message subtype",
+			types.Message{Message: slack.Message{Msg: slack.Msg{SubType: slack.MsgSubTypeBotMessage}}},
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
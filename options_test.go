@@ -0,0 +1,35 @@
+package slackdump
+
+import (
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+)
+
+func TestMaxRequestsPerMinute(t *testing.T) {
+	tiers := []network.Tier{network.Tier2, network.Tier3, network.Tier4, network.NoTier}
+	tests := []struct {
+		name      string
+		reqPerMin uint
+		want      []float64 // requests per minute of tiers
+	}{
+		{"not set", 0, []float64{40, 170, 220, 6120}},
+		{"above the defaults", 1000, []float64{40, 170, 220, 6120}},
+		{"capped", 100, []float64{40, 100, 200, 6120}},
+		{"floor", 10, []float64{20, 50, 100, 6000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefOptions
+			MaxRequestsPerMinute(tt.reqPerMin)(&opts)
+			sd := &Session{options: opts}
+			for i, tier := range tiers {
+				if got, want := sd.limiter(tier).Limit(), rate.Limit(tt.want[i]/60.0); got != want {
+					t.Errorf("%s limit = %v, want %v", tier, got, want)
+				}
+			}
+		})
+	}
+}
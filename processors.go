@@ -12,6 +12,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/internal/network"
 	"github.com/rusq/slackdump/v2/internal/structures/files"
 	"github.com/rusq/slackdump/v2/types"
 )
@@ -71,16 +72,30 @@ func runProcessFuncs(m []types.Message, channelID string, processFn ...ProcessFu
 // by limiter l.  The File.PublicURL will be updated to point to the downloaded
 // file, instead of Slack server URL.  It returns ProcessFunction and
 // CancelFunc. CancelFunc must be called, i.e. by deferring it's execution.
+// Once CancelFunc returns, the download errors, if any, are available from
+// Session.DownloadErrors, the number of skipped files from
+// Session.SkippedFiles, and the download statistics from Session.DownloadStats.
 func (sd *Session) newFileProcessFn(ctx context.Context, dir string, l *rate.Limiter) (ProcessFunc, cancelFunc, error) {
 	// set up a file downloader and add it to the post-process functions
 	// slice
+	nameFn := sd.filenameFn(dir)
 	dl := downloader.New(
-		sd.client,
+		sd.fileGetter(),
 		sd.fs,
 		downloader.Limiter(l),
 		downloader.Retries(sd.options.DownloadRetries),
 		downloader.Workers(sd.options.Workers),
 		downloader.Logger(sd.l()),
+		downloader.SkipExisting(sd.options.SkipExistingFiles),
+		downloader.WithFilter(downloader.TypeFilter(sd.options.FileTypes)),
+		downloader.MaxFileSize(sd.options.MaxFileSize),
+		downloader.MaxTotalBytes(sd.options.MaxTotalBytes),
+		downloader.PreserveTimes(sd.options.PreserveFileTimes),
+		downloader.DedupByContent(sd.options.DedupByContent),
+		downloader.Compress(sd.options.CompressFiles),
+		downloader.BytesPerSec(sd.options.DownloadBytesPerSec),
+		downloader.Progress(sd.options.OnFileProgress),
+		downloader.WithNameFunc(nameFn),
 	)
 	var filesC = make(chan *slack.File, filesCbufSz)
 
@@ -90,7 +105,7 @@ func (sd *Session) newFileProcessFn(ctx context.Context, dir string, l *rate.Lim
 	}
 
 	fn := func(msg []types.Message, _ string) (ProcessResult, error) {
-		n := pipeAndUpdateFiles(filesC, msg, dir)
+		n := pipeAndUpdateFiles(filesC, msg, dir, dl.Accepts, dl.Filename)
 		return ProcessResult{Entity: "files", Count: n}, nil
 	}
 
@@ -98,26 +113,83 @@ func (sd *Session) newFileProcessFn(ctx context.Context, dir string, l *rate.Lim
 		trace.Log(ctx, "info", "closing files channel")
 		close(filesC)
 		<-dlDoneC
+		sd.addDownloadErrors(dl.Errors(), dl.Stats())
 	}
 	return fn, cancelFn, nil
 }
 
+// addDownloadErrors records the download errors and the download statistics.
+func (sd *Session) addDownloadErrors(errs downloader.DownloadErrors, st downloader.Stats) {
+	sd.dlErrMu.Lock()
+	defer sd.dlErrMu.Unlock()
+	sd.dlErrs = append(sd.dlErrs, errs...)
+	sd.dlStats.Add(st)
+}
+
+// DownloadErrors returns the errors for all files that failed to download
+// during the lifetime of the session, when the files download is enabled.  It
+// returns nil, if all files were downloaded successfully.
+func (sd *Session) DownloadErrors() downloader.DownloadErrors {
+	sd.dlErrMu.Lock()
+	defer sd.dlErrMu.Unlock()
+	if len(sd.dlErrs) == 0 {
+		return nil
+	}
+	errs := make(downloader.DownloadErrors, len(sd.dlErrs))
+	copy(errs, sd.dlErrs)
+	return errs
+}
+
+// SkippedFiles returns the number of files that were not downloaded during the
+// lifetime of the session, because they were rejected by the file type filter,
+// or exceeded the maximum file size.
+func (sd *Session) SkippedFiles() int {
+	sd.dlErrMu.Lock()
+	defer sd.dlErrMu.Unlock()
+	return sd.dlStats.Skipped
+}
+
+// DownloadStats returns the file download statistics for the lifetime of the
+// session.
+func (sd *Session) DownloadStats() downloader.Stats {
+	sd.dlErrMu.Lock()
+	defer sd.dlErrMu.Unlock()
+	return sd.dlStats
+}
+
+// filenameFn returns the naming function for the files of the conversation
+// channelID.  It uses the file naming template, if it was set in the options.
+func (sd *Session) filenameFn(channelID string) downloader.FilenameFunc {
+	if sd.nameTmpl == nil {
+		return downloader.Filename
+	}
+	return downloader.TemplateNameFunc(sd.nameTmpl, channelID)
+}
+
 // pipeAndUpdateFiles scans the messages and sends all the files discovered to
-// the filesC.
-func pipeAndUpdateFiles(filesC chan<- *slack.File, msgs []types.Message, dir string) int {
+// the filesC.  The URLs of files rejected by the accept function are left
+// intact, as they will not be downloaded; nil accepts all files.  It returns
+// the number of accepted files.  The file paths are generated with nameFn,
+// that must be the naming function of the downloader.
+func pipeAndUpdateFiles(filesC chan<- *slack.File, msgs []types.Message, dir string, accept downloader.FilterFunc, nameFn downloader.FilenameFunc) int {
 	// place files in the download queue
 	total := 0
 	_ = files.Extract(msgs, files.Root, func(file slack.File, addr files.Addr) error {
+		// rejected files are still sent, so that the downloader would
+		// account for them.
 		filesC <- &file
+		if accept != nil && !accept(&file) {
+			return nil
+		}
 		total++
-		return files.Update(msgs, addr, files.UpdatePathFn(path.Join(dir, downloader.Filename(&file))))
+		return files.Update(msgs, addr, files.UpdatePathFn(path.Join(dir, nameFn(&file))))
 	})
 	return total
 }
 
 // newThreadProcessFn returns the new thread processor function.  It will use limiter l
 // to limit the API calls rate.
-func (sd *Session) newThreadProcessFn(ctx context.Context, l *rate.Limiter, oldest, latest time.Time) ProcessFunc {
+func (sd *Session) newThreadProcessFn(ctx context.Context, l *network.Limiter, oldest, latest time.Time) ProcessFunc {
 	processFn := func(chunk []types.Message, channelID string) (ProcessResult, error) {
 		n, err := sd.populateThreads(ctx, l, chunk, channelID, oldest, latest, sd.dumpThread)
 		if err != nil {
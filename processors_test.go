@@ -1,14 +1,23 @@
 package slackdump
 
 import (
+	"context"
+	"io"
+	"os"
 	"path"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/rusq/slackdump/v2/downloader"
+	"github.com/rusq/slackdump/v2/fsadapter"
+	"github.com/rusq/slackdump/v2/internal/mocks/mock_downloader"
 	"github.com/rusq/slackdump/v2/types"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
 )
 
 func TestSession_pipeFiles(t *testing.T) {
@@ -76,6 +85,25 @@ func TestSession_pipeFiles(t *testing.T) {
 			}
 		}
 	})
+	t.Run("rejected files are sent, but left intact", func(t *testing.T) {
+		msgs := []types.Message{{Message: slack.Message{Msg: slack.Msg{
+			Files: []slack.File{file4, file5, file6},
+		}}}}
+		filter := func(f *slack.File) bool { return f.ID != file5.ID }
+
+		var gotIDs []string
+		filesC := make(chan *slack.File, 3)
+		n := pipeAndUpdateFiles(filesC, msgs, "test_dir", filter, downloader.Filename)
+		close(filesC)
+		assert.Equal(t, 2, n)
+		for f := range filesC {
+			gotIDs = append(gotIDs, f.ID)
+		}
+
+		assert.Equal(t, []string{file4.ID, file5.ID, file6.ID}, gotIDs)
+		assert.Equal(t, path.Join("test_dir", downloader.Filename(&file4)), msgs[0].Files[0].URLPrivateDownload)
+		assert.Equal(t, file5.URLPrivateDownload, msgs[0].Files[1].URLPrivateDownload)
+	})
 }
 
 func pipeTestSuite(t *testing.T, msgs []types.Message, dir string) []slack.File {
@@ -92,8 +120,72 @@ func pipeTestSuite(t *testing.T, msgs []types.Message, dir string) []slack.File
 	}(filesC)
 	wg.Add(1)
 
-	pipeAndUpdateFiles(filesC, msgs, dir)
+	pipeAndUpdateFiles(filesC, msgs, dir, nil, downloader.Filename)
 	close(filesC)
 	wg.Wait()
 	return got
 }
+
+func TestSession_filenameFn(t *testing.T) {
+	file := slack.File{ID: "F1", Name: "photo.jpg", Timestamp: slack.JSONTime(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC).Unix())}
+	t.Run("standard", func(t *testing.T) {
+		var sd Session
+		assert.Equal(t, "F1-photo.jpg", sd.filenameFn("C1")(&file))
+	})
+	t.Run("template", func(t *testing.T) {
+		tmpl, err := downloader.ParseNameTemplate("{{.Channel}}_{{.Timestamp}}_{{.Name}}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sd := Session{nameTmpl: tmpl}
+		assert.Equal(t, "C1_2023-01-02_photo.jpg", sd.filenameFn("C1")(&file))
+	})
+}
+
+func TestSession_newFileProcessFn(t *testing.T) {
+	t.Run("uses the file getter", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		md := mock_downloader.NewMockDownloader(ctrl)
+		md.EXPECT().
+			GetFile("https://file1_url", gomock.Any()).
+			DoAndReturn(func(_ string, w io.Writer) error {
+				_, err := io.WriteString(w, "contents")
+				return err
+			}).
+			Times(1)
+
+		dir := t.TempDir()
+		sd := &Session{client: newmockClienter(ctrl), options: DefOptions, fs: fsadapter.NewDirectory(dir)}
+		sd.SetFileGetter(md)
+		sd.SetDownloadLimiter(rate.NewLimiter(rate.Inf, 1))
+
+		fn, cancelFn, err := sd.newFileProcessFn(context.Background(), "CHANNEL", sd.downloadLimiter())
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs := []types.Message{{Message: slack.Message{Msg: slack.Msg{Files: []slack.File{
+			{ID: "f1", Name: "filename1.ext", URLPrivateDownload: "https://file1_url", Size: 8},
+		}}}}}
+		res, err := fn(msgs, "CHANNEL")
+		cancelFn()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, ProcessResult{Entity: "files", Count: 1}, res)
+		assert.Empty(t, sd.DownloadErrors())
+
+		got, err := os.ReadFile(filepath.Join(dir, "CHANNEL", "f1-filename1.ext"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "contents", string(got))
+	})
+	t.Run("nil getter and limiter are ignored", func(t *testing.T) {
+		mc := newmockClienter(gomock.NewController(t))
+		sd := &Session{client: mc, options: DefOptions}
+		sd.SetFileGetter(nil)
+		sd.SetDownloadLimiter(nil)
+		assert.Equal(t, mc, sd.fileGetter())
+		assert.NotNil(t, sd.downloadLimiter())
+	})
+}
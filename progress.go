@@ -0,0 +1,64 @@
+package slackdump
+
+// In this file: progress of the message fetching.
+
+import (
+	"time"
+
+	"github.com/rusq/slackdump/v2/logger"
+)
+
+// MessageProgress is the progress of fetching the messages of a
+// conversation, see Options.OnMessageProgress.
+type MessageProgress struct {
+	ChannelID   string // conversation ID
+	ChannelName string // conversation name, empty for direct messages, or if not known
+	Page        int    // number of pages fetched so far
+	Messages    int    // number of messages fetched so far, not counting the thread replies
+	Done        bool   // the last page is fetched
+}
+
+// progressInterval is the minimum interval between the progress messages,
+// that are logged while the conversation is being fetched.  Each page is
+// logged in the verbose mode.
+var progressInterval = 10 * time.Second
+
+// msgProgress reports the progress of fetching the messages of a single
+// conversation.
+type msgProgress struct {
+	p     MessageProgress
+	l     logger.Interface
+	label string // conversation label for the log messages
+	fn    func(MessageProgress)
+	last  time.Time // time of the last progress message
+}
+
+func (sd *Session) newMsgProgress(channelID, name string) *msgProgress {
+	label := channelID
+	if name != "" {
+		label = "#" + name + " (" + channelID + ")"
+	}
+	return &msgProgress{
+		p:     MessageProgress{ChannelID: channelID, ChannelName: name},
+		l:     logger.With(sd.l(), "channel", channelID, "channel_name", name),
+		label: label,
+		fn:    sd.options.OnMessageProgress,
+		last:  time.Now(),
+	}
+}
+
+// report reports that the page of messages is fetched, and total messages
+// were fetched so far.  The progress is logged, if progressInterval has
+// passed since the last message, and passed to Options.OnMessageProgress,
+// if set.
+func (pr *msgProgress) report(page, total int, done bool) {
+	pr.p.Page, pr.p.Messages, pr.p.Done = page, total, done
+	if pr.fn != nil {
+		pr.fn(pr.p)
+	}
+	if done || time.Since(pr.last) < progressInterval {
+		return
+	}
+	pr.last = time.Now()
+	pr.l.Printf("%s: fetched %d messages, %d pages so far", pr.label, total, page)
+}
@@ -0,0 +1,40 @@
+package slackdump
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rusq/dlog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_msgProgress_report(t *testing.T) {
+	defer func(d time.Duration) { progressInterval = d }(progressInterval)
+
+	var (
+		buf bytes.Buffer
+		got []MessageProgress
+	)
+	opts := DefOptions
+	opts.Logger = dlog.New(&buf, "", 0, false)
+	opts.OnMessageProgress = func(p MessageProgress) { got = append(got, p) }
+	sd := &Session{options: opts}
+
+	progressInterval = time.Hour
+	pr := sd.newMsgProgress("C01", "general")
+	pr.report(1, 200, false)
+	assert.Empty(t, buf.String(), "must not log before the interval")
+
+	progressInterval = 0
+	pr.report(2, 400, false)
+	pr.report(3, 450, true)
+	assert.Equal(t, "#general (C01): fetched 400 messages, 2 pages so far\n", buf.String(), "the last page is not logged")
+
+	assert.Equal(t, []MessageProgress{
+		{ChannelID: "C01", ChannelName: "general", Page: 1, Messages: 200},
+		{ChannelID: "C01", ChannelName: "general", Page: 2, Messages: 400},
+		{ChannelID: "C01", ChannelName: "general", Page: 3, Messages: 450, Done: true},
+	}, got)
+	assert.Equal(t, "D01", sd.newMsgProgress("D01", "").label)
+}
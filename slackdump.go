@@ -4,8 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
 	"runtime/trace"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"errors"
@@ -13,8 +19,8 @@ import (
 	"github.com/slack-go/slack"
 	"golang.org/x/time/rate"
 
-	"github.com/rusq/chttp"
 	"github.com/rusq/slackdump/v2/auth"
+	"github.com/rusq/slackdump/v2/downloader"
 	"github.com/rusq/slackdump/v2/fsadapter"
 	"github.com/rusq/slackdump/v2/internal/network"
 	"github.com/rusq/slackdump/v2/internal/structures"
@@ -35,11 +41,34 @@ type Session struct {
 
 	fs fsadapter.FS // filesystem for saving attachments
 
+	// dlClient is the client used to download the files, it has the
+	// FileDownloadTimeout.  If nil, the client is used.
+	dlClient *slack.Client
+	// getter is the file getter used to download the attachments, nil if
+	// the client is used.
+	getter downloader.Downloader
+	// dlLimiter is the rate limiter of the file downloads, nil if the
+	// default limiter is used.
+	dlLimiter *rate.Limiter
+
 	// Users contains the list of users and populated on NewSession
 	Users     types.Users          `json:"users"`
 	UserIndex structures.UserIndex `json:"-"`
 
 	options Options
+	// nameTmpl is the parsed Options.FileNameTemplate, nil if the standard
+	// file naming is used.
+	nameTmpl *template.Template
+	// msgFilter is the compiled Options.MessageFilter, nil if all messages
+	// are kept.
+	msgFilter *regexp.Regexp
+	// cps are the checkpoints of the conversation dumps, nil if the
+	// Options.CheckpointFile is not set.
+	cps *checkpoints
+
+	dlErrMu sync.Mutex                // protects dlErrs and dlStats
+	dlErrs  downloader.DownloadErrors // files that failed to download
+	dlStats downloader.Stats          // file download statistics
 }
 
 // clienter is the interface with some functions of slack.Client with the sole
@@ -52,8 +81,13 @@ type clienter interface {
 	GetFile(downloadURL string, writer io.Writer) error
 	GetTeamInfo() (*slack.TeamInfo, error)
 	GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error)
+	GetUserPresenceContext(ctx context.Context, user string) (*slack.UserPresence, error)
+	GetUserProfileContext(ctx context.Context, params *slack.GetUserProfileParameters) (*slack.UserProfile, error)
+	GetTeamProfileContext(ctx context.Context) (*slack.TeamProfile, error)
 	GetEmojiContext(ctx context.Context) (map[string]string, error)
 	GetUsersInConversationContext(ctx context.Context, params *slack.GetUsersInConversationParameters) ([]string, string, error)
+	ListPinsContext(ctx context.Context, channel string) ([]slack.Item, *slack.Paging, error)
+	GetFilesContext(ctx context.Context, params slack.GetFilesParameters) ([]slack.File, *slack.Paging, error)
 }
 
 var (
@@ -88,27 +122,74 @@ func NewWithOptions(ctx context.Context, authProvider auth.Provider, opts Option
 		return nil, err
 	}
 
-	httpCl, err := chttp.New("https://slack.com", authProvider.Cookies())
+	var nameTmpl *template.Template
+	if opts.FileNameTemplate != "" {
+		var err error
+		if nameTmpl, err = downloader.ParseNameTemplate(opts.FileNameTemplate); err != nil {
+			return nil, err
+		}
+	}
+	var msgFilter *regexp.Regexp
+	if opts.MessageFilter != "" {
+		var err error
+		if msgFilter, err = regexp.Compile(opts.MessageFilter); err != nil {
+			return nil, fmt.Errorf("invalid message filter %q: %w", opts.MessageFilter, err)
+		}
+	}
+
+	var cps *checkpoints
+	if opts.CheckpointFile != "" {
+		var err error
+		if cps, err = loadCheckpoints(opts.CheckpointFile); err != nil {
+			return nil, err
+		}
+	}
+
+	tr, err := HTTPTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+	httpCl, err := newHTTPClient(authProvider.Cookies(), tr, opts.HTTPTimeout)
 	if err != nil {
 		return nil, err
 	}
-
 	cl := slack.New(authProvider.SlackToken(), slack.OptionHTTPClient(httpCl))
 
+	// files are downloaded by the separate client, as the large files need
+	// a longer timeout than the API calls.  The transport is shared.
+	dlHTTPCl, err := newHTTPClient(authProvider.Cookies(), tr, opts.FileDownloadTimeout)
+	if err != nil {
+		return nil, err
+	}
+	dlCl := slack.New(authProvider.SlackToken(), slack.OptionHTTPClient(dlHTTPCl))
+
 	authTestResp, err := cl.AuthTestContext(ctx)
 	if err != nil {
 		return nil, &AuthError{Err: err}
 	}
 
 	sd := &Session{
-		client:  cl,
-		options: opts,
-		wspInfo: authTestResp,
-		fs:      fsadapter.NewDirectory("."), // default is to save attachments to the current directory.
+		client:    cl,
+		options:   opts,
+		nameTmpl:  nameTmpl,
+		msgFilter: msgFilter,
+		cps:       cps,
+		wspInfo:   authTestResp,
+		dlClient:  dlCl,
+		fs:        fsadapter.NewDirectory("."), // default is to save attachments to the current directory.
 	}
 
 	network.SetLogger(sd.l())
 
+	if opts.Workers <= 0 {
+		sd.options.Workers = downloader.DefaultWorkers()
+	}
+	sd.l().Printf("> using %d file download workers", sd.options.Workers)
+
+	if eid := sd.EnterpriseID(); eid != "" {
+		sd.l().Printf("> Enterprise Grid workspace %s (organisation %s), the workspace context is added to the listings", sd.TeamID(), eid)
+	}
+
 	if err := os.MkdirAll(opts.CacheDir, 0700); err != nil {
 		return nil, fmt.Errorf("failed to create the cache directory: %s", err)
 	}
@@ -128,22 +209,71 @@ func NewWithOptions(ctx context.Context, authProvider auth.Provider, opts Option
 // TestAuth attempts to authenticate with the given provider.  It will return
 // AuthError if faled.
 func TestAuth(ctx context.Context, provider auth.Provider) error {
-	ctx, task := trace.NewTask(ctx, "TestAuth")
+	_, err := AuthInfo(ctx, provider)
+	return err
+}
+
+// Identity is the information about the authenticated user and the workspace.
+type Identity struct {
+	slack.AuthTestResponse
+	// Scopes are the OAuth scopes of the token, as reported by Slack.  It is
+	// empty for the tokens that are not limited by scopes, i.e. client
+	// tokens.
+	Scopes []string
+}
+
+// AuthInfo tests the credentials of the provider, and returns the information
+// about the authenticated user and the workspace.  It returns AuthError, if
+// the credentials are invalid.  Options, if given, configure the HTTP
+// transport, i.e. WithProxy.
+func AuthInfo(ctx context.Context, provider auth.Provider, opts ...Option) (*Identity, error) {
+	ctx, task := trace.NewTask(ctx, "AuthInfo")
 	defer task.End()
 
-	httpCl, err := chttp.New("https://slack.com", provider.Cookies())
+	options := DefOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	tr, err := HTTPTransport(options)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	httpCl, err := newHTTPClient(provider.Cookies(), tr, options.HTTPTimeout)
+	if err != nil {
+		return nil, err
 	}
+	hr := &headerRecorder{cl: httpCl}
 
-	cl := slack.New(provider.SlackToken(), slack.OptionHTTPClient(httpCl))
+	cl := slack.New(provider.SlackToken(), slack.OptionHTTPClient(hr))
 
 	region := trace.StartRegion(ctx, "AuthTestContext")
 	defer region.End()
-	if _, err := cl.AuthTestContext(ctx); err != nil {
-		return &AuthError{Err: err}
+	resp, err := cl.AuthTestContext(ctx)
+	if err != nil {
+		return nil, &AuthError{Err: err}
 	}
-	return nil
+	id := Identity{AuthTestResponse: *resp}
+	if scopes := hr.header.Get("X-OAuth-Scopes"); scopes != "" {
+		for _, s := range strings.Split(scopes, ",") {
+			id.Scopes = append(id.Scopes, strings.TrimSpace(s))
+		}
+	}
+	return &id, nil
+}
+
+// headerRecorder is the HTTP client, that records the headers of the last
+// response.
+type headerRecorder struct {
+	cl     *http.Client
+	header http.Header
+}
+
+func (hr *headerRecorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := hr.cl.Do(req)
+	if err == nil {
+		hr.header = resp.Header
+	}
+	return resp, err
 }
 
 // Client returns the underlying slack.Client.
@@ -151,6 +281,15 @@ func (sd *Session) Client() *slack.Client {
 	return sd.client.(*slack.Client)
 }
 
+// FileClient returns the slack.Client used to download the files.  It differs
+// from Client by the request timeout, see Options.FileDownloadTimeout.
+func (sd *Session) FileClient() *slack.Client {
+	if sd.dlClient == nil {
+		return sd.Client()
+	}
+	return sd.dlClient
+}
+
 // Me returns the current authenticated user in a rather dirty manner.
 // If the user cache is unitnitialised, it returns ErrNoUserCache.
 func (sd *Session) Me() (slack.User, error) {
@@ -164,6 +303,29 @@ func (sd *Session) CurrentUserID() string {
 	return sd.wspInfo.UserID
 }
 
+// Workspace is the information about the workspace, that the session is
+// authenticated with, as reported by auth.test.
+type Workspace struct {
+	TeamID       string `json:"team_id"`
+	Team         string `json:"team"`
+	URL          string `json:"url"`
+	EnterpriseID string `json:"enterprise_id,omitempty"`
+}
+
+// Workspace returns the information about the workspace, that the session is
+// authenticated with.
+func (sd *Session) Workspace() Workspace {
+	if sd.wspInfo == nil {
+		return Workspace{}
+	}
+	return Workspace{
+		TeamID:       sd.wspInfo.TeamID,
+		Team:         sd.wspInfo.Team,
+		URL:          sd.wspInfo.URL,
+		EnterpriseID: sd.wspInfo.EnterpriseID,
+	}
+}
+
 // SetFS sets the filesystem to save attachments to (slackdump defaults to the
 // current directory otherwise).
 func (sd *Session) SetFS(fs fsadapter.FS) {
@@ -173,8 +335,71 @@ func (sd *Session) SetFS(fs fsadapter.FS) {
 	sd.fs = fs
 }
 
-func (sd *Session) limiter(t network.Tier) *rate.Limiter {
-	return network.NewLimiter(t, sd.options.Tier3Burst, int(sd.options.Tier3Boost))
+// SetFileGetter sets the file getter that is used to download the
+// attachments, i.e. a mock or a caching proxy (slackdump defaults to the
+// Slack client otherwise).
+func (sd *Session) SetFileGetter(g downloader.Downloader) {
+	if g == nil {
+		return
+	}
+	sd.getter = g
+}
+
+// SetDownloadLimiter sets the rate limiter of the file downloads (slackdump
+// defaults to the limiter without the tier limits otherwise).
+func (sd *Session) SetDownloadLimiter(l *rate.Limiter) {
+	if l == nil {
+		return
+	}
+	sd.dlLimiter = l
+}
+
+// fileGetter returns the file getter used to download the attachments.
+func (sd *Session) fileGetter() downloader.Downloader {
+	if sd.getter == nil {
+		if sd.dlClient != nil {
+			return sd.dlClient
+		}
+		return sd.client
+	}
+	return sd.getter
+}
+
+// downloadLimiter returns the rate limiter of the file downloads.
+func (sd *Session) downloadLimiter() *rate.Limiter {
+	if sd.dlLimiter == nil {
+		return sd.limiter(network.NoTier).Limiter
+	}
+	return sd.dlLimiter
+}
+
+// limiter returns the new limiter of the tier t.  The Tier-2 limiter uses the
+// Tier-2 boost and burst, the other tiers use the Tier-3 ones.  The rate is
+// capped with Options.MaxRequestsPerMinute.
+func (sd *Session) limiter(t network.Tier) *network.Limiter {
+	burst, boost := sd.options.Tier3Burst, sd.options.Tier3Boost
+	if t == network.Tier2 {
+		burst, boost = sd.options.Tier2Burst, sd.options.Tier2Boost
+	}
+	return network.NewLimiter(t, burst, capBoost(t, boost, sd.options.MaxRequestsPerMinute))
+}
+
+// CacheFiles returns the user and channel cache files of all workspaces, that
+// exist in the cache directory of opts.
+func CacheFiles(opts Options) ([]string, error) {
+	var files []string
+	for _, filename := range []string{opts.UserCacheFilename, opts.ChannelCacheFilename} {
+		if filename == "" {
+			continue
+		}
+		ne := filenameSplit(filename)
+		matches, err := filepath.Glob(filepath.Join(opts.CacheDir, filenameJoin(nameExt{ne[0] + "-*", ne[1]})))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
 }
 
 func checkCacheFile(filename string, maxAge time.Duration) error {
@@ -0,0 +1,87 @@
+package slackdump
+
+// In this file: streaming conversation messages.
+
+import (
+	"context"
+	"errors"
+	"runtime/trace"
+	"time"
+
+	"github.com/rusq/slackdump/v2/internal/network"
+	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/types"
+)
+
+// MessageFunc is the signature of the function that StreamConversation calls
+// for each message.  If it returns an error, the stream is stopped, and the
+// error is returned to the caller.
+type MessageFunc func(msg types.Message) error
+
+// StreamConversation fetches the messages from the conversation identified
+// by link (see Dump for the supported link formats) within the timeframe
+// between oldest and latest, and calls fn for each message, page by page, as
+// they are returned by the API.
+//
+// Unlike Dump, which collects all messages of the conversation in memory
+// before returning them, StreamConversation only holds one page of messages
+// (plus the replies of the threads started in that page) at a time, so the
+// caller could process the messages and discard them.  This allows to dump
+// very large channels with a constant memory footprint.  The tradeoffs are:
+//
+//   - messages are not sorted across the pages: the API returns them from
+//     the newest to the oldest, and so does StreamConversation;
+//   - the checkpoints are not used, as the messages that were passed to fn
+//     are not retained, and there's nothing to resume from;
+//   - the thread link is still fetched in full before fn is called, as the
+//     thread replies are returned by a different API, that does not have the
+//     same page semantic.
+//
+// Files are downloaded, if the DumpFiles option is set, the same way as Dump
+// does, and the file URLs of the messages passed to fn are updated to point
+// to the downloaded files.  StreamConversation returns once all the files are
+// downloaded.
+func (sd *Session) StreamConversation(ctx context.Context, link string, oldest, latest time.Time, fn MessageFunc) error {
+	ctx, task := trace.NewTask(ctx, "StreamConversation")
+	defer task.End()
+
+	if fn == nil {
+		return errors.New("message function is nil")
+	}
+	sl, err := structures.ParseLink(link)
+	if err != nil {
+		return err
+	}
+	if !sl.IsValid() {
+		return errors.New("invalid link")
+	}
+
+	var processFn []ProcessFunc
+	if sd.options.DumpFiles {
+		fileFn, cancelFn, err := sd.newFileProcessFn(ctx, sl.Channel, sd.downloadLimiter())
+		if err != nil {
+			return err
+		}
+		defer cancelFn()
+		processFn = append(processFn, fileFn)
+	}
+
+	pageFn := func(chunk []types.Message, _ string) error {
+		for i := range chunk {
+			if err := fn(chunk[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if sl.IsThread() {
+		msgs, err := sd.dumpThread(ctx, sd.limiter(network.Tier3), sl.Channel, sl.ThreadTS, oldest, latest, processFn...)
+		if err != nil {
+			return err
+		}
+		types.SortMessages(msgs)
+		return pageFn(msgs, "")
+	}
+	return sd.streamChannel(ctx, sl.Channel, "", "", 0, oldest, latest, pageFn, processFn...)
+}
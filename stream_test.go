@@ -0,0 +1,140 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v2/types"
+)
+
+func TestSession_StreamConversation(t *testing.T) {
+	errStop := errors.New("stop")
+	tests := []struct {
+		name     string
+		link     string
+		expectFn func(mc *mockClienter)
+		stopAt   int // stop the stream after this many messages, 0 - don't stop.
+		want     []types.Message
+		wantErr  bool
+	}{
+		{
+			"pages are streamed in order",
+			"CHANNEL",
+			func(c *mockClienter) {
+				first := c.EXPECT().
+					GetConversationHistoryContext(
+						gomock.Any(),
+						&slack.GetConversationHistoryParameters{
+							ChannelID: "CHANNEL",
+							Limit:     DefOptions.ConversationsPerReq,
+							Inclusive: true,
+						}).
+					Return(
+						&slack.GetConversationHistoryResponse{
+							HasMore:       true,
+							SlackResponse: slack.SlackResponse{Ok: true},
+							ResponseMetaData: struct {
+								NextCursor string "json:\"next_cursor\""
+							}{"cur"},
+							Messages: []slack.Message{testMsg3.Message, testMsg2.Message},
+						},
+						nil).
+					Times(1)
+				c.EXPECT().
+					GetConversationHistoryContext(
+						gomock.Any(),
+						&slack.GetConversationHistoryParameters{
+							ChannelID: "CHANNEL",
+							Cursor:    "cur",
+							Limit:     DefOptions.ConversationsPerReq,
+							Inclusive: true,
+						}).
+					Return(
+						&slack.GetConversationHistoryResponse{
+							SlackResponse: slack.SlackResponse{Ok: true},
+							Messages:      []slack.Message{testMsg1.Message},
+						},
+						nil).
+					Times(1).
+					After(first)
+			},
+			0,
+			[]types.Message{testMsg3, testMsg2, testMsg1},
+			false,
+		},
+		{
+			"callback error stops the stream",
+			"CHANNEL",
+			func(c *mockClienter) {
+				c.EXPECT().
+					GetConversationHistoryContext(gomock.Any(), gomock.Any()).
+					Return(
+						&slack.GetConversationHistoryResponse{
+							HasMore:       true,
+							SlackResponse: slack.SlackResponse{Ok: true},
+							ResponseMetaData: struct {
+								NextCursor string "json:\"next_cursor\""
+							}{"cur"},
+							Messages: []slack.Message{testMsg3.Message, testMsg2.Message},
+						},
+						nil).
+					Times(1)
+			},
+			1,
+			[]types.Message{testMsg3},
+			true,
+		},
+		{
+			"thread link",
+			"CHANNEL:1638497751.040300",
+			func(c *mockClienter) {
+				c.EXPECT().
+					GetConversationRepliesContext(
+						gomock.Any(),
+						&slack.GetConversationRepliesParameters{ChannelID: "CHANNEL", Timestamp: "1638497751.040300", Limit: DefOptions.RepliesPerReq, Inclusive: true},
+					).
+					Return([]slack.Message{testMsg2.Message, testMsg1.Message}, false, "", nil).
+					Times(1)
+			},
+			0,
+			[]types.Message{testMsg1, testMsg2},
+			false,
+		},
+		{
+			"invalid link",
+			"https://example.com",
+			func(c *mockClienter) {},
+			0,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mc := newmockClienter(ctrl)
+			tt.expectFn(mc)
+
+			sd := &Session{client: mc, options: DefOptions}
+			var got []types.Message
+			err := sd.StreamConversation(context.Background(), tt.link, time.Time{}, time.Time{}, func(msg types.Message) error {
+				got = append(got, msg)
+				if tt.stopAt > 0 && len(got) == tt.stopAt {
+					return errStop
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Session.StreamConversation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -9,14 +9,47 @@ import (
 	"errors"
 
 	"github.com/slack-go/slack"
-	"golang.org/x/time/rate"
 
 	"github.com/rusq/slackdump/v2/internal/network"
 	"github.com/rusq/slackdump/v2/internal/structures"
+	"github.com/rusq/slackdump/v2/logger"
 	"github.com/rusq/slackdump/v2/types"
 )
 
-type threadFunc func(ctx context.Context, l *rate.Limiter, channelID string, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error)
+// ErrNotThread is returned by DumpThreadURL, if the URL does not point to a
+// thread.
+var ErrNotThread = errors.New("url does not point to a thread")
+
+// DumpThreadURL dumps a single thread with all the replies, identified by the
+// thread permalink, i.e.
+//
+//	https://ora600.slack.com/archives/CHM82GF99/p1577694990000400
+//
+// The permalink of a reply, that has the thread_ts parameter, resolves to the
+// whole thread.  Files are downloaded, if the DumpFiles option is set, the same
+// way as Dump does.
+func (sd *Session) DumpThreadURL(ctx context.Context, threadURL string, processFn ...ProcessFunc) (*types.Conversation, error) {
+	sl, err := parseThreadURL(threadURL)
+	if err != nil {
+		return nil, err
+	}
+	return sd.Dump(ctx, sl.String(), time.Time{}, time.Time{}, processFn...)
+}
+
+// parseThreadURL parses the thread permalink, see DumpThreadURL.
+func parseThreadURL(threadURL string) (structures.SlackLink, error) {
+	channelID, threadTS, err := structures.ParsePermalink(threadURL)
+	if err != nil {
+		return structures.SlackLink{}, err
+	}
+	sl := structures.SlackLink{Channel: channelID, ThreadTS: threadTS}
+	if !sl.IsThread() {
+		return structures.SlackLink{}, ErrNotThread
+	}
+	return sl, nil
+}
+
+type threadFunc func(ctx context.Context, l *network.Limiter, channelID string, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error)
 
 // dumpThreadAsConversation dumps a single thread identified by (channelID,
 // threadTS). Optionally one can provide a number of processFn that will be
@@ -64,7 +97,7 @@ func (sd *Session) dumpThreadAsConversation(
 // ref: https://api.slack.com/messaging/retrieving
 func (*Session) populateThreads(
 	ctx context.Context,
-	l *rate.Limiter,
+	l *network.Limiter,
 	msgs []types.Message,
 	channelID string,
 	oldest, latest time.Time,
@@ -93,7 +126,7 @@ func (*Session) populateThreads(
 // of messages.
 func (sd *Session) dumpThread(
 	ctx context.Context,
-	l *rate.Limiter,
+	l *network.Limiter,
 	channelID string,
 	threadTS string,
 	oldest, latest time.Time,
@@ -103,6 +136,7 @@ func (sd *Session) dumpThread(
 		thread     []types.Message
 		cursor     string
 		fetchStart = time.Now()
+		keepAll    bool // keep all replies, see Session.keepThread
 	)
 	for i := 0; ; i++ {
 		var (
@@ -139,14 +173,18 @@ func (sd *Session) dumpThread(
 		if 0 < i && 1 < len(msgs) {
 			msgs = msgs[1:]
 		}
-		thread = append(thread, types.ConvertMsgs(msgs)...)
+		if i == 0 && len(msgs) > 0 {
+			// the first message is the thread parent.
+			keepAll = sd.keepThread(types.Message{Message: msgs[0]})
+		}
+		thread = append(thread, sd.filterMessagesBy(types.ConvertMsgs(msgs), sd.hasContentFilter() && !keepAll)...)
 
 		prs, err := runProcessFuncs(thread, channelID, processFn...)
 		if err != nil {
 			return nil, err
 		}
 
-		sd.l().Printf("  thread request #%5d, fetched: %4d, total: %8d, process results: %s (speed: %6.2f/sec, avg: %6.2f/sec)\n",
+		logger.With(sd.l(), "channel", channelID, "thread", threadTS).Printf("  thread request #%5d, fetched: %4d, total: %8d, process results: %s (speed: %6.2f/sec, avg: %6.2f/sec)\n",
 			i+1, len(msgs), len(thread),
 			prs,
 			float64(len(msgs))/time.Since(reqStart).Seconds(),
@@ -154,7 +192,7 @@ func (sd *Session) dumpThread(
 		)
 
 		if !hasmore {
-			sd.l().Printf("  thread fetch complete, total: %d", len(thread))
+			logger.With(sd.l(), "channel", channelID, "thread", threadTS).Printf("  thread fetch complete, total: %d", len(thread))
 			break
 		}
 		cursor = nextCursor
@@ -10,7 +10,6 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
-	"golang.org/x/time/rate"
 
 	"github.com/rusq/slackdump/v2/internal/network"
 	"github.com/rusq/slackdump/v2/internal/structures"
@@ -182,7 +181,7 @@ func TestSession_DumpThreadWithFiles(t *testing.T) {
 func TestSession_populateThreads(t *testing.T) {
 	type args struct {
 		ctx       context.Context
-		l         *rate.Limiter
+		l         *network.Limiter
 		msgs      []types.Message
 		channelID string
 		oldest    time.Time
@@ -202,7 +201,7 @@ func TestSession_populateThreads(t *testing.T) {
 				l:         network.NewLimiter(network.NoTier, 1, 0),
 				msgs:      []types.Message{testMsg1},
 				channelID: "x",
-				dumpFn: func(ctx context.Context, l *rate.Limiter, channelID, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error) {
+				dumpFn: func(ctx context.Context, l *network.Limiter, channelID, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error) {
 					return nil, nil
 				},
 			},
@@ -216,7 +215,7 @@ func TestSession_populateThreads(t *testing.T) {
 				l:         network.NewLimiter(network.NoTier, 1, 0),
 				msgs:      []types.Message{testMsg1, testMsg4t},
 				channelID: "x",
-				dumpFn: func(ctx context.Context, l *rate.Limiter, channelID, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error) {
+				dumpFn: func(ctx context.Context, l *network.Limiter, channelID, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error) {
 					return []types.Message{testMsg4t, testMsg2}, nil
 				},
 			},
@@ -230,7 +229,7 @@ func TestSession_populateThreads(t *testing.T) {
 				l:         network.NewLimiter(network.NoTier, 1, 0),
 				msgs:      []types.Message{testMsg4t, testMsg1},
 				channelID: "x",
-				dumpFn: func(ctx context.Context, l *rate.Limiter, channelID, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error) {
+				dumpFn: func(ctx context.Context, l *network.Limiter, channelID, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error) {
 					return []types.Message{}, nil
 				},
 			},
@@ -244,7 +243,7 @@ func TestSession_populateThreads(t *testing.T) {
 				l:         network.NewLimiter(network.NoTier, 1, 0),
 				msgs:      []types.Message{testMsg4t},
 				channelID: "x",
-				dumpFn: func(ctx context.Context, l *rate.Limiter, channelID, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error) {
+				dumpFn: func(ctx context.Context, l *network.Limiter, channelID, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error) {
 					return nil, errors.New("bam")
 				},
 			},
@@ -275,7 +274,7 @@ func TestSession_dumpThread(t *testing.T) {
 	}
 	type args struct {
 		ctx       context.Context
-		l         *rate.Limiter
+		l         *network.Limiter
 		channelID string
 		threadTS  string
 		oldest    time.Time
@@ -398,3 +397,77 @@ func TestSession_dumpThread(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseThreadURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		threadURL string
+		want      structures.SlackLink
+		wantErr   error
+	}{
+		{
+			"public channel thread",
+			"https://ora600.slack.com/archives/CHM82GF99/p1577694990000400",
+			structures.SlackLink{Channel: "CHM82GF99", ThreadTS: "1577694990.000400"},
+			nil,
+		},
+		{
+			"private channel thread",
+			"https://ora600.slack.com/archives/GHM82GF99/p1577694990000400",
+			structures.SlackLink{Channel: "GHM82GF99", ThreadTS: "1577694990.000400"},
+			nil,
+		},
+		{
+			"archived reply resolves to the thread",
+			"https://ora600.slack.com/archives/CHM82GF99/p1577695000000500?thread_ts=1577694990.000400&cid=CHM82GF99",
+			structures.SlackLink{Channel: "CHM82GF99", ThreadTS: "1577694990.000400"},
+			nil,
+		},
+		{
+			"channel url is not a thread",
+			"https://ora600.slack.com/archives/CHM82GF99",
+			structures.SlackLink{},
+			ErrNotThread,
+		},
+		{
+			"not a slack url",
+			"https://example.com/archives/CHM82GF99/p1577694990000400",
+			structures.SlackLink{},
+			structures.ErrNotSlackURL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseThreadURL(tt.threadURL)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("parseThreadURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	t.Run("invalid thread_ts", func(t *testing.T) {
+		_, err := parseThreadURL("https://ora600.slack.com/archives/CHM82GF99/p1577694990000400?thread_ts=garbage")
+		assert.Error(t, err)
+	})
+}
+
+func TestSession_DumpThreadURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mc := newmockClienter(ctrl)
+	mc.EXPECT().
+		GetConversationRepliesContext(
+			gomock.Any(),
+			&slack.GetConversationRepliesParameters{ChannelID: "GHM82GF99", Timestamp: "1577694990.000400", Limit: DefOptions.RepliesPerReq, Inclusive: true},
+		).
+		Return([]slack.Message{testMsg1.Message, testMsg2.Message}, false, "", nil).
+		Times(1)
+	mockConvInfo(mc, "GHM82GF99", "private_channel")
+
+	sd := &Session{client: mc, options: DefOptions}
+	got, err := sd.DumpThreadURL(context.Background(), "https://ora600.slack.com/archives/GHM82GF99/p1577694990000400")
+	if err != nil {
+		t.Fatalf("Session.DumpThreadURL() error = %v", err)
+	}
+	assert.Equal(t, &types.Conversation{Name: "private_channel", ID: "GHM82GF99", ThreadTS: "1577694990.000400", Messages: []types.Message{testMsg1, testMsg2}}, got)
+}
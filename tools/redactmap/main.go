@@ -0,0 +1,63 @@
+// Command redactmap prints the redaction map, saved by slackdump with the
+// -redact-map-encrypt flag, in plain JSON.  It must be run on the same
+// machine, or with the same passphrase, as the export.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/rusq/osenv/v2"
+
+	"github.com/rusq/slackdump/v2/internal/encio"
+	"github.com/rusq/slackdump/v2/internal/redact"
+)
+
+var passphrase = flag.String("passphrase", osenv.Secret("SLACKDUMP_PASSPHRASE", ""), "passphrase, if the map was encrypted with the passphrase")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <redaction map file>\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *passphrase); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(filename, passphrase string) error {
+	var (
+		f   io.ReadCloser
+		err error
+	)
+	if passphrase != "" {
+		key, kerr := encio.PassphraseKey(passphrase)
+		if kerr != nil {
+			return kerr
+		}
+		f, err = encio.OpenWithKey(filename, key)
+	} else {
+		f, err = encio.Open(filename)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	m, err := redact.ReadMap(f)
+	if err != nil {
+		return fmt.Errorf("error reading the redaction map: %w", err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
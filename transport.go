@@ -0,0 +1,91 @@
+package slackdump
+
+// In this file: HTTP transport configuration.
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rusq/chttp"
+)
+
+// slackURL is the domain of the Slack cookies.
+const slackURL = "https://slack.com"
+
+// defDialTimeout is the Go default of the dial timeout and the keep-alive
+// period, see http.DefaultTransport.
+const defDialTimeout = 30 * time.Second
+
+// HTTPTransport returns the HTTP transport with the proxy, TLS and timeout
+// settings of opts.  If the Proxy is not set, the proxy is taken from the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables, same as for the
+// default transport.
+func HTTPTransport(opts Options) (*http.Transport, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.DialTimeout != 0 || opts.KeepAlive != 0 {
+		dialer := &net.Dialer{Timeout: defDialTimeout, KeepAlive: defDialTimeout}
+		if opts.DialTimeout != 0 {
+			dialer.Timeout = opts.DialTimeout
+		}
+		if opts.KeepAlive != 0 {
+			dialer.KeepAlive = opts.KeepAlive
+		}
+		tr.DialContext = dialer.DialContext
+	}
+	tr.ResponseHeaderTimeout = opts.ResponseHeaderTimeout
+	if opts.Proxy != "" {
+		proxyURL, err := ParseProxyURL(opts.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		tr.Proxy = http.ProxyURL(proxyURL)
+	}
+	if opts.InsecureSkipVerify {
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		}
+		tr.TLSClientConfig.InsecureSkipVerify = true
+	}
+	return tr, nil
+}
+
+// errInvalidProxy is returned, if the proxy URL can't be parsed.  The URL
+// is not included in the error, as it may contain the password.
+var errInvalidProxy = errors.New("invalid proxy URL")
+
+// ParseProxyURL parses the proxy URL.  The scheme defaults to http, if it is
+// not set, as the proxies are often given as "host:port".
+func ParseProxyURL(s string) (*url.URL, error) {
+	if !strings.Contains(s, "://") {
+		// "host:port" would be parsed as the scheme "host" with the opaque
+		// "port".
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return nil, errInvalidProxy
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("%w: unsupported scheme %q", errInvalidProxy, u.Scheme)
+	}
+	return u, nil
+}
+
+// newHTTPClient returns the HTTP client with the Slack cookies, that uses
+// the transport tr, and the request timeout.
+func newHTTPClient(cookies []*http.Cookie, tr http.RoundTripper, timeout time.Duration) (*http.Client, error) {
+	cl, err := chttp.NewWithTransport(slackURL, cookies, chttp.NewTransport(tr))
+	if err != nil {
+		return nil, err
+	}
+	cl.Timeout = timeout
+	return cl, nil
+}
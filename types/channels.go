@@ -1,9 +1,11 @@
 package types
 
 import (
+	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"text/tabwriter"
 
 	"github.com/slack-go/slack"
@@ -14,24 +16,218 @@ import (
 // Channels keeps slice of channels.
 type Channels []slack.Channel
 
+// ChannelColumns are the columns of the channels text output, in the default
+// order.
+var ChannelColumns = []string{"id", "arch", "saved", "name"}
+
+// channelHeader is the header of the channels text output.
+var channelHeader = map[string]string{"id": "ID", "arch": "Arch", "saved": "Saved", "name": "What"}
+
 // ToText outputs Channels to w in text format.
 func (cs Channels) ToText(w io.Writer, ui structures.UserIndex) (err error) {
-	const strFormat = "%s\t%s\t%s\t%s\n"
+	return cs.ToTextColumns(w, ui, nil)
+}
+
+// ToTextColumns outputs Channels to w in text format, including only the
+// columns cols, see ChannelColumns.  If cols is empty, all columns are output.
+func (cs Channels) ToTextColumns(w io.Writer, ui structures.UserIndex, cols []string) error {
+	if len(cols) == 0 {
+		cols = ChannelColumns
+	}
+	if err := CheckColumns(cols, ChannelColumns); err != nil {
+		return err
+	}
 	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
 	defer writer.Flush()
-	fmt.Fprintf(writer, strFormat, "ID", "Arch", "Saved", "What")
+	writeRow(writer, cols, channelHeader)
 	for i, ch := range cs {
-		who := ui.ChannelName(&ch)
 		archived := "-"
 		if cs[i].IsArchived || ui.IsDeleted(ch.User) {
 			archived = "arch"
 		}
 		saved := "-"
-		if _, err := os.Stat(ch.ID + ".json"); err == nil {
-			saved = "saved"
+		if contains(cols, "saved") {
+			if _, err := os.Stat(ch.ID + ".json"); err == nil {
+				saved = "saved"
+			}
 		}
 
-		fmt.Fprintf(writer, strFormat, ch.ID, archived, saved, who)
+		writeRow(writer, cols, map[string]string{"id": ch.ID, "arch": archived, "saved": saved, "name": ui.ChannelName(&ch)})
 	}
 	return nil
 }
+
+// ToCSV outputs Channels to w in CSV format, with the header row.  The
+// columns are: ID, Name, Type, IsArchived, MemberCount.  Names of the direct
+// messages are resolved using ui.
+func (cs Channels) ToCSV(w io.Writer, ui structures.UserIndex) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"ID", "Name", "Type", "IsArchived", "MemberCount"}); err != nil {
+		return fmt.Errorf("writer error: %w", err)
+	}
+	for i := range cs {
+		ch := &cs[i]
+		if err := cw.Write([]string{
+			ch.ID,
+			chanName(ch, ui),
+			chanType(ch),
+			strconv.FormatBool(ch.IsArchived),
+			strconv.Itoa(ch.NumMembers),
+		}); err != nil {
+			return fmt.Errorf("writer error: %w", err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// chanName returns the plain name of the channel, for DMs it is the name of
+// the user.
+func chanName(ch *slack.Channel, ui structures.UserIndex) string {
+	if ch.IsIM {
+		return ui.Username(ch.User)
+	}
+	return ch.Name
+}
+
+// chanType returns the type of the channel, disregarding the archived status.
+func chanType(ch *slack.Channel) string {
+	switch {
+	case ch.IsIM:
+		return ChanTypeIM
+	case ch.IsMpIM:
+		return ChanTypeMPIM
+	case ch.IsPrivate || ch.IsGroup:
+		return ChanTypePrivate
+	default:
+		return ChanTypePublic
+	}
+}
+
+// Channel group types, in the order of appearance in the grouped output.
+const (
+	ChanTypePublic   = "public"
+	ChanTypePrivate  = "private"
+	ChanTypeMPIM     = "mpim"
+	ChanTypeIM       = "im"
+	ChanTypeArchived = "archived"
+)
+
+var chanGroupOrder = []string{ChanTypePublic, ChanTypePrivate, ChanTypeMPIM, ChanTypeIM, ChanTypeArchived}
+
+// ChannelGroup is the group of channels of the same type.
+type ChannelGroup struct {
+	Type     string   `json:"type"`
+	Count    int      `json:"count"`
+	Channels Channels `json:"channels"`
+}
+
+// ChannelGroups is the list of channels grouped by type.
+type ChannelGroups []ChannelGroup
+
+// chanGroupType returns the group type of the channel.  Archived channels
+// form their own group regardless of their type.
+func chanGroupType(ch *slack.Channel) string {
+	if ch.IsArchived {
+		return ChanTypeArchived
+	}
+	return chanType(ch)
+}
+
+// ArchivedFilter selects the channels by their archived status.
+type ArchivedFilter uint8
+
+const (
+	ArchivedAny  ArchivedFilter = iota // both archived and active channels
+	ArchivedOnly                       // only the archived channels
+	ActiveOnly                         // only the active channels
+)
+
+// Match returns true, if the channel ch passes the filter.
+func (f ArchivedFilter) Match(ch *slack.Channel) bool {
+	switch f {
+	case ArchivedOnly:
+		return ch.IsArchived
+	case ActiveOnly:
+		return !ch.IsArchived
+	default:
+		return true
+	}
+}
+
+// FilterArchived returns the channels, that pass the filter f.
+func (cs Channels) FilterArchived(f ArchivedFilter) Channels {
+	if f == ArchivedAny {
+		return cs
+	}
+	ret := make(Channels, 0, len(cs))
+	for i := range cs {
+		if f.Match(&cs[i]) {
+			ret = append(ret, cs[i])
+		}
+	}
+	return ret
+}
+
+// GroupByType groups the channels by type: public, private, mpim, im and
+// archived.  All groups are present in the output, even if they are empty.
+func (cs Channels) GroupByType() ChannelGroups {
+	idx := make(map[string]int, len(chanGroupOrder))
+	groups := make(ChannelGroups, len(chanGroupOrder))
+	for i, typ := range chanGroupOrder {
+		idx[typ] = i
+		groups[i] = ChannelGroup{Type: typ, Channels: Channels{}}
+	}
+	for i := range cs {
+		g := &groups[idx[chanGroupType(&cs[i])]]
+		g.Channels = append(g.Channels, cs[i])
+		g.Count++
+	}
+	return groups
+}
+
+// ToText outputs the summary of channel groups, followed by the channels in
+// each non-empty group to w in text format.
+func (cg ChannelGroups) ToText(w io.Writer, ui structures.UserIndex) error {
+	return cg.ToTextColumns(w, ui, nil)
+}
+
+// ToTextColumns is the same as ToText, but the channels include only the
+// columns cols, see Channels.ToTextColumns.
+func (cg ChannelGroups) ToTextColumns(w io.Writer, ui structures.UserIndex, cols []string) error {
+	if err := CheckColumns(cols, ChannelColumns); err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%s\t%s\n", "Type", "Count")
+	total := 0
+	for _, g := range cg {
+		fmt.Fprintf(writer, "%s\t%d\n", g.Type, g.Count)
+		total += g.Count
+	}
+	fmt.Fprintf(writer, "%s\t%d\n", "total", total)
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("writer error: %w", err)
+	}
+
+	for _, g := range cg {
+		if g.Count == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d):\n", g.Type, g.Count)
+		if err := g.Channels.ToTextColumns(w, ui, cols); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToCSV outputs the channels of all groups to w in CSV format, in the order of
+// the groups.  The columns are the same as in Channels.ToCSV.
+func (cg ChannelGroups) ToCSV(w io.Writer, ui structures.UserIndex) error {
+	var all Channels
+	for _, g := range cg {
+		all = append(all, g.Channels...)
+	}
+	return all.ToCSV(w, ui)
+}
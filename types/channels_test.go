@@ -0,0 +1,139 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func testChannel(id string, fn func(ch *slack.Channel)) slack.Channel {
+	var ch slack.Channel
+	ch.ID = id
+	ch.NameNormalized = id
+	fn(&ch)
+	return ch
+}
+
+var testMixedChannels = Channels{
+	testChannel("CPUB1", func(ch *slack.Channel) {}),
+	testChannel("CPUB2", func(ch *slack.Channel) {}),
+	testChannel("GPRIV", func(ch *slack.Channel) { ch.IsPrivate = true }),
+	testChannel("GMPIM", func(ch *slack.Channel) { ch.IsMpIM = true; ch.IsPrivate = true }),
+	testChannel("DIM01", func(ch *slack.Channel) { ch.IsIM = true }),
+	testChannel("DIM02", func(ch *slack.Channel) { ch.IsIM = true }),
+	testChannel("CARCH", func(ch *slack.Channel) { ch.IsArchived = true }),
+	testChannel("GARCH", func(ch *slack.Channel) { ch.IsArchived = true; ch.IsPrivate = true }),
+}
+
+func TestChannels_GroupByType(t *testing.T) {
+	got := testMixedChannels.GroupByType()
+
+	wantCounts := map[string]int{
+		ChanTypePublic:   2,
+		ChanTypePrivate:  1,
+		ChanTypeMPIM:     1,
+		ChanTypeIM:       2,
+		ChanTypeArchived: 2,
+	}
+	var gotTypes []string
+	for _, g := range got {
+		gotTypes = append(gotTypes, g.Type)
+		assert.Equalf(t, wantCounts[g.Type], g.Count, "count for %s", g.Type)
+		assert.Lenf(t, g.Channels, g.Count, "channels for %s", g.Type)
+	}
+	assert.Equal(t, chanGroupOrder, gotTypes)
+	assert.Equal(t, "GPRIV", got[1].Channels[0].ID)
+	assert.Equal(t, []string{"CARCH", "GARCH"}, []string{got[4].Channels[0].ID, got[4].Channels[1].ID})
+}
+
+func TestChannels_FilterArchived(t *testing.T) {
+	ids := func(cs Channels) []string {
+		var ret []string
+		for _, ch := range cs {
+			ret = append(ret, ch.ID)
+		}
+		return ret
+	}
+	assert.Len(t, testMixedChannels.FilterArchived(ArchivedAny), len(testMixedChannels))
+	assert.Equal(t, []string{"CARCH", "GARCH"}, ids(testMixedChannels.FilterArchived(ArchivedOnly)))
+	assert.Equal(t, []string{"CPUB1", "CPUB2", "GPRIV", "GMPIM", "DIM01", "DIM02"}, ids(testMixedChannels.FilterArchived(ActiveOnly)))
+}
+
+func TestChannels_GroupByType_empty(t *testing.T) {
+	got := Channels{}.GroupByType()
+	assert.Len(t, got, len(chanGroupOrder))
+	for _, g := range got {
+		assert.Zero(t, g.Count)
+		assert.NotNil(t, g.Channels)
+	}
+}
+
+func TestChannelGroups_ToText(t *testing.T) {
+	cs := Channels{
+		testChannel("CPUB1", func(ch *slack.Channel) {}),
+		testChannel("CARCH", func(ch *slack.Channel) { ch.IsArchived = true }),
+	}
+	var buf bytes.Buffer
+	if err := cs.GroupByType().ToText(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "Type      Count\n" +
+		"public    1\n" +
+		"private   0\n" +
+		"mpim      0\n" +
+		"im        0\n" +
+		"archived  1\n" +
+		"total     2\n" +
+		"\npublic (1):\n" +
+		"ID     Arch  Saved  What\n" +
+		"CPUB1  -     -      #CPUB1\n" +
+		"\narchived (1):\n" +
+		"ID     Arch  Saved  What\n" +
+		"CARCH  arch  -      #CARCH\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestChannelGroups_ToTextColumns(t *testing.T) {
+	cs := Channels{
+		testChannel("CPUB1", func(ch *slack.Channel) {}),
+	}
+	var buf bytes.Buffer
+	if err := cs.GroupByType().ToTextColumns(&buf, nil, []string{"id", "name"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Type      Count\n" +
+		"public    1\n" +
+		"private   0\n" +
+		"mpim      0\n" +
+		"im        0\n" +
+		"archived  0\n" +
+		"total     1\n" +
+		"\npublic (1):\n" +
+		"ID     What\n" +
+		"CPUB1  #CPUB1\n"
+	assert.Equal(t, want, buf.String())
+
+	if err := cs.GroupByType().ToTextColumns(&buf, nil, []string{"members"}); err == nil {
+		t.Error("expected an error for the unknown column")
+	}
+}
+
+func TestChannels_ToCSV(t *testing.T) {
+	cs := Channels{
+		testChannel("CPUB1", func(ch *slack.Channel) { ch.Name = "general, main"; ch.NumMembers = 42 }),
+		testChannel("GARCH", func(ch *slack.Channel) { ch.Name = `"quoted"`; ch.IsArchived = true; ch.IsPrivate = true }),
+		testChannel("DIM01", func(ch *slack.Channel) { ch.IsIM = true; ch.User = "U01" }),
+	}
+	ui := Users{{ID: "U01", Name: "alice"}}.IndexByID()
+	var buf bytes.Buffer
+	if err := cs.ToCSV(&buf, ui); err != nil {
+		t.Fatal(err)
+	}
+	want := "ID,Name,Type,IsArchived,MemberCount\n" +
+		"CPUB1,\"general, main\",public,false,42\n" +
+		"GARCH,\"\"\"quoted\"\"\",private,true,0\n" +
+		"DIM01,alice,im,false,0\n"
+	assert.Equal(t, want, buf.String())
+}
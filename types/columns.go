@@ -0,0 +1,40 @@
+package types
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// In this file: the column selection of the text output of the lists.
+
+// CheckColumns returns an error if any of the columns cols is not one of the
+// known columns.
+func CheckColumns(cols []string, known []string) error {
+	for _, col := range cols {
+		if !contains(known, col) {
+			return fmt.Errorf("unknown column %q, must be one of %v", col, known)
+		}
+	}
+	return nil
+}
+
+// writeRow writes the values of the columns cols from the row to w, separated
+// by tabs.  The missing values are written as empty strings.
+func writeRow(w io.Writer, cols []string, row map[string]string) error {
+	values := make([]string, len(cols))
+	for i, col := range cols {
+		values[i] = row[col]
+	}
+	_, err := fmt.Fprintln(w, strings.Join(values, "\t"))
+	return err
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -42,15 +42,36 @@ func (c Conversation) IsThread() bool {
 	return c.ThreadTS != ""
 }
 
-// ToText outputs Messages m to io.Writer w in text format.
+// MessageCount returns the number of messages in the conversation, including
+// the thread replies.
+func (c Conversation) MessageCount() int {
+	n := len(c.Messages)
+	for i := range c.Messages {
+		n += len(c.Messages[i].ThreadReplies)
+	}
+	return n
+}
+
+// ToText outputs Messages m to io.Writer w in text format.  Timestamps are
+// rendered in UTC.
 func (c Conversation) ToText(w io.Writer, userIdx structures.UserIndex) (err error) {
+	return c.ToTextIn(w, userIdx, time.UTC)
+}
+
+// ToTextIn outputs Messages m to io.Writer w in text format, rendering the
+// timestamps in the location loc.  If loc is nil, UTC is used.  The location
+// only affects how the time is displayed, not how the messages are grouped.
+func (c Conversation) ToTextIn(w io.Writer, userIdx structures.UserIndex, loc *time.Location) (err error) {
+	if loc == nil {
+		loc = time.UTC
+	}
 	buf := bufio.NewWriter(w)
 	defer buf.Flush()
 
-	return generateText(w, c.Messages, "", userIdx)
+	return generateText(w, c.Messages, "", userIdx, loc)
 }
 
-func generateText(w io.Writer, m []Message, prefix string, userIdx structures.UserIndex) error {
+func generateText(w io.Writer, m []Message, prefix string, userIdx structures.UserIndex, loc *time.Location) error {
 	var (
 		prevMsg  Message
 		prevTime time.Time
@@ -66,12 +87,12 @@ func generateText(w io.Writer, m []Message, prefix string, userIdx structures.Us
 		} else {
 			fmt.Fprintf(w, prefix+"\n"+prefix+"> %s [%s] @ %s:\n%s\n",
 				userIdx.Sender(&message.Message), message.User,
-				t.Format(textTimeFmt),
+				t.In(loc).Format(textTimeFmt),
 				prefix+html.UnescapeString(message.Text),
 			)
 		}
 		if len(message.ThreadReplies) > 0 {
-			if err := generateText(w, message.ThreadReplies, "|   ", userIdx); err != nil {
+			if err := generateText(w, message.ThreadReplies, "|   ", userIdx, loc); err != nil {
 				return err
 			}
 		}
@@ -3,6 +3,7 @@ package types
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/rusq/slackdump/v2/internal/structures"
 	"github.com/slack-go/slack"
@@ -82,7 +83,7 @@ func Test_generateText(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			w := &bytes.Buffer{}
-			if err := generateText(w, tt.args.m, tt.args.prefix, tt.args.userIdx); (err != nil) != tt.wantErr {
+			if err := generateText(w, tt.args.m, tt.args.prefix, tt.args.userIdx, time.UTC); (err != nil) != tt.wantErr {
 				t.Errorf("Session.generateText() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
@@ -92,3 +93,39 @@ func Test_generateText(t *testing.T) {
 	}
 
 }
+
+func TestConversation_ToTextIn(t *testing.T) {
+	c := Conversation{ID: "C123", Messages: []Message{testMsg1}}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %s", err)
+	}
+	tests := []struct {
+		name  string
+		loc   *time.Location
+		wantW string
+	}{
+		{"utc", time.UTC, "\n> U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nTest message < > < >\n"},
+		{"nil is utc", nil, "\n> U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nTest message < > < >\n"},
+		{"tokyo", tokyo, "\n> U10H7D9RR [U10H7D9RR] @ 03/12/2021 11:15:51 +0900:\nTest message < > < >\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			if err := c.ToTextIn(&w, nil, tt.loc); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantW, w.String())
+		})
+	}
+}
+
+func TestConversation_MessageCount(t *testing.T) {
+	c := Conversation{Messages: []Message{testMsg1, testMsg2, testMsg4t}}
+	if got := c.MessageCount(); got != 4 {
+		t.Errorf("MessageCount() = %d, want 4", got)
+	}
+	if got := (Conversation{}).MessageCount(); got != 0 {
+		t.Errorf("MessageCount() on empty conversation = %d, want 0", got)
+	}
+}
@@ -12,15 +12,20 @@ import (
 type Message struct {
 	slack.Message
 	ThreadReplies []Message `json:"slackdump_thread_replies,omitempty"`
+	// ThreadFile is the name of the file with the thread of this message,
+	// if the threads are written to the separate files, in which case the
+	// ThreadReplies are empty.
+	ThreadFile string `json:"slackdump_thread_file,omitempty"`
 }
 
 func (m Message) Datetime() (time.Time, error) {
 	return structures.ParseSlackTS(m.Timestamp)
 }
 
-// IsBotMessage returns true if the message is from a bot.
+// IsBotMessage returns true if the message is from a bot or an app, i.e. an
+// integration post.
 func (m Message) IsBotMessage() bool {
-	return m.Msg.BotID != ""
+	return m.Msg.BotID != "" || m.Msg.SubType == slack.MsgSubTypeBotMessage
 }
 
 func (m Message) IsThread() bool {
@@ -1,9 +1,11 @@
 package types
 
 import (
+	"encoding/csv"
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
 	"text/tabwriter"
 
 	"github.com/slack-go/slack"
@@ -14,17 +16,50 @@ import (
 // Users is a slice of users.
 type Users []slack.User
 
+// UserColumns are the columns of the users text output, in the default
+// order.
+var UserColumns = []string{"name", "id", "bot", "deleted", "restricted"}
+
+// UserExtraColumns are the optional columns of the users text output, that
+// are not output by default.  The "presence" column is empty, unless the
+// presence was fetched with Session.GetUsersPresence.
+var UserExtraColumns = []string{"tz", "tz_offset", "presence"}
+
+// userHeader is the header of the users text output.
+var userHeader = map[string]string{
+	"name": "Name", "id": "ID", "bot": "Bot?", "deleted": "Deleted?", "restricted": "Restricted?",
+	"tz": "Time Zone", "tz_offset": "UTC Offset", "presence": "Presence",
+}
+
+// AllUserColumns returns all the columns that can be selected for the users
+// text output.
+func AllUserColumns() []string {
+	return append(append([]string{}, UserColumns...), UserExtraColumns...)
+}
+
 // ToText outputs Users us to io.Writer w in Text format
-func (us Users) ToText(w io.Writer, _ structures.UserIndex) error {
-	const strFormat = "%s\t%s\t%s\t%s\t%s\n"
+func (us Users) ToText(w io.Writer, ui structures.UserIndex) error {
+	return us.ToTextColumns(w, ui, nil)
+}
+
+// ToTextColumns outputs Users us to w in text format, including only the
+// columns cols, see AllUserColumns.  If cols is empty, the UserColumns are
+// output.
+func (us Users) ToTextColumns(w io.Writer, _ structures.UserIndex, cols []string) error {
+	if len(cols) == 0 {
+		cols = UserColumns
+	}
+	if err := CheckColumns(cols, AllUserColumns()); err != nil {
+		return err
+	}
 	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
 	defer writer.Flush()
 
 	// header
-	if _, err := fmt.Fprintf(writer, strFormat, "Name", "ID", "Bot?", "Deleted?", "Restricted?"); err != nil {
+	if err := writeRow(writer, cols, userHeader); err != nil {
 		return fmt.Errorf("writer error: %w", err)
 	}
-	if _, err := fmt.Fprintf(writer, strFormat, "", "", "", "", ""); err != nil {
+	if err := writeRow(writer, cols, nil); err != nil {
 		return fmt.Errorf("writer error: %w", err)
 	}
 
@@ -55,9 +90,10 @@ func (us Users) ToText(w io.Writer, _ structures.UserIndex) error {
 			restricted = "restricted"
 		}
 
-		_, err := fmt.Fprintf(writer, strFormat,
-			name, usermap[name].ID, bot, deleted, restricted,
-		)
+		err := writeRow(writer, cols, map[string]string{
+			"name": name, "id": usermap[name].ID, "bot": bot, "deleted": deleted, "restricted": restricted,
+			"tz": usermap[name].TZ, "tz_offset": tzOffset(usermap[name]), "presence": usermap[name].Presence,
+		})
 		if err != nil {
 			return fmt.Errorf("writer error: %w", err)
 		}
@@ -65,6 +101,36 @@ func (us Users) ToText(w io.Writer, _ structures.UserIndex) error {
 	return nil
 }
 
+// tzOffset returns the UTC offset of the user time zone in "+hh:mm" format,
+// or an empty string, if the user has no time zone set.
+func tzOffset(u *slack.User) string {
+	if u.TZ == "" {
+		return ""
+	}
+	sign, off := '+', u.TZOffset
+	if off < 0 {
+		sign, off = '-', -off
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, off/3600, off%3600/60)
+}
+
+// ToCSV outputs Users us to io.Writer w in CSV format, with the header row.
+// The columns are: ID, Name, RealName, Email, Deleted.
+func (us Users) ToCSV(w io.Writer, _ structures.UserIndex) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"ID", "Name", "RealName", "Email", "Deleted"}); err != nil {
+		return fmt.Errorf("writer error: %w", err)
+	}
+	for i := range us {
+		u := &us[i]
+		if err := cw.Write([]string{u.ID, u.Name, u.RealName, u.Profile.Email, strconv.FormatBool(u.Deleted)}); err != nil {
+			return fmt.Errorf("writer error: %w", err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
 // IndexByID returns the userID map to relevant *slack.User
 func (us Users) IndexByID() structures.UserIndex {
 	return structures.NewUserIndex(us)
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/rusq/slackdump/v2/internal/fixtures"
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,3 +36,88 @@ func TestUsers_ToText(t *testing.T) {
 		})
 	}
 }
+
+func TestUsers_ToTextColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		cols    []string
+		wantW   string
+		wantErr bool
+	}{
+		{
+			"id only",
+			[]string{"id"},
+			"ID\n\nDELD\nLOL4\nLOL3\nLOL1\n",
+			false,
+		},
+		{
+			"id and name",
+			[]string{"id", "name"},
+			"ID    Name\n      \nDELD  ka\nLOL4  motherfucker\nLOL3  yay\nLOL1  yippi\n",
+			false,
+		},
+		{
+			"unknown column",
+			[]string{"id", "email"},
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			if err := testUsers.ToTextColumns(w, nil, tt.cols); (err != nil) != tt.wantErr {
+				t.Errorf("Users.ToTextColumns() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.wantW, w.String())
+		})
+	}
+}
+
+func TestUsers_ToTextColumns_extra(t *testing.T) {
+	us := Users{
+		{ID: "U01", Name: "alice", TZ: "Europe/London", Presence: "active"},
+		{ID: "U02", Name: "bob", TZ: "America/Los_Angeles", TZOffset: -25200, Presence: "away"},
+		{ID: "U03", Name: "charlie"},
+	}
+	w := &bytes.Buffer{}
+	if err := us.ToTextColumns(w, nil, []string{"name", "tz", "tz_offset", "presence"}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Name     Time Zone            UTC Offset  Presence\n                                          \nalice    Europe/London        +00:00      active\nbob      America/Los_Angeles  -07:00      away\ncharlie                                   \n", w.String())
+}
+
+func Test_tzOffset(t *testing.T) {
+	tests := []struct {
+		name string
+		u    slack.User
+		want string
+	}{
+		{"no time zone", slack.User{}, ""},
+		{"utc", slack.User{TZ: "Europe/London"}, "+00:00"},
+		{"negative", slack.User{TZ: "America/Los_Angeles", TZOffset: -25200}, "-07:00"},
+		{"half hour", slack.User{TZ: "Asia/Kolkata", TZOffset: 19800}, "+05:30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tzOffset(&tt.u))
+		})
+	}
+}
+
+func TestUsers_ToCSV(t *testing.T) {
+	us := Users{
+		{ID: "U01", Name: "alice", RealName: "Alice Liddell"},
+		{ID: "U02", Name: "bob", RealName: "Bob, Jr.", Deleted: true},
+	}
+	us[0].Profile.Email = "alice@example.com"
+	var buf bytes.Buffer
+	if err := us.ToCSV(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "ID,Name,RealName,Email,Deleted\n" +
+		"U01,alice,Alice Liddell,alice@example.com,false\n" +
+		"U02,bob,\"Bob, Jr.\",,true\n"
+	assert.Equal(t, want, buf.String())
+}
@@ -15,6 +15,7 @@ import (
 	"errors"
 
 	"github.com/slack-go/slack"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/rusq/slackdump/v2/internal/encio"
 	"github.com/rusq/slackdump/v2/internal/network"
@@ -55,10 +56,14 @@ func (sd *Session) GetUsers(ctx context.Context) (types.Users, error) {
 func (sd *Session) fetchUsers(ctx context.Context) (types.Users, error) {
 	var (
 		users []slack.User
+		opts  []slack.GetUsersOption
 	)
-	if err := network.WithRetry(ctx, network.NewLimiter(network.Tier2, sd.options.Tier2Burst, int(sd.options.Tier2Boost)), sd.options.Tier2Retries, func() error {
+	if teamID := sd.gridTeamID(); teamID != "" {
+		opts = append(opts, slack.GetUsersOptionTeamID(teamID))
+	}
+	if err := network.WithRetry(ctx, sd.limiter(network.Tier2), sd.options.Tier2Retries, func() error {
 		var err error
-		users, err = sd.client.GetUsersContext(ctx)
+		users, err = sd.client.GetUsersContext(ctx, opts...)
 		return err
 	}); err != nil {
 		trace.Logf(ctx, "error", "GetUsers error=%s", err)
@@ -73,6 +78,123 @@ func (sd *Session) fetchUsers(ctx context.Context) (types.Users, error) {
 	return users, nil
 }
 
+// GetUsersPresence fetches the presence of the users us, and sets the
+// Presence field of each user.  It costs one Tier-2 API call per user, the
+// calls are made concurrently, sharing the Tier-2 limiter.  Deleted users are
+// skipped.  The presence is never cached, as it changes all the time.
+func (sd *Session) GetUsersPresence(ctx context.Context, us types.Users) error {
+	ctx, task := trace.NewTask(ctx, "GetUsersPresence")
+	defer task.End()
+
+	var (
+		lim = sd.limiter(network.Tier2)
+		eg  errgroup.Group
+	)
+	eg.SetLimit(defNumWorkers)
+	for i := range us {
+		u := &us[i]
+		if u.Deleted {
+			continue
+		}
+		eg.Go(func() error {
+			return network.WithRetry(ctx, lim, sd.options.Tier2Retries, func() error {
+				p, err := sd.client.GetUserPresenceContext(ctx, u.ID)
+				if err != nil {
+					return fmt.Errorf("presence of %s: %w", u.ID, err)
+				}
+				u.Presence = p.Presence
+				return nil
+			})
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		trace.Logf(ctx, "error", "GetUsersPresence error=%s", err)
+		return err
+	}
+	return nil
+}
+
+// GetUsersCustomFields fetches the custom profile fields of the users us,
+// and sets the Profile.Fields of each user, with the labels of the fields
+// populated.  The field definitions are fetched once from the team profile,
+// then it costs one Tier-4 API call per user, as Slack does not provide a
+// batch method.  Deleted users and bots are skipped.  If the workspace has no
+// custom fields, or they are not available to the token, the users are not
+// modified, and no error is returned.
+func (sd *Session) GetUsersCustomFields(ctx context.Context, us types.Users) error {
+	ctx, task := trace.NewTask(ctx, "GetUsersCustomFields")
+	defer task.End()
+
+	var tp *slack.TeamProfile
+	if err := network.WithRetry(ctx, sd.limiter(network.Tier3), sd.options.Tier3Retries, func() error {
+		var err error
+		tp, err = sd.client.GetTeamProfileContext(ctx)
+		return err
+	}); err != nil {
+		var ser slack.SlackErrorResponse
+		if !errors.As(err, &ser) {
+			return err
+		}
+		trace.Logf(ctx, "error", "GetTeamProfile error=%s", err)
+		sd.l().Printf("custom profile fields are not available: %s, skipping", err)
+		return nil
+	}
+	labels := make(map[string]string, len(tp.Fields))
+	for _, f := range tp.Fields {
+		labels[f.ID] = f.Label
+	}
+	if len(labels) == 0 {
+		sd.l().Println("workspace has no custom profile fields, skipping")
+		return nil
+	}
+
+	var (
+		lim = sd.limiter(network.Tier4)
+		eg  errgroup.Group
+	)
+	eg.SetLimit(defNumWorkers)
+	for i := range us {
+		u := &us[i]
+		if u.Deleted || u.IsBot {
+			continue
+		}
+		eg.Go(func() error {
+			return network.WithRetry(ctx, lim, sd.options.Tier4Retries, func() error {
+				p, err := sd.client.GetUserProfileContext(ctx, &slack.GetUserProfileParameters{UserID: u.ID})
+				if err != nil {
+					return fmt.Errorf("profile of %s: %w", u.ID, err)
+				}
+				u.Profile.SetFieldsMap(labelFields(p.FieldsMap(), labels))
+				return nil
+			})
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		trace.Logf(ctx, "error", "GetUsersCustomFields error=%s", err)
+		return err
+	}
+	return nil
+}
+
+// labelFields sets the labels of the custom profile fields from the labels
+// map of field ID to the label.  Fields with empty values are dropped.
+func labelFields(fields map[string]slack.UserProfileCustomField, labels map[string]string) map[string]slack.UserProfileCustomField {
+	if len(fields) == 0 {
+		return nil
+	}
+	ret := make(map[string]slack.UserProfileCustomField, len(fields))
+	for id, f := range fields {
+		if f.Value == "" {
+			continue
+		}
+		if f.Label == "" {
+			f.Label = labels[id]
+		}
+		ret[id] = f
+	}
+	return ret
+}
+
 // loadUsers tries to load the users from the file
 func (sd *Session) loadUserCache(filename string, suffix string, maxAge time.Duration) (types.Users, error) {
 	filename = sd.makeCacheFilename(filename, suffix)
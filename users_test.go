@@ -301,3 +301,96 @@ func FuzzFilenameSplit(f *testing.F) {
 		assert.Equal(t, input, joined)
 	})
 }
+
+func TestSession_GetUsersPresence(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		us := types.Users{
+			{ID: "U01", Name: "alice"},
+			{ID: "U02", Name: "bob"},
+			{ID: "U03", Name: "charlie", Deleted: true},
+		}
+		mc := newmockClienter(gomock.NewController(t))
+		mc.EXPECT().GetUserPresenceContext(gomock.Any(), "U01").Return(&slack.UserPresence{Presence: "active"}, nil)
+		mc.EXPECT().GetUserPresenceContext(gomock.Any(), "U02").Return(&slack.UserPresence{Presence: "away"}, nil)
+
+		sd := &Session{client: mc, options: DefOptions}
+		if err := sd.GetUsersPresence(context.Background(), us); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "active", us[0].Presence)
+		assert.Equal(t, "away", us[1].Presence)
+		assert.Equal(t, "", us[2].Presence)
+	})
+	t.Run("api error", func(t *testing.T) {
+		us := types.Users{{ID: "U01", Name: "alice"}}
+		mc := newmockClienter(gomock.NewController(t))
+		mc.EXPECT().GetUserPresenceContext(gomock.Any(), "U01").Return(nil, errors.New("not_authed"))
+
+		sd := &Session{client: mc, options: DefOptions}
+		if err := sd.GetUsersPresence(context.Background(), us); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
+
+func TestSession_GetUsersCustomFields(t *testing.T) {
+	teamProfile := &slack.TeamProfile{Fields: []slack.TeamProfileField{
+		{ID: "Xf01", Label: "Team"},
+		{ID: "Xf02", Label: "Location"},
+	}}
+	t.Run("ok", func(t *testing.T) {
+		us := types.Users{
+			{ID: "U01", Name: "alice"},
+			{ID: "U02", Name: "bot", IsBot: true},
+			{ID: "U03", Name: "charlie", Deleted: true},
+		}
+		var p slack.UserProfile
+		p.SetFieldsMap(map[string]slack.UserProfileCustomField{
+			"Xf01": {Value: "Platform"},
+			"Xf02": {Value: ""},
+		})
+		mc := newmockClienter(gomock.NewController(t))
+		mc.EXPECT().GetTeamProfileContext(gomock.Any()).Return(teamProfile, nil)
+		mc.EXPECT().GetUserProfileContext(gomock.Any(), &slack.GetUserProfileParameters{UserID: "U01"}).Return(&p, nil)
+
+		sd := &Session{client: mc, options: DefOptions}
+		if err := sd.GetUsersCustomFields(context.Background(), us); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, map[string]slack.UserProfileCustomField{"Xf01": {Value: "Platform", Label: "Team"}}, us[0].Profile.FieldsMap())
+		assert.Equal(t, 0, us[1].Profile.Fields.Len())
+		assert.Equal(t, 0, us[2].Profile.Fields.Len())
+	})
+	t.Run("no custom fields", func(t *testing.T) {
+		us := types.Users{{ID: "U01", Name: "alice"}}
+		mc := newmockClienter(gomock.NewController(t))
+		mc.EXPECT().GetTeamProfileContext(gomock.Any()).Return(&slack.TeamProfile{}, nil)
+
+		sd := &Session{client: mc, options: DefOptions}
+		if err := sd.GetUsersCustomFields(context.Background(), us); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 0, us[0].Profile.Fields.Len())
+	})
+	t.Run("team profile unavailable", func(t *testing.T) {
+		us := types.Users{{ID: "U01", Name: "alice"}}
+		mc := newmockClienter(gomock.NewController(t))
+		mc.EXPECT().GetTeamProfileContext(gomock.Any()).Return(nil, slack.SlackErrorResponse{Err: "missing_scope"})
+
+		sd := &Session{client: mc, options: DefOptions}
+		if err := sd.GetUsersCustomFields(context.Background(), us); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("user profile error", func(t *testing.T) {
+		us := types.Users{{ID: "U01", Name: "alice"}}
+		mc := newmockClienter(gomock.NewController(t))
+		mc.EXPECT().GetTeamProfileContext(gomock.Any()).Return(teamProfile, nil)
+		mc.EXPECT().GetUserProfileContext(gomock.Any(), gomock.Any()).Return(nil, errors.New("not_authed"))
+
+		sd := &Session{client: mc, options: DefOptions}
+		if err := sd.GetUsersCustomFields(context.Background(), us); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}